@@ -0,0 +1,277 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultPollInterval is how often the context-aware methods read the state of
+// the asynchronous job they started, unless WithPollInterval says otherwise.
+const DefaultPollInterval = 250 * time.Millisecond
+
+// jobPollInterval is the default poll interval; tests shorten it.
+var jobPollInterval = DefaultPollInterval
+
+// historyPageSize is the number of entries QueryHistoryContext reads per
+// QueryHistory call, between which it checks its context.
+var historyPageSize int32 = 100
+
+// SearchAbortedError is returned by SearchContext when its context is done
+// before the search completes. It unwraps to the context error, so
+// errors.Is(err, context.DeadlineExceeded) holds after a timeout.
+type SearchAbortedError struct {
+	// Err is ctx.Err(), joined with the error of RequestAbort if that failed.
+	Err error
+	// Result is what EndSearch returned after RequestAbort, usually with
+	// ResultCode OperationResultCodeOrcAborted and the updates found so far;
+	// nil if EndSearch failed.
+	Result *ISearchResult
+}
+
+func (e *SearchAbortedError) Error() string {
+	return fmt.Sprintf("windowsupdate: search aborted: %v", e.Err)
+}
+
+func (e *SearchAbortedError) Unwrap() error {
+	return e.Err
+}
+
+// HistoryCanceledError is returned by QueryHistoryContext when its context is
+// done before every entry was read. It unwraps to the context error.
+type HistoryCanceledError struct {
+	// Err is ctx.Err().
+	Err error
+	// Entries are the entries read before the cancellation, in order.
+	Entries []*IUpdateHistoryEntry
+}
+
+func (e *HistoryCanceledError) Error() string {
+	return fmt.Sprintf("windowsupdate: history query canceled after %d entries: %v", len(e.Entries), e.Err)
+}
+
+func (e *HistoryCanceledError) Unwrap() error {
+	return e.Err
+}
+
+// DownloadAbortedError is returned by DownloadContext when its context is done
+// before the download completes. It unwraps to the context error.
+type DownloadAbortedError struct {
+	// Err is ctx.Err(), joined with the error of RequestAbort if that failed.
+	Err error
+	// Result is what EndDownload returned after RequestAbort, with the
+	// per-update results of the updates processed so far; nil if EndDownload
+	// failed.
+	Result *IDownloadResult
+}
+
+func (e *DownloadAbortedError) Error() string {
+	return fmt.Sprintf("windowsupdate: download aborted: %v", e.Err)
+}
+
+func (e *DownloadAbortedError) Unwrap() error {
+	return e.Err
+}
+
+// InstallationAbortedError is returned by InstallContext and UninstallContext
+// when their context is done before the operation completes. It unwraps to the
+// context error.
+type InstallationAbortedError struct {
+	// Err is ctx.Err(), joined with the error of RequestAbort if that failed.
+	Err error
+	// Result is what EndInstall or EndUninstall returned after RequestAbort;
+	// nil if it failed.
+	Result *IInstallationResult
+}
+
+func (e *InstallationAbortedError) Error() string {
+	return fmt.Sprintf("windowsupdate: installation aborted: %v", e.Err)
+}
+
+func (e *InstallationAbortedError) Unwrap() error {
+	return e.Err
+}
+
+// Progress is a snapshot of an asynchronous download or installation.
+type Progress struct {
+	// PercentComplete is the progress of the whole operation.
+	PercentComplete int32
+	// CurrentUpdateIndex is the index, in the updates passed to the operation,
+	// of the update being processed.
+	CurrentUpdateIndex           int32
+	CurrentUpdatePercentComplete int32
+
+	// The byte counts and DownloadPhase are only reported by downloads.
+	CurrentUpdateBytesDownloaded int64
+	CurrentUpdateBytesToDownload int64
+	TotalBytesDownloaded         int64
+	TotalBytesToDownload         int64
+	DownloadPhase                DownloadPhase
+}
+
+// JobOption configures the context-aware asynchronous operations such as
+// SearchContext and DownloadContext.
+type JobOption func(*jobOptions)
+
+type jobOptions struct {
+	pollInterval time.Duration
+	onProgress   func(Progress)
+	progress     chan<- Progress
+	callbacks    JobCallbacks
+	stream       bool
+	// completed is signaled by the completion callback so that runJob does
+	// not wait for the next poll; nil unless set by notifyCompleted.
+	completed chan struct{}
+}
+
+// WithPollInterval sets how often the job is polled, DefaultPollInterval by
+// default.
+func WithPollInterval(interval time.Duration) JobOption {
+	return func(o *jobOptions) {
+		o.pollInterval = interval
+	}
+}
+
+// WithProgressFunc calls fn, on the calling goroutine, with each new progress
+// snapshot of a download or installation.
+func WithProgressFunc(fn func(Progress)) JobOption {
+	return func(o *jobOptions) {
+		o.onProgress = fn
+	}
+}
+
+// WithProgress sends each new progress snapshot of a download or installation
+// to ch, blocking until it is received or the context is done, and closes ch
+// when DownloadContext, InstallContext or UninstallContext returns.
+func WithProgress(ch chan<- Progress) JobOption {
+	return func(o *jobOptions) {
+		o.progress = ch
+	}
+}
+
+// WithStreamedUpdates makes a search leave its updates unconverted, as
+// IUpdateSearcher.SearchStream does, for ISearchResult.All to convert them
+// one at a time. Other operations ignore it.
+func WithStreamedUpdates() JobOption {
+	return func(o *jobOptions) {
+		o.stream = true
+	}
+}
+
+func newJobOptions(options []JobOption) jobOptions {
+	o := jobOptions{pollInterval: jobPollInterval}
+	for _, option := range options {
+		option(&o)
+	}
+	if o.pollInterval <= 0 {
+		o.pollInterval = jobPollInterval
+	}
+	return o
+}
+
+// notifyCompleted makes the completion callback of the job signal o.completed,
+// in addition to calling the Completed handler set with WithCallbacks.
+func (o *jobOptions) notifyCompleted() {
+	completed := make(chan struct{}, 1)
+	handler := o.callbacks.Completed
+	o.callbacks.Completed = func() {
+		if handler != nil {
+			handler()
+		}
+		select {
+		case completed <- struct{}{}:
+		default:
+		}
+	}
+	o.completed = completed
+}
+
+// beginOptions returns the options to pass to BeginXxx.
+func (o jobOptions) beginOptions() []JobOption {
+	options := []JobOption{WithCallbacks(o.callbacks)}
+	if o.stream {
+		options = append(options, WithStreamedUpdates())
+	}
+	return options
+}
+
+// done closes the progress channel, if any; the methods taking JobOptions
+// defer it.
+func (o jobOptions) done() {
+	if o.progress != nil {
+		close(o.progress)
+	}
+}
+
+// report passes p to the progress function and channel, if any. Sending on the
+// channel blocks until it is received or ctx is done.
+func (o jobOptions) report(ctx context.Context, p Progress) {
+	if o.onProgress != nil {
+		o.onProgress(p)
+	}
+	if o.progress != nil {
+		select {
+		case o.progress <- p:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// asyncJob is the part of ISearchJob, IDownloadJob and IInstallationJob runJob
+// drives.
+type asyncJob interface {
+	GetIsCompleted() (bool, error)
+	RequestAbort() error
+}
+
+// runJob polls job until it completes, reading progress, if not nil, on each
+// poll and reporting it to the options whenever it changed. A signal on
+// o.completed triggers the next poll right away. If ctx is done first it
+// requests the job to abort and returns ctx.Err(), joined with the error of
+// RequestAbort if that failed, in which case the job may still be running. If
+// polling fails it also aborts and returns the error.
+func runJob(ctx context.Context, job asyncJob, o jobOptions, progress func() (Progress, error)) error {
+	var last *Progress
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+	for {
+		completed, err := job.GetIsCompleted()
+		if err == nil && progress != nil && (o.onProgress != nil || o.progress != nil) {
+			var p Progress
+			if p, err = progress(); err == nil && (last == nil || p != *last) {
+				last = &p
+				o.report(ctx, p)
+			}
+		}
+		if err != nil {
+			job.RequestAbort()
+			return err
+		}
+		if completed {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if err := job.RequestAbort(); err != nil {
+				return errors.Join(ctx.Err(), err)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		case <-o.completed:
+		}
+	}
+}
@@ -0,0 +1,537 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// setJobPollInterval shortens the polling of asynchronous jobs for a test.
+func setJobPollInterval(t *testing.T, interval time.Duration) {
+	t.Helper()
+	saved := jobPollInterval
+	jobPollInterval = interval
+	t.Cleanup(func() { jobPollInterval = saved })
+}
+
+// newFakeSearchJob returns a fake ISearchJob that completes after polls reads
+// of IsCompleted, or never if polls is negative. Aborting it completes it.
+func newFakeSearchJob(polls int) (*fakeDispatcher, *atomic.Bool) {
+	var aborted atomic.Bool
+	var reads int
+	job := newFakeDispatcher(map[string]interface{}{
+		"IsCompleted": fakeMember(func(params ...interface{}) (interface{}, error) {
+			reads++
+			return aborted.Load() || (polls >= 0 && reads > polls), nil
+		}),
+		"CleanUp": nil,
+		"RequestAbort": fakeMember(func(params ...interface{}) (interface{}, error) {
+			aborted.Store(true)
+			return nil, nil
+		}),
+	})
+	return job, &aborted
+}
+
+func newFakeAsyncSearcher(job *fakeDispatcher, resultCode OperationResultCode) *IUpdateSearcher {
+	return &IUpdateSearcher{disp: newFakeDispatcher(map[string]interface{}{
+		"BeginSearch": job,
+		"EndSearch": newFakeDispatcher(map[string]interface{}{
+			"ResultCode":     resultCode,
+			"RootCategories": newFakeCollection(),
+			"Updates":        newFakeCollection(newFakeUpdate(nil)),
+			"Warnings":       newFakeCollection(),
+		}),
+	})}
+}
+
+func countCalls(d *fakeDispatcher, call string) int {
+	n := 0
+	for _, c := range d.Calls() {
+		if c == call {
+			n++
+		}
+	}
+	return n
+}
+
+func TestIUpdateSearcher_SearchContext(t *testing.T) {
+	setJobPollInterval(t, time.Millisecond)
+	job, _ := newFakeSearchJob(3)
+	searcher := newFakeAsyncSearcher(job, OperationResultCodeOrcSucceeded)
+
+	result, err := searcher.SearchContext(context.Background(), "IsInstalled=0")
+	if err != nil {
+		t.Fatalf("SearchContext failed: %v", err)
+	}
+	if result.ResultCode != OperationResultCodeOrcSucceeded || len(result.Updates) != 1 {
+		t.Errorf("result = %+v", result)
+	}
+	if n := countCalls(job, "GetProperty IsCompleted"); n < 4 {
+		t.Errorf("IsCompleted read %d times, want at least 4", n)
+	}
+	if countCalls(job, "CallMethod RequestAbort") != 0 || countCalls(job, "CallMethod CleanUp") != 1 {
+		t.Errorf("job calls = %v, want a single CleanUp", job.Calls())
+	}
+}
+
+func TestIUpdateSearcher_SearchContext_Deadline(t *testing.T) {
+	setJobPollInterval(t, time.Millisecond)
+	job, aborted := newFakeSearchJob(-1)
+	searcher := newFakeAsyncSearcher(job, OperationResultCodeOrcAborted)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result, err := searcher.SearchContext(ctx, "IsInstalled=0")
+	if result != nil {
+		t.Errorf("result = %+v, want nil", result)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SearchContext error = %v, want context.DeadlineExceeded", err)
+	}
+	var abortedErr *SearchAbortedError
+	if !errors.As(err, &abortedErr) || abortedErr.Result == nil || abortedErr.Result.ResultCode != OperationResultCodeOrcAborted {
+		t.Errorf("SearchContext error = %#v, want a *SearchAbortedError with the aborted result", err)
+	}
+	if !aborted.Load() || countCalls(job, "CallMethod CleanUp") != 1 {
+		t.Errorf("job calls = %v, want RequestAbort and CleanUp", job.Calls())
+	}
+}
+
+func TestIUpdateSearcher_SearchContext_Errors(t *testing.T) {
+	setJobPollInterval(t, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job, _ := newFakeSearchJob(0)
+	if _, err := newFakeAsyncSearcher(job, OperationResultCodeOrcSucceeded).SearchContext(ctx, "IsInstalled=0"); err != context.Canceled {
+		t.Errorf("SearchContext with a canceled context error = %v, want context.Canceled", err)
+	}
+	if len(job.Calls()) != 0 {
+		t.Errorf("a search was started with a canceled context: %v", job.Calls())
+	}
+
+	testErr := errors.New("RPC server unavailable")
+	reads := 0
+	job = newFakeDispatcher(map[string]interface{}{
+		// BeginSearch reads IsCompleted once; polling fails.
+		"IsCompleted": fakeMember(func(params ...interface{}) (interface{}, error) {
+			if reads++; reads > 1 {
+				return nil, testErr
+			}
+			return false, nil
+		}),
+		"CleanUp":      nil,
+		"RequestAbort": nil,
+	})
+	if _, err := newFakeAsyncSearcher(job, OperationResultCodeOrcSucceeded).SearchContext(context.Background(), "IsInstalled=0"); err != testErr {
+		t.Errorf("SearchContext error = %v, want %v", err, testErr)
+	}
+	if countCalls(job, "CallMethod RequestAbort") != 1 || countCalls(job, "CallMethod CleanUp") != 1 {
+		t.Errorf("job calls = %v, want RequestAbort and CleanUp", job.Calls())
+	}
+}
+
+func TestIUpdateSearcher_SearchContext_AbortFails(t *testing.T) {
+	setJobPollInterval(t, time.Millisecond)
+	abortErr := errors.New("RPC server unavailable")
+	job := newFakeDispatcher(map[string]interface{}{
+		"IsCompleted":  false,
+		"CleanUp":      nil,
+		"RequestAbort": abortErr,
+	})
+	searcher := newFakeAsyncSearcher(job, OperationResultCodeOrcAborted)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := searcher.SearchContext(ctx, "IsInstalled=0")
+	var abortedErr *SearchAbortedError
+	if !errors.As(err, &abortedErr) || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, abortErr) {
+		t.Fatalf("SearchContext error = %v, want a *SearchAbortedError with the deadline and the RequestAbort error", err)
+	}
+	if abortedErr.Result == nil || countCalls(job, "CallMethod CleanUp") != 1 {
+		t.Errorf("Result = %v, job calls = %v, want EndSearch and CleanUp after the failed abort", abortedErr.Result, job.Calls())
+	}
+}
+
+func newFakeHistoryEntry(title string) *fakeDispatcher {
+	return newFakeDispatcher(map[string]interface{}{
+		"ClientApplicationID": "",
+		"Date":                time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC),
+		"Description":         "",
+		"HResult":             int32(0),
+		"Operation":           UpdateOperationUoInstallation,
+		"ResultCode":          OperationResultCodeOrcSucceeded,
+		"ServerSelection":     ServerSelectionSsWindowsUpdate,
+		"ServiceID":           "",
+		"SupportUrl":          "",
+		"Title":               title,
+		"UninstallationNotes": "",
+		"UninstallationSteps": newFakeCollection(),
+		"UnmappedResultCode":  int32(0),
+		"UpdateIdentity":      newFakeDispatcher(map[string]interface{}{"RevisionNumber": int32(1), "UpdateID": title}),
+	})
+}
+
+// newFakeHistorySearcher returns a searcher with total history entries that
+// calls onPage after each QueryHistory.
+func newFakeHistorySearcher(total int, onPage func()) (*IUpdateSearcher, *[][2]int32) {
+	var pages [][2]int32
+	return &IUpdateSearcher{disp: newFakeDispatcher(map[string]interface{}{
+		"GetTotalHistoryCount": int32(total),
+		"QueryHistory": fakeMember(func(params ...interface{}) (interface{}, error) {
+			start, count := params[0].(int32), params[1].(int32)
+			pages = append(pages, [2]int32{start, count})
+			var entries []interface{}
+			for i := start; i < start+count && int(i) < total; i++ {
+				entries = append(entries, newFakeHistoryEntry(fmt.Sprint(i)))
+			}
+			onPage()
+			return newFakeCollection(entries...), nil
+		}),
+	})}, &pages
+}
+
+func TestIUpdateSearcher_QueryHistoryContext(t *testing.T) {
+	saved := historyPageSize
+	historyPageSize = 10
+	defer func() { historyPageSize = saved }()
+
+	searcher, pages := newFakeHistorySearcher(25, func() {})
+	entries, err := searcher.QueryHistoryContext(context.Background(), 0, -1)
+	if err != nil {
+		t.Fatalf("QueryHistoryContext failed: %v", err)
+	}
+	if len(entries) != 25 || entries[24].Title != "24" {
+		t.Errorf("read %d entries", len(entries))
+	}
+	if want := [][2]int32{{0, 10}, {10, 10}, {20, 5}}; fmt.Sprint(*pages) != fmt.Sprint(want) {
+		t.Errorf("pages = %v, want %v", *pages, want)
+	}
+
+	searcher, pages = newFakeHistorySearcher(25, func() {})
+	entries, err = searcher.QueryHistoryContext(context.Background(), 5, 12)
+	if err != nil || len(entries) != 12 || entries[0].Title != "5" {
+		t.Errorf("QueryHistoryContext(5, 12) = %d entries, %v", len(entries), err)
+	}
+	if want := [][2]int32{{5, 10}, {15, 2}}; fmt.Sprint(*pages) != fmt.Sprint(want) {
+		t.Errorf("pages = %v, want %v", *pages, want)
+	}
+
+	searcher, pages = newFakeHistorySearcher(25, func() {})
+	entries, err = searcher.QueryHistoryContext(context.Background(), 20, math.MaxInt32)
+	if err != nil || len(entries) != 5 || entries[0].Title != "20" {
+		t.Errorf("QueryHistoryContext(20, MaxInt32) = %d entries, %v", len(entries), err)
+	}
+
+	if _, err := searcher.QueryHistoryContext(context.Background(), -1, 10); err == nil {
+		t.Error("QueryHistoryContext with a negative start index succeeded")
+	}
+}
+
+func TestIUpdateSearcher_QueryHistoryContext_Canceled(t *testing.T) {
+	saved := historyPageSize
+	historyPageSize = 10
+	defer func() { historyPageSize = saved }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	searcher, pages := newFakeHistorySearcher(25, cancel)
+	entries, err := searcher.QueryHistoryContext(ctx, 0, -1)
+	if entries != nil {
+		t.Errorf("entries = %v, want nil", entries)
+	}
+	var canceled *HistoryCanceledError
+	if !errors.As(err, &canceled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("QueryHistoryContext error = %v, want a *HistoryCanceledError", err)
+	}
+	if len(canceled.Entries) != 10 || len(*pages) != 1 {
+		t.Errorf("%d partial entries after %d pages, want 10 after 1", len(canceled.Entries), len(*pages))
+	}
+}
+
+// fakeJob is an asyncJob that completes after polls polls, or never if polls
+// is negative, reporting the poll number as PercentComplete.
+type fakeJob struct {
+	polls    int
+	reads    int
+	aborts   int
+	abortErr error
+}
+
+func (j *fakeJob) GetIsCompleted() (bool, error) {
+	j.reads++
+	return j.polls >= 0 && j.reads > j.polls, nil
+}
+
+func (j *fakeJob) RequestAbort() error {
+	j.aborts++
+	return j.abortErr
+}
+
+func (j *fakeJob) progress() (Progress, error) {
+	return Progress{PercentComplete: int32(min(j.reads, 3))}, nil
+}
+
+func TestRunJob_Progress(t *testing.T) {
+	job := &fakeJob{polls: 4}
+	ch := make(chan Progress, 10)
+	var called []int32
+	o := newJobOptions([]JobOption{
+		WithPollInterval(time.Millisecond),
+		WithProgress(ch),
+		WithProgressFunc(func(p Progress) { called = append(called, p.PercentComplete) }),
+	})
+	if err := runJob(context.Background(), job, o, job.progress); err != nil {
+		t.Fatalf("runJob failed: %v", err)
+	}
+	o.done()
+
+	var sent []int32
+	for p := range ch {
+		sent = append(sent, p.PercentComplete)
+	}
+	// Unchanged progress is reported once.
+	if want := "[1 2 3]"; fmt.Sprint(sent) != want || fmt.Sprint(called) != want {
+		t.Errorf("sent %v and called with %v, want %s", sent, called, want)
+	}
+	if job.reads != 5 || job.aborts != 0 {
+		t.Errorf("job polled %d times and aborted %d times, want 5 and 0", job.reads, job.aborts)
+	}
+}
+
+func TestRunJob_Canceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	job := &fakeJob{polls: -1}
+	// A full unbuffered channel must not block the abort.
+	ch := make(chan Progress)
+	if err := runJob(ctx, job, newJobOptions([]JobOption{WithPollInterval(time.Millisecond), WithProgress(ch)}), job.progress); err != context.DeadlineExceeded {
+		t.Errorf("runJob error = %v, want context.DeadlineExceeded", err)
+	}
+	if job.aborts != 1 {
+		t.Errorf("job aborted %d times, want 1", job.aborts)
+	}
+
+	abortErr := errors.New("abort failed")
+	job = &fakeJob{polls: -1, abortErr: abortErr}
+	err := runJob(ctx, job, newJobOptions([]JobOption{WithPollInterval(time.Millisecond)}), nil)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, abortErr) {
+		t.Errorf("runJob error = %v, want both the context and the abort error", err)
+	}
+}
+
+func TestNewJobOptions(t *testing.T) {
+	setJobPollInterval(t, time.Second)
+	if o := newJobOptions(nil); o.pollInterval != time.Second {
+		t.Errorf("default poll interval = %v, want %v", o.pollInterval, time.Second)
+	}
+	if o := newJobOptions([]JobOption{WithPollInterval(-1)}); o.pollInterval != time.Second {
+		t.Errorf("poll interval for a negative option = %v, want the default", o.pollInterval)
+	}
+	if o := newJobOptions([]JobOption{WithPollInterval(time.Minute)}); o.pollInterval != time.Minute {
+		t.Errorf("poll interval = %v, want %v", o.pollInterval, time.Minute)
+	}
+}
+
+// newFakeProgressJob returns a fake IDownloadJob or IInstallationJob that
+// completes after polls reads of IsCompleted, or never if polls is negative,
+// and whose progress grows evenly from the first poll, after the read made by
+// BeginXxx, to 100 percent. Aborting it completes it.
+func newFakeProgressJob(polls int) *fakeDispatcher {
+	var aborted atomic.Bool
+	var reads int
+	return newFakeDispatcher(map[string]interface{}{
+		"IsCompleted": fakeMember(func(params ...interface{}) (interface{}, error) {
+			reads++
+			return aborted.Load() || (polls >= 0 && reads > polls), nil
+		}),
+		"Updates": newFakeCollection(),
+		"GetProgress": fakeMember(func(params ...interface{}) (interface{}, error) {
+			percent := int32(0)
+			if polls >= 0 {
+				percent = int32(min(100*(reads-1)/max(polls-1, 1), 100))
+			}
+			return newFakeDispatcher(map[string]interface{}{
+				"CurrentUpdateBytesDownloaded": int64(percent),
+				"CurrentUpdateBytesToDownload": int64(100),
+				"CurrentUpdateDownloadPhase":   DownloadPhaseDownloading,
+				"CurrentUpdateIndex":           int32(0),
+				"CurrentUpdatePercentComplete": percent,
+				"PercentComplete":              percent,
+				"TotalBytesDownloaded":         int64(percent),
+				"TotalBytesToDownload":         int64(100),
+			}), nil
+		}),
+		"CleanUp": nil,
+		"RequestAbort": fakeMember(func(params ...interface{}) (interface{}, error) {
+			aborted.Store(true)
+			return nil, nil
+		}),
+	})
+}
+
+func TestIUpdateDownloader_DownloadContext(t *testing.T) {
+	job := newFakeProgressJob(4)
+	downloader := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{
+		"BeginDownload": job,
+		"EndDownload":   newFakeDispatcher(map[string]interface{}{"HResult": int32(0), "ResultCode": OperationResultCodeOrcSucceeded}),
+	})}
+	update, err := toIUpdate(newFakeUpdate(nil))
+	if err != nil {
+		t.Fatalf("toIUpdate failed: %v", err)
+	}
+
+	ch := make(chan Progress)
+	var events []Progress
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for p := range ch {
+			events = append(events, p)
+		}
+	}()
+	result, err := downloader.DownloadContext(context.Background(), []*IUpdate{update}, WithPollInterval(time.Millisecond), WithProgress(ch))
+	<-done
+	if err != nil {
+		t.Fatalf("DownloadContext failed: %v", err)
+	}
+	if result.ResultCode != OperationResultCodeOrcSucceeded {
+		t.Errorf("result = %+v", result)
+	}
+	if len(events) != 3 || events[2].PercentComplete != 100 || events[2].TotalBytesDownloaded != 100 || events[2].DownloadPhase != DownloadPhaseDownloading {
+		t.Errorf("progress events = %+v, want 3 ending at 100%%", events)
+	}
+	if countCalls(job, "CallMethod RequestAbort") != 0 || countCalls(job, "CallMethod CleanUp") != 1 || job.Releases() != 1 {
+		t.Errorf("job calls = %v with %d releases, want a single CleanUp and Release", job.Calls(), job.Releases())
+	}
+}
+
+func TestIUpdateDownloader_DownloadContext_Deadline(t *testing.T) {
+	job := newFakeProgressJob(-1)
+	downloader := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{
+		"BeginDownload": job,
+		"EndDownload":   newFakeDispatcher(map[string]interface{}{"HResult": int32(0), "ResultCode": OperationResultCodeOrcAborted}),
+	})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result, err := downloader.DownloadContext(ctx, nil, WithPollInterval(time.Millisecond))
+	if result != nil {
+		t.Errorf("result = %+v, want nil", result)
+	}
+	var aborted *DownloadAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, context.DeadlineExceeded) || aborted.Result == nil || aborted.Result.ResultCode != OperationResultCodeOrcAborted {
+		t.Errorf("DownloadContext error = %#v, want a *DownloadAbortedError with the aborted result", err)
+	}
+	if countCalls(job, "CallMethod RequestAbort") != 1 || countCalls(job, "CallMethod CleanUp") != 1 {
+		t.Errorf("job calls = %v, want RequestAbort and CleanUp", job.Calls())
+	}
+}
+
+func TestIUpdateInstaller_InstallContext(t *testing.T) {
+	for _, op := range []string{"Install", "Uninstall"} {
+		t.Run(op, func(t *testing.T) {
+			job := newFakeProgressJob(3)
+			installer := &IUpdateInstaller{disp: newFakeDispatcher(map[string]interface{}{
+				"Begin" + op: job,
+				"End" + op: newFakeDispatcher(map[string]interface{}{
+					"HResult":        int32(0),
+					"RebootRequired": true,
+					"ResultCode":     OperationResultCodeOrcSucceeded,
+				}),
+			})}
+			run := installer.InstallContext
+			if op == "Uninstall" {
+				run = installer.UninstallContext
+			}
+
+			var events []Progress
+			result, err := run(context.Background(), nil, WithPollInterval(time.Millisecond), WithProgressFunc(func(p Progress) {
+				events = append(events, p)
+			}))
+			if err != nil {
+				t.Fatalf("%sContext failed: %v", op, err)
+			}
+			if !result.RebootRequired {
+				t.Errorf("result = %+v", result)
+			}
+			if len(events) != 2 || events[1].PercentComplete != 100 || events[1].TotalBytesDownloaded != 0 || events[1].DownloadPhase != 0 {
+				t.Errorf("progress events = %+v, want 2 installation events ending at 100%%", events)
+			}
+			if countCalls(job, "CallMethod CleanUp") != 1 {
+				t.Errorf("job calls = %v, want CleanUp", job.Calls())
+			}
+		})
+	}
+}
+
+func TestIUpdateInstaller_InstallContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ch := make(chan Progress)
+	job := newFakeProgressJob(0)
+	installer := &IUpdateInstaller{disp: newFakeDispatcher(map[string]interface{}{"BeginInstall": job})}
+	if _, err := installer.InstallContext(ctx, nil, WithProgress(ch)); err != context.Canceled {
+		t.Errorf("InstallContext with a canceled context error = %v, want context.Canceled", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("progress channel not closed")
+	}
+	if len(job.Calls()) != 0 {
+		t.Errorf("an installation was started with a canceled context: %v", job.Calls())
+	}
+}
+
+func TestDownloadAndInstallContext_AbortFails(t *testing.T) {
+	abortErr := errors.New("RPC server unavailable")
+	newJob := func() *fakeDispatcher {
+		job := newFakeProgressJob(-1)
+		job.members["RequestAbort"] = abortErr
+		return job
+	}
+	ended := newFakeDispatcher(map[string]interface{}{"HResult": int32(0), "RebootRequired": false, "ResultCode": OperationResultCodeOrcAborted})
+
+	downloadJob := newJob()
+	downloader := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{"BeginDownload": downloadJob, "EndDownload": ended})}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := downloader.DownloadContext(ctx, nil, WithPollInterval(time.Millisecond))
+	var downloadAborted *DownloadAbortedError
+	if !errors.As(err, &downloadAborted) || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, abortErr) || downloadAborted.Result == nil {
+		t.Errorf("DownloadContext error = %v, want a *DownloadAbortedError with the EndDownload result", err)
+	}
+	if countCalls(downloadJob, "CallMethod CleanUp") != 1 {
+		t.Errorf("download job calls = %v, want CleanUp", downloadJob.Calls())
+	}
+
+	installJob := newJob()
+	installer := &IUpdateInstaller{disp: newFakeDispatcher(map[string]interface{}{"BeginInstall": installJob, "EndInstall": ended})}
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = installer.InstallContext(ctx, nil, WithPollInterval(time.Millisecond))
+	var installAborted *InstallationAbortedError
+	if !errors.As(err, &installAborted) || !errors.Is(err, abortErr) || installAborted.Result == nil {
+		t.Errorf("InstallContext error = %v, want an *InstallationAbortedError with the EndInstall result", err)
+	}
+	if countCalls(installJob, "CallMethod CleanUp") != 1 {
+		t.Errorf("installation job calls = %v, want CleanUp", installJob.Calls())
+	}
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"sync"
+)
+
+// JobCallbacks are Go handlers for the callbacks WUA makes during an
+// asynchronous search, download or installation started with WithCallbacks.
+// They run on a WUA thread, one at a time for a given job, and must return
+// quickly without calling back into the session; hand the work to another
+// goroutine if needed.
+type JobCallbacks struct {
+	// Progress is called by IDownloadProgressChangedCallback and
+	// IInstallationProgressChangedCallback. Searches report no progress.
+	Progress func(Progress)
+	// Completed is called by ISearchCompletedCallback,
+	// IDownloadCompletedCallback and IInstallationCompletedCallback.
+	Completed func()
+}
+
+func (c JobCallbacks) empty() bool {
+	return c.Progress == nil && c.Completed == nil
+}
+
+// WithCallbacks passes per-call callback objects forwarding to callbacks to
+// BeginSearch, BeginDownload, BeginInstall or BeginUninstall, instead of the
+// shared no-op callback. They are unregistered by the Release method of the job,
+// after which no handler is called.
+func WithCallbacks(callbacks JobCallbacks) JobOption {
+	return func(o *jobOptions) {
+		o.callbacks = callbacks
+	}
+}
+
+// CallbacksOf returns the JobCallbacks set by options, for other implementations
+// of Searcher, Downloader and Installer such as package wufake.
+func CallbacksOf(options ...JobOption) JobCallbacks {
+	return newJobOptions(options).callbacks
+}
+
+// ProgressOf returns a function reporting a progress snapshot to the
+// WithProgress channel and WithProgressFunc of options, and a function closing
+// the channel, to call when the operation returns. It is for other
+// implementations of Downloader and Installer such as package wufake.
+func ProgressOf(ctx context.Context, options ...JobOption) (report func(Progress), done func()) {
+	o := newJobOptions(options)
+	return func(p Progress) { o.report(ctx, p) }, o.done
+}
+
+// callbackKind identifies the WUA callback interface a callback object
+// implements.
+type callbackKind int
+
+const (
+	searchCompletedCallback callbackKind = iota + 1
+	downloadProgressChangedCallback
+	downloadCompletedCallback
+	installationProgressChangedCallback
+	installationCompletedCallback
+)
+
+// callbackEvent is a decoded callback Invoke.
+type callbackEvent struct {
+	kind     callbackKind
+	progress Progress // for the *ProgressChanged kinds
+}
+
+// callbackRouter is the handle table between the callback COM objects, which
+// only carry a handle, and the Go handlers of the call that created them.
+type callbackRouter interface {
+	// register adds callbacks to the table and returns their handle.
+	register(callbacks JobCallbacks) uintptr
+	// route calls the handler for event registered under handle, if any.
+	route(handle uintptr, event callbackEvent)
+	// unregister removes handle, waiting for a handler running for it.
+	unregister(handle uintptr)
+}
+
+// jobCallbackRouter routes the callbacks of every per-call callback object.
+var jobCallbackRouter callbackRouter = newCallbackTable()
+
+// callbackTable is the callbackRouter used at run time. Handlers of one handle
+// are serialized; handlers of different handles run concurrently.
+type callbackTable struct {
+	mu      sync.Mutex
+	next    uintptr
+	entries map[uintptr]*callbackEntry
+}
+
+type callbackEntry struct {
+	mu        sync.Mutex
+	callbacks JobCallbacks
+	closed    bool
+}
+
+func newCallbackTable() *callbackTable {
+	return &callbackTable{entries: make(map[uintptr]*callbackEntry)}
+}
+
+func (t *callbackTable) register(callbacks JobCallbacks) uintptr {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	t.entries[t.next] = &callbackEntry{callbacks: callbacks}
+	return t.next
+}
+
+func (t *callbackTable) route(handle uintptr, event callbackEvent) {
+	t.mu.Lock()
+	entry := t.entries[handle]
+	t.mu.Unlock()
+	if entry == nil {
+		return
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.closed {
+		return
+	}
+	switch event.kind {
+	case downloadProgressChangedCallback, installationProgressChangedCallback:
+		if entry.callbacks.Progress != nil {
+			entry.callbacks.Progress(event.progress)
+		}
+	case searchCompletedCallback, downloadCompletedCallback, installationCompletedCallback:
+		if entry.callbacks.Completed != nil {
+			entry.callbacks.Completed()
+		}
+	}
+}
+
+func (t *callbackTable) unregister(handle uintptr) {
+	t.mu.Lock()
+	entry := t.entries[handle]
+	delete(t.entries, handle)
+	t.mu.Unlock()
+	if entry == nil {
+		return
+	}
+	entry.mu.Lock()
+	entry.closed = true
+	entry.mu.Unlock()
+}
+
+// decodeCallbackArgs decodes the IXxxCallbackArgs of a callback of kind. Only
+// the progress arguments have properties.
+func decodeCallbackArgs(kind callbackKind, args dispatcher) (callbackEvent, error) {
+	event := callbackEvent{kind: kind}
+	if args == nil {
+		return event, nil
+	}
+	switch kind {
+	case downloadProgressChangedCallback:
+		progressDisp, err := toIDispatchErr(args.GetProperty("Progress"))
+		if err != nil || progressDisp == nil {
+			return event, err
+		}
+		progress, err := toIDownloadProgress(progressDisp)
+		if err != nil {
+			progressDisp.Release()
+			return event, err
+		}
+		defer progress.Release()
+		event.progress = progress.toProgress()
+	case installationProgressChangedCallback:
+		progressDisp, err := toIDispatchErr(args.GetProperty("Progress"))
+		if err != nil || progressDisp == nil {
+			return event, err
+		}
+		progress, err := toIInstallationProgress(progressDisp)
+		if err != nil {
+			progressDisp.Release()
+			return event, err
+		}
+		defer progress.Release()
+		event.progress = progress.toProgress()
+	}
+	return event, nil
+}
+
+// jobCallbackArgs returns the callback arguments of a BeginXxx call, one per
+// kind, and a function releasing them. Without callbacks it returns the shared
+// no-op callback.
+func jobCallbackArgs(callbacks JobCallbacks, kinds ...callbackKind) ([]interface{}, func()) {
+	args := make([]interface{}, len(kinds))
+	if callbacks.empty() {
+		for i := range kinds {
+			args[i] = newNoopCallback()
+		}
+		return args, func() {}
+	}
+
+	handle := jobCallbackRouter.register(callbacks)
+	releases := make([]func(), len(kinds))
+	for i, kind := range kinds {
+		args[i], releases[i] = newJobCallback(kind, handle)
+	}
+	return args, func() {
+		jobCallbackRouter.unregister(handle)
+		for _, release := range releases {
+			release()
+		}
+	}
+}
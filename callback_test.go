@@ -0,0 +1,258 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCallbackTable_Route(t *testing.T) {
+	table := newCallbackTable()
+	var progress []int32
+	completed := 0
+	handle := table.register(JobCallbacks{
+		Progress:  func(p Progress) { progress = append(progress, p.PercentComplete) },
+		Completed: func() { completed++ },
+	})
+	other := table.register(JobCallbacks{})
+
+	table.route(handle, callbackEvent{kind: downloadProgressChangedCallback, progress: Progress{PercentComplete: 40}})
+	table.route(handle, callbackEvent{kind: installationProgressChangedCallback, progress: Progress{PercentComplete: 80}})
+	table.route(handle, callbackEvent{kind: searchCompletedCallback})
+	table.route(other, callbackEvent{kind: downloadCompletedCallback})
+	table.route(handle+100, callbackEvent{kind: downloadCompletedCallback})
+	if len(progress) != 2 || progress[1] != 80 || completed != 1 {
+		t.Errorf("handlers got progress %v and %d completions, want [40 80] and 1", progress, completed)
+	}
+
+	table.unregister(handle)
+	table.unregister(handle)
+	table.route(handle, callbackEvent{kind: installationCompletedCallback})
+	if completed != 1 {
+		t.Error("a handler was called after unregister")
+	}
+	if handle == other {
+		t.Error("register returned the same handle twice")
+	}
+}
+
+func TestCallbackTable_Concurrent(t *testing.T) {
+	table := newCallbackTable()
+	var running, calls int
+	handle := table.register(JobCallbacks{Progress: func(Progress) {
+		// Handlers of one handle are serialized, so no lock is needed here.
+		running++
+		if running != 1 {
+			t.Error("handlers of one handle ran concurrently")
+		}
+		calls++
+		running--
+	}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				table.route(handle, callbackEvent{kind: downloadProgressChangedCallback})
+				other := table.register(JobCallbacks{})
+				table.unregister(other)
+			}
+		}()
+	}
+	wg.Wait()
+	table.unregister(handle)
+	if calls != 800 {
+		t.Errorf("handler called %d times, want 800", calls)
+	}
+}
+
+func TestDecodeCallbackArgs(t *testing.T) {
+	progress := newFakeDispatcher(map[string]interface{}{
+		"CurrentUpdateBytesDownloaded": int64(10),
+		"CurrentUpdateBytesToDownload": int64(20),
+		"CurrentUpdateDownloadPhase":   DownloadPhaseVerifying,
+		"CurrentUpdateIndex":           int32(1),
+		"CurrentUpdatePercentComplete": int32(50),
+		"PercentComplete":              int32(75),
+		"TotalBytesDownloaded":         int64(30),
+		"TotalBytesToDownload":         int64(40),
+	})
+	event, err := decodeCallbackArgs(downloadProgressChangedCallback, newFakeDispatcher(map[string]interface{}{"Progress": progress}))
+	if err != nil {
+		t.Fatalf("decodeCallbackArgs failed: %v", err)
+	}
+	want := Progress{
+		PercentComplete:              75,
+		CurrentUpdateIndex:           1,
+		CurrentUpdatePercentComplete: 50,
+		CurrentUpdateBytesDownloaded: 10,
+		CurrentUpdateBytesToDownload: 20,
+		TotalBytesDownloaded:         30,
+		TotalBytesToDownload:         40,
+		DownloadPhase:                DownloadPhaseVerifying,
+	}
+	if event.kind != downloadProgressChangedCallback || event.progress != want {
+		t.Errorf("event = %+v, want progress %+v", event, want)
+	}
+	if progress.Releases() != 1 {
+		t.Errorf("progress released %d times, want 1", progress.Releases())
+	}
+
+	installProgress := newFakeDispatcher(map[string]interface{}{
+		"CurrentUpdateIndex":           int32(2),
+		"CurrentUpdatePercentComplete": int32(10),
+		"PercentComplete":              int32(90),
+	})
+	event, err = decodeCallbackArgs(installationProgressChangedCallback, newFakeDispatcher(map[string]interface{}{"Progress": installProgress}))
+	if err != nil || event.progress != (Progress{PercentComplete: 90, CurrentUpdateIndex: 2, CurrentUpdatePercentComplete: 10}) {
+		t.Errorf("decodeCallbackArgs = %+v, %v", event, err)
+	}
+
+	// Completion args have no properties and may be absent.
+	if event, err := decodeCallbackArgs(searchCompletedCallback, nil); err != nil || event.kind != searchCompletedCallback {
+		t.Errorf("decodeCallbackArgs(completed) = %+v, %v", event, err)
+	}
+
+	testErr := errors.New("RPC_E_DISCONNECTED")
+	if _, err := decodeCallbackArgs(installationProgressChangedCallback, newFakeDispatcher(map[string]interface{}{"Progress": testErr})); err != testErr {
+		t.Errorf("decodeCallbackArgs error = %v, want %v", err, testErr)
+	}
+}
+
+// recordingRouter is a callbackRouter recording registrations.
+type recordingRouter struct {
+	mu           sync.Mutex
+	callbacks    map[uintptr]JobCallbacks
+	unregistered []uintptr
+}
+
+func setRecordingRouter(t *testing.T) *recordingRouter {
+	t.Helper()
+	r := &recordingRouter{callbacks: make(map[uintptr]JobCallbacks)}
+	saved := jobCallbackRouter
+	jobCallbackRouter = r
+	t.Cleanup(func() { jobCallbackRouter = saved })
+	return r
+}
+
+func (r *recordingRouter) register(callbacks JobCallbacks) uintptr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	handle := uintptr(len(r.callbacks) + 1)
+	r.callbacks[handle] = callbacks
+	return handle
+}
+
+func (r *recordingRouter) route(handle uintptr, event callbackEvent) {
+	r.mu.Lock()
+	callbacks := r.callbacks[handle]
+	r.mu.Unlock()
+	if event.kind == downloadCompletedCallback && callbacks.Completed != nil {
+		callbacks.Completed()
+	}
+}
+
+func (r *recordingRouter) unregister(handle uintptr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.unregistered = append(r.unregistered, handle)
+}
+
+func (r *recordingRouter) registered() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.callbacks)
+}
+
+func TestBeginDownload_Callbacks(t *testing.T) {
+	router := setRecordingRouter(t)
+	job := newFakeProgressJob(0)
+	downloader := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{"BeginDownload": job})}
+
+	if _, err := downloader.BeginDownload(nil); err != nil {
+		t.Fatalf("BeginDownload failed: %v", err)
+	}
+	if router.registered() != 0 {
+		t.Error("BeginDownload without callbacks registered some")
+	}
+
+	completed := false
+	started, err := downloader.BeginDownload(nil, WithCallbacks(JobCallbacks{Completed: func() { completed = true }}))
+	if err != nil {
+		t.Fatalf("BeginDownload failed: %v", err)
+	}
+	if router.registered() != 1 {
+		t.Fatalf("BeginDownload registered %d callbacks, want 1", router.registered())
+	}
+	router.route(1, callbackEvent{kind: downloadCompletedCallback})
+	if !completed {
+		t.Error("Completed handler not called")
+	}
+	started.Release()
+	started.Release()
+	if len(router.unregistered) != 1 || router.unregistered[0] != 1 {
+		t.Errorf("unregistered %v, want [1] once", router.unregistered)
+	}
+
+	failing := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{"BeginDownload": errors.New("E_FAIL")})}
+	if _, err := failing.BeginDownload(nil, WithCallbacks(JobCallbacks{Completed: func() {}})); err == nil {
+		t.Fatal("BeginDownload succeeded, want an error")
+	}
+	if len(router.unregistered) != 2 {
+		t.Errorf("callbacks of a failed BeginDownload not unregistered: %v", router.unregistered)
+	}
+}
+
+func TestDownloadContext_CompletedCallbackWakes(t *testing.T) {
+	router := setRecordingRouter(t)
+	var aborted bool
+	reads := 0
+	job := newFakeDispatcher(map[string]interface{}{
+		// Completed on the second poll, which only the completion callback
+		// triggers before the poll interval.
+		"IsCompleted": fakeMember(func(params ...interface{}) (interface{}, error) {
+			reads++
+			if reads == 2 {
+				go router.route(1, callbackEvent{kind: downloadCompletedCallback})
+			}
+			return reads > 2, nil
+		}),
+		"Updates":      newFakeCollection(),
+		"CleanUp":      nil,
+		"RequestAbort": fakeMember(func(params ...interface{}) (interface{}, error) { aborted = true; return nil, nil }),
+	})
+	downloader := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{
+		"BeginDownload": job,
+		"EndDownload":   newFakeDispatcher(map[string]interface{}{"HResult": int32(0), "ResultCode": OperationResultCodeOrcSucceeded}),
+	})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := downloader.DownloadContext(ctx, nil, WithPollInterval(time.Hour)); err != nil {
+		t.Fatalf("DownloadContext failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || aborted {
+		t.Errorf("DownloadContext returned after %v (aborted %v), want right after the completion callback", elapsed, aborted)
+	}
+	if len(router.unregistered) != 1 {
+		t.Errorf("callbacks unregistered %d times, want 1", len(router.unregistered))
+	}
+}
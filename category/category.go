@@ -0,0 +1,163 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package category is a registry of well-known WUA update classifications and
+// products, so that searches can name them instead of copying GUIDs:
+//
+//	searcher.SearchCriteria(criteria.And(criteria.IsInstalled(false), category.SecurityUpdates.Criteria()))
+package category
+
+import (
+	"strings"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/criteria"
+)
+
+// Kind is the type of a category, as reported by ICategory.Type.
+type Kind string
+
+// The kinds of categories in the registry.
+const (
+	Classification Kind = "UpdateClassification"
+	Product        Kind = "Product"
+)
+
+// Category is a WUA category. IDs are lower-case GUIDs.
+type Category struct {
+	ID   string
+	Name string
+	Kind Kind
+}
+
+// Criteria returns the search criterion matching updates in the category.
+func (c Category) Criteria() criteria.Term {
+	return criteria.CategoryIDsContains(c.ID)
+}
+
+// The update classifications.
+var (
+	Applications      = Category{ID: "5c9376ab-8ce6-464a-b136-22113dd69801", Name: "Applications", Kind: Classification}
+	Connectors        = Category{ID: "434de588-ed14-48f5-8eed-a15e09a991f6", Name: "Connectors", Kind: Classification}
+	CriticalUpdates   = Category{ID: "e6cf1350-c01b-414d-a61f-263d14d133b4", Name: "Critical Updates", Kind: Classification}
+	DefinitionUpdates = Category{ID: "e0789628-ce08-4437-be74-2495b842f43b", Name: "Definition Updates", Kind: Classification}
+	DeveloperKits     = Category{ID: "e140075d-8433-45c3-ad87-e72345b36078", Name: "Developer Kits", Kind: Classification}
+	Drivers           = Category{ID: "ebfc1fc5-71a4-4f7b-9aca-3b9a503104a0", Name: "Drivers", Kind: Classification}
+	FeaturePacks      = Category{ID: "b54e7d24-7add-428f-8b75-90a396fa584f", Name: "Feature Packs", Kind: Classification}
+	Guidance          = Category{ID: "9511d615-35b2-47bb-927f-f73d8e9260bb", Name: "Guidance", Kind: Classification}
+	SecurityUpdates   = Category{ID: "0fa1201d-4330-4fa8-8ae9-b877473b6441", Name: "Security Updates", Kind: Classification}
+	ServicePacks      = Category{ID: "68c5b0a3-d1a6-4553-ae49-01d3a7827828", Name: "Service Packs", Kind: Classification}
+	Tools             = Category{ID: "b4832bd8-e735-4761-8daf-37f882276dab", Name: "Tools", Kind: Classification}
+	UpdateRollups     = Category{ID: "28bc880e-0592-4cbf-8f95-c79b17911d5f", Name: "Update Rollups", Kind: Classification}
+	Updates           = Category{ID: "cd5ffd1e-e932-4e3a-bf74-18bf0b1bbd83", Name: "Updates", Kind: Classification}
+	Upgrades          = Category{ID: "3689bdc8-b205-4af4-8d4a-a63924c5e9d5", Name: "Upgrades", Kind: Classification}
+)
+
+// Some of the products.
+var (
+	Windows                    = Category{ID: "6964aab4-c5b5-43bd-a17d-ffb4346a8e1d", Name: "Windows", Kind: Product}
+	Windows7                   = Category{ID: "bfe5b177-a086-47a0-b102-097e4fa1f807", Name: "Windows 7", Kind: Product}
+	Windows81                  = Category{ID: "6407468e-edc7-4ecd-8c32-521f64cee65e", Name: "Windows 8.1", Kind: Product}
+	Windows10                  = Category{ID: "a3c2375d-0c8a-42f9-bce0-28333e198407", Name: "Windows 10", Kind: Product}
+	Windows10Version1903       = Category{ID: "b3c75dc1-155f-4be4-b015-3f1a91758359", Name: "Windows 10, version 1903 and later", Kind: Product}
+	Windows11                  = Category{ID: "72e7624a-5b00-45d2-b92f-e561c0a6a160", Name: "Windows 11", Kind: Product}
+	WindowsServer2012R2        = Category{ID: "d31bd4c3-d872-41c9-a2e7-231f372588cb", Name: "Windows Server 2012 R2", Kind: Product}
+	WindowsServer2016          = Category{ID: "569e8e8f-c6cd-42c8-92a3-efbb20a0f6f5", Name: "Windows Server 2016", Kind: Product}
+	WindowsServer2019          = Category{ID: "f702a48c-919b-45d6-9aef-ca4248d50397", Name: "Windows Server 2019", Kind: Product}
+	MicrosoftDefenderAntivirus = Category{ID: "8c3fcc84-7410-4a95-8b89-a166a0190486", Name: "Microsoft Defender Antivirus", Kind: Product}
+	Office2016                 = Category{ID: "25aed893-7c2d-4a31-ae22-28ff8ac150ed", Name: "Office 2016", Kind: Product}
+)
+
+var registry = []Category{
+	Applications, Connectors, CriticalUpdates, DefinitionUpdates, DeveloperKits,
+	Drivers, FeaturePacks, Guidance, SecurityUpdates, ServicePacks, Tools,
+	UpdateRollups, Updates, Upgrades,
+	Windows, Windows7, Windows81, Windows10, Windows10Version1903, Windows11,
+	WindowsServer2012R2, WindowsServer2016, WindowsServer2019,
+	MicrosoftDefenderAntivirus, Office2016,
+}
+
+// All returns every registered category, classifications first.
+func All() []Category {
+	return append([]Category(nil), registry...)
+}
+
+// ByID looks up a registered category by GUID, ignoring case and braces.
+func ByID(id string) (Category, bool) {
+	id = normalizeID(id)
+	for _, c := range registry {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Category{}, false
+}
+
+// ByName looks up a registered category by name, ignoring case.
+func ByName(name string) (Category, bool) {
+	for _, c := range registry {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return Category{}, false
+}
+
+func normalizeID(id string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(id), "{}"))
+}
+
+// fromICategory returns the registered category for c, or one built from its
+// properties if it is not registered.
+func fromICategory(c *windowsupdate.ICategory) Category {
+	if registered, ok := ByID(c.CategoryID); ok {
+		return registered
+	}
+	return Category{ID: normalizeID(c.CategoryID), Name: c.Name, Kind: Kind(c.Type)}
+}
+
+// Of returns the categories of update of the given kind, in order.
+func Of(update *windowsupdate.IUpdate, kind Kind) []Category {
+	var categories []Category
+	for _, c := range update.Categories {
+		if c != nil && Kind(c.Type) == kind {
+			categories = append(categories, fromICategory(c))
+		}
+	}
+	return categories
+}
+
+// ClassificationOf returns the classification of update, such as
+// SecurityUpdates. WUA assigns one classification per update.
+func ClassificationOf(update *windowsupdate.IUpdate) (Category, bool) {
+	classifications := Of(update, Classification)
+	if len(classifications) == 0 {
+		return Category{}, false
+	}
+	return classifications[0], true
+}
+
+// ProductsOf returns the products update applies to.
+func ProductsOf(update *windowsupdate.IUpdate) []Category {
+	return Of(update, Product)
+}
+
+// In reports whether update belongs to category c.
+func In(update *windowsupdate.IUpdate, c Category) bool {
+	for _, category := range update.Categories {
+		if category != nil && normalizeID(category.CategoryID) == c.ID {
+			return true
+		}
+	}
+	return false
+}
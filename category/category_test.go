@@ -0,0 +1,84 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package category
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+func TestRegistry(t *testing.T) {
+	guid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	ids := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, c := range All() {
+		if !guid.MatchString(c.ID) {
+			t.Errorf("%s: ID %q is not a lower-case GUID", c.Name, c.ID)
+		}
+		if c.Kind != Classification && c.Kind != Product {
+			t.Errorf("%s: Kind = %q", c.Name, c.Kind)
+		}
+		if ids[c.ID] || names[c.Name] {
+			t.Errorf("%s: duplicate ID or name", c.Name)
+		}
+		ids[c.ID], names[c.Name] = true, true
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if c, ok := ByID("{0FA1201D-4330-4FA8-8AE9-B877473B6441}"); !ok || c != SecurityUpdates {
+		t.Errorf("ByID() = %v, %v; want SecurityUpdates", c, ok)
+	}
+	if c, ok := ByName("definition updates"); !ok || c != DefinitionUpdates {
+		t.Errorf("ByName() = %v, %v; want DefinitionUpdates", c, ok)
+	}
+	if _, ok := ByID("00000000-0000-0000-0000-000000000000"); ok {
+		t.Error("ByID() found an unknown ID")
+	}
+	if _, ok := ByName("Hotfixes"); ok {
+		t.Error("ByName() found an unknown name")
+	}
+}
+
+func TestCriteria(t *testing.T) {
+	if got, want := Drivers.Criteria().String(), "CategoryIDs contains 'ebfc1fc5-71a4-4f7b-9aca-3b9a503104a0'"; got != want {
+		t.Errorf("Criteria() = %q, want %q", got, want)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	update := &windowsupdate.IUpdate{Categories: []*windowsupdate.ICategory{
+		{CategoryID: "0FA1201D-4330-4FA8-8AE9-B877473B6441", Name: "Security Updates", Type: "UpdateClassification"},
+		{CategoryID: "72e7624a-5b00-45d2-b92f-e561c0a6a160", Name: "Windows 11", Type: "Product"},
+		{CategoryID: "11111111-2222-3333-4444-555555555555", Name: "Contoso Widgets", Type: "Product"},
+		nil,
+	}}
+
+	if c, ok := ClassificationOf(update); !ok || c != SecurityUpdates {
+		t.Errorf("ClassificationOf() = %v, %v; want SecurityUpdates", c, ok)
+	}
+	want := []Category{Windows11, {ID: "11111111-2222-3333-4444-555555555555", Name: "Contoso Widgets", Kind: Product}}
+	if got := ProductsOf(update); !reflect.DeepEqual(got, want) {
+		t.Errorf("ProductsOf() = %v, want %v", got, want)
+	}
+	if !In(update, SecurityUpdates) || !In(update, Windows11) || In(update, Drivers) {
+		t.Error("In() is wrong")
+	}
+	if _, ok := ClassificationOf(&windowsupdate.IUpdate{}); ok {
+		t.Error("ClassificationOf() of an update without categories succeeded")
+	}
+}
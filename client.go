@@ -0,0 +1,338 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ceshihao/windowsupdate/criteria"
+)
+
+// ErrClientClosed is returned by the methods of a Client after Close.
+var ErrClientClosed = errors.New("windowsupdate: client closed")
+
+// executor prepares the worker thread of a Client. comExecutor initializes a
+// COM apartment on Windows; tests use a fake.
+type executor interface {
+	// start is called on the locked worker thread before the first call.
+	start() error
+	// stop is called on the worker thread after the last call.
+	stop()
+}
+
+// clientRequest is a call queued to the worker.
+type clientRequest struct {
+	fn   func(session *IUpdateSession) error
+	done chan error
+	// state is settled once, to requestFinished by the worker or to
+	// requestAbandoned by Do when its context is done first.
+	state *atomic.Int32
+}
+
+const (
+	requestPending int32 = iota
+	requestFinished
+	requestAbandoned
+)
+
+// Client runs every WUA call on a dedicated, locked OS thread that owns an
+// initialized COM apartment and the IUpdateSession, so its methods can be
+// called from any goroutine without CoInitializeEx or runtime.LockOSThread.
+// Calls are queued and executed one at a time.
+//
+// The objects returned by a Client, such as the updates of a search result,
+// belong to the worker thread: pass them back to Client methods, and use Do to
+// call anything else on them, e.g. IUpdate.Load.
+type Client struct {
+	requests chan clientRequest
+	closing  chan struct{}
+	stopped  chan struct{}
+	close    sync.Once
+}
+
+// NewClient starts the worker thread, initializes COM on it and creates the
+// IUpdateSession with options.
+func NewClient(options ...SessionOption) (*Client, error) {
+	return newClient(comExecutor{}, func() (*IUpdateSession, error) {
+		return NewUpdateSession(options...)
+	})
+}
+
+func newClient(exec executor, newSession func() (*IUpdateSession, error)) (*Client, error) {
+	c := &Client{
+		requests: make(chan clientRequest),
+		closing:  make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	started := make(chan error, 1)
+	go c.run(exec, newSession, started)
+	if err := <-started; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// run is the worker loop. It reports the outcome of the setup on started and
+// then serves requests until Close.
+func (c *Client) run(exec executor, newSession func() (*IUpdateSession, error), started chan<- error) {
+	defer close(c.stopped)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := exec.start(); err != nil {
+		started <- err
+		return
+	}
+	defer exec.stop()
+
+	session, err := newSession()
+	if err != nil {
+		started <- err
+		return
+	}
+	defer session.Close()
+	started <- nil
+
+	for {
+		select {
+		case req := <-c.requests:
+			c.serve(req, session)
+		case <-c.closing:
+			return
+		}
+	}
+}
+
+// serve runs a request. If Do has already returned, nobody can use what fn
+// obtained, so the objects it added to the session are released.
+func (c *Client) serve(req clientRequest, session *IUpdateSession) {
+	var mark int
+	if session.arena != nil {
+		mark = session.arena.mark()
+	}
+	err := call(req.fn, session)
+	if !req.state.CompareAndSwap(requestPending, requestFinished) && session.arena != nil {
+		session.arena.releaseSince(mark)
+	}
+	req.done <- err
+}
+
+// call runs fn, turning a panic into an error so that the worker survives it.
+func call(fn func(session *IUpdateSession) error, session *IUpdateSession) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("windowsupdate: panic in client call: %v", r)
+		}
+	}()
+	return fn(session)
+}
+
+// Do runs fn on the worker thread with the session of the client and returns
+// its error. If ctx is done before fn starts, fn is not run; if it is done
+// while fn runs, Do returns ctx.Err() without waiting, and fn completes in the
+// background since a COM call cannot be interrupted. The objects fn obtains
+// through the session are then released when it returns, as the caller has
+// gone; fn must not keep them elsewhere.
+func (c *Client) Do(ctx context.Context, fn func(session *IUpdateSession) error) error {
+	req := clientRequest{fn: fn, done: make(chan error, 1), state: new(atomic.Int32)}
+	select {
+	case c.requests <- req:
+	case <-c.closing:
+		return ErrClientClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-req.done:
+		return err
+	case <-ctx.Done():
+		if req.state.CompareAndSwap(requestPending, requestAbandoned) {
+			return ctx.Err()
+		}
+		// fn finished meanwhile, and its results are kept.
+		return <-req.done
+	}
+}
+
+// Search creates a searcher and performs IUpdateSearcher.SearchContext, so the
+// search is aborted when ctx is done.
+func (c *Client) Search(ctx context.Context, criteria string) (*ISearchResult, error) {
+	var result *ISearchResult
+	err := c.Do(ctx, func(session *IUpdateSession) error {
+		searcher, err := session.CreateUpdateSearcher()
+		if err != nil {
+			return err
+		}
+		defer searcher.Release()
+		result, err = searcher.SearchContext(ctx, criteria)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SearchStream performs Search with WithStreamedUpdates and returns an
+// iterator converting each update on the worker thread when the loop reaches
+// it, as ISearchResult.All does. The search runs when the loop starts, and its
+// failure is yielded with a nil update. As with All, the updates hold their COM
+// objects until released, with Do, or until Close.
+func (c *Client) SearchStream(ctx context.Context, criteria string) iter.Seq2[*IUpdate, error] {
+	return func(yield func(*IUpdate, error) bool) {
+		var result *ISearchResult
+		var count int32
+		err := c.Do(ctx, func(session *IUpdateSession) error {
+			searcher, err := session.CreateUpdateSearcher()
+			if err != nil {
+				return err
+			}
+			defer searcher.Release()
+			if result, err = searcher.SearchContext(ctx, criteria, WithStreamedUpdates()); err != nil {
+				return err
+			}
+			if result.updatesDisp == nil {
+				return nil
+			}
+			count, err = toInt32Err(result.updatesDisp.GetProperty("Count"))
+			return err
+		})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer c.Do(context.Background(), func(*IUpdateSession) error {
+			result.Release()
+			return nil
+		})
+
+		for i := 0; i < int(count); i++ {
+			var update *IUpdate
+			err := c.Do(ctx, func(*IUpdateSession) error {
+				var err error
+				update, err = updateAt(result.updatesDisp, i, result.updateFields)
+				return err
+			})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(update, nil) {
+				return
+			}
+		}
+	}
+}
+
+// SearchCriteria performs Search with a criteria built with package criteria.
+func (c *Client) SearchCriteria(ctx context.Context, expr criteria.Expr) (*ISearchResult, error) {
+	return c.Search(ctx, expr.String())
+}
+
+// QueryHistory creates a searcher and performs IUpdateSearcher.QueryHistoryContext.
+func (c *Client) QueryHistory(ctx context.Context, startIndex int32, count int32) ([]*IUpdateHistoryEntry, error) {
+	var entries []*IUpdateHistoryEntry
+	err := c.Do(ctx, func(session *IUpdateSession) error {
+		searcher, err := session.CreateUpdateSearcher()
+		if err != nil {
+			return err
+		}
+		defer searcher.Release()
+		entries, err = searcher.QueryHistoryContext(ctx, startIndex, count)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Download creates a downloader and performs IUpdateDownloader.DownloadContext,
+// so the download is aborted when ctx is done. Progress callbacks run on the worker
+// thread.
+func (c *Client) Download(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IDownloadResult, error) {
+	var result *IDownloadResult
+	err := c.Do(ctx, func(session *IUpdateSession) error {
+		downloader, err := session.CreateUpdateDownloader()
+		if err != nil {
+			return err
+		}
+		defer downloader.Release()
+		result, err = downloader.DownloadContext(ctx, updates, options...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Install creates an installer and performs IUpdateInstaller.InstallContext,
+// so the installation is aborted when ctx is done. Progress callbacks run on the worker
+// thread.
+func (c *Client) Install(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IInstallationResult, error) {
+	var result *IInstallationResult
+	err := c.Do(ctx, func(session *IUpdateSession) error {
+		installer, err := session.CreateUpdateInstaller()
+		if err != nil {
+			return err
+		}
+		defer installer.Release()
+		result, err = installer.InstallContext(ctx, updates, options...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Uninstall creates an installer and performs IUpdateInstaller.UninstallContext,
+// so the uninstallation is aborted when ctx is done. Progress callbacks run on the worker
+// thread.
+func (c *Client) Uninstall(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IInstallationResult, error) {
+	var result *IInstallationResult
+	err := c.Do(ctx, func(session *IUpdateSession) error {
+		installer, err := session.CreateUpdateInstaller()
+		if err != nil {
+			return err
+		}
+		defer installer.Release()
+		result, err = installer.UninstallContext(ctx, updates, options...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Close stops the worker thread once the running call, if any, returns, closes
+// the session, releasing every object obtained through the client, and
+// uninitializes COM. Calls not yet started fail with ErrClientClosed. Close is
+// safe to call more than once.
+func (c *Client) Close() error {
+	c.close.Do(func() {
+		close(c.closing)
+	})
+	<-c.stopped
+	return nil
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ceshihao/windowsupdate/criteria"
+)
+
+// fakeExecutor records the setup of the worker thread.
+type fakeExecutor struct {
+	startErr error
+	started  atomic.Int32
+	stopped  atomic.Int32
+}
+
+func (e *fakeExecutor) start() error {
+	e.started.Add(1)
+	return e.startErr
+}
+
+func (e *fakeExecutor) stop() {
+	e.stopped.Add(1)
+}
+
+func newFakeClient(t *testing.T, exec *fakeExecutor, updates ...interface{}) *Client {
+	t.Helper()
+	client, err := newClient(exec, func() (*IUpdateSession, error) {
+		return toIUpdateSession(newFakeSession(updates...))
+	})
+	if err != nil {
+		t.Fatalf("newClient failed: %v", err)
+	}
+	return client
+}
+
+func TestClient_Search(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := newFakeClient(t, exec, newFakeUpdate(nil))
+
+	result, err := client.Search(context.Background(), "IsInstalled=0")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Updates) != 1 {
+		t.Errorf("Updates = %+v", result.Updates)
+	}
+
+	// WU_E_INVALID_CRITERIA from the fake searcher comes back to the caller.
+	if _, err := client.Search(context.Background(), "bogus"); err == nil {
+		t.Error("Search with invalid criteria succeeded")
+	}
+	if result, err := client.SearchCriteria(context.Background(), criteria.IsInstalled(false)); err != nil || len(result.Updates) != 1 {
+		t.Errorf("SearchCriteria() = %v, %v", result, err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if exec.started.Load() != 1 || exec.stopped.Load() != 1 {
+		t.Errorf("executor started %d and stopped %d times, want once each", exec.started.Load(), exec.stopped.Load())
+	}
+}
+
+func TestClient_SearchStream(t *testing.T) {
+	first := newFakeUpdate(map[string]interface{}{"Title": "First"})
+	second := newFakeUpdate(map[string]interface{}{"Title": "Second"})
+	client := newFakeClient(t, &fakeExecutor{}, first, second)
+	defer client.Close()
+
+	var titles []string
+	for update, err := range client.SearchStream(context.Background(), "IsInstalled=0") {
+		if err != nil {
+			t.Fatalf("SearchStream yielded %v", err)
+		}
+		titles = append(titles, update.Title)
+		break
+	}
+	if len(titles) != 1 || titles[0] != "First" {
+		t.Errorf("titles = %v", titles)
+	}
+	if len(second.Calls()) != 0 {
+		t.Errorf("the update after break was converted: %v", second.Calls())
+	}
+
+	for update, err := range client.SearchStream(context.Background(), "bogus") {
+		if update != nil || err == nil {
+			t.Errorf("SearchStream with invalid criteria yielded %v, %v", update, err)
+		}
+	}
+}
+
+func TestClient_StartError(t *testing.T) {
+	testErr := errors.New("CoInitializeEx failed")
+	exec := &fakeExecutor{startErr: testErr}
+	sessionCreated := false
+	_, err := newClient(exec, func() (*IUpdateSession, error) {
+		sessionCreated = true
+		return nil, nil
+	})
+	if err != testErr {
+		t.Errorf("newClient error = %v, want %v", err, testErr)
+	}
+	if sessionCreated || exec.stopped.Load() != 0 {
+		t.Errorf("session created = %v, stopped %d times after a failed start", sessionCreated, exec.stopped.Load())
+	}
+}
+
+func TestClient_SessionError(t *testing.T) {
+	testErr := errors.New("class not registered")
+	exec := &fakeExecutor{}
+	_, err := newClient(exec, func() (*IUpdateSession, error) {
+		return nil, testErr
+	})
+	if err != testErr {
+		t.Errorf("newClient error = %v, want %v", err, testErr)
+	}
+	if exec.stopped.Load() != 1 {
+		t.Errorf("executor stopped %d times, want once", exec.stopped.Load())
+	}
+}
+
+func TestClient_SerializesCalls(t *testing.T) {
+	client := newFakeClient(t, &fakeExecutor{})
+	defer client.Close()
+
+	var running, overlaps, calls atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := client.Do(context.Background(), func(session *IUpdateSession) error {
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				calls.Add(1)
+				running.Add(-1)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Do failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 50 || overlaps.Load() != 0 {
+		t.Errorf("%d calls, %d overlapping; want 50 and 0", calls.Load(), overlaps.Load())
+	}
+}
+
+func TestClient_ErrorAndPanic(t *testing.T) {
+	client := newFakeClient(t, &fakeExecutor{})
+	defer client.Close()
+
+	testErr := errors.New("access denied")
+	if err := client.Do(context.Background(), func(*IUpdateSession) error { return testErr }); err != testErr {
+		t.Errorf("Do error = %v, want %v", err, testErr)
+	}
+	err := client.Do(context.Background(), func(*IUpdateSession) error { panic("boom") })
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Do error = %v, want the panic", err)
+	}
+	if err := client.Do(context.Background(), func(*IUpdateSession) error { return nil }); err != nil {
+		t.Errorf("Do after a panic failed: %v", err)
+	}
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	client := newFakeClient(t, &fakeExecutor{})
+	defer client.Close()
+
+	release := make(chan struct{})
+	busy := make(chan struct{})
+	go client.Do(context.Background(), func(*IUpdateSession) error {
+		close(busy)
+		<-release
+		return nil
+	})
+	<-busy
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran := false
+	if err := client.Do(ctx, func(*IUpdateSession) error { ran = true; return nil }); err != context.Canceled {
+		t.Errorf("Do error = %v, want context.Canceled", err)
+	}
+	close(release)
+	if err := client.Do(context.Background(), func(*IUpdateSession) error { return nil }); err != nil {
+		t.Errorf("Do failed: %v", err)
+	}
+	if ran {
+		t.Error("a call canceled while queued was run")
+	}
+}
+
+func TestClient_ContextCanceledReleasesLateResult(t *testing.T) {
+	tracker := NewLeakTracker()
+	update := newFakeUpdate(nil)
+	client, err := newClient(&fakeExecutor{}, func() (*IUpdateSession, error) {
+		return newIUpdateSession(newFakeSession(update), sessionOptions{tracker: tracker})
+	})
+	if err != nil {
+		t.Fatalf("newClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	go func() {
+		<-started
+		cancel()
+	}()
+	err = client.Do(ctx, func(session *IUpdateSession) error {
+		close(started)
+		<-proceed
+		searcher, err := session.CreateUpdateSearcher()
+		if err != nil {
+			return err
+		}
+		_, err = searcher.Search("IsInstalled=0")
+		return err
+	})
+	if err != context.Canceled {
+		t.Errorf("Do error = %v, want context.Canceled", err)
+	}
+	close(proceed)
+	// The next call waits for the late one to return.
+	if err := client.Do(context.Background(), func(*IUpdateSession) error { return nil }); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	if got := tracker.Unreleased(); len(got) != 1 || got[0].Path != "IUpdateSession" {
+		t.Errorf("Unreleased() = %+v after the late call, want only the session", got)
+	}
+	if update.Releases() != 1 {
+		t.Errorf("update released %d times, want 1", update.Releases())
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	exec := &fakeExecutor{}
+	client := newFakeClient(t, exec)
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+	if exec.stopped.Load() != 1 {
+		t.Errorf("executor stopped %d times, want once", exec.stopped.Load())
+	}
+	if _, err := client.Search(context.Background(), "IsInstalled=0"); err != ErrClientClosed {
+		t.Errorf("Search after Close error = %v, want ErrClientClosed", err)
+	}
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+
+	"github.com/go-ole/go-ole"
+)
+
+// sFalse is the S_FALSE returned by CoInitializeEx when the thread already is
+// in the multithreaded apartment.
+const sFalse = 0x00000001
+
+// comExecutor joins the worker thread of a Client to the multithreaded COM
+// apartment. A single-threaded apartment would need the worker to pump
+// messages for COM to deliver the job callbacks and other calls made into it
+// from WUA threads, and the worker only waits on its queue.
+type comExecutor struct{}
+
+func (comExecutor) start() error {
+	err := ole.CoInitializeEx(0, ole.COINIT_MULTITHREADED|ole.COINIT_SPEED_OVER_MEMORY)
+	var oleErr *ole.OleError
+	if errors.As(err, &oleErr) && oleErr.Code() == sFalse {
+		// Still balanced by CoUninitialize in stop.
+		return nil
+	}
+	return err
+}
+
+func (comExecutor) stop() {
+	ole.CoUninitialize()
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+// newComObject always fails with ErrNotSupported on non-Windows platforms, so
+// NewUpdateSession, NewAutomaticUpdates, NewSystemInformation,
+// NewUpdateServiceManager, NewWindowsUpdateAgentInfo and the collection
+// constructors fail cleanly instead of reaching COM.
+func newComObject(programID string) (dispatcher, error) {
+	return nil, ErrNotSupported
+}
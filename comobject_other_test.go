@@ -0,0 +1,63 @@
+//go:build !windows
+
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConstructors_NotSupported(t *testing.T) {
+	constructors := map[string]func() error{
+		"NewUpdateSession": func() error {
+			_, err := NewUpdateSession(WithRecorder(NewRecorder()))
+			return err
+		},
+		"NewAutomaticUpdates": func() error {
+			_, err := NewAutomaticUpdates()
+			return err
+		},
+		"NewSystemInformation": func() error {
+			_, err := NewSystemInformation()
+			return err
+		},
+		"NewUpdateServiceManager": func() error {
+			_, err := NewUpdateServiceManager()
+			return err
+		},
+		"NewWindowsUpdateAgentInfo": func() error {
+			_, err := NewWindowsUpdateAgentInfo()
+			return err
+		},
+		"NewUpdateCollection": func() error {
+			_, err := NewUpdateCollection()
+			return err
+		},
+		"NewStringCollection": func() error {
+			_, err := NewStringCollection()
+			return err
+		},
+		"NewClient": func() error {
+			_, err := NewClient()
+			return err
+		},
+	}
+	for name, constructor := range constructors {
+		if err := constructor(); !errors.Is(err, ErrNotSupported) {
+			t.Errorf("%s() error = %v, want ErrNotSupported", name, err)
+		}
+	}
+}
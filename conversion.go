@@ -0,0 +1,272 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ole/go-ole"
+)
+
+// hrParamNotFound is DISP_E_PARAMNOTFOUND, the SCODE of the VT_ERROR that
+// stands for a missing value.
+const hrParamNotFound HRESULT = 0x80020004
+
+// ConversionError is returned when a property or method returns a VARIANT that
+// cannot be converted to the Go type of its field, e.g. a VT_BSTR that is not
+// a number for an int32 field, or a number out of its range.
+type ConversionError struct {
+	Property string // The property or method that returned the value.
+	VT       ole.VT // The VARTYPE of the value.
+	Value    interface{}
+	Type     string // The Go type converted to, e.g. "int32".
+}
+
+func (e *ConversionError) Error() string {
+	property := e.Property
+	if property == "" {
+		property = "value"
+	}
+	if e.Value == nil {
+		return fmt.Sprintf("windowsupdate: %s: cannot convert %s to %s", property, e.VT, e.Type)
+	}
+	return fmt.Sprintf("windowsupdate: %s: cannot convert %s %#v to %s", property, e.VT, e.Value, e.Type)
+}
+
+// decimal is the value of a VT_DECIMAL: a 96-bit magnitude divided by 10 to
+// the power of Scale. WUA reports download sizes as decimals.
+type decimal struct {
+	Scale    uint8
+	Negative bool
+	Hi       uint32
+	Lo       uint64
+}
+
+// rat returns the exact value of d.
+func (d decimal) rat() *big.Rat {
+	n := new(big.Int).Lsh(new(big.Int).SetUint64(uint64(d.Hi)), 64)
+	n.Or(n, new(big.Int).SetUint64(d.Lo))
+	if d.Negative {
+		n.Neg(n)
+	}
+	return new(big.Rat).SetFrac(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d.Scale)), nil))
+}
+
+// conversionError returns the error of converting v to typ.
+func conversionError(v *variant, typ string) error {
+	return &ConversionError{Property: v.Name, VT: v.VT, Value: v.Value, Type: typ}
+}
+
+// variantMissing reports whether v holds no value, which converts to the zero
+// value of any type: a nil variant, VT_EMPTY, VT_NULL, or the VT_ERROR of
+// DISP_E_PARAMNOTFOUND.
+func variantMissing(v *variant) bool {
+	if v == nil {
+		return true
+	}
+	switch v.VT {
+	case ole.VT_EMPTY, ole.VT_NULL:
+		return true
+	}
+	hr, ok := v.Value.(HRESULT)
+	return ok && hr == hrParamNotFound
+}
+
+// integerOf returns the value of x if it is an integer of any kind that fits
+// in an int64.
+func integerOf(x interface{}) (int64, bool) {
+	switch x := x.(type) {
+	case int8:
+		return int64(x), true
+	case int16:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	case int:
+		return int64(x), true
+	case uint8:
+		return int64(x), true
+	case uint16:
+		return int64(x), true
+	case uint32:
+		return int64(x), true
+	case uint64:
+		return int64(x), x <= math.MaxInt64
+	case uint:
+		return int64(x), uint64(x) <= math.MaxInt64
+	}
+	return 0, false
+}
+
+// variantInteger converts v to an integer of the given bit size. Every numeric
+// kind widens or narrows to it as long as the value is whole and in range, a
+// VT_BSTR is parsed as a decimal number, and a VT_ERROR converts to its SCODE
+// so that HResult properties keep their code.
+func variantInteger(v *variant, bitSize int, typ string) (int64, error) {
+	if variantMissing(v) {
+		return 0, nil
+	}
+	n, ok := integerOf(v.Value)
+	switch x := v.Value.(type) {
+	case float32:
+		n, ok = floatInteger(float64(x), bitSize)
+	case float64:
+		n, ok = floatInteger(x, bitSize)
+	case decimal:
+		r := x.rat()
+		ok = r.IsInt() && r.Num().IsInt64()
+		if ok {
+			n = r.Num().Int64()
+		}
+	case string:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(x), 10, bitSize)
+		n, ok = parsed, err == nil
+	case HRESULT:
+		n, ok = int64(int32(x)), true
+	}
+	if !ok || bitSize < 64 && (n < -1<<(bitSize-1) || n > 1<<(bitSize-1)-1) {
+		return 0, conversionError(v, typ)
+	}
+	return n, nil
+}
+
+// floatInteger returns x as an integer of the given bit size if it is whole
+// and in range.
+func floatInteger(x float64, bitSize int) (int64, bool) {
+	limit := math.Ldexp(1, bitSize-1)
+	if x != math.Trunc(x) || x < -limit || x >= limit {
+		return 0, false
+	}
+	return int64(x), true
+}
+
+// variantFloat converts v to a float of the given bit size. Every numeric kind
+// converts to it, rounding if needed, as long as it is in range, and a VT_BSTR
+// is parsed as a number.
+func variantFloat(v *variant, bitSize int, typ string) (float64, error) {
+	if variantMissing(v) {
+		return 0, nil
+	}
+	var f float64
+	switch x := v.Value.(type) {
+	case float32:
+		f = float64(x)
+	case float64:
+		f = x
+	case uint64:
+		f = float64(x)
+	case uint:
+		f = float64(x)
+	case decimal:
+		f, _ = x.rat().Float64()
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(x), bitSize)
+		if err != nil {
+			return 0, conversionError(v, typ)
+		}
+		f = parsed
+	default:
+		n, ok := integerOf(x)
+		if !ok {
+			return 0, conversionError(v, typ)
+		}
+		f = float64(n)
+	}
+	if bitSize == 32 && !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+		return 0, conversionError(v, typ)
+	}
+	return f, nil
+}
+
+// variantToString converts v to a string. Numbers are formatted in decimal.
+func variantToString(v *variant) (string, error) {
+	if variantMissing(v) {
+		return "", nil
+	}
+	switch x := v.Value.(type) {
+	case string:
+		return x, nil
+	case int8, int16, int32, int64, int, uint8, uint16, uint32, uint64, uint:
+		return fmt.Sprint(x), nil
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64), nil
+	case decimal:
+		return x.rat().FloatString(int(x.Scale)), nil
+	}
+	return "", conversionError(v, "string")
+}
+
+// variantToBool converts v to a bool. Numbers are true unless zero, as
+// VARIANT_BOOL is, and a VT_BSTR is parsed by strconv.ParseBool.
+func variantToBool(v *variant) (bool, error) {
+	if variantMissing(v) {
+		return false, nil
+	}
+	switch x := v.Value.(type) {
+	case bool:
+		return x, nil
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(x))
+		if err != nil {
+			return false, conversionError(v, "bool")
+		}
+		return parsed, nil
+	case float32, float64, decimal:
+		f, err := variantFloat(v, 64, "bool")
+		return f != 0, err
+	case uint64:
+		return x != 0, nil
+	case uint:
+		return x != 0, nil
+	}
+	n, ok := integerOf(v.Value)
+	if !ok {
+		return false, conversionError(v, "bool")
+	}
+	return n != 0, nil
+}
+
+// variantToTime converts a VT_DATE to a time. go-ole decodes dates it cannot
+// represent as float64, which are reported as errors.
+func variantToTime(v *variant) (*time.Time, error) {
+	if variantMissing(v) {
+		return nil, nil
+	}
+	if x, ok := v.Value.(time.Time); ok {
+		return &x, nil
+	}
+	return nil, conversionError(v, "time.Time")
+}
+
+// variantToIDispatch converts a VT_DISPATCH to its dispatcher, nil for a null
+// object.
+func variantToIDispatch(v *variant) (dispatcher, error) {
+	if variantMissing(v) {
+		return nil, nil
+	}
+	if v.VT == ole.VT_DISPATCH {
+		d, _ := v.Value.(dispatcher)
+		return d, nil
+	}
+	return nil, conversionError(v, "object")
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// fails marks a conversion expected to return a *ConversionError.
+type fails struct{}
+
+// converters convert a variant with each variantToXxx function, returning the
+// value as an interface.
+var converters = []struct {
+	name    string
+	convert func(*variant) (interface{}, error)
+}{
+	{"int32", func(v *variant) (interface{}, error) { return variantToInt32(v) }},
+	{"int64", func(v *variant) (interface{}, error) { return variantToInt64(v) }},
+	{"float32", func(v *variant) (interface{}, error) { return variantToFloat32(v) }},
+	{"float64", func(v *variant) (interface{}, error) { return variantToFloat64(v) }},
+	{"string", func(v *variant) (interface{}, error) { return variantToString(v) }},
+	{"bool", func(v *variant) (interface{}, error) { return variantToBool(v) }},
+	{"time", func(v *variant) (interface{}, error) {
+		t, err := variantToTime(v)
+		if t == nil {
+			return nil, err
+		}
+		return *t, err
+	}},
+	{"dispatch", func(v *variant) (interface{}, error) {
+		d, err := variantToIDispatch(v)
+		if d == nil {
+			return nil, err
+		}
+		return d, err
+	}},
+	{"HRESULT", func(v *variant) (interface{}, error) { return variantToHResult(v) }},
+}
+
+func TestVariantConversions(t *testing.T) {
+	date := time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC)
+	child := newFakeDispatcher(nil)
+	f := fails{}
+	tests := []struct {
+		name string
+		v    *variant
+		// The result of each converter, in the order of converters.
+		want [9]interface{}
+	}{
+		{"nil", nil, [9]interface{}{int32(0), int64(0), float32(0), 0.0, "", false, nil, nil, int32(0)}},
+		{"EMPTY", &variant{VT: ole.VT_EMPTY}, [9]interface{}{int32(0), int64(0), float32(0), 0.0, "", false, nil, nil, int32(0)}},
+		{"NULL", &variant{VT: ole.VT_NULL}, [9]interface{}{int32(0), int64(0), float32(0), 0.0, "", false, nil, nil, int32(0)}},
+		{"ERROR missing", &variant{VT: ole.VT_ERROR, Value: hrParamNotFound}, [9]interface{}{int32(0), int64(0), float32(0), 0.0, "", false, nil, nil, int32(0)}},
+		{"ERROR", &variant{VT: ole.VT_ERROR, Value: HRESULT(0x80240024)}, [9]interface{}{int32(-2145124316), int64(-2145124316), f, f, f, f, f, f, int32(-2145124316)}},
+		{"I1", &variant{VT: ole.VT_I1, Value: int8(-8)}, [9]interface{}{int32(-8), int64(-8), float32(-8), -8.0, "-8", true, f, f, int32(-8)}},
+		{"UI1", &variant{VT: ole.VT_UI1, Value: uint8(200)}, [9]interface{}{int32(200), int64(200), float32(200), 200.0, "200", true, f, f, int32(200)}},
+		{"I2", &variant{VT: ole.VT_I2, Value: int16(-2)}, [9]interface{}{int32(-2), int64(-2), float32(-2), -2.0, "-2", true, f, f, int32(-2)}},
+		{"UI2", &variant{VT: ole.VT_UI2, Value: uint16(65535)}, [9]interface{}{int32(65535), int64(65535), float32(65535), 65535.0, "65535", true, f, f, int32(65535)}},
+		{"I4", &variant{VT: ole.VT_I4, Value: int32(4)}, [9]interface{}{int32(4), int64(4), float32(4), 4.0, "4", true, f, f, int32(4)}},
+		{"I4 HRESULT", &variant{VT: ole.VT_I4, Value: int32(-2145124318)}, [9]interface{}{int32(-2145124318), int64(-2145124318), float32(-2145124318), -2145124318.0, "-2145124318", true, f, f, int32(-2145124318)}},
+		{"I4 zero", &variant{VT: ole.VT_I4, Value: int32(0)}, [9]interface{}{int32(0), int64(0), float32(0), 0.0, "0", false, f, f, int32(0)}},
+		{"UI4", &variant{VT: ole.VT_UI4, Value: uint32(4)}, [9]interface{}{int32(4), int64(4), float32(4), 4.0, "4", true, f, f, int32(4)}},
+		{"UI4 above int32", &variant{VT: ole.VT_UI4, Value: uint32(math.MaxUint32)}, [9]interface{}{f, int64(math.MaxUint32), float32(math.MaxUint32), float64(math.MaxUint32), "4294967295", true, f, f, int32(-1)}},
+		{"UI4 HRESULT", &variant{VT: ole.VT_UI4, Value: uint32(0x80240022)}, [9]interface{}{f, int64(0x80240022), float32(0x80240022), float64(0x80240022), "2149842978", true, f, f, int32(-2145124318)}},
+		{"I8", &variant{VT: ole.VT_I8, Value: int64(8)}, [9]interface{}{int32(8), int64(8), float32(8), 8.0, "8", true, f, f, int32(8)}},
+		{"I8 above int32", &variant{VT: ole.VT_I8, Value: int64(1 << 40)}, [9]interface{}{f, int64(1 << 40), float32(1 << 40), float64(1 << 40), "1099511627776", true, f, f, f}},
+		{"I8 below int32", &variant{VT: ole.VT_I8, Value: int64(-1 << 40)}, [9]interface{}{f, int64(-1 << 40), float32(-1 << 40), float64(-1 << 40), "-1099511627776", true, f, f, f}},
+		{"UI8", &variant{VT: ole.VT_UI8, Value: uint64(8)}, [9]interface{}{int32(8), int64(8), float32(8), 8.0, "8", true, f, f, int32(8)}},
+		{"UI8 above int64", &variant{VT: ole.VT_UI8, Value: uint64(math.MaxUint64)}, [9]interface{}{f, f, float32(math.MaxUint64), float64(math.MaxUint64), "18446744073709551615", true, f, f, f}},
+		{"INT", &variant{VT: ole.VT_INT, Value: int(-3)}, [9]interface{}{int32(-3), int64(-3), float32(-3), -3.0, "-3", true, f, f, int32(-3)}},
+		{"UINT", &variant{VT: ole.VT_UINT, Value: uint(3)}, [9]interface{}{int32(3), int64(3), float32(3), 3.0, "3", true, f, f, int32(3)}},
+		{"R4", &variant{VT: ole.VT_R4, Value: float32(2)}, [9]interface{}{int32(2), int64(2), float32(2), 2.0, "2", true, f, f, int32(2)}},
+		{"R4 fraction", &variant{VT: ole.VT_R4, Value: float32(1.5)}, [9]interface{}{f, f, float32(1.5), 1.5, "1.5", true, f, f, f}},
+		{"R8", &variant{VT: ole.VT_R8, Value: 2048.0}, [9]interface{}{int32(2048), int64(2048), float32(2048), 2048.0, "2048", true, f, f, int32(2048)}},
+		{"R8 fraction", &variant{VT: ole.VT_R8, Value: 2.5}, [9]interface{}{f, f, float32(2.5), 2.5, "2.5", true, f, f, f}},
+		{"R8 zero", &variant{VT: ole.VT_R8, Value: 0.0}, [9]interface{}{int32(0), int64(0), float32(0), 0.0, "0", false, f, f, int32(0)}},
+		{"R8 above float32", &variant{VT: ole.VT_R8, Value: 1e300}, [9]interface{}{f, f, f, 1e300, "1e+300", true, f, f, f}},
+		{"DECIMAL", &variant{VT: ole.VT_DECIMAL, Value: decimal{Lo: 52428800}}, [9]interface{}{int32(52428800), int64(52428800), float32(52428800), 52428800.0, "52428800", true, f, f, int32(52428800)}},
+		{"DECIMAL negative", &variant{VT: ole.VT_DECIMAL, Value: decimal{Negative: true, Lo: 7}}, [9]interface{}{int32(-7), int64(-7), float32(-7), -7.0, "-7", true, f, f, int32(-7)}},
+		{"DECIMAL above int32", &variant{VT: ole.VT_DECIMAL, Value: decimal{Lo: 1 << 40}}, [9]interface{}{f, int64(1 << 40), float32(1 << 40), float64(1 << 40), "1099511627776", true, f, f, f}},
+		{"DECIMAL above int64", &variant{VT: ole.VT_DECIMAL, Value: decimal{Hi: 1}}, [9]interface{}{f, f, float32(1 << 64), float64(1 << 64), "18446744073709551616", true, f, f, f}},
+		{"DECIMAL scaled whole", &variant{VT: ole.VT_DECIMAL, Value: decimal{Scale: 2, Lo: 300}}, [9]interface{}{int32(3), int64(3), float32(3), 3.0, "3.00", true, f, f, int32(3)}},
+		{"DECIMAL fraction", &variant{VT: ole.VT_DECIMAL, Value: decimal{Scale: 1, Lo: 15}}, [9]interface{}{f, f, float32(1.5), 1.5, "1.5", true, f, f, f}},
+		{"DECIMAL zero", &variant{VT: ole.VT_DECIMAL, Value: decimal{}}, [9]interface{}{int32(0), int64(0), float32(0), 0.0, "0", false, f, f, int32(0)}},
+		{"BSTR", &variant{VT: ole.VT_BSTR, Value: "text"}, [9]interface{}{f, f, f, f, "text", f, f, f, f}},
+		{"BSTR number", &variant{VT: ole.VT_BSTR, Value: " 1024 "}, [9]interface{}{int32(1024), int64(1024), float32(1024), 1024.0, " 1024 ", f, f, f, int32(1024)}},
+		{"BSTR fraction", &variant{VT: ole.VT_BSTR, Value: "0.5"}, [9]interface{}{f, f, float32(0.5), 0.5, "0.5", f, f, f, f}},
+		{"BSTR above int32", &variant{VT: ole.VT_BSTR, Value: "4294967296"}, [9]interface{}{f, int64(4294967296), float32(4294967296), 4294967296.0, "4294967296", f, f, f, f}},
+		{"BSTR bool", &variant{VT: ole.VT_BSTR, Value: "true"}, [9]interface{}{f, f, f, f, "true", true, f, f, f}},
+		{"BSTR one", &variant{VT: ole.VT_BSTR, Value: "1"}, [9]interface{}{int32(1), int64(1), float32(1), 1.0, "1", true, f, f, int32(1)}},
+		{"BSTR empty", &variant{VT: ole.VT_BSTR, Value: ""}, [9]interface{}{f, f, f, f, "", f, f, f, f}},
+		{"BOOL", &variant{VT: ole.VT_BOOL, Value: true}, [9]interface{}{f, f, f, f, f, true, f, f, f}},
+		{"DATE", &variant{VT: ole.VT_DATE, Value: date}, [9]interface{}{f, f, f, f, f, f, date, f, f}},
+		{"DATE undecoded", &variant{VT: ole.VT_DATE, Value: 3e6}, [9]interface{}{int32(3e6), int64(3e6), float32(3e6), 3e6, "3e+06", true, f, f, int32(3e6)}},
+		{"DISPATCH", &variant{VT: ole.VT_DISPATCH, Value: child}, [9]interface{}{f, f, f, f, f, f, f, child, f}},
+		{"DISPATCH null", &variant{VT: ole.VT_DISPATCH}, [9]interface{}{f, f, f, f, f, f, f, nil, f}},
+		{"CY", &variant{VT: ole.VT_CY}, [9]interface{}{f, f, f, f, f, f, f, f, f}},
+		{"UNKNOWN", &variant{VT: ole.VT_UNKNOWN, Value: &ole.IUnknown{}}, [9]interface{}{f, f, f, f, f, f, f, f, f}},
+	}
+	for _, tt := range tests {
+		for i, c := range converters {
+			got, err := c.convert(tt.v.named("Property"))
+			if _, wantErr := tt.want[i].(fails); wantErr {
+				var convErr *ConversionError
+				if !errors.As(err, &convErr) || convErr.Property != "Property" || convErr.VT != tt.v.VT {
+					t.Errorf("%s to %s: got %#v, %v; want a *ConversionError", tt.name, c.name, got, err)
+				}
+				continue
+			}
+			if err != nil || got != tt.want[i] {
+				t.Errorf("%s to %s: got %#v, %v; want %#v", tt.name, c.name, got, err, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestConversionError(t *testing.T) {
+	_, err := toInt32Err(&variant{VT: ole.VT_BSTR, Value: "lots", Name: "RecommendedHardDiskSpace"}, nil)
+	for _, want := range []string{"RecommendedHardDiskSpace", "VT_BSTR", `"lots"`, "int32"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want it to contain %s", err, want)
+		}
+	}
+	_, err = toInt32Err(&variant{VT: ole.VT_CY}, nil)
+	if want := "windowsupdate: value: cannot convert VT_CY to int32"; err == nil || err.Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
+
+func TestConversion_Properties(t *testing.T) {
+	// WUA returns these with other VARTYPEs than documented.
+	disp := newFakeDispatcher(map[string]interface{}{
+		"RecommendedHardDiskSpace": int16(300),
+		"HResult":                  &variant{VT: ole.VT_ERROR, Value: HRESULT(0x80240022)},
+		"ResultCode":               uint32(2),
+		"MaxDownloadSize":          &variant{VT: ole.VT_DECIMAL, Value: decimal{Lo: 1 << 33}},
+	})
+	if got, err := toInt32Err(disp.GetProperty("RecommendedHardDiskSpace")); got != 300 || err != nil {
+		t.Errorf("RecommendedHardDiskSpace = %d, %v; want 300", got, err)
+	}
+	if got, err := toInt32Err(disp.GetProperty("HResult")); HRESULT(uint32(got)) != 0x80240022 || err != nil {
+		t.Errorf("HResult = %#x, %v; want 0x80240022", got, err)
+	}
+	if got, err := toEnumErr[OperationResultCode](disp.GetProperty("ResultCode")); got != OperationResultCodeOrcSucceeded || err != nil {
+		t.Errorf("ResultCode = %v, %v; want Succeeded", got, err)
+	}
+	if got, err := toInt64Err(disp.GetProperty("MaxDownloadSize")); got != 1<<33 || err != nil {
+		t.Errorf("MaxDownloadSize = %d, %v; want %d", got, err, int64(1<<33))
+	}
+
+	_, err := toInt32Err(disp.GetProperty("MaxDownloadSize"))
+	var convErr *ConversionError
+	if !errors.As(err, &convErr) || convErr.Property != "MaxDownloadSize" || convErr.VT != ole.VT_DECIMAL || convErr.Type != "int32" {
+		t.Errorf("MaxDownloadSize as int32: error = %v, want a *ConversionError", err)
+	}
+}
+
+func TestFromOleVariant_DecimalAndError(t *testing.T) {
+	var v ole.VARIANT
+	raw := (*[16]byte)(unsafe.Pointer(&v))
+	raw[0] = byte(ole.VT_DECIMAL)
+	raw[2] = 2    // scale
+	raw[3] = 0x80 // sign
+	raw[4] = 1    // Hi32
+	v.Val = 150
+	got, err := fromOleVariant(&v, nil)
+	if want := (decimal{Scale: 2, Negative: true, Hi: 1, Lo: 150}); err != nil || got.Value != want {
+		t.Errorf("fromOleVariant(VT_DECIMAL) = %+v, %v; want %+v", got, err, want)
+	}
+
+	v = ole.NewVariant(ole.VT_ERROR, int64(uint32(hrParamNotFound)))
+	if got, err := fromOleVariant(&v, nil); err != nil || got.Value != hrParamNotFound {
+		t.Errorf("fromOleVariant(VT_ERROR) = %+v, %v; want %v", got, err, hrParamNotFound)
+	}
+}
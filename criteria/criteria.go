@@ -0,0 +1,251 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package criteria builds Windows Update Agent search criteria, the strings
+// accepted by IUpdateSearcher.Search, from typed terms.
+//
+//	c := criteria.Or(
+//		criteria.And(criteria.IsInstalled(false), criteria.Type(criteria.Software)),
+//		criteria.And(criteria.IsInstalled(false), criteria.Type(criteria.Driver)),
+//	)
+//	c.String() // "(IsInstalled=0 and Type='Software') or (IsInstalled=0 and Type='Driver')"
+//
+// WUA only allows "or" at the top level of a criteria, so And takes terms and
+// Or takes terms or And groups; anything else does not compile.
+//
+// Criteria read from configuration can be checked with Parse, which reports
+// errors with their column, and normalized with Normalize before being passed to
+// WUA.
+// https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-search
+package criteria
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Expr is a search criteria: a Term, an And group or an Or of those.
+type Expr interface {
+	// String returns the criteria in the form accepted by WUA.
+	String() string
+	isExpr()
+}
+
+// Clause is an operand of Or: a Term or an And group.
+type Clause interface {
+	Expr
+	isClause()
+}
+
+// Operator is the comparison of a Term.
+type Operator string
+
+// The operators of the criteria language.
+const (
+	Equal    Operator = "="
+	NotEqual Operator = "!="
+	Contains Operator = "contains"
+)
+
+// Value is the literal a Term compares against: a quoted string or an integer.
+type Value struct {
+	Quoted bool
+	Str    string
+	Int    int64
+}
+
+// String returns a string literal.
+func String(s string) Value {
+	return Value{Quoted: true, Str: s}
+}
+
+// Int returns an integer literal.
+func Int(i int64) Value {
+	return Value{Int: i}
+}
+
+// Bool returns the integer literal WUA uses for a boolean, 1 or 0.
+func Bool(b bool) Value {
+	if b {
+		return Int(1)
+	}
+	return Int(0)
+}
+
+func (v Value) String() string {
+	if v.Quoted {
+		return "'" + Escape(v.Str) + "'"
+	}
+	return strconv.FormatInt(v.Int, 10)
+}
+
+// Escape escapes backslashes and single quotes with a backslash so that s can
+// be used inside a quoted criteria literal, as IUpdateSearcher.EscapeString does.
+func Escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+// Term is a single criterion such as IsInstalled=0.
+type Term struct {
+	Name     string
+	Operator Operator
+	Value    Value
+}
+
+func (t Term) String() string {
+	if t.Operator == Contains {
+		return t.Name + " contains " + t.Value.String()
+	}
+	return t.Name + string(t.Operator) + t.Value.String()
+}
+
+func (Term) isExpr()   {}
+func (Term) isClause() {}
+
+// AndExpr is a group of terms that must all match.
+type AndExpr struct {
+	Terms []Term
+}
+
+// And returns a group matching updates that match every term.
+func And(terms ...Term) AndExpr {
+	return AndExpr{Terms: terms}
+}
+
+func (a AndExpr) String() string {
+	parts := make([]string, len(a.Terms))
+	for i, term := range a.Terms {
+		parts[i] = term.String()
+	}
+	return strings.Join(parts, " and ")
+}
+
+func (AndExpr) isExpr()   {}
+func (AndExpr) isClause() {}
+
+// OrExpr is a top-level disjunction of clauses.
+type OrExpr struct {
+	Clauses []Clause
+}
+
+// Or returns a criteria matching updates that match any clause.
+func Or(clauses ...Clause) OrExpr {
+	return OrExpr{Clauses: clauses}
+}
+
+// String parenthesizes And groups of more than one term.
+func (o OrExpr) String() string {
+	parts := make([]string, len(o.Clauses))
+	for i, clause := range o.Clauses {
+		parts[i] = clause.String()
+		if and, ok := clause.(AndExpr); ok && len(and.Terms) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " or ")
+}
+
+func (OrExpr) isExpr() {}
+
+// UpdateType is a value of the Type criterion.
+type UpdateType string
+
+// The update types.
+const (
+	Software UpdateType = "Software"
+	Driver   UpdateType = "Driver"
+)
+
+// Action is a value of the DeploymentAction criterion.
+type Action string
+
+// The deployment actions.
+const (
+	Installation         Action = "Installation"
+	Uninstallation       Action = "Uninstallation"
+	Detection            Action = "Detection"
+	OptionalInstallation Action = "OptionalInstallation"
+)
+
+// Type finds updates of the given type.
+func Type(t UpdateType) Term {
+	return Term{Name: "Type", Operator: Equal, Value: String(string(t))}
+}
+
+// NotType finds updates that are not of the given type.
+func NotType(t UpdateType) Term {
+	return Term{Name: "Type", Operator: NotEqual, Value: String(string(t))}
+}
+
+// DeploymentAction finds updates deployed for the given action.
+func DeploymentAction(a Action) Term {
+	return Term{Name: "DeploymentAction", Operator: Equal, Value: String(string(a))}
+}
+
+// IsAssigned finds updates that are, or are not, intended for deployment by
+// Automatic Updates.
+func IsAssigned(b bool) Term {
+	return Term{Name: "IsAssigned", Operator: Equal, Value: Bool(b)}
+}
+
+// BrowseOnly finds updates that are, or are not, considered optional.
+func BrowseOnly(b bool) Term {
+	return Term{Name: "BrowseOnly", Operator: Equal, Value: Bool(b)}
+}
+
+// AutoSelectOnWebSites finds updates that are, or are not, flagged to be
+// automatically selected by Windows Update.
+func AutoSelectOnWebSites(b bool) Term {
+	return Term{Name: "AutoSelectOnWebSites", Operator: Equal, Value: Bool(b)}
+}
+
+// UpdateID finds the update with the given ID.
+func UpdateID(id string) Term {
+	return Term{Name: "UpdateID", Operator: Equal, Value: String(id)}
+}
+
+// NotUpdateID excludes the update with the given ID.
+func NotUpdateID(id string) Term {
+	return Term{Name: "UpdateID", Operator: NotEqual, Value: String(id)}
+}
+
+// RevisionNumber finds updates with the given revision number. WUA expects it
+// to be combined with UpdateID.
+func RevisionNumber(n int32) Term {
+	return Term{Name: "RevisionNumber", Operator: Equal, Value: Int(int64(n))}
+}
+
+// CategoryIDsContains finds updates that belong to the category with the given ID.
+func CategoryIDsContains(id string) Term {
+	return Term{Name: "CategoryIDs", Operator: Contains, Value: String(id)}
+}
+
+// IsInstalled finds updates that are, or are not, installed.
+func IsInstalled(b bool) Term {
+	return Term{Name: "IsInstalled", Operator: Equal, Value: Bool(b)}
+}
+
+// IsHidden finds updates that are, or are not, hidden.
+func IsHidden(b bool) Term {
+	return Term{Name: "IsHidden", Operator: Equal, Value: Bool(b)}
+}
+
+// IsPresent finds updates that are, or are not, present on the computer.
+func IsPresent(b bool) Term {
+	return Term{Name: "IsPresent", Operator: Equal, Value: Bool(b)}
+}
+
+// RebootRequired finds updates that do, or do not, require a restart.
+func RebootRequired(b bool) Term {
+	return Term{Name: "RebootRequired", Operator: Equal, Value: Bool(b)}
+}
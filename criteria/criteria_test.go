@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criteria
+
+import "testing"
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		term Term
+		want string
+	}{
+		{Type(Software), "Type='Software'"},
+		{NotType(Driver), "Type!='Driver'"},
+		{DeploymentAction(OptionalInstallation), "DeploymentAction='OptionalInstallation'"},
+		{IsAssigned(true), "IsAssigned=1"},
+		{BrowseOnly(false), "BrowseOnly=0"},
+		{AutoSelectOnWebSites(true), "AutoSelectOnWebSites=1"},
+		{UpdateID("7a3e1c1e-1b4f-4f4c-9d0a-3c3b1a0b5f11"), "UpdateID='7a3e1c1e-1b4f-4f4c-9d0a-3c3b1a0b5f11'"},
+		{NotUpdateID("x"), "UpdateID!='x'"},
+		{RevisionNumber(200), "RevisionNumber=200"},
+		{CategoryIDsContains("0fa1201d-4330-4fa8-8ae9-b877473b6441"), "CategoryIDs contains '0fa1201d-4330-4fa8-8ae9-b877473b6441'"},
+		{IsInstalled(false), "IsInstalled=0"},
+		{IsHidden(true), "IsHidden=1"},
+		{IsPresent(false), "IsPresent=0"},
+		{RebootRequired(true), "RebootRequired=1"},
+		{UpdateID(`it's a \ test`), `UpdateID='it\'s a \\ test'`},
+	}
+	for _, tt := range tests {
+		if got := tt.term.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestComposition(t *testing.T) {
+	tests := []struct {
+		expr Expr
+		want string
+	}{
+		{And(IsInstalled(false), IsHidden(false)), "IsInstalled=0 and IsHidden=0"},
+		{Or(IsInstalled(true), IsHidden(true)), "IsInstalled=1 or IsHidden=1"},
+		{
+			Or(And(IsInstalled(false), Type(Software)), And(IsInstalled(false), Type(Driver))),
+			"(IsInstalled=0 and Type='Software') or (IsInstalled=0 and Type='Driver')",
+		},
+		{Or(And(IsInstalled(false)), RebootRequired(true)), "IsInstalled=0 or RebootRequired=1"},
+		{And(UpdateID("id"), RevisionNumber(3)), "UpdateID='id' and RevisionNumber=3"},
+	}
+	for _, tt := range tests {
+		if got := tt.expr.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := map[string]string{
+		"":                 "",
+		"simple":           "simple",
+		"with'quote":       `with\'quote`,
+		`with"double`:      `with"double`,
+		`with\backslash`:   `with\\backslash`,
+		`it's a \'mixed\'`: `it\'s a \\\'mixed\\\'`,
+	}
+	for input, want := range tests {
+		if got := Escape(input); got != want {
+			t.Errorf("Escape(%q) = %q, want %q", input, got, want)
+		}
+		expr, err := Parse("UpdateID='" + Escape(input) + "'")
+		if err != nil || expr != UpdateID(input) {
+			t.Errorf("Parse of escaped %q = %v, %v; want %v", input, expr, err, UpdateID(input))
+		}
+	}
+}
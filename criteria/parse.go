@@ -0,0 +1,467 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criteria
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError is an invalid criteria string. Column is the 1-based position, in
+// characters, of the offending token.
+type SyntaxError struct {
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("criteria: column %d: %s", e.Column, e.Msg)
+}
+
+// valueKind is the type of value a criterion compares against.
+type valueKind int
+
+const (
+	boolValue valueKind = iota
+	intValue
+	stringValue
+)
+
+// criterion describes one documented criterion.
+type criterion struct {
+	name      string
+	operators []Operator
+	kind      valueKind
+	// values lists the accepted string values, if they are restricted.
+	values []string
+}
+
+var knownCriteria = []criterion{
+	{name: "Type", operators: []Operator{Equal, NotEqual}, kind: stringValue, values: []string{string(Software), string(Driver)}},
+	{name: "DeploymentAction", operators: []Operator{Equal}, kind: stringValue, values: []string{string(Installation), string(Uninstallation), string(Detection), string(OptionalInstallation)}},
+	{name: "IsAssigned", operators: []Operator{Equal}, kind: boolValue},
+	{name: "BrowseOnly", operators: []Operator{Equal}, kind: boolValue},
+	{name: "AutoSelectOnWebSites", operators: []Operator{Equal}, kind: boolValue},
+	{name: "UpdateID", operators: []Operator{Equal, NotEqual}, kind: stringValue},
+	{name: "RevisionNumber", operators: []Operator{Equal}, kind: intValue},
+	{name: "CategoryIDs", operators: []Operator{Contains}, kind: stringValue},
+	{name: "IsInstalled", operators: []Operator{Equal}, kind: boolValue},
+	{name: "IsHidden", operators: []Operator{Equal}, kind: boolValue},
+	{name: "IsPresent", operators: []Operator{Equal}, kind: boolValue},
+	{name: "RebootRequired", operators: []Operator{Equal}, kind: boolValue},
+}
+
+// lookupCriterion finds a criterion by name, ignoring case.
+func lookupCriterion(name string) *criterion {
+	for i := range knownCriteria {
+		if strings.EqualFold(knownCriteria[i].name, name) {
+			return &knownCriteria[i]
+		}
+	}
+	return nil
+}
+
+// checkOperator reports why op is not valid for c, or "" if it is.
+func (c *criterion) checkOperator(op Operator) string {
+	names := make([]string, len(c.operators))
+	for i, o := range c.operators {
+		if o == op {
+			return ""
+		}
+		names[i] = string(o)
+	}
+	return fmt.Sprintf("%s does not support operator %s, only %s", c.name, op, strings.Join(names, ", "))
+}
+
+// checkValue reports why value is not valid for c, or "" if it is. A restricted
+// string value is returned in its documented case.
+func (c *criterion) checkValue(value Value) (Value, string) {
+	switch c.kind {
+	case boolValue:
+		if value.Quoted || (value.Int != 0 && value.Int != 1) {
+			return value, fmt.Sprintf("%s expects 0 or 1, got %s", c.name, value)
+		}
+	case intValue:
+		if value.Quoted || value.Int < 0 {
+			return value, fmt.Sprintf("%s expects a non-negative integer, got %s", c.name, value)
+		}
+	case stringValue:
+		if !value.Quoted {
+			return value, fmt.Sprintf("%s expects a quoted string, got %s", c.name, value)
+		}
+		if c.values == nil {
+			return value, ""
+		}
+		for _, v := range c.values {
+			if strings.EqualFold(v, value.Str) {
+				return String(v), ""
+			}
+		}
+		return value, fmt.Sprintf("%s expects one of %s, got %s", c.name, strings.Join(c.values, ", "), value)
+	}
+	return value, ""
+}
+
+// Parse parses and validates a criteria string. Criterion names, operators and
+// keywords are matched without regard to case; the returned Expr uses the
+// documented spelling, so Parse(s).String() is the canonical form of s.
+func Parse(s string) (Expr, error) {
+	p := &parser{lexer: lexer{input: []rune(s)}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return n.expr(false)
+}
+
+// Normalize returns the canonical form of a criteria string.
+func Normalize(s string) (string, error) {
+	expr, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return expr.String(), nil
+}
+
+// Validate checks an Expr built by hand, for instance a Term literal, against
+// the documented criteria.
+func Validate(expr Expr) error {
+	switch e := expr.(type) {
+	case Term:
+		c := lookupCriterion(e.Name)
+		if c == nil || c.name != e.Name {
+			return fmt.Errorf("criteria: unknown criterion %q", e.Name)
+		}
+		if msg := c.checkOperator(e.Operator); msg != "" {
+			return fmt.Errorf("criteria: %s", msg)
+		}
+		if value, msg := c.checkValue(e.Value); msg != "" || value != e.Value {
+			if msg == "" {
+				msg = fmt.Sprintf("%s value %s must be spelled %s", c.name, e.Value, value)
+			}
+			return fmt.Errorf("criteria: %s", msg)
+		}
+	case AndExpr:
+		if len(e.Terms) == 0 {
+			return fmt.Errorf("criteria: empty and")
+		}
+		for _, term := range e.Terms {
+			if err := Validate(term); err != nil {
+				return err
+			}
+		}
+	case OrExpr:
+		if len(e.Clauses) == 0 {
+			return fmt.Errorf("criteria: empty or")
+		}
+		for _, clause := range e.Clauses {
+			if err := Validate(clause); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("criteria: unsupported expression %T", expr)
+	}
+	return nil
+}
+
+// node is the parse tree, before the nesting rules are applied.
+type node struct {
+	op       string // "term", "and" or "or"
+	column   int
+	term     Term
+	children []*node
+}
+
+// expr converts the parse tree into an Expr, flattening nested groups and
+// rejecting an or below an and.
+func (n *node) expr(insideAnd bool) (Expr, error) {
+	switch n.op {
+	case "and":
+		and := AndExpr{}
+		for _, child := range n.children {
+			e, err := child.expr(true)
+			if err != nil {
+				return nil, err
+			}
+			switch e := e.(type) {
+			case Term:
+				and.Terms = append(and.Terms, e)
+			case AndExpr:
+				and.Terms = append(and.Terms, e.Terms...)
+			}
+		}
+		return and, nil
+	case "or":
+		if insideAnd {
+			return nil, &SyntaxError{Column: n.column, Msg: "or is only allowed at the top level, not inside and"}
+		}
+		or := OrExpr{}
+		for _, child := range n.children {
+			e, err := child.expr(false)
+			if err != nil {
+				return nil, err
+			}
+			switch e := e.(type) {
+			case Clause:
+				or.Clauses = append(or.Clauses, e)
+			case OrExpr:
+				or.Clauses = append(or.Clauses, e.Clauses...)
+			}
+		}
+		return or, nil
+	}
+	return n.term, nil
+}
+
+type parser struct {
+	lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Column: p.tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (*node, error) {
+	return p.parseList("or", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (*node, error) {
+	return p.parseList("and", p.parsePrimary)
+}
+
+// parseList parses operands separated by the keyword. A single operand is
+// returned as is; the column of a list is that of its first keyword.
+func (p *parser) parseList(keyword string, operand func() (*node, error)) (*node, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	list := &node{op: keyword, children: []*node{first}}
+	for p.tok.isKeyword(keyword) {
+		if len(list.children) == 1 {
+			list.column = p.tok.column
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		list.children = append(list.children, n)
+	}
+	if len(list.children) == 1 {
+		return first, nil
+	}
+	return list, nil
+}
+
+func (p *parser) parsePrimary() (*node, error) {
+	if p.tok.kind == tokLParen {
+		open := p.tok
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ) to close ( at column %d, got %s", open.column, p.tok)
+		}
+		return n, p.next()
+	}
+	return p.parseTerm()
+}
+
+func (p *parser) parseTerm() (*node, error) {
+	if p.tok.kind != tokIdent || p.tok.isKeyword("and") || p.tok.isKeyword("or") || p.tok.isKeyword("contains") {
+		return nil, p.errorf("expected a criterion, got %s", p.tok)
+	}
+	nameTok := p.tok
+	c := lookupCriterion(nameTok.text)
+	if c == nil {
+		return nil, p.errorf("unknown criterion %q", nameTok.text)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	var op Operator
+	switch {
+	case p.tok.kind == tokEqual:
+		op = Equal
+	case p.tok.kind == tokNotEqual:
+		op = NotEqual
+	case p.tok.isKeyword("contains"):
+		op = Contains
+	default:
+		return nil, p.errorf("expected =, != or contains after %s, got %s", c.name, p.tok)
+	}
+	if msg := c.checkOperator(op); msg != "" {
+		return nil, p.errorf("%s", msg)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	var value Value
+	switch p.tok.kind {
+	case tokString:
+		value = String(p.tok.text)
+	case tokInt:
+		i, err := strconv.ParseInt(p.tok.text, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer %s", p.tok.text)
+		}
+		value = Int(i)
+	default:
+		return nil, p.errorf("expected a value after %s %s, got %s", c.name, op, p.tok)
+	}
+	value, msg := c.checkValue(value)
+	if msg != "" {
+		return nil, p.errorf("%s", msg)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return &node{op: "term", column: nameTok.column, term: Term{Name: c.name, Operator: op, Value: value}}, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokEqual
+	tokNotEqual
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of criteria"
+	case tokString:
+		return "string " + String(t.text).String()
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func (l *lexer) lex() (token, error) {
+	for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	column := start + 1
+	if l.pos == len(l.input) {
+		return token{kind: tokEOF, column: column}, nil
+	}
+
+	r := l.input[l.pos]
+	switch {
+	case r == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", column: column}, nil
+	case r == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", column: column}, nil
+	case r == '=':
+		l.pos++
+		return token{kind: tokEqual, text: "=", column: column}, nil
+	case r == '!':
+		if l.pos+1 < len(l.input) && l.input[l.pos+1] == '=' {
+			l.pos += 2
+			return token{kind: tokNotEqual, text: "!=", column: column}, nil
+		}
+		return token{}, &SyntaxError{Column: column, Msg: "expected !="}
+	case r == '\'':
+		return l.lexString(column)
+	case r == '-' || unicode.IsDigit(r):
+		l.pos++
+		for l.pos < len(l.input) && unicode.IsDigit(l.input[l.pos]) {
+			l.pos++
+		}
+		text := string(l.input[start:l.pos])
+		if text == "-" {
+			return token{}, &SyntaxError{Column: column, Msg: "expected a digit after -"}
+		}
+		return token{kind: tokInt, text: text, column: column}, nil
+	case unicode.IsLetter(r) || r == '_':
+		for l.pos < len(l.input) && (unicode.IsLetter(l.input[l.pos]) || unicode.IsDigit(l.input[l.pos]) || l.input[l.pos] == '_') {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: string(l.input[start:l.pos]), column: column}, nil
+	}
+	return token{}, &SyntaxError{Column: column, Msg: fmt.Sprintf("unexpected character %q", r)}
+}
+
+// lexString reads a single-quoted string in which \ escapes the next character.
+func (l *lexer) lexString(column int) (token, error) {
+	var b strings.Builder
+	l.pos++
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		l.pos++
+		switch r {
+		case '\'':
+			return token{kind: tokString, text: b.String(), column: column}, nil
+		case '\\':
+			if l.pos == len(l.input) {
+				return token{}, &SyntaxError{Column: l.pos, Msg: "unterminated escape sequence"}
+			}
+			r = l.input[l.pos]
+			l.pos++
+		}
+		b.WriteRune(r)
+	}
+	return token{}, &SyntaxError{Column: column, Msg: "unterminated string"}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package criteria
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Expr
+	}{
+		{"IsInstalled=0", IsInstalled(false)},
+		{"  isinstalled = 0  AND ishidden=0", And(IsInstalled(false), IsHidden(false))},
+		{"Type='driver'", Type(Driver)},
+		{"Type != 'Software'", NotType(Software)},
+		{"CategoryIDs CONTAINS '0fa1201d-4330-4fa8-8ae9-b877473b6441'", CategoryIDsContains("0fa1201d-4330-4fa8-8ae9-b877473b6441")},
+		{"UpdateID='a' and RevisionNumber=200", And(UpdateID("a"), RevisionNumber(200))},
+		{`UpdateID='it\'s \\'`, UpdateID(`it's \`)},
+		{"(IsInstalled=0)", IsInstalled(false)},
+		{"IsInstalled=0 and (IsHidden=0 and IsPresent=1)", And(IsInstalled(false), IsHidden(false), IsPresent(true))},
+		{
+			"(IsInstalled=0 and Type='Software') or (IsInstalled=0 and Type='Driver')",
+			Or(And(IsInstalled(false), Type(Software)), And(IsInstalled(false), Type(Driver))),
+		},
+		{"IsHidden=1 or (RebootRequired=1 or IsPresent=1)", Or(IsHidden(true), RebootRequired(true), IsPresent(true))},
+		{"(IsHidden=1 or RebootRequired=1)", Or(IsHidden(true), RebootRequired(true))},
+		{"DeploymentAction='OptionalInstallation' and AutoSelectOnWebSites=1 and BrowseOnly=0 and IsAssigned=1",
+			And(DeploymentAction(OptionalInstallation), AutoSelectOnWebSites(true), BrowseOnly(false), IsAssigned(true))},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input  string
+		column int
+		msg    string
+	}{
+		{"", 1, "expected a criterion, got end of criteria"},
+		{"IsInstaled=0", 1, `unknown criterion "IsInstaled"`},
+		{"IsInstalled=0 and IsHidden", 27, "expected =, != or contains after IsHidden, got end of criteria"},
+		{"IsInstalled!=0", 12, "IsInstalled does not support operator !=, only ="},
+		{"CategoryIDs='x'", 12, "CategoryIDs does not support operator =, only contains"},
+		{"IsInstalled=2", 13, "IsInstalled expects 0 or 1, got 2"},
+		{"IsInstalled='0'", 13, "IsInstalled expects 0 or 1, got '0'"},
+		{"RevisionNumber=-1", 16, "RevisionNumber expects a non-negative integer, got -1"},
+		{"UpdateID=5", 10, "UpdateID expects a quoted string, got 5"},
+		{"Type='Firmware'", 6, "Type expects one of Software, Driver, got 'Firmware'"},
+		{"UpdateID='abc", 10, "unterminated string"},
+		{"IsInstalled=0 IsHidden=0", 15, `unexpected "IsHidden"`},
+		{"(IsInstalled=0", 15, "expected ) to close ( at column 1, got end of criteria"},
+		{"IsInstalled=0 and (IsHidden=0 or IsPresent=1)", 31, "or is only allowed at the top level, not inside and"},
+		{"IsInstalled=0 & IsHidden=0", 15, "unexpected character '&'"},
+		{"IsInstalled=0 and or", 19, `expected a criterion, got "or"`},
+		{"Type='Software' and Type ! 'Driver'", 26, "expected !="},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want a *SyntaxError", tt.input, err)
+			continue
+		}
+		if syntaxErr.Column != tt.column || syntaxErr.Msg != tt.msg {
+			t.Errorf("Parse(%q) error = column %d: %s, want column %d: %s", tt.input, syntaxErr.Column, syntaxErr.Msg, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"isinstalled=0 AND type = 'software'":                 "IsInstalled=0 and Type='Software'",
+		"(IsHidden = 1) OR (IsInstalled=0 and IsPresent = 1)": "IsHidden=1 or (IsInstalled=0 and IsPresent=1)",
+		"categoryids contains 'x'":                            "CategoryIDs contains 'x'",
+	}
+	for input, want := range tests {
+		got, err := Normalize(input)
+		if err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", input, got, err, want)
+		}
+		if again, err := Normalize(got); err != nil || again != got {
+			t.Errorf("Normalize(%q) = %q, %v; want it unchanged", got, again, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []Expr{
+		IsInstalled(false),
+		And(IsInstalled(false), Type(Driver)),
+		Or(And(UpdateID("a"), RevisionNumber(1)), IsHidden(true)),
+	}
+	for _, expr := range valid {
+		if err := Validate(expr); err != nil {
+			t.Errorf("Validate(%s) = %v", expr, err)
+		}
+	}
+
+	invalid := []Expr{
+		Term{Name: "IsInstaled", Operator: Equal, Value: Int(0)},
+		Term{Name: "isinstalled", Operator: Equal, Value: Int(0)},
+		Term{Name: "IsInstalled", Operator: Contains, Value: Int(0)},
+		Term{Name: "Type", Operator: Equal, Value: String("driver")},
+		Type("Firmware"),
+		And(),
+		Or(),
+		Or(IsInstalled(false), And(IsHidden(true), Term{Name: "IsHidden", Operator: Equal, Value: Int(3)})),
+	}
+	for _, expr := range invalid {
+		if err := Validate(expr); err == nil {
+			t.Errorf("Validate(%#v) succeeded", expr)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+	"github.com/go-ole/go-ole/oleutil"
+)
+
+// ErrNotSupported is returned by the constructors of COM objects, such as
+// NewUpdateSession, on platforms without Windows Update Agent.
+var ErrNotSupported = errors.New("windowsupdate: not supported on this platform")
+
+// dispatcher is the IDispatch property and method layer every wrapper type is
+// built on. comDispatcher forwards to a live COM object through oleutil;
+// fakeDispatcher serves scripted values so the toIXxx converters can be tested
+// without Windows.
+type dispatcher interface {
+	GetProperty(name string, params ...interface{}) (*variant, error)
+	PutProperty(name string, params ...interface{}) (*variant, error)
+	CallMethod(name string, params ...interface{}) (*variant, error)
+	// CreateObject creates a new object, such as an update collection, from the
+	// same backend as this dispatcher.
+	CreateObject(programID string) (dispatcher, error)
+	// IDispatch returns the underlying COM object, or nil if there is none.
+	IDispatch() *ole.IDispatch
+	// Release drops the reference to the object. Later calls fail with
+	// ErrReleased; releasing again is a no-op.
+	Release()
+}
+
+// variant is the decoded form of an ole.VARIANT: the VARTYPE reported by the
+// object and its Go value. A VT_DISPATCH value holds a dispatcher, or nil for a
+// null object; a VT_ERROR value holds its SCODE as an HRESULT and a VT_DECIMAL
+// value a decimal. Name is the member that returned it, for ConversionError.
+type variant struct {
+	VT    ole.VT
+	Value interface{}
+	Name  string
+}
+
+// named returns a copy of v returned by the member name.
+func (v *variant) named(name string) *variant {
+	if v == nil {
+		return nil
+	}
+	named := *v
+	named.Name = name
+	return &named
+}
+
+// comDispatcher is a dispatcher backed by a COM IDispatch.
+type comDispatcher struct {
+	mu   sync.Mutex
+	disp *ole.IDispatch
+}
+
+func newComDispatcher(disp *ole.IDispatch) dispatcher {
+	if disp == nil {
+		return nil
+	}
+	return &comDispatcher{disp: disp}
+}
+
+func (d *comDispatcher) GetProperty(name string, params ...interface{}) (*variant, error) {
+	disp := d.IDispatch()
+	if disp == nil {
+		return nil, ErrReleased
+	}
+	result, err := fromOleVariant(oleutil.GetProperty(disp, name, comParams(params)...))
+	return result.named(name), err
+}
+
+func (d *comDispatcher) PutProperty(name string, params ...interface{}) (*variant, error) {
+	disp := d.IDispatch()
+	if disp == nil {
+		return nil, ErrReleased
+	}
+	result, err := fromOleVariant(oleutil.PutProperty(disp, name, comParams(params)...))
+	return result.named(name), err
+}
+
+func (d *comDispatcher) CallMethod(name string, params ...interface{}) (*variant, error) {
+	disp := d.IDispatch()
+	if disp == nil {
+		return nil, ErrReleased
+	}
+	result, err := fromOleVariant(oleutil.CallMethod(disp, name, comParams(params)...))
+	return result.named(name), err
+}
+
+func (d *comDispatcher) CreateObject(programID string) (dispatcher, error) {
+	return newComObject(programID)
+}
+
+func (d *comDispatcher) IDispatch() *ole.IDispatch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.disp
+}
+
+func (d *comDispatcher) Release() {
+	d.mu.Lock()
+	disp := d.disp
+	d.disp = nil
+	d.mu.Unlock()
+	if disp != nil {
+		disp.Release()
+	}
+}
+
+// comParams replaces dispatcher arguments with their COM objects so they can be
+// marshalled by oleutil.
+func comParams(params []interface{}) []interface{} {
+	converted := make([]interface{}, len(params))
+	for i, param := range params {
+		if d, ok := param.(dispatcher); ok {
+			converted[i] = dispatchOf(d)
+			continue
+		}
+		converted[i] = param
+	}
+	return converted
+}
+
+// fromOleVariant decodes the result of an oleutil call.
+func fromOleVariant(v *ole.VARIANT, err error) (*variant, error) {
+	if err != nil {
+		return nil, newComError(err)
+	}
+	if v == nil {
+		return &variant{VT: ole.VT_EMPTY}, nil
+	}
+	switch v.VT {
+	case ole.VT_DISPATCH:
+		if disp := v.ToIDispatch(); disp != nil {
+			return &variant{VT: ole.VT_DISPATCH, Value: newComDispatcher(disp)}, nil
+		}
+		return &variant{VT: ole.VT_DISPATCH}, nil
+	case ole.VT_ERROR:
+		return &variant{VT: v.VT, Value: HRESULT(uint32(v.Val))}, nil
+	case ole.VT_DECIMAL:
+		return &variant{VT: v.VT, Value: decimalOf(v)}, nil
+	}
+	return &variant{VT: v.VT, Value: v.Value()}, nil
+}
+
+// decimalOf decodes the DECIMAL filling a VT_DECIMAL VARIANT, which go-ole
+// leaves to the caller: its scale and sign take the reserved word after the
+// VARTYPE, its high 32 bits the next two words and its low 64 bits Val.
+func decimalOf(v *ole.VARIANT) decimal {
+	raw := (*[16]byte)(unsafe.Pointer(v))
+	return decimal{
+		Scale:    raw[2],
+		Negative: raw[3]&0x80 != 0,
+		Hi:       binary.LittleEndian.Uint32(raw[4:8]),
+		Lo:       uint64(v.Val),
+	}
+}
+
+// dispatchOf returns the COM object behind d, or nil.
+func dispatchOf(d dispatcher) *ole.IDispatch {
+	if d == nil {
+		return nil
+	}
+	return d.IDispatch()
+}
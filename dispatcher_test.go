@@ -0,0 +1,298 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate/criteria"
+	"github.com/go-ole/go-ole"
+)
+
+// newFakeUpdate returns a fake IUpdate exposing every IUpdate member. Members in
+// overrides replace the defaults; a nil override removes the member, as on an
+// agent that predates the interface declaring it.
+func newFakeUpdate(overrides map[string]interface{}) *fakeDispatcher {
+	deadline := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	members := map[string]interface{}{
+		"AutoSelectOnWebSites":            true,
+		"BundledUpdates":                  newFakeCollection(),
+		"CanRequireSource":                false,
+		"Categories":                      newFakeCollection(newFakeCategory("0fa1201d-4330-4fa8-8ae9-b877473b6441", "Security Updates", "UpdateClassification")),
+		"Deadline":                        deadline,
+		"DeltaCompressedContentAvailable": false,
+		"DeltaCompressedContentPreferred": true,
+		"DeploymentAction":                DeploymentActionDaInstallation,
+		"Description":                     "A security issue has been identified.",
+		"DownloadContents":                newFakeCollection(),
+		"DownloadPriority":                DownloadPriorityDpNormal,
+		"EulaAccepted":                    true,
+		"EulaText":                        "",
+		"HandlerID":                       "http://schemas.microsoft.com/msus/2002/12/UpdateHandlers/WindowsPatch",
+		"Identity":                        newFakeDispatcher(map[string]interface{}{"RevisionNumber": int32(200), "UpdateID": "7a3e1c1e-1b4f-4f4c-9d0a-3c3b1a0b5f11"}),
+		"Image":                           &variant{VT: ole.VT_DISPATCH},
+		"InstallationBehavior":            newFakeInstallationBehavior(InstallationRebootBehaviorIrbCanRequestReboot),
+		"IsBeta":                          false,
+		"IsDownloaded":                    false,
+		"IsHidden":                        false,
+		"IsInstalled":                     false,
+		"IsMandatory":                     false,
+		"IsUninstallable":                 true,
+		"KBArticleIDs":                    newFakeCollection("5034441"),
+		"Languages":                       newFakeCollection(),
+		"LastDeploymentChangeTime":        deadline,
+		"MaxDownloadSize":                 int64(400 << 20),
+		"MinDownloadSize":                 int64(0),
+		"MoreInfoUrls":                    newFakeCollection("https://support.microsoft.com/help/5034441"),
+		"MsrcSeverity":                    "Important",
+		"RecommendedCpuSpeed":             int32(0),
+		"RecommendedHardDiskSpace":        int32(0),
+		"RecommendedMemory":               int32(0),
+		"ReleaseNotes":                    "",
+		"SecurityBulletinIDs":             newFakeCollection(),
+		"SupersededUpdateIDs":             newFakeCollection(),
+		"SupportUrl":                      "https://support.microsoft.com",
+		"Title":                           "2024-01 Security Update (KB5034441)",
+		"UninstallationBehavior":          newFakeInstallationBehavior(InstallationRebootBehaviorIrbNeverReboots),
+		"UninstallationNotes":             "",
+		"UninstallationSteps":             newFakeCollection(),
+		"CveIDs":                          newFakeCollection("CVE-2024-20666"),
+		"IsPresent":                       false,
+		"RebootRequired":                  false,
+		"BrowseOnly":                      false,
+		"PerUser":                         false,
+		"AutoDownload":                    AutoDownloadModeAllowAutoDownload,
+		"AutoSelection":                   AutoSelectionModeAutoSelectIfDownloaded,
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(members, name)
+			continue
+		}
+		members[name] = value
+	}
+	return newFakeDispatcher(members)
+}
+
+func newFakeCategory(id, name, categoryType string) *fakeDispatcher {
+	return newFakeDispatcher(map[string]interface{}{
+		"CategoryID":  id,
+		"Children":    newFakeCollection(),
+		"Description": name,
+		"Image":       &variant{VT: ole.VT_DISPATCH},
+		"Name":        name,
+		"Order":       int32(0),
+		"Type":        categoryType,
+	})
+}
+
+func newFakeInstallationBehavior(rebootBehavior InstallationRebootBehavior) *fakeDispatcher {
+	return newFakeDispatcher(map[string]interface{}{
+		"CanRequestUserInput":         false,
+		"Impact":                      InstallationImpactIiNormal,
+		"RebootBehavior":              rebootBehavior,
+		"RequiresNetworkConnectivity": false,
+	})
+}
+
+func TestToIUpdate_FakeDispatcher(t *testing.T) {
+	update, err := toIUpdate(newFakeUpdate(nil))
+	if err != nil {
+		t.Fatalf("toIUpdate failed: %v", err)
+	}
+	if update.Title != "2024-01 Security Update (KB5034441)" {
+		t.Errorf("Title = %q", update.Title)
+	}
+	if update.Identity == nil || update.Identity.RevisionNumber != 200 {
+		t.Errorf("Identity = %+v", update.Identity)
+	}
+	if len(update.KBArticleIDs) != 1 || update.KBArticleIDs[0] != "5034441" {
+		t.Errorf("KBArticleIDs = %v", update.KBArticleIDs)
+	}
+	if len(update.Categories) != 1 || update.Categories[0].Type != "UpdateClassification" {
+		t.Errorf("Categories = %+v", update.Categories)
+	}
+	if update.Image != nil {
+		t.Errorf("Image = %+v, want nil for a null dispatch", update.Image)
+	}
+	if update.InstallationBehavior == nil || update.InstallationBehavior.RebootBehavior != InstallationRebootBehaviorIrbCanRequestReboot {
+		t.Errorf("InstallationBehavior = %+v", update.InstallationBehavior)
+	}
+	if update.Deadline == nil || update.Deadline.Year() != 2026 {
+		t.Errorf("Deadline = %v", update.Deadline)
+	}
+	if update.MaxDownloadSize != 400<<20 {
+		t.Errorf("MaxDownloadSize = %d", update.MaxDownloadSize)
+	}
+	if len(update.CveIDs) != 1 || update.AutoSelection != AutoSelectionModeAutoSelectIfDownloaded {
+		t.Errorf("IUpdate2-5 properties not read: CveIDs = %v, AutoSelection = %d", update.CveIDs, update.AutoSelection)
+	}
+}
+
+func TestToIUpdate_OptionalInterfacesMissing(t *testing.T) {
+	// An IUpdate from an agent without IUpdate2-5 does not implement those members.
+	update, err := toIUpdate(newFakeUpdate(map[string]interface{}{
+		"CveIDs":         nil,
+		"IsPresent":      nil,
+		"RebootRequired": nil,
+		"BrowseOnly":     nil,
+		"PerUser":        nil,
+		"AutoDownload":   nil,
+		"AutoSelection":  nil,
+	}))
+	if err != nil {
+		t.Fatalf("toIUpdate failed: %v", err)
+	}
+	if update.CveIDs != nil || update.AutoDownload != 0 || update.AutoSelection != 0 {
+		t.Errorf("optional properties = %v, %d, %d; want zero values", update.CveIDs, update.AutoDownload, update.AutoSelection)
+	}
+}
+
+func TestToIUpdate_RequiredPropertyError(t *testing.T) {
+	testErr := errors.New("access denied")
+	if _, err := toIUpdate(newFakeUpdate(map[string]interface{}{"Title": testErr})); err != testErr {
+		t.Errorf("toIUpdate error = %v, want %v", err, testErr)
+	}
+}
+
+func TestToISearchResult_FakeDispatcher(t *testing.T) {
+	result, err := toISearchResult(newFakeDispatcher(map[string]interface{}{
+		"ResultCode":     OperationResultCodeOrcSucceeded,
+		"RootCategories": newFakeCollection(),
+		"Updates":        newFakeCollection(newFakeUpdate(nil), newFakeUpdate(map[string]interface{}{"Title": "Second"})),
+		"Warnings": newFakeCollection(newFakeDispatcher(map[string]interface{}{
+			"Context": UpdateExceptionContextUecSearchIncomplete,
+			"HResult": int64(-2145124302),
+			"Message": "search incomplete",
+		})),
+	}))
+	if err != nil {
+		t.Fatalf("toISearchResult failed: %v", err)
+	}
+	if len(result.Updates) != 2 || result.Updates[1].Title != "Second" {
+		t.Errorf("Updates = %+v", result.Updates)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Context != UpdateExceptionContextUecSearchIncomplete {
+		t.Errorf("Warnings = %+v", result.Warnings)
+	}
+}
+
+func TestToIUpdateHistoryEntry_FakeDispatcher(t *testing.T) {
+	date := time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC)
+	entry, err := toIUpdateHistoryEntry(newFakeDispatcher(map[string]interface{}{
+		"ClientApplicationID": "wuauclt",
+		"Date":                date,
+		"Description":         "",
+		"HResult":             int32(-2145124329),
+		"Operation":           UpdateOperationUoInstallation,
+		"ResultCode":          OperationResultCodeOrcFailed,
+		"ServerSelection":     ServerSelectionSsWindowsUpdate,
+		"ServiceID":           "",
+		"SupportUrl":          "",
+		"Title":               "KB5034441",
+		"UninstallationNotes": "",
+		"UninstallationSteps": newFakeCollection(),
+		"UnmappedResultCode":  int32(0),
+		"UpdateIdentity":      newFakeDispatcher(map[string]interface{}{"RevisionNumber": int32(1), "UpdateID": "id"}),
+	}))
+	if err != nil {
+		t.Fatalf("toIUpdateHistoryEntry failed: %v", err)
+	}
+	if !entry.Date.Equal(date) || entry.HResult != -2145124329 || entry.UpdateIdentity.UpdateID != "id" {
+		t.Errorf("entry = %+v", entry)
+	}
+}
+
+func TestIUpdateDownloader_Download_FakeDispatcher(t *testing.T) {
+	update, err := toIUpdate(newFakeUpdate(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloader := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{
+		"Download": newFakeDispatcher(map[string]interface{}{
+			"HResult":    int32(0),
+			"ResultCode": OperationResultCodeOrcSucceeded,
+		}),
+	})}
+
+	result, err := downloader.Download([]*IUpdate{update})
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if result.ResultCode != OperationResultCodeOrcSucceeded {
+		t.Errorf("ResultCode = %d", result.ResultCode)
+	}
+
+	updates, err := toIDispatchErr(downloader.disp.GetProperty("Updates"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := toInt32Err(updates.GetProperty("Count"))
+	if err != nil || count != 1 {
+		t.Errorf("Updates collection has %d items, %v; want 1", count, err)
+	}
+}
+
+func TestComParams(t *testing.T) {
+	disp := &ole.IDispatch{}
+	params := comParams([]interface{}{"criteria", &comDispatcher{disp: disp}, nil, int32(1)})
+	if params[0] != "criteria" || params[1] != disp || params[2] != nil || params[3] != int32(1) {
+		t.Errorf("comParams = %v", params)
+	}
+}
+
+func TestFromOleVariant(t *testing.T) {
+	v := ole.NewVariant(ole.VT_I4, 42)
+	decoded, err := fromOleVariant(&v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.VT != ole.VT_I4 || decoded.Value != int32(42) {
+		t.Errorf("fromOleVariant = %+v", decoded)
+	}
+
+	null := ole.NewVariant(ole.VT_DISPATCH, 0)
+	if decoded, _ := fromOleVariant(&null, nil); decoded.Value != nil {
+		t.Errorf("null dispatch decoded to %v, want nil", decoded.Value)
+	}
+
+	testErr := errors.New("boom")
+	if _, err := fromOleVariant(nil, testErr); err != testErr {
+		t.Errorf("fromOleVariant error = %v, want %v", err, testErr)
+	}
+}
+
+func TestIUpdateSearcher_SearchCriteria_FakeDispatcher(t *testing.T) {
+	var got []interface{}
+	searcher := &IUpdateSearcher{disp: newFakeDispatcher(map[string]interface{}{
+		"Search": fakeMember(func(params ...interface{}) (interface{}, error) {
+			got = params
+			return newFakeDispatcher(map[string]interface{}{
+				"ResultCode":     OperationResultCodeOrcSucceeded,
+				"RootCategories": newFakeCollection(),
+				"Updates":        newFakeCollection(),
+				"Warnings":       newFakeCollection(),
+			}), nil
+		}),
+	})}
+
+	if _, err := searcher.SearchCriteria(criteria.Or(criteria.IsInstalled(false), criteria.Type(criteria.Driver))); err != nil {
+		t.Fatalf("SearchCriteria failed: %v", err)
+	}
+	if len(got) != 1 || got[0] != "IsInstalled=0 or Type='Driver'" {
+		t.Errorf("Search called with %v", got)
+	}
+}
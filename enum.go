@@ -15,8 +15,10 @@ package windowsupdate
 
 // OperationResultCode defines the possible results of a download, install, uninstall, or verification operation on an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-operationresultcode
+type OperationResultCode int32
+
 const (
-	OperationResultCodeOrcNotStarted int32 = iota
+	OperationResultCodeOrcNotStarted OperationResultCode = iota
 	OperationResultCodeOrcInProgress
 	OperationResultCodeOrcSucceeded
 	OperationResultCodeOrcSucceededWithErrors
@@ -24,131 +26,606 @@ const (
 	OperationResultCodeOrcAborted
 )
 
+var operationResultCodeNames = enumNames[OperationResultCode]{
+	OperationResultCodeOrcNotStarted:          "NotStarted",
+	OperationResultCodeOrcInProgress:          "InProgress",
+	OperationResultCodeOrcSucceeded:           "Succeeded",
+	OperationResultCodeOrcSucceededWithErrors: "SucceededWithErrors",
+	OperationResultCodeOrcFailed:              "Failed",
+	OperationResultCodeOrcAborted:             "Aborted",
+}
+
+// String returns the name of v, e.g. "Succeeded".
+func (v OperationResultCode) String() string {
+	return operationResultCodeNames.format("OperationResultCode", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v OperationResultCode) MarshalText() ([]byte, error) {
+	return operationResultCodeNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *OperationResultCode) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseOperationResultCode(string(text))
+	return err
+}
+
+// ParseOperationResultCode parses the name or number of an OperationResultCode.
+func ParseOperationResultCode(s string) (OperationResultCode, error) {
+	return operationResultCodeNames.parse("OperationResultCode", s)
+}
+
 // DeploymentAction defines the action for which an update is eligible.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-deploymentaction
+type DeploymentAction int32
+
 const (
-	DeploymentActionDaNone int32 = iota
+	DeploymentActionDaNone DeploymentAction = iota
 	DeploymentActionDaDetection
 	DeploymentActionDaInstallation
 	DeploymentActionDaUninstallation
 	DeploymentActionDaOptionalInstallation
 )
 
+var deploymentActionNames = enumNames[DeploymentAction]{
+	DeploymentActionDaNone:                 "None",
+	DeploymentActionDaDetection:            "Detection",
+	DeploymentActionDaInstallation:         "Installation",
+	DeploymentActionDaUninstallation:       "Uninstallation",
+	DeploymentActionDaOptionalInstallation: "OptionalInstallation",
+}
+
+// String returns the name of v, e.g. "Installation".
+func (v DeploymentAction) String() string {
+	return deploymentActionNames.format("DeploymentAction", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v DeploymentAction) MarshalText() ([]byte, error) {
+	return deploymentActionNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *DeploymentAction) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseDeploymentAction(string(text))
+	return err
+}
+
+// ParseDeploymentAction parses the name or number of a DeploymentAction.
+func ParseDeploymentAction(s string) (DeploymentAction, error) {
+	return deploymentActionNames.parse("DeploymentAction", s)
+}
+
 // DownloadPriority defines the priority of a download.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-downloadpriority
+type DownloadPriority int32
+
 const (
-	DownloadPriorityDpLow    int32 = 1
-	DownloadPriorityDpNormal int32 = 2
-	DownloadPriorityDpHigh   int32 = 3
+	DownloadPriorityDpLow    DownloadPriority = 1
+	DownloadPriorityDpNormal DownloadPriority = 2
+	DownloadPriorityDpHigh   DownloadPriority = 3
 )
 
+var downloadPriorityNames = enumNames[DownloadPriority]{
+	DownloadPriorityDpLow:    "Low",
+	DownloadPriorityDpNormal: "Normal",
+	DownloadPriorityDpHigh:   "High",
+}
+
+// String returns the name of v, e.g. "High".
+func (v DownloadPriority) String() string {
+	return downloadPriorityNames.format("DownloadPriority", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v DownloadPriority) MarshalText() ([]byte, error) {
+	return downloadPriorityNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *DownloadPriority) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseDownloadPriority(string(text))
+	return err
+}
+
+// ParseDownloadPriority parses the name or number of a DownloadPriority.
+func ParseDownloadPriority(s string) (DownloadPriority, error) {
+	return downloadPriorityNames.parse("DownloadPriority", s)
+}
+
 // InstallationImpact defines the impact of installing an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-installationimpact
+type InstallationImpact int32
+
 const (
-	InstallationImpactIiNormal int32 = iota
+	InstallationImpactIiNormal InstallationImpact = iota
 	InstallationImpactIiMinor
 	InstallationImpactIiRequiresExclusiveHandling
 )
 
+var installationImpactNames = enumNames[InstallationImpact]{
+	InstallationImpactIiNormal:                    "Normal",
+	InstallationImpactIiMinor:                     "Minor",
+	InstallationImpactIiRequiresExclusiveHandling: "RequiresExclusiveHandling",
+}
+
+// String returns the name of v, e.g. "RequiresExclusiveHandling".
+func (v InstallationImpact) String() string {
+	return installationImpactNames.format("InstallationImpact", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v InstallationImpact) MarshalText() ([]byte, error) {
+	return installationImpactNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *InstallationImpact) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseInstallationImpact(string(text))
+	return err
+}
+
+// ParseInstallationImpact parses the name or number of an InstallationImpact.
+func ParseInstallationImpact(s string) (InstallationImpact, error) {
+	return installationImpactNames.parse("InstallationImpact", s)
+}
+
 // InstallationRebootBehavior defines the restart behavior of an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-installationrebootbehavior
+type InstallationRebootBehavior int32
+
 const (
-	InstallationRebootBehaviorIrbNeverReboots int32 = iota
+	InstallationRebootBehaviorIrbNeverReboots InstallationRebootBehavior = iota
 	InstallationRebootBehaviorIrbAlwaysRequiresReboot
 	InstallationRebootBehaviorIrbCanRequestReboot
 )
 
+var installationRebootBehaviorNames = enumNames[InstallationRebootBehavior]{
+	InstallationRebootBehaviorIrbNeverReboots:         "NeverReboots",
+	InstallationRebootBehaviorIrbAlwaysRequiresReboot: "AlwaysRequiresReboot",
+	InstallationRebootBehaviorIrbCanRequestReboot:     "CanRequestReboot",
+}
+
+// String returns the name of v, e.g. "CanRequestReboot".
+func (v InstallationRebootBehavior) String() string {
+	return installationRebootBehaviorNames.format("InstallationRebootBehavior", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v InstallationRebootBehavior) MarshalText() ([]byte, error) {
+	return installationRebootBehaviorNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *InstallationRebootBehavior) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseInstallationRebootBehavior(string(text))
+	return err
+}
+
+// ParseInstallationRebootBehavior parses the name or number of an InstallationRebootBehavior.
+func ParseInstallationRebootBehavior(s string) (InstallationRebootBehavior, error) {
+	return installationRebootBehaviorNames.parse("InstallationRebootBehavior", s)
+}
+
 // UpdateOperation defines the operation for which an update is being installed or uninstalled.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-updateoperation
+type UpdateOperation int32
+
 const (
-	UpdateOperationUoInstallation   int32 = 1
-	UpdateOperationUoUninstallation int32 = 2
+	UpdateOperationUoInstallation   UpdateOperation = 1
+	UpdateOperationUoUninstallation UpdateOperation = 2
 )
 
+var updateOperationNames = enumNames[UpdateOperation]{
+	UpdateOperationUoInstallation:   "Installation",
+	UpdateOperationUoUninstallation: "Uninstallation",
+}
+
+// String returns the name of v, e.g. "Uninstallation".
+func (v UpdateOperation) String() string {
+	return updateOperationNames.format("UpdateOperation", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v UpdateOperation) MarshalText() ([]byte, error) {
+	return updateOperationNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *UpdateOperation) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseUpdateOperation(string(text))
+	return err
+}
+
+// ParseUpdateOperation parses the name or number of an UpdateOperation.
+func ParseUpdateOperation(s string) (UpdateOperation, error) {
+	return updateOperationNames.parse("UpdateOperation", s)
+}
+
 // UpdateExceptionContext defines the context in which an IUpdateException object can be provided.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-updateexceptioncontext
+type UpdateExceptionContext int32
+
 const (
-	UpdateExceptionContextUecGeneral int32 = iota + 1
+	UpdateExceptionContextUecGeneral UpdateExceptionContext = iota + 1
 	UpdateExceptionContextUecWindowsDriver
 	UpdateExceptionContextUecWindowsInstaller
 	UpdateExceptionContextUecSearchIncomplete
 )
 
+var updateExceptionContextNames = enumNames[UpdateExceptionContext]{
+	UpdateExceptionContextUecGeneral:          "General",
+	UpdateExceptionContextUecWindowsDriver:    "WindowsDriver",
+	UpdateExceptionContextUecWindowsInstaller: "WindowsInstaller",
+	UpdateExceptionContextUecSearchIncomplete: "SearchIncomplete",
+}
+
+// String returns the name of v, e.g. "WindowsInstaller".
+func (v UpdateExceptionContext) String() string {
+	return updateExceptionContextNames.format("UpdateExceptionContext", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v UpdateExceptionContext) MarshalText() ([]byte, error) {
+	return updateExceptionContextNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *UpdateExceptionContext) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseUpdateExceptionContext(string(text))
+	return err
+}
+
+// ParseUpdateExceptionContext parses the name or number of an UpdateExceptionContext.
+func ParseUpdateExceptionContext(s string) (UpdateExceptionContext, error) {
+	return updateExceptionContextNames.parse("UpdateExceptionContext", s)
+}
+
 // ServerSelection defines the update server that is used for a search or download operation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-serverselection
+type ServerSelection int32
+
 const (
-	ServerSelectionSsDefault       int32 = iota // Use the default server.
-	ServerSelectionSsManagedServer              // Use the managed server (WSUS).
-	ServerSelectionSsWindowsUpdate              // Use Windows Update.
-	ServerSelectionSsOthers                     // Use a non-Microsoft server.
+	ServerSelectionSsDefault       ServerSelection = iota // Use the default server.
+	ServerSelectionSsManagedServer                        // Use the managed server (WSUS).
+	ServerSelectionSsWindowsUpdate                        // Use Windows Update.
+	ServerSelectionSsOthers                               // Use a non-Microsoft server.
 )
 
+var serverSelectionNames = enumNames[ServerSelection]{
+	ServerSelectionSsDefault:       "Default",
+	ServerSelectionSsManagedServer: "ManagedServer",
+	ServerSelectionSsWindowsUpdate: "WindowsUpdate",
+	ServerSelectionSsOthers:        "Others",
+}
+
+// String returns the name of v, e.g. "WindowsUpdate".
+func (v ServerSelection) String() string {
+	return serverSelectionNames.format("ServerSelection", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v ServerSelection) MarshalText() ([]byte, error) {
+	return serverSelectionNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *ServerSelection) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseServerSelection(string(text))
+	return err
+}
+
+// ParseServerSelection parses the name or number of a ServerSelection.
+func ParseServerSelection(s string) (ServerSelection, error) {
+	return serverSelectionNames.parse("ServerSelection", s)
+}
+
 // AutomaticUpdatesNotificationLevel defines the notification level for automatic updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-automaticupdatesnotificationlevel
+type AutomaticUpdatesNotificationLevel int32
+
 const (
-	AutomaticUpdatesNotificationLevelAunlNotConfigured            int32 = iota // Not configured.
-	AutomaticUpdatesNotificationLevelAunlDisabled                              // Disabled.
-	AutomaticUpdatesNotificationLevelAunlNotifyBeforeDownload                  // Notify before download.
-	AutomaticUpdatesNotificationLevelAunlNotifyBeforeInstallation              // Notify before installation.
-	AutomaticUpdatesNotificationLevelAunlScheduledInstallation                 // Scheduled installation.
+	AutomaticUpdatesNotificationLevelAunlNotConfigured            AutomaticUpdatesNotificationLevel = iota // Not configured.
+	AutomaticUpdatesNotificationLevelAunlDisabled                                                          // Disabled.
+	AutomaticUpdatesNotificationLevelAunlNotifyBeforeDownload                                              // Notify before download.
+	AutomaticUpdatesNotificationLevelAunlNotifyBeforeInstallation                                          // Notify before installation.
+	AutomaticUpdatesNotificationLevelAunlScheduledInstallation                                             // Scheduled installation.
 )
 
+var automaticUpdatesNotificationLevelNames = enumNames[AutomaticUpdatesNotificationLevel]{
+	AutomaticUpdatesNotificationLevelAunlNotConfigured:            "NotConfigured",
+	AutomaticUpdatesNotificationLevelAunlDisabled:                 "Disabled",
+	AutomaticUpdatesNotificationLevelAunlNotifyBeforeDownload:     "NotifyBeforeDownload",
+	AutomaticUpdatesNotificationLevelAunlNotifyBeforeInstallation: "NotifyBeforeInstallation",
+	AutomaticUpdatesNotificationLevelAunlScheduledInstallation:    "ScheduledInstallation",
+}
+
+// String returns the name of v, e.g. "NotifyBeforeDownload".
+func (v AutomaticUpdatesNotificationLevel) String() string {
+	return automaticUpdatesNotificationLevelNames.format("AutomaticUpdatesNotificationLevel", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v AutomaticUpdatesNotificationLevel) MarshalText() ([]byte, error) {
+	return automaticUpdatesNotificationLevelNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *AutomaticUpdatesNotificationLevel) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseAutomaticUpdatesNotificationLevel(string(text))
+	return err
+}
+
+// ParseAutomaticUpdatesNotificationLevel parses the name or number of an AutomaticUpdatesNotificationLevel.
+func ParseAutomaticUpdatesNotificationLevel(s string) (AutomaticUpdatesNotificationLevel, error) {
+	return automaticUpdatesNotificationLevelNames.parse("AutomaticUpdatesNotificationLevel", s)
+}
+
 // AutomaticUpdatesScheduledInstallationDay defines the days of the week for scheduled automatic updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-automaticupdatesscheduledinstallationday
+type AutomaticUpdatesScheduledInstallationDay int32
+
 const (
-	AutomaticUpdatesScheduledInstallationDayAuisdEveryDay       int32 = iota // Every day.
-	AutomaticUpdatesScheduledInstallationDayAuisdEverySunday                 // Every Sunday.
-	AutomaticUpdatesScheduledInstallationDayAuisdEveryMonday                 // Every Monday.
-	AutomaticUpdatesScheduledInstallationDayAuisdEveryTuesday                // Every Tuesday.
-	AutomaticUpdatesScheduledInstallationDayAuisdEveryWednesday              // Every Wednesday.
-	AutomaticUpdatesScheduledInstallationDayAuisdEveryThursday               // Every Thursday.
-	AutomaticUpdatesScheduledInstallationDayAuisdEveryFriday                 // Every Friday.
-	AutomaticUpdatesScheduledInstallationDayAuisdEverySaturday               // Every Saturday.
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryDay       AutomaticUpdatesScheduledInstallationDay = iota // Every day.
+	AutomaticUpdatesScheduledInstallationDayAuisdEverySunday                                                    // Every Sunday.
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryMonday                                                    // Every Monday.
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryTuesday                                                   // Every Tuesday.
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryWednesday                                                 // Every Wednesday.
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryThursday                                                  // Every Thursday.
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryFriday                                                    // Every Friday.
+	AutomaticUpdatesScheduledInstallationDayAuisdEverySaturday                                                  // Every Saturday.
 )
 
+var automaticUpdatesScheduledInstallationDayNames = enumNames[AutomaticUpdatesScheduledInstallationDay]{
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryDay:       "EveryDay",
+	AutomaticUpdatesScheduledInstallationDayAuisdEverySunday:    "EverySunday",
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryMonday:    "EveryMonday",
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryTuesday:   "EveryTuesday",
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryWednesday: "EveryWednesday",
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryThursday:  "EveryThursday",
+	AutomaticUpdatesScheduledInstallationDayAuisdEveryFriday:    "EveryFriday",
+	AutomaticUpdatesScheduledInstallationDayAuisdEverySaturday:  "EverySaturday",
+}
+
+// String returns the name of v, e.g. "EveryMonday".
+func (v AutomaticUpdatesScheduledInstallationDay) String() string {
+	return automaticUpdatesScheduledInstallationDayNames.format("AutomaticUpdatesScheduledInstallationDay", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v AutomaticUpdatesScheduledInstallationDay) MarshalText() ([]byte, error) {
+	return automaticUpdatesScheduledInstallationDayNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *AutomaticUpdatesScheduledInstallationDay) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseAutomaticUpdatesScheduledInstallationDay(string(text))
+	return err
+}
+
+// ParseAutomaticUpdatesScheduledInstallationDay parses the name or number of an AutomaticUpdatesScheduledInstallationDay.
+func ParseAutomaticUpdatesScheduledInstallationDay(s string) (AutomaticUpdatesScheduledInstallationDay, error) {
+	return automaticUpdatesScheduledInstallationDayNames.parse("AutomaticUpdatesScheduledInstallationDay", s)
+}
+
 // DownloadPhase defines the phase of the download.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-downloadphase
+type DownloadPhase int32
+
 const (
-	DownloadPhaseInitializing int32 = iota + 1
+	DownloadPhaseInitializing DownloadPhase = iota + 1
 	DownloadPhaseDownloading
 	DownloadPhaseVerifying
 )
 
-// AutoDownload defines auto download behavior for IUpdate5.
+var downloadPhaseNames = enumNames[DownloadPhase]{
+	DownloadPhaseInitializing: "Initializing",
+	DownloadPhaseDownloading:  "Downloading",
+	DownloadPhaseVerifying:    "Verifying",
+}
+
+// String returns the name of v, e.g. "Verifying".
+func (v DownloadPhase) String() string {
+	return downloadPhaseNames.format("DownloadPhase", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v DownloadPhase) MarshalText() ([]byte, error) {
+	return downloadPhaseNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *DownloadPhase) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseDownloadPhase(string(text))
+	return err
+}
+
+// ParseDownloadPhase parses the name or number of a DownloadPhase.
+func ParseDownloadPhase(s string) (DownloadPhase, error) {
+	return downloadPhaseNames.parse("DownloadPhase", s)
+}
+
+// AutoDownloadMode defines auto download behavior for IUpdate5.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-autodownloadmode
+type AutoDownloadMode int32
+
 const (
-	AutoDownloadModeForbidAutoDownload int32 = iota
+	AutoDownloadModeForbidAutoDownload AutoDownloadMode = iota
 	AutoDownloadModeAllowAutoDownload
 )
 
-// AutoSelection defines auto selection behavior for IUpdate5.
+var autoDownloadModeNames = enumNames[AutoDownloadMode]{
+	AutoDownloadModeForbidAutoDownload: "ForbidAutoDownload",
+	AutoDownloadModeAllowAutoDownload:  "AllowAutoDownload",
+}
+
+// String returns the name of v, e.g. "AllowAutoDownload".
+func (v AutoDownloadMode) String() string {
+	return autoDownloadModeNames.format("AutoDownloadMode", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v AutoDownloadMode) MarshalText() ([]byte, error) {
+	return autoDownloadModeNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *AutoDownloadMode) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseAutoDownloadMode(string(text))
+	return err
+}
+
+// ParseAutoDownloadMode parses the name or number of an AutoDownloadMode.
+func ParseAutoDownloadMode(s string) (AutoDownloadMode, error) {
+	return autoDownloadModeNames.parse("AutoDownloadMode", s)
+}
+
+// AutoSelectionMode defines auto selection behavior for IUpdate5.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-autoselectionmode
+type AutoSelectionMode int32
+
 const (
-	AutoSelectionModeLetWindowsUpdateDecide int32 = iota
+	AutoSelectionModeLetWindowsUpdateDecide AutoSelectionMode = iota
 	AutoSelectionModeAutoSelectIfDownloaded
 	AutoSelectionModeNeverAutoSelect
 	AutoSelectionModeAlwaysAutoSelect
 )
 
+var autoSelectionModeNames = enumNames[AutoSelectionMode]{
+	AutoSelectionModeLetWindowsUpdateDecide: "LetWindowsUpdateDecide",
+	AutoSelectionModeAutoSelectIfDownloaded: "AutoSelectIfDownloaded",
+	AutoSelectionModeNeverAutoSelect:        "NeverAutoSelect",
+	AutoSelectionModeAlwaysAutoSelect:       "AlwaysAutoSelect",
+}
+
+// String returns the name of v, e.g. "NeverAutoSelect".
+func (v AutoSelectionMode) String() string {
+	return autoSelectionModeNames.format("AutoSelectionMode", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v AutoSelectionMode) MarshalText() ([]byte, error) {
+	return autoSelectionModeNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *AutoSelectionMode) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseAutoSelectionMode(string(text))
+	return err
+}
+
+// ParseAutoSelectionMode parses the name or number of an AutoSelectionMode.
+func ParseAutoSelectionMode(s string) (AutoSelectionMode, error) {
+	return autoSelectionModeNames.parse("AutoSelectionMode", s)
+}
+
 // UpdateServiceRegistrationState defines the state of a service registration.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-updateserviceregistrationstate
+type UpdateServiceRegistrationState int32
+
 const (
-	UpdateServiceRegistrationStateNotRegistered int32 = iota + 1
+	UpdateServiceRegistrationStateNotRegistered UpdateServiceRegistrationState = iota + 1
 	UpdateServiceRegistrationStateRegistrationPending
 	UpdateServiceRegistrationStateRegistered
 )
 
+var updateServiceRegistrationStateNames = enumNames[UpdateServiceRegistrationState]{
+	UpdateServiceRegistrationStateNotRegistered:       "NotRegistered",
+	UpdateServiceRegistrationStateRegistrationPending: "RegistrationPending",
+	UpdateServiceRegistrationStateRegistered:          "Registered",
+}
+
+// String returns the name of v, e.g. "Registered".
+func (v UpdateServiceRegistrationState) String() string {
+	return updateServiceRegistrationStateNames.format("UpdateServiceRegistrationState", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v UpdateServiceRegistrationState) MarshalText() ([]byte, error) {
+	return updateServiceRegistrationStateNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *UpdateServiceRegistrationState) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseUpdateServiceRegistrationState(string(text))
+	return err
+}
+
+// ParseUpdateServiceRegistrationState parses the name or number of an UpdateServiceRegistrationState.
+func ParseUpdateServiceRegistrationState(s string) (UpdateServiceRegistrationState, error) {
+	return updateServiceRegistrationStateNames.parse("UpdateServiceRegistrationState", s)
+}
+
 // AddServiceFlag defines flags for AddService2.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-addserviceflag
+type AddServiceFlag int32
+
 const (
-	AddServiceFlagAsfAllowPendingRegistration int32 = 1
-	AddServiceFlagAsfAllowOnlineRegistration  int32 = 2
-	AddServiceFlagAsfRegisterServiceWithAU    int32 = 4
+	AddServiceFlagAsfAllowPendingRegistration AddServiceFlag = 1
+	AddServiceFlagAsfAllowOnlineRegistration  AddServiceFlag = 2
+	AddServiceFlagAsfRegisterServiceWithAU    AddServiceFlag = 4
 )
 
+var addServiceFlagNames = enumNames[AddServiceFlag]{
+	AddServiceFlagAsfAllowPendingRegistration: "AllowPendingRegistration",
+	AddServiceFlagAsfAllowOnlineRegistration:  "AllowOnlineRegistration",
+	AddServiceFlagAsfRegisterServiceWithAU:    "RegisterServiceWithAU",
+}
+
+// String returns the names of the flags set in v joined by "|", e.g.
+// "AllowPendingRegistration|RegisterServiceWithAU".
+func (v AddServiceFlag) String() string {
+	return addServiceFlagNames.formatFlags("AddServiceFlag", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v AddServiceFlag) MarshalText() ([]byte, error) {
+	return addServiceFlagNames.marshalFlags(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *AddServiceFlag) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseAddServiceFlag(string(text))
+	return err
+}
+
+// ParseAddServiceFlag parses names of flags joined by "|", or a number.
+func ParseAddServiceFlag(s string) (AddServiceFlag, error) {
+	return addServiceFlagNames.parseFlags("AddServiceFlag", s)
+}
+
 // UpdateType defines the type of an update.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-updatetype
+type UpdateType int32
+
 const (
-	UpdateTypeSoftware int32 = iota + 1
+	UpdateTypeSoftware UpdateType = iota + 1
 	UpdateTypeDriver
 )
+
+var updateTypeNames = enumNames[UpdateType]{
+	UpdateTypeSoftware: "Software",
+	UpdateTypeDriver:   "Driver",
+}
+
+// String returns the name of v, e.g. "Driver".
+func (v UpdateType) String() string {
+	return updateTypeNames.format("UpdateType", v)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v UpdateType) MarshalText() ([]byte, error) {
+	return updateTypeNames.marshal(v), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *UpdateType) UnmarshalText(text []byte) (err error) {
+	*v, err = ParseUpdateType(string(text))
+	return err
+}
+
+// ParseUpdateType parses the name or number of an UpdateType.
+func ParseUpdateType(s string) (UpdateType, error) {
+	return updateTypeNames.parse("UpdateType", s)
+}
@@ -13,13 +13,20 @@ limitations under the License.
 
 package windowsupdate
 
-import "testing"
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestOperationResultCode(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant OperationResultCode
+		expected OperationResultCode
 	}{
 		{"OrcNotStarted", OperationResultCodeOrcNotStarted, 0},
 		{"OrcInProgress", OperationResultCodeOrcInProgress, 1},
@@ -41,8 +48,8 @@ func TestOperationResultCode(t *testing.T) {
 func TestDeploymentAction(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant DeploymentAction
+		expected DeploymentAction
 	}{
 		{"DaNone", DeploymentActionDaNone, 0},
 		{"DaDetection", DeploymentActionDaDetection, 1},
@@ -63,8 +70,8 @@ func TestDeploymentAction(t *testing.T) {
 func TestDownloadPriority(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant DownloadPriority
+		expected DownloadPriority
 	}{
 		{"DpLow", DownloadPriorityDpLow, 1},
 		{"DpNormal", DownloadPriorityDpNormal, 2},
@@ -83,8 +90,8 @@ func TestDownloadPriority(t *testing.T) {
 func TestInstallationImpact(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant InstallationImpact
+		expected InstallationImpact
 	}{
 		{"IiNormal", InstallationImpactIiNormal, 0},
 		{"IiMinor", InstallationImpactIiMinor, 1},
@@ -103,8 +110,8 @@ func TestInstallationImpact(t *testing.T) {
 func TestInstallationRebootBehavior(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant InstallationRebootBehavior
+		expected InstallationRebootBehavior
 	}{
 		{"IrbNeverReboots", InstallationRebootBehaviorIrbNeverReboots, 0},
 		{"IrbAlwaysRequiresReboot", InstallationRebootBehaviorIrbAlwaysRequiresReboot, 1},
@@ -123,8 +130,8 @@ func TestInstallationRebootBehavior(t *testing.T) {
 func TestUpdateOperation(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant UpdateOperation
+		expected UpdateOperation
 	}{
 		{"UoInstallation", UpdateOperationUoInstallation, 1},
 		{"UoUninstallation", UpdateOperationUoUninstallation, 2},
@@ -142,8 +149,8 @@ func TestUpdateOperation(t *testing.T) {
 func TestUpdateExceptionContext(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant UpdateExceptionContext
+		expected UpdateExceptionContext
 	}{
 		{"UecGeneral", UpdateExceptionContextUecGeneral, 1},
 		{"UecWindowsDriver", UpdateExceptionContextUecWindowsDriver, 2},
@@ -163,8 +170,8 @@ func TestUpdateExceptionContext(t *testing.T) {
 func TestServerSelection(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant ServerSelection
+		expected ServerSelection
 	}{
 		{"SsDefault", ServerSelectionSsDefault, 0},
 		{"SsManagedServer", ServerSelectionSsManagedServer, 1},
@@ -184,8 +191,8 @@ func TestServerSelection(t *testing.T) {
 func TestAutomaticUpdatesNotificationLevel(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant AutomaticUpdatesNotificationLevel
+		expected AutomaticUpdatesNotificationLevel
 	}{
 		{"AunlNotConfigured", AutomaticUpdatesNotificationLevelAunlNotConfigured, 0},
 		{"AunlDisabled", AutomaticUpdatesNotificationLevelAunlDisabled, 1},
@@ -206,8 +213,8 @@ func TestAutomaticUpdatesNotificationLevel(t *testing.T) {
 func TestAutomaticUpdatesScheduledInstallationDay(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant AutomaticUpdatesScheduledInstallationDay
+		expected AutomaticUpdatesScheduledInstallationDay
 	}{
 		{"AuisdEveryDay", AutomaticUpdatesScheduledInstallationDayAuisdEveryDay, 0},
 		{"AuisdEverySunday", AutomaticUpdatesScheduledInstallationDayAuisdEverySunday, 1},
@@ -231,8 +238,8 @@ func TestAutomaticUpdatesScheduledInstallationDay(t *testing.T) {
 func TestDownloadPhase(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant DownloadPhase
+		expected DownloadPhase
 	}{
 		{"Initializing", DownloadPhaseInitializing, 1},
 		{"Downloading", DownloadPhaseDownloading, 2},
@@ -251,8 +258,8 @@ func TestDownloadPhase(t *testing.T) {
 func TestAutoDownloadMode(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant AutoDownloadMode
+		expected AutoDownloadMode
 	}{
 		{"ForbidAutoDownload", AutoDownloadModeForbidAutoDownload, 0},
 		{"AllowAutoDownload", AutoDownloadModeAllowAutoDownload, 1},
@@ -270,8 +277,8 @@ func TestAutoDownloadMode(t *testing.T) {
 func TestAutoSelectionMode(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant AutoSelectionMode
+		expected AutoSelectionMode
 	}{
 		{"LetWindowsUpdateDecide", AutoSelectionModeLetWindowsUpdateDecide, 0},
 		{"AutoSelectIfDownloaded", AutoSelectionModeAutoSelectIfDownloaded, 1},
@@ -291,8 +298,8 @@ func TestAutoSelectionMode(t *testing.T) {
 func TestUpdateServiceRegistrationState(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant UpdateServiceRegistrationState
+		expected UpdateServiceRegistrationState
 	}{
 		{"NotRegistered", UpdateServiceRegistrationStateNotRegistered, 1},
 		{"RegistrationPending", UpdateServiceRegistrationStateRegistrationPending, 2},
@@ -311,8 +318,8 @@ func TestUpdateServiceRegistrationState(t *testing.T) {
 func TestAddServiceFlag(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant AddServiceFlag
+		expected AddServiceFlag
 	}{
 		{"AsfAllowPendingRegistration", AddServiceFlagAsfAllowPendingRegistration, 1},
 		{"AsfAllowOnlineRegistration", AddServiceFlagAsfAllowOnlineRegistration, 2},
@@ -331,8 +338,8 @@ func TestAddServiceFlag(t *testing.T) {
 func TestUpdateType(t *testing.T) {
 	tests := []struct {
 		name     string
-		constant int32
-		expected int32
+		constant UpdateType
+		expected UpdateType
 	}{
 		{"Software", UpdateTypeSoftware, 1},
 		{"Driver", UpdateTypeDriver, 2},
@@ -346,3 +353,103 @@ func TestUpdateType(t *testing.T) {
 		})
 	}
 }
+
+func TestEnumText(t *testing.T) {
+	tests := []struct {
+		value interface {
+			fmt.Stringer
+			encoding.TextMarshaler
+		}
+		text string
+	}{
+		{OperationResultCodeOrcSucceeded, "Succeeded"},
+		{DeploymentActionDaOptionalInstallation, "OptionalInstallation"},
+		{DownloadPriorityDpHigh, "High"},
+		{InstallationImpactIiRequiresExclusiveHandling, "RequiresExclusiveHandling"},
+		{InstallationRebootBehaviorIrbCanRequestReboot, "CanRequestReboot"},
+		{UpdateOperationUoUninstallation, "Uninstallation"},
+		{UpdateExceptionContextUecSearchIncomplete, "SearchIncomplete"},
+		{ServerSelectionSsManagedServer, "ManagedServer"},
+		{AutomaticUpdatesNotificationLevelAunlScheduledInstallation, "ScheduledInstallation"},
+		{AutomaticUpdatesScheduledInstallationDayAuisdEveryFriday, "EveryFriday"},
+		{DownloadPhaseVerifying, "Verifying"},
+		{AutoDownloadModeAllowAutoDownload, "AllowAutoDownload"},
+		{AutoSelectionModeNeverAutoSelect, "NeverAutoSelect"},
+		{UpdateServiceRegistrationStateRegistrationPending, "RegistrationPending"},
+		{AddServiceFlagAsfAllowPendingRegistration | AddServiceFlagAsfRegisterServiceWithAU, "AllowPendingRegistration|RegisterServiceWithAU"},
+		{UpdateTypeDriver, "Driver"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tt.value.String(); got != tt.text {
+				t.Errorf("String() = %q, want %q", got, tt.text)
+			}
+			text, err := tt.value.MarshalText()
+			if err != nil || string(text) != tt.text {
+				t.Errorf("MarshalText() = %q, %v, want %q", text, err, tt.text)
+			}
+			// Unmarshal into a new value of the same type.
+			decoded := reflect.New(reflect.TypeOf(tt.value))
+			if err := decoded.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(strings.ToLower(tt.text))); err != nil {
+				t.Fatalf("UnmarshalText(%q) failed: %v", strings.ToLower(tt.text), err)
+			}
+			if got := decoded.Elem().Interface(); got != tt.value {
+				t.Errorf("UnmarshalText(%q) = %v, want %v", strings.ToLower(tt.text), got, tt.value)
+			}
+		})
+	}
+}
+
+func TestEnumText_Unknown(t *testing.T) {
+	code := OperationResultCode(9)
+	if got, want := code.String(), "OperationResultCode(9)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	text, _ := code.MarshalText()
+	if string(text) != "9" {
+		t.Errorf("MarshalText() = %q, want 9", text)
+	}
+	if parsed, err := ParseOperationResultCode(string(text)); err != nil || parsed != code {
+		t.Errorf("ParseOperationResultCode(%q) = %v, %v, want %v", text, parsed, err, code)
+	}
+	if _, err := ParseOperationResultCode("Succeded"); err == nil {
+		t.Error("ParseOperationResultCode accepted a misspelled name")
+	}
+
+	flags := AddServiceFlagAsfAllowOnlineRegistration | 8
+	if got, want := flags.String(), "AllowOnlineRegistration|AddServiceFlag(0x8)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if text, _ := flags.MarshalText(); string(text) != "10" {
+		t.Errorf("MarshalText() = %q, want 10", text)
+	}
+	if got := AddServiceFlag(0).String(); got != "0" {
+		t.Errorf("AddServiceFlag(0).String() = %q, want 0", got)
+	}
+	if _, err := ParseAddServiceFlag("AllowOnlineRegistration|Bogus"); err == nil {
+		t.Error("ParseAddServiceFlag accepted an unknown flag")
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	result := IInstallationResult{RebootRequired: true, ResultCode: OperationResultCodeOrcSucceededWithErrors}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"HResult":0,"RebootRequired":true,"ResultCode":"SucceededWithErrors"}`; string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+
+	var config struct {
+		ServerSelection ServerSelection
+		Priority        DownloadPriority
+	}
+	if err := json.Unmarshal([]byte(`{"ServerSelection": "WindowsUpdate", "Priority": "3"}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.ServerSelection != ServerSelectionSsWindowsUpdate || config.Priority != DownloadPriorityDpHigh {
+		t.Errorf("json.Unmarshal = %+v", config)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// enumNames maps the values of a WUA enum to their names, without the
+// prefixes of the C identifiers (OperationResultCodeOrcSucceeded is
+// "Succeeded"). Values missing from the map, e.g. those added by newer
+// versions of WUA, are written as numbers so they survive a round trip.
+type enumNames[T ~int32] map[T]string
+
+func (names enumNames[T]) format(typ string, v T) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s(%d)", typ, int32(v))
+}
+
+func (names enumNames[T]) marshal(v T) []byte {
+	if name, ok := names[v]; ok {
+		return []byte(name)
+	}
+	return strconv.AppendInt(nil, int64(v), 10)
+}
+
+// parse accepts a name, in any case, or a decimal number.
+func (names enumNames[T]) parse(typ string, s string) (T, error) {
+	s = strings.TrimSpace(s)
+	for v, name := range names {
+		if strings.EqualFold(name, s) {
+			return v, nil
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 32); err == nil {
+		return T(n), nil
+	}
+	return 0, fmt.Errorf("windowsupdate: invalid %s %q", typ, s)
+}
+
+// flags returns the names of the bits set in v in ascending order, and the
+// bits that have no name.
+func (names enumNames[T]) flags(v T) ([]string, T) {
+	bits := make([]T, 0, len(names))
+	for bit := range names {
+		bits = append(bits, bit)
+	}
+	slices.Sort(bits)
+	var set []string
+	for _, bit := range bits {
+		if v&bit == bit {
+			set = append(set, names[bit])
+			v &^= bit
+		}
+	}
+	return set, v
+}
+
+func (names enumNames[T]) formatFlags(typ string, v T) string {
+	set, rest := names.flags(v)
+	if rest != 0 {
+		set = append(set, fmt.Sprintf("%s(%#x)", typ, int32(rest)))
+	}
+	if len(set) == 0 {
+		return "0"
+	}
+	return strings.Join(set, "|")
+}
+
+func (names enumNames[T]) marshalFlags(v T) []byte {
+	set, rest := names.flags(v)
+	if rest != 0 || len(set) == 0 {
+		return strconv.AppendInt(nil, int64(v), 10)
+	}
+	return []byte(strings.Join(set, "|"))
+}
+
+// parseFlags accepts names joined by "|", or a decimal number.
+func (names enumNames[T]) parseFlags(typ string, s string) (T, error) {
+	if n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32); err == nil {
+		return T(n), nil
+	}
+	var v T
+	for _, name := range strings.Split(s, "|") {
+		bit, err := names.parse(typ, name)
+		if err != nil {
+			return 0, err
+		}
+		v |= bit
+	}
+	return v, nil
+}
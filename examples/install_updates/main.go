@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 
 	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/patch"
 	"github.com/go-ole/go-ole"
 	"github.com/scjalliance/comshim"
 )
@@ -17,55 +20,28 @@ func main() {
 	ole.CoInitializeEx(0, ole.COINIT_APARTMENTTHREADED|ole.COINIT_SPEED_OVER_MEMORY)
 	defer ole.CoUninitialize()
 
-	var err error
-
 	session, err := windowsupdate.NewUpdateSession()
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
 	}
-
-	// Search Updates
-	fmt.Println("Step 1: Search Updates")
-	searcher, err := session.CreateUpdateSearcher()
-	if err != nil {
-		panic(err)
-	}
-
-	result, err := searcher.Search("IsInstalled=1")
+	defer session.Close()
+
+	// Search, accept EULAs, download and install the updates not installed yet.
+	report, runErr := patch.Run(context.Background(), patch.Plan{
+		Session: session,
+		Hooks: patch.Hooks{
+			After: func(ctx context.Context, stage patch.StageReport) {
+				fmt.Printf("%s: %d updates\n", stage.Stage, stage.Updates)
+			},
+		},
+	})
+
+	b, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
 	}
-
-	b, _ := json.Marshal(result)
 	fmt.Println(string(b))
-
-	// Download Updates
-	fmt.Println("Step 2: Download Updates")
-	downloader, err := session.CreateUpdateDownloader()
-	if err != nil {
-		panic(err)
+	if runErr != nil {
+		log.Fatal(runErr)
 	}
-
-	downloadResult, err := downloader.Download(result.Updates)
-	if err != nil {
-		panic(err)
-	}
-
-	c, _ := json.Marshal(downloadResult)
-	fmt.Println(string(c))
-
-	// Install Updates
-	fmt.Println("Step 3: Install Updates")
-	installer, err := session.CreateUpdateInstaller()
-	if err != nil {
-		panic(err)
-	}
-
-	installationResult, err := installer.Install(result.Updates)
-	if err != nil {
-		panic(err)
-	}
-
-	d, _ := json.Marshal(installationResult)
-	fmt.Println(string(d))
 }
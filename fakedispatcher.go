@@ -0,0 +1,226 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-ole/go-ole"
+)
+
+// DISP_E_UNKNOWNNAME, returned by IDispatch for a member the object does not
+// implement, e.g. an IUpdate5 property on an older agent.
+const hrDispUnknownName = 0x80020006
+
+// fakeMember computes the value of a property or method from its arguments.
+type fakeMember func(params ...interface{}) (interface{}, error)
+
+// fakeDispatcher is a map-backed dispatcher. Members hold plain Go values that
+// are converted to the variant COM would return: int16, int32 or an enum type,
+// int64, uint32, float32, float64, string, bool, time.Time, a dispatcher, nil (VT_EMPTY), a
+// *variant for an explicit VARTYPE, an error to fail the call, or a fakeMember to
+// compute any of those from the call arguments. Unknown members fail with
+// DISP_E_UNKNOWNNAME.
+type fakeDispatcher struct {
+	mu       sync.Mutex
+	members  map[string]interface{}
+	calls    []string
+	releases int
+}
+
+func newFakeDispatcher(members map[string]interface{}) *fakeDispatcher {
+	d := &fakeDispatcher{members: make(map[string]interface{}, len(members))}
+	for name, value := range members {
+		d.members[name] = value
+	}
+	return d
+}
+
+// newFakeCollection returns a fake of the WUA collection objects (IUpdateCollection,
+// IStringCollection, ICategoryCollection...) holding items.
+func newFakeCollection(items ...interface{}) *fakeDispatcher {
+	d := newFakeDispatcher(map[string]interface{}{"ReadOnly": false})
+	d.members["Count"] = fakeMember(func(params ...interface{}) (interface{}, error) {
+		return int32(len(items)), nil
+	})
+	d.members["Item"] = fakeMember(func(params ...interface{}) (interface{}, error) {
+		i, err := fakeIndex(params, len(items))
+		if err != nil {
+			return nil, err
+		}
+		return items[i], nil
+	})
+	d.members["Add"] = fakeMember(func(params ...interface{}) (interface{}, error) {
+		if len(params) != 1 {
+			return nil, ole.NewError(ole.E_INVALIDARG)
+		}
+		items = append(items, params[0])
+		return int32(len(items) - 1), nil
+	})
+	d.members["Clear"] = fakeMember(func(params ...interface{}) (interface{}, error) {
+		items = nil
+		return nil, nil
+	})
+	d.members["Insert"] = fakeMember(func(params ...interface{}) (interface{}, error) {
+		if len(params) != 2 {
+			return nil, ole.NewError(ole.E_INVALIDARG)
+		}
+		i, err := fakeIndex(params[:1], len(items)+1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items[:i], append([]interface{}{params[1]}, items[i:]...)...)
+		return nil, nil
+	})
+	d.members["RemoveAt"] = fakeMember(func(params ...interface{}) (interface{}, error) {
+		i, err := fakeIndex(params, len(items))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items[:i], items[i+1:]...)
+		return nil, nil
+	})
+	d.members["Copy"] = fakeMember(func(params ...interface{}) (interface{}, error) {
+		return newFakeCollection(append([]interface{}(nil), items...)...), nil
+	})
+	return d
+}
+
+func fakeIndex(params []interface{}, length int) (int, error) {
+	if len(params) != 1 {
+		return 0, ole.NewError(ole.E_INVALIDARG)
+	}
+	var i int
+	switch index := params[0].(type) {
+	case int:
+		i = index
+	case int32:
+		i = int(index)
+	default:
+		return 0, ole.NewError(ole.E_INVALIDARG)
+	}
+	if i < 0 || i >= length {
+		return 0, ole.NewError(ole.E_INVALIDARG)
+	}
+	return i, nil
+}
+
+func (d *fakeDispatcher) GetProperty(name string, params ...interface{}) (*variant, error) {
+	return d.invoke("GetProperty", name, params)
+}
+
+// PutProperty replaces the member with the last argument.
+func (d *fakeDispatcher) PutProperty(name string, params ...interface{}) (*variant, error) {
+	d.mu.Lock()
+	d.calls = append(d.calls, "PutProperty "+name)
+	if len(params) > 0 {
+		d.members[name] = params[len(params)-1]
+	}
+	d.mu.Unlock()
+	return &variant{VT: ole.VT_EMPTY}, nil
+}
+
+func (d *fakeDispatcher) CallMethod(name string, params ...interface{}) (*variant, error) {
+	return d.invoke("CallMethod", name, params)
+}
+
+func (d *fakeDispatcher) CreateObject(programID string) (dispatcher, error) {
+	switch programID {
+	case "Microsoft.Update.UpdateColl", "Microsoft.Update.StringColl":
+		return newFakeCollection(), nil
+	}
+	return nil, ole.NewError(ole.CO_E_CLASSSTRING)
+}
+
+func (d *fakeDispatcher) IDispatch() *ole.IDispatch {
+	return nil
+}
+
+// Release counts the releases, see Releases. A fake stays usable after it.
+func (d *fakeDispatcher) Release() {
+	d.mu.Lock()
+	d.releases++
+	d.mu.Unlock()
+}
+
+// Releases returns how many times Release was called.
+func (d *fakeDispatcher) Releases() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.releases
+}
+
+// Calls returns the members accessed so far, e.g. "GetProperty Title".
+func (d *fakeDispatcher) Calls() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.calls...)
+}
+
+func (d *fakeDispatcher) invoke(kind string, name string, params []interface{}) (*variant, error) {
+	d.mu.Lock()
+	d.calls = append(d.calls, kind+" "+name)
+	value, ok := d.members[name]
+	d.mu.Unlock()
+	if !ok {
+		return nil, ole.NewError(hrDispUnknownName)
+	}
+	if member, ok := value.(fakeMember); ok {
+		var err error
+		if value, err = member(params...); err != nil {
+			return nil, err
+		}
+	}
+	if err, ok := value.(error); ok {
+		return nil, err
+	}
+	result, err := toFakeVariant(value)
+	return result.named(name), err
+}
+
+func toFakeVariant(value interface{}) (*variant, error) {
+	switch v := value.(type) {
+	case nil:
+		return &variant{VT: ole.VT_EMPTY}, nil
+	case *variant:
+		return v, nil
+	case int16:
+		return &variant{VT: ole.VT_I2, Value: v}, nil
+	case int32:
+		return &variant{VT: ole.VT_I4, Value: v}, nil
+	case int64:
+		return &variant{VT: ole.VT_I8, Value: v}, nil
+	case uint32:
+		return &variant{VT: ole.VT_UI4, Value: v}, nil
+	case float32:
+		return &variant{VT: ole.VT_R4, Value: v}, nil
+	case float64:
+		return &variant{VT: ole.VT_R8, Value: v}, nil
+	case string:
+		return &variant{VT: ole.VT_BSTR, Value: v}, nil
+	case bool:
+		return &variant{VT: ole.VT_BOOL, Value: v}, nil
+	case time.Time:
+		return &variant{VT: ole.VT_DATE, Value: v}, nil
+	case dispatcher:
+		return &variant{VT: ole.VT_DISPATCH, Value: v}, nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Int32 {
+		return &variant{VT: ole.VT_I4, Value: int32(rv.Int())}, nil
+	}
+	return nil, fmt.Errorf("windowsupdate: fake dispatcher cannot return %T", value)
+}
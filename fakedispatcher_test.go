@@ -0,0 +1,143 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ole/go-ole"
+)
+
+func TestFakeDispatcher_UnknownMember(t *testing.T) {
+	d := newFakeDispatcher(nil)
+	_, err := d.GetProperty("CveIDs")
+	var oleErr *ole.OleError
+	if !errors.As(err, &oleErr) || oleErr.Code() != hrDispUnknownName {
+		t.Errorf("GetProperty of unknown member returned %v, want DISP_E_UNKNOWNNAME", err)
+	}
+}
+
+func TestFakeDispatcher_Values(t *testing.T) {
+	now := time.Now()
+	child := newFakeDispatcher(nil)
+	testErr := errors.New("boom")
+	d := newFakeDispatcher(map[string]interface{}{
+		"I2":     int16(2),
+		"I4":     int32(4),
+		"I8":     int64(8),
+		"UI4":    uint32(4),
+		"R4":     float32(1.5),
+		"R8":     2.5,
+		"BSTR":   "text",
+		"BOOL":   true,
+		"DATE":   now,
+		"Child":  child,
+		"Empty":  nil,
+		"Null":   &variant{VT: ole.VT_NULL},
+		"Failed": testErr,
+	})
+
+	tests := []struct {
+		name  string
+		vt    ole.VT
+		value interface{}
+	}{
+		{"I2", ole.VT_I2, int16(2)},
+		{"I4", ole.VT_I4, int32(4)},
+		{"I8", ole.VT_I8, int64(8)},
+		{"UI4", ole.VT_UI4, uint32(4)},
+		{"R4", ole.VT_R4, float32(1.5)},
+		{"R8", ole.VT_R8, 2.5},
+		{"BSTR", ole.VT_BSTR, "text"},
+		{"BOOL", ole.VT_BOOL, true},
+		{"DATE", ole.VT_DATE, now},
+		{"Child", ole.VT_DISPATCH, child},
+		{"Empty", ole.VT_EMPTY, nil},
+		{"Null", ole.VT_NULL, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := d.GetProperty(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.VT != tt.vt || v.Value != tt.value {
+				t.Errorf("GetProperty(%q) = {%v %v}, want {%v %v}", tt.name, v.VT, v.Value, tt.vt, tt.value)
+			}
+		})
+	}
+
+	if _, err := d.GetProperty("Failed"); err != testErr {
+		t.Errorf("GetProperty(Failed) error = %v, want %v", err, testErr)
+	}
+}
+
+func TestFakeDispatcher_PutPropertyAndCalls(t *testing.T) {
+	d := newFakeDispatcher(map[string]interface{}{"Online": true})
+	if _, err := d.PutProperty("Online", false); err != nil {
+		t.Fatal(err)
+	}
+	online, err := toBoolErr(d.GetProperty("Online"))
+	if err != nil || online {
+		t.Errorf("Online = %v, %v; want false, nil", online, err)
+	}
+	calls := d.Calls()
+	if len(calls) != 2 || calls[0] != "PutProperty Online" || calls[1] != "GetProperty Online" {
+		t.Errorf("Calls() = %v", calls)
+	}
+}
+
+func TestFakeCollection(t *testing.T) {
+	coll := newFakeCollection("a", "b")
+	if _, err := coll.CallMethod("Add", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coll.CallMethod("Insert", int32(0), "z"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coll.CallMethod("RemoveAt", int32(1)); err != nil {
+		t.Fatal(err)
+	}
+	got, err := iStringCollectionToStringArrayErr(coll, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"z", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("collection = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("collection = %v, want %v", got, want)
+		}
+	}
+	if _, err := coll.GetProperty("Item", 3); err == nil {
+		t.Error("Item out of range did not fail")
+	}
+}
+
+func TestFakeDispatcher_CreateObject(t *testing.T) {
+	d := newFakeDispatcher(nil)
+	if _, err := d.CreateObject("Microsoft.Update.UpdateColl"); err != nil {
+		t.Errorf("CreateObject(UpdateColl) failed: %v", err)
+	}
+	if _, err := d.CreateObject("Microsoft.Update.Session"); err == nil {
+		t.Error("CreateObject(Session) succeeded, want error")
+	}
+	if d.IDispatch() != nil {
+		t.Error("IDispatch() of a fake is not nil")
+	}
+}
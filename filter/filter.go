@@ -0,0 +1,436 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package filter selects updates with expressions WUA search criteria cannot
+// express, evaluated client-side against the IUpdate values Search returns:
+//
+//	severity>=Important and kb in (5034441, 5034439) and size<500MB
+//
+// An expression is comparisons joined with and, or, not and parentheses. The
+// fields are:
+//
+//	severity  MsrcSeverity, ordered Unspecified < Low < Moderate < Important < Critical
+//	kb        KBArticleIDs, with or without the KB prefix
+//	cve       CveIDs
+//	category  names of Categories
+//	title     Title
+//	size      MaxDownloadSize, with an optional B, KB, MB, GB or TB suffix
+//	deployed  LastDeploymentChangeTime, as '2006-01-02' (midnight UTC) or an RFC 3339 time
+//	age       time since LastDeploymentChangeTime, e.g. 30d, 12h or 2w
+//
+// and the boolean fields installed, downloaded, hidden, mandatory, beta,
+// uninstallable and rebootrequired, used bare as in "not installed".
+//
+// Comparisons use =, !=, <, <=, >, >=, "in (v1, v2...)", and ~ or !~ for a
+// regular expression match. String comparisons ignore case. Values are bare
+// words, numbers or strings quoted with ' or ". Fields with several values (kb,
+// cve, category) match if any value matches; != and !~ match if none does.
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+// Expr is a parsed filter expression.
+type Expr struct {
+	// Now returns the time the age field is measured from. If nil, time.Now is
+	// used.
+	Now func() time.Time
+
+	source string
+	root   node
+}
+
+// Parse parses a filter expression.
+func Parse(s string) (*Expr, error) {
+	p := &parser{lexer: lexer{input: []rune(s)}}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Expr{source: s, root: root}, nil
+}
+
+// MustParse is like Parse but panics if the expression cannot be parsed.
+func MustParse(s string) *Expr {
+	e, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Match reports whether update matches the expression.
+func (e *Expr) Match(update *windowsupdate.IUpdate) bool {
+	now := time.Now
+	if e.Now != nil {
+		now = e.Now
+	}
+	return e.root.match(update, now)
+}
+
+// Apply returns the updates matching the expression, in order.
+func (e *Expr) Apply(updates []*windowsupdate.IUpdate) []*windowsupdate.IUpdate {
+	var matched []*windowsupdate.IUpdate
+	for _, update := range updates {
+		if e.Match(update) {
+			matched = append(matched, update)
+		}
+	}
+	return matched
+}
+
+// Filter parses expr and returns the updates matching it.
+func Filter(updates []*windowsupdate.IUpdate, expr string) ([]*windowsupdate.IUpdate, error) {
+	e, err := Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return e.Apply(updates), nil
+}
+
+// node is a compiled expression.
+type node interface {
+	match(update *windowsupdate.IUpdate, now func() time.Time) bool
+}
+
+type andNode []node
+
+func (n andNode) match(update *windowsupdate.IUpdate, now func() time.Time) bool {
+	for _, child := range n {
+		if !child.match(update, now) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []node
+
+func (n orNode) match(update *windowsupdate.IUpdate, now func() time.Time) bool {
+	for _, child := range n {
+		if child.match(update, now) {
+			return true
+		}
+	}
+	return false
+}
+
+type notNode struct {
+	node
+}
+
+func (n notNode) match(update *windowsupdate.IUpdate, now func() time.Time) bool {
+	return !n.node.match(update, now)
+}
+
+type matchFunc func(update *windowsupdate.IUpdate, now func() time.Time) bool
+
+func (f matchFunc) match(update *windowsupdate.IUpdate, now func() time.Time) bool {
+	return f(update, now)
+}
+
+type fieldKind int
+
+const (
+	boolField fieldKind = iota
+	orderedField
+	stringsField
+)
+
+// field is a property of IUpdate that expressions can test.
+type field struct {
+	name string
+	kind fieldKind
+	ops  []string
+
+	// For boolField.
+	boolean func(*windowsupdate.IUpdate) bool
+
+	// For orderedField: parse converts a value token, get reads the update,
+	// reporting false if the property is not set.
+	parse func(value token) (float64, error)
+	get   func(update *windowsupdate.IUpdate, now func() time.Time) (float64, bool)
+
+	// For stringsField: the values of the update, and how to normalize values
+	// before comparing them.
+	values    func(*windowsupdate.IUpdate) []string
+	normalize func(string) string
+}
+
+var (
+	orderedOps = []string{"=", "!=", "<", "<=", ">", ">=", "in"}
+	stringOps  = []string{"=", "!=", "in", "~", "!~"}
+)
+
+var fields = []*field{
+	{name: "severity", kind: orderedField, ops: orderedOps, parse: parseSeverity, get: func(u *windowsupdate.IUpdate, _ func() time.Time) (float64, bool) {
+		rank, ok := severityRank(u.MsrcSeverity)
+		return float64(rank), ok
+	}},
+	{name: "kb", kind: stringsField, ops: []string{"=", "!=", "in"}, values: func(u *windowsupdate.IUpdate) []string { return u.KBArticleIDs }, normalize: normalizeKB},
+	{name: "cve", kind: stringsField, ops: stringOps, values: func(u *windowsupdate.IUpdate) []string { return u.CveIDs }},
+	{name: "category", kind: stringsField, ops: stringOps, values: func(u *windowsupdate.IUpdate) []string {
+		names := make([]string, 0, len(u.Categories))
+		for _, category := range u.Categories {
+			if category != nil {
+				names = append(names, category.Name)
+			}
+		}
+		return names
+	}},
+	{name: "title", kind: stringsField, ops: stringOps, values: func(u *windowsupdate.IUpdate) []string { return []string{u.Title} }},
+	{name: "size", kind: orderedField, ops: orderedOps, parse: parseSize, get: func(u *windowsupdate.IUpdate, _ func() time.Time) (float64, bool) {
+		return float64(u.MaxDownloadSize), true
+	}},
+	{name: "deployed", kind: orderedField, ops: orderedOps[:6], parse: parseTime, get: func(u *windowsupdate.IUpdate, _ func() time.Time) (float64, bool) {
+		if u.LastDeploymentChangeTime == nil {
+			return 0, false
+		}
+		return float64(u.LastDeploymentChangeTime.Unix()), true
+	}},
+	{name: "age", kind: orderedField, ops: orderedOps[:6], parse: parseAge, get: func(u *windowsupdate.IUpdate, now func() time.Time) (float64, bool) {
+		if u.LastDeploymentChangeTime == nil {
+			return 0, false
+		}
+		return now().Sub(*u.LastDeploymentChangeTime).Seconds(), true
+	}},
+	{name: "installed", boolean: func(u *windowsupdate.IUpdate) bool { return u.IsInstalled }},
+	{name: "downloaded", boolean: func(u *windowsupdate.IUpdate) bool { return u.IsDownloaded }},
+	{name: "hidden", boolean: func(u *windowsupdate.IUpdate) bool { return u.IsHidden }},
+	{name: "mandatory", boolean: func(u *windowsupdate.IUpdate) bool { return u.IsMandatory }},
+	{name: "beta", boolean: func(u *windowsupdate.IUpdate) bool { return u.IsBeta }},
+	{name: "uninstallable", boolean: func(u *windowsupdate.IUpdate) bool { return u.IsUninstallable }},
+	{name: "rebootrequired", boolean: func(u *windowsupdate.IUpdate) bool { return u.RebootRequired }},
+}
+
+func lookupField(name string) *field {
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f
+		}
+	}
+	return nil
+}
+
+func fieldNames() string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func (f *field) supports(op string) bool {
+	for _, o := range f.ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// compile builds the node testing the field with op against values.
+func (f *field) compile(op string, values []token) (node, error) {
+	switch f.kind {
+	case boolField:
+		return matchFunc(func(u *windowsupdate.IUpdate, _ func() time.Time) bool { return f.boolean(u) }), nil
+	case orderedField:
+		return f.compileOrdered(op, values)
+	}
+	return f.compileStrings(op, values)
+}
+
+func (f *field) compileOrdered(op string, values []token) (node, error) {
+	parsed := make([]float64, len(values))
+	for i, value := range values {
+		var err error
+		if parsed[i], err = f.parse(value); err != nil {
+			return nil, errorAt(value, "invalid %s value %s: %v", f.name, value, err)
+		}
+	}
+	want := parsed[0]
+	var compare func(got float64) bool
+	switch op {
+	case "=":
+		compare = func(got float64) bool { return got == want }
+	case "!=":
+		compare = func(got float64) bool { return got != want }
+	case "<":
+		compare = func(got float64) bool { return got < want }
+	case "<=":
+		compare = func(got float64) bool { return got <= want }
+	case ">":
+		compare = func(got float64) bool { return got > want }
+	case ">=":
+		compare = func(got float64) bool { return got >= want }
+	case "in":
+		compare = func(got float64) bool {
+			for _, want := range parsed {
+				if got == want {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return matchFunc(func(u *windowsupdate.IUpdate, now func() time.Time) bool {
+		got, ok := f.get(u, now)
+		return ok && compare(got)
+	}), nil
+}
+
+func (f *field) compileStrings(op string, values []token) (node, error) {
+	normalize := strings.ToLower
+	if f.normalize != nil {
+		normalize = f.normalize
+	}
+
+	var matchOne func(got string) bool
+	switch op {
+	case "~", "!~":
+		re, err := regexp.Compile(values[0].text)
+		if err != nil {
+			return nil, errorAt(values[0], "invalid regular expression: %v", err)
+		}
+		matchOne = re.MatchString
+	default:
+		wanted := make(map[string]bool, len(values))
+		for _, value := range values {
+			wanted[normalize(value.text)] = true
+		}
+		matchOne = func(got string) bool { return wanted[normalize(got)] }
+	}
+
+	negate := op == "!=" || op == "!~"
+	return matchFunc(func(u *windowsupdate.IUpdate, _ func() time.Time) bool {
+		for _, got := range f.values(u) {
+			if matchOne(got) {
+				return !negate
+			}
+		}
+		return negate
+	}), nil
+}
+
+// severities are the MsrcSeverity values in increasing order; "" is Unspecified.
+var severities = []string{"", "Low", "Moderate", "Important", "Critical"}
+
+func severityRank(severity string) (int, bool) {
+	for rank, s := range severities {
+		if strings.EqualFold(s, severity) {
+			return rank, true
+		}
+	}
+	return 0, false
+}
+
+func parseSeverity(value token) (float64, error) {
+	if strings.EqualFold(value.text, "Unspecified") {
+		return 0, nil
+	}
+	rank, ok := severityRank(value.text)
+	if !ok || value.text == "" {
+		return 0, fmt.Errorf("expected Unspecified, Low, Moderate, Important or Critical")
+	}
+	return float64(rank), nil
+}
+
+func normalizeKB(kb string) string {
+	kb = strings.ToLower(strings.TrimSpace(kb))
+	return strings.TrimPrefix(kb, "kb")
+}
+
+var sizeUnits = map[string]float64{
+	"":   1,
+	"b":  1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+	"tb": 1 << 40,
+}
+
+func parseSize(value token) (float64, error) {
+	number, unit := splitUnit(value.text)
+	multiplier, ok := sizeUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q, expected B, KB, MB, GB or TB", unit)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number")
+	}
+	return n * multiplier, nil
+}
+
+var ageUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+func parseAge(value token) (float64, error) {
+	number, unit := splitUnit(value.text)
+	multiplier, ok := ageUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("expected a duration such as 30d, 12h or 2w")
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a number")
+	}
+	return n * multiplier.Seconds(), nil
+}
+
+func parseTime(value token) (float64, error) {
+	if t, err := time.Parse(time.DateOnly, value.text); err == nil {
+		return float64(t.Unix()), nil
+	}
+	t, err := time.Parse(time.RFC3339, value.text)
+	if err != nil {
+		return 0, fmt.Errorf("expected a date such as '2006-01-02' or an RFC 3339 time")
+	}
+	return float64(t.Unix()), nil
+}
+
+// splitUnit splits a number such as 500MB into "500" and "MB".
+func splitUnit(s string) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
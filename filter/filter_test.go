@@ -0,0 +1,139 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+var now = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+func testUpdates() []*windowsupdate.IUpdate {
+	deployed := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	return []*windowsupdate.IUpdate{
+		{
+			Title:                    "2024-01 Cumulative Update for Windows 11 (KB5034441)",
+			MsrcSeverity:             "Critical",
+			KBArticleIDs:             []string{"5034441"},
+			CveIDs:                   []string{"CVE-2024-20666"},
+			Categories:               []*windowsupdate.ICategory{{Name: "Security Updates"}, {Name: "Windows 11"}},
+			MaxDownloadSize:          800 << 20,
+			LastDeploymentChangeTime: deployed(10),
+		},
+		{
+			Title:                    "Servicing Stack Update (KB5034439)",
+			MsrcSeverity:             "Important",
+			KBArticleIDs:             []string{"5034439"},
+			Categories:               []*windowsupdate.ICategory{{Name: "Critical Updates"}},
+			MaxDownloadSize:          20 << 20,
+			LastDeploymentChangeTime: deployed(40),
+			IsInstalled:              true,
+		},
+		{
+			Title:           "Intel - Display - 31.0.101.4502",
+			Categories:      []*windowsupdate.ICategory{{Name: "Drivers"}},
+			MaxDownloadSize: 300 << 20,
+			IsHidden:        true,
+		},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{"severity>=Important", []int{0, 1}},
+		{"severity = critical", []int{0}},
+		{"severity < Moderate", []int{2}},
+		{"severity in (Low, Important)", []int{1}},
+		{"kb in (5034441, KB5034439)", []int{0, 1}},
+		{"kb = 'kb5034439'", []int{1}},
+		{"kb != 5034441", []int{1, 2}},
+		{"size<500MB", []int{1, 2}},
+		{"size >= 0.5GB", []int{0}},
+		{"size > 20971520", []int{0, 2}},
+		{"cve = cve-2024-20666", []int{0}},
+		{"cve ~ '^CVE-2024-'", []int{0}},
+		{"category = 'security updates'", []int{0}},
+		{"category in ('Drivers', 'Critical Updates')", []int{1, 2}},
+		{"category !~ Update", []int{2}},
+		{`title ~ 'KB\d+'`, []int{0, 1}},
+		{`title !~ "KB\d+"`, []int{2}},
+		{"deployed >= '2026-01-01'", []int{0}},
+		{"deployed < '2026-01-20T00:00:00Z'", []int{1}},
+		{"age < 30d", []int{0}},
+		{"age >= 4w", []int{1}},
+		{"installed", []int{1}},
+		{"not installed and not hidden", []int{0}},
+		{"severity>=Important and kb in (5034441,5034439) and size<500MB", []int{1}},
+		{"hidden or (severity = Critical and not installed)", []int{0, 2}},
+		{"not (hidden or installed)", []int{0}},
+		{"SEVERITY >= important AND Installed", []int{1}},
+	}
+	for _, tt := range tests {
+		updates := testUpdates()
+		e, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		e.Now = func() time.Time { return now }
+		var want []*windowsupdate.IUpdate
+		for _, i := range tt.want {
+			want = append(want, updates[i])
+		}
+		if got := e.Apply(updates); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q matched %v, want %v", tt.expr, titles(got), titles(want))
+		}
+	}
+}
+
+func titles(updates []*windowsupdate.IUpdate) []string {
+	var titles []string
+	for _, update := range updates {
+		titles = append(titles, update.Title)
+	}
+	return titles
+}
+
+func TestFilterHelper(t *testing.T) {
+	updates := testUpdates()
+	got, err := Filter(updates, "kb = 5034441")
+	if err != nil || len(got) != 1 || got[0] != updates[0] {
+		t.Errorf("Filter() = %v, %v; want the first update", titles(got), err)
+	}
+	if _, err := Filter(updates, "kb >"); err == nil {
+		t.Error("Filter() with an invalid expression succeeded")
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	if e := MustParse("installed"); e.String() != "installed" {
+		t.Errorf("String() = %q", e.String())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParse of an invalid expression did not panic")
+		}
+	}()
+	MustParse("installed and")
+}
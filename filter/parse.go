@@ -0,0 +1,324 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SyntaxError is an invalid filter expression. Column is the 1-based position,
+// in characters, of the offending token.
+type SyntaxError struct {
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("filter: column %d: %s", e.Column, e.Msg)
+}
+
+type parser struct {
+	lexer
+	tok token
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return errorAt(p.tok, format, args...)
+}
+
+func errorAt(tok token, format string, args ...interface{}) error {
+	return &SyntaxError{Column: tok.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := orNode{first}
+	for p.tok.isKeyword("or") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, n)
+	}
+	if len(or) == 1 {
+		return first, nil
+	}
+	return or, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	and := andNode{first}
+	for p.tok.isKeyword("and") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, n)
+	}
+	if len(and) == 1 {
+		return first, nil
+	}
+	return and, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	switch {
+	case p.tok.isKeyword("not"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	case p.tok.kind == tokLParen:
+		open := p.tok
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ) to close ( at column %d, got %s", open.column, p.tok)
+		}
+		return n, p.next()
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	if p.tok.kind != tokIdent || p.tok.isKeyword("and") || p.tok.isKeyword("or") || p.tok.isKeyword("in") {
+		return nil, p.errorf("expected a field, got %s", p.tok)
+	}
+	fieldTok := p.tok
+	f := lookupField(fieldTok.text)
+	if f == nil {
+		return nil, p.errorf("unknown field %q, expected one of %s", fieldTok.text, fieldNames())
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	if f.kind == boolField {
+		// Boolean fields are used bare: "installed", "not hidden".
+		return f.compile("", nil)
+	}
+
+	var op string
+	switch {
+	case p.tok.kind == tokOperator:
+		op = p.tok.text
+	case p.tok.isKeyword("in"):
+		op = "in"
+	default:
+		return nil, p.errorf("expected an operator after %s, got %s", f.name, p.tok)
+	}
+	if !f.supports(op) {
+		return nil, p.errorf("%s does not support operator %s, only %s", f.name, op, strings.Join(f.ops, ", "))
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	var values []token
+	if op == "in" {
+		if p.tok.kind != tokLParen {
+			return nil, p.errorf("expected ( after in, got %s", p.tok)
+		}
+		for {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if !p.tok.isValue() {
+				return nil, p.errorf("expected a value, got %s", p.tok)
+			}
+			values = append(values, p.tok)
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if p.tok.kind == tokRParen {
+				break
+			}
+			if p.tok.kind != tokComma {
+				return nil, p.errorf("expected , or ) in list, got %s", p.tok)
+			}
+		}
+	} else {
+		if !p.tok.isValue() {
+			return nil, p.errorf("expected a value after %s %s, got %s", f.name, op, p.tok)
+		}
+		values = append(values, p.tok)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	return f.compile(op, values)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	column int
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) isValue() bool {
+	return t.kind == tokIdent || t.kind == tokNumber || t.kind == tokString
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return "string " + strconv.Quote(t.text)
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func (l *lexer) peek(offset int) rune {
+	if l.pos+offset < len(l.input) {
+		return l.input[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) lex() (token, error) {
+	for l.pos < len(l.input) && unicode.IsSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	column := start + 1
+	if l.pos == len(l.input) {
+		return token{kind: tokEOF, column: column}, nil
+	}
+
+	r := l.input[l.pos]
+	switch {
+	case r == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", column: column}, nil
+	case r == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", column: column}, nil
+	case r == ',':
+		l.pos++
+		return token{kind: tokComma, text: ",", column: column}, nil
+	case r == '\'' || r == '"':
+		return l.lexString(r, column)
+	case strings.ContainsRune("=!<>~", r):
+		l.pos++
+		switch {
+		case r == '~':
+		case l.peek(0) == '=':
+			l.pos++
+		case r == '!' && l.peek(0) == '~':
+			l.pos++
+		case r == '!':
+			return token{}, &SyntaxError{Column: column, Msg: "expected != or !~"}
+		}
+		text := string(l.input[start:l.pos])
+		if text == "==" {
+			text = "="
+		}
+		return token{kind: tokOperator, text: text, column: column}, nil
+	case unicode.IsDigit(r):
+		for l.pos < len(l.input) && (unicode.IsLetter(l.input[l.pos]) || unicode.IsDigit(l.input[l.pos]) || l.input[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: string(l.input[start:l.pos]), column: column}, nil
+	case unicode.IsLetter(r) || r == '_':
+		for l.pos < len(l.input) && (unicode.IsLetter(l.input[l.pos]) || unicode.IsDigit(l.input[l.pos]) || l.input[l.pos] == '_' || l.input[l.pos] == '-') {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: string(l.input[start:l.pos]), column: column}, nil
+	}
+	return token{}, &SyntaxError{Column: column, Msg: fmt.Sprintf("unexpected character %q", r)}
+}
+
+// lexString reads a string quoted with quote. A backslash escapes the quote or
+// another backslash and is kept as is before any other character, so regular
+// expressions such as 'KB\d+' need no doubling.
+func (l *lexer) lexString(quote rune, column int) (token, error) {
+	var b strings.Builder
+	l.pos++
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		l.pos++
+		switch r {
+		case quote:
+			return token{kind: tokString, text: b.String(), column: column}, nil
+		case '\\':
+			if l.pos == len(l.input) {
+				return token{}, &SyntaxError{Column: l.pos, Msg: "unterminated escape sequence"}
+			}
+			if next := l.input[l.pos]; next == quote || next == '\\' {
+				r = next
+				l.pos++
+			}
+		}
+		b.WriteRune(r)
+	}
+	return token{}, &SyntaxError{Column: column, Msg: "unterminated string"}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input  string
+		column int
+		msg    string
+	}{
+		{"", 1, "expected a field, got end of filter"},
+		{"sevrity>=Important", 1, `unknown field "sevrity"`},
+		{"severity>=Importnt", 11, `invalid severity value "Importnt": expected Unspecified, Low, Moderate, Important or Critical`},
+		{"size<500XB", 6, `invalid size value "500XB": unknown unit "XB", expected B, KB, MB, GB or TB`},
+		{"age < 30", 7, `invalid age value "30": expected a duration such as 30d, 12h or 2w`},
+		{"deployed > 'last week'", 12, `invalid deployed value string "last week": expected a date such as '2006-01-02' or an RFC 3339 time`},
+		{"kb ~ '5034'", 4, "kb does not support operator ~, only =, !=, in"},
+		{"age in (1d)", 5, "age does not support operator in, only =, !=, <, <=, >, >="},
+		{"title ~ '('", 9, "invalid regular expression: error parsing regexp: missing closing ): `(`"},
+		{"kb in 5034441", 7, `expected ( after in, got "5034441"`},
+		{"kb in (5034441 5034439)", 16, `expected , or ) in list, got "5034439"`},
+		{"kb in (5034441,)", 16, `expected a value, got ")"`},
+		{"kb 5034441", 4, `expected an operator after kb, got "5034441"`},
+		{"kb =", 5, "expected a value after kb =, got end of filter"},
+		{"installed hidden", 11, `unexpected "hidden"`},
+		{"(installed or hidden", 21, "expected ) to close ( at column 1, got end of filter"},
+		{"installed and", 14, "expected a field, got end of filter"},
+		{"title = 'abc", 9, "unterminated string"},
+		{"size ! 5", 6, "expected != or !~"},
+		{"size < 5 & installed", 10, "unexpected character '&'"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) error = %v, want a *SyntaxError", tt.input, err)
+			continue
+		}
+		if syntaxErr.Column != tt.column || !strings.HasPrefix(syntaxErr.Msg, tt.msg) {
+			t.Errorf("Parse(%q) error = column %d: %s, want column %d: %s", tt.input, syntaxErr.Column, syntaxErr.Msg, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestSyntaxError_Error(t *testing.T) {
+	err := &SyntaxError{Column: 3, Msg: "boom"}
+	if got := err.Error(); got != "filter: column 3: boom" {
+		t.Errorf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-ole/go-ole"
+)
+
+// FixtureVersion is the version of the fixture format written by Recorder.
+const FixtureVersion = 1
+
+// Fixture is a recording of the COM traffic of an update session: every
+// property read, property write and method call made on every object reached
+// from the session, with its result. Objects are numbered in the order they were
+// first seen; Root is the session itself.
+type Fixture struct {
+	Version int              `json:"version"`
+	Root    int              `json:"root"`
+	Objects []*FixtureObject `json:"objects"`
+}
+
+// FixtureObject holds the calls recorded on one COM object.
+type FixtureObject struct {
+	ID    int            `json:"id"`
+	Calls []*FixtureCall `json:"calls"`
+}
+
+// FixtureCall is one recorded call. Kind is "GetProperty", "PutProperty",
+// "CallMethod" or "CreateObject"; for CreateObject, Name is the program ID.
+// Exactly one of Result and Error is set.
+type FixtureCall struct {
+	Kind   string          `json:"kind"`
+	Name   string          `json:"name"`
+	Params []*FixtureValue `json:"params,omitempty"`
+	Result *FixtureValue   `json:"result,omitempty"`
+	Error  *FixtureError   `json:"error,omitempty"`
+}
+
+// FixtureValue is a recorded VARIANT. VT is the VARTYPE reported by WUA. Value
+// holds the JSON encoding of the Go value, except for VT_DISPATCH where Object
+// is the ID of the referenced object, or 0 for a null object.
+type FixtureValue struct {
+	VT     ole.VT          `json:"vt"`
+	Value  json.RawMessage `json:"value,omitempty"`
+	Object int             `json:"object,omitempty"`
+}
+
+// FixtureError is a recorded failed call.
+type FixtureError struct {
+	HResult uint32 `json:"hresult,omitempty"`
+	Message string `json:"message"`
+}
+
+// ReadFixture decodes a fixture written by Fixture.Write.
+func ReadFixture(r io.Reader) (*Fixture, error) {
+	var fixture Fixture
+	if err := json.NewDecoder(r).Decode(&fixture); err != nil {
+		return nil, err
+	}
+	if fixture.Version != FixtureVersion {
+		return nil, fmt.Errorf("windowsupdate: unsupported fixture version %d", fixture.Version)
+	}
+	return &fixture, nil
+}
+
+// Write encodes the fixture as indented JSON.
+func (f *Fixture) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(f)
+}
+
+// object returns the recorded object with the given ID, or nil.
+func (f *Fixture) object(id int) *FixtureObject {
+	for _, object := range f.Objects {
+		if object.ID == id {
+			return object
+		}
+	}
+	return nil
+}
+
+// encodeFixtureError records err, keeping the HRESULT of COM errors as
+// HResultOf reads it: the scode of a DISP_E_EXCEPTION rather than
+// DISP_E_EXCEPTION itself.
+func encodeFixtureError(err error) *FixtureError {
+	if hr, ok := HResultOf(err); ok {
+		message := err.Error()
+		var oleErr *ole.OleError
+		if errors.As(err, &oleErr) {
+			message = oleErr.Error()
+		}
+		return &FixtureError{HResult: uint32(hr), Message: message}
+	}
+	return &FixtureError{Message: err.Error()}
+}
+
+func (e *FixtureError) decode() error {
+	if e.HResult != 0 {
+		return newComError(ole.NewErrorWithDescription(uintptr(e.HResult), e.Message))
+	}
+	return errors.New(e.Message)
+}
+
+// encodeFixtureValue encodes v. objectID resolves dispatchers to object IDs.
+func encodeFixtureValue(v *variant, objectID func(dispatcher) int) (*FixtureValue, error) {
+	value := &FixtureValue{VT: v.VT}
+	switch x := v.Value.(type) {
+	case nil:
+		return value, nil
+	case dispatcher:
+		value.Object = objectID(x)
+		return value, nil
+	case time.Time:
+		// time.Time marshals to RFC 3339, which is all we need.
+	}
+	raw, err := json.Marshal(v.Value)
+	if err != nil {
+		return nil, err
+	}
+	value.Value = raw
+	return value, nil
+}
+
+// encodeFixtureParam encodes a call argument. Arguments that are not
+// dispatchers are recorded with the VARTYPE oleutil would marshal them as; raw
+// COM pointers such as the asynchronous callbacks are recorded as null.
+func encodeFixtureParam(param interface{}, objectID func(dispatcher) int) (*FixtureValue, error) {
+	switch p := param.(type) {
+	case nil, *ole.IDispatch, *ole.IUnknown:
+		return &FixtureValue{VT: ole.VT_NULL}, nil
+	case dispatcher:
+		return &FixtureValue{VT: ole.VT_DISPATCH, Object: objectID(p)}, nil
+	case int, int32, int16, int8, uint8, uint16, uint32:
+		return encodeFixtureValue(&variant{VT: ole.VT_I4, Value: p}, objectID)
+	}
+	v, err := toFakeVariant(param)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFixtureValue(v, objectID)
+}
+
+// decodeFixtureValue decodes a recorded value. object resolves object IDs.
+func decodeFixtureValue(value *FixtureValue, object func(int) dispatcher) (*variant, error) {
+	decoded := &variant{VT: value.VT}
+	if value.VT == ole.VT_DISPATCH {
+		if value.Object != 0 {
+			decoded.Value = object(value.Object)
+		}
+		return decoded, nil
+	}
+	if len(value.Value) == 0 || string(value.Value) == "null" {
+		return decoded, nil
+	}
+
+	var err error
+	switch value.VT {
+	case ole.VT_I1:
+		decoded.Value, err = unmarshalAs[int8](value.Value)
+	case ole.VT_UI1:
+		decoded.Value, err = unmarshalAs[uint8](value.Value)
+	case ole.VT_I2:
+		decoded.Value, err = unmarshalAs[int16](value.Value)
+	case ole.VT_UI2:
+		decoded.Value, err = unmarshalAs[uint16](value.Value)
+	case ole.VT_I4:
+		decoded.Value, err = unmarshalAs[int32](value.Value)
+	case ole.VT_UI4:
+		decoded.Value, err = unmarshalAs[uint32](value.Value)
+	case ole.VT_I8:
+		decoded.Value, err = unmarshalAs[int64](value.Value)
+	case ole.VT_UI8:
+		decoded.Value, err = unmarshalAs[uint64](value.Value)
+	case ole.VT_INT:
+		decoded.Value, err = unmarshalAs[int](value.Value)
+	case ole.VT_UINT:
+		decoded.Value, err = unmarshalAs[uint](value.Value)
+	case ole.VT_R4:
+		decoded.Value, err = unmarshalAs[float32](value.Value)
+	case ole.VT_R8:
+		decoded.Value, err = unmarshalAs[float64](value.Value)
+	case ole.VT_BSTR:
+		decoded.Value, err = unmarshalAs[string](value.Value)
+	case ole.VT_BOOL:
+		decoded.Value, err = unmarshalAs[bool](value.Value)
+	case ole.VT_DATE:
+		decoded.Value, err = unmarshalAs[time.Time](value.Value)
+	case ole.VT_ERROR:
+		decoded.Value, err = unmarshalAs[HRESULT](value.Value)
+	case ole.VT_DECIMAL:
+		decoded.Value, err = unmarshalAs[decimal](value.Value)
+	default:
+		err = fmt.Errorf("windowsupdate: cannot decode fixture value of type %s", value.VT)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+func unmarshalAs[T any](raw json.RawMessage) (T, error) {
+	var value T
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-ole/go-ole"
+)
+
+func TestFixtureValue_RoundTrip(t *testing.T) {
+	object := newFakeDispatcher(nil)
+	objectID := func(d dispatcher) int {
+		if d == object {
+			return 4
+		}
+		return -1
+	}
+	resolve := func(id int) dispatcher {
+		if id == 4 {
+			return object
+		}
+		return nil
+	}
+
+	tests := []*variant{
+		{VT: ole.VT_EMPTY},
+		{VT: ole.VT_I2, Value: int16(-2)},
+		{VT: ole.VT_I4, Value: int32(-2145124329)},
+		{VT: ole.VT_UI4, Value: uint32(0x80240024)},
+		{VT: ole.VT_I8, Value: int64(400 << 20)},
+		{VT: ole.VT_R8, Value: 1.5},
+		{VT: ole.VT_BSTR, Value: "KB5034441"},
+		{VT: ole.VT_BOOL, Value: true},
+		{VT: ole.VT_DATE, Value: time.Date(2026, 1, 14, 3, 0, 0, 0, time.UTC)},
+		{VT: ole.VT_ERROR, Value: HRESULT(0x80020004)},
+		{VT: ole.VT_DECIMAL, Value: decimal{Scale: 2, Negative: true, Hi: 1, Lo: 150}},
+		{VT: ole.VT_DISPATCH, Value: object},
+		{VT: ole.VT_DISPATCH},
+	}
+	for _, want := range tests {
+		encoded, err := encodeFixtureValue(want, objectID)
+		if err != nil {
+			t.Errorf("encodeFixtureValue(%+v) failed: %v", want, err)
+			continue
+		}
+		got, err := decodeFixtureValue(encoded, resolve)
+		if err != nil {
+			t.Errorf("decodeFixtureValue(%+v) failed: %v", encoded, err)
+			continue
+		}
+		if got.VT != want.VT || !reflect.DeepEqual(got.Value, want.Value) {
+			t.Errorf("round trip of %+v = %+v", want, got)
+		}
+	}
+}
+
+func TestDecodeFixtureValue_UnsupportedType(t *testing.T) {
+	if _, err := decodeFixtureValue(&FixtureValue{VT: ole.VT_ARRAY, Value: []byte("[]")}, nil); err == nil {
+		t.Error("decoding a VT_ARRAY value succeeded")
+	}
+}
+
+func TestFixtureError(t *testing.T) {
+	decoded := encodeFixtureError(ole.NewError(0x80240024)).decode()
+	var oleErr *ole.OleError
+	if !errors.As(decoded, &oleErr) || oleErr.Code() != 0x80240024 {
+		t.Errorf("decoded COM error = %v, want HRESULT 0x80240024", decoded)
+	}
+	if !errors.Is(decoded, ErrNoUpdate) {
+		t.Errorf("errors.Is(%v, ErrNoUpdate) = false, want true", decoded)
+	}
+	decoded = encodeFixtureError(newComError(ole.NewErrorWithSubError(0x80020009, "", fakeExcepInfo(0x8024402C)))).decode()
+	if hr, ok := HResultOf(decoded); !ok || hr != 0x8024402C {
+		t.Errorf("HResultOf(decoded DISP_E_EXCEPTION) = %#x, %v, want the scode 0x8024402C", uint32(hr), ok)
+	}
+	if !errors.Is(decoded, ErrNoConnection) {
+		t.Errorf("errors.Is(%v, ErrNoConnection) = false, want true", decoded)
+	}
+	if decoded := encodeFixtureError(errors.New("boom")).decode(); decoded.Error() != "boom" {
+		t.Errorf("decoded error = %v, want boom", decoded)
+	}
+}
+
+func TestReadFixture_Version(t *testing.T) {
+	if _, err := ReadFixture(strings.NewReader(`{"version": 99, "root": 1}`)); err == nil {
+		t.Error("ReadFixture accepted an unknown version")
+	}
+	if _, err := ReadFixture(strings.NewReader(`{`)); err == nil {
+		t.Error("ReadFixture accepted malformed JSON")
+	}
+}
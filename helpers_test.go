@@ -154,25 +154,25 @@ func TestEnumValues(t *testing.T) {
 		expected int32
 	}{
 		// OperationResultCode
-		{"OrcNotStarted", OperationResultCodeOrcNotStarted, 0},
-		{"OrcInProgress", OperationResultCodeOrcInProgress, 1},
-		{"OrcSucceeded", OperationResultCodeOrcSucceeded, 2},
-		{"OrcSucceededWithErrors", OperationResultCodeOrcSucceededWithErrors, 3},
-		{"OrcFailed", OperationResultCodeOrcFailed, 4},
-		{"OrcAborted", OperationResultCodeOrcAborted, 5},
+		{"OrcNotStarted", int32(OperationResultCodeOrcNotStarted), 0},
+		{"OrcInProgress", int32(OperationResultCodeOrcInProgress), 1},
+		{"OrcSucceeded", int32(OperationResultCodeOrcSucceeded), 2},
+		{"OrcSucceededWithErrors", int32(OperationResultCodeOrcSucceededWithErrors), 3},
+		{"OrcFailed", int32(OperationResultCodeOrcFailed), 4},
+		{"OrcAborted", int32(OperationResultCodeOrcAborted), 5},
 
 		// DeploymentAction
-		{"DaNone", DeploymentActionDaNone, 0},
-		{"DaInstallation", DeploymentActionDaInstallation, 2},
+		{"DaNone", int32(DeploymentActionDaNone), 0},
+		{"DaInstallation", int32(DeploymentActionDaInstallation), 2},
 
 		// DownloadPriority
-		{"DpLow", DownloadPriorityDpLow, 1},
-		{"DpNormal", DownloadPriorityDpNormal, 2},
-		{"DpHigh", DownloadPriorityDpHigh, 3},
+		{"DpLow", int32(DownloadPriorityDpLow), 1},
+		{"DpNormal", int32(DownloadPriorityDpNormal), 2},
+		{"DpHigh", int32(DownloadPriorityDpHigh), 3},
 
 		// ServerSelection
-		{"SsDefault", ServerSelectionSsDefault, 0},
-		{"SsWindowsUpdate", ServerSelectionSsWindowsUpdate, 2},
+		{"SsDefault", int32(ServerSelectionSsDefault), 0},
+		{"SsWindowsUpdate", int32(ServerSelectionSsWindowsUpdate), 2},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,247 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-ole/go-ole"
+)
+
+// HRESULT is a COM result code, as reported by failed WUA calls and by the
+// HResult properties of results and history entries. Non-zero values are
+// errors; errors.Is matches them against the sentinel errors of this package.
+// https://learn.microsoft.com/en-us/openspecs/windows_protocols/ms-erref/0642cb2f-2075-4469-918c-4441e69c548a
+type HRESULT uint32
+
+// Sentinel errors matched by errors.Is against the HRESULT of an error. Each
+// covers the codes WUA reports for the same condition.
+var (
+	ErrNoUpdate          = errors.New("windowsupdate: no updates")
+	ErrInstallNotAllowed = errors.New("windowsupdate: installation not allowed")
+	ErrRebootRequired    = errors.New("windowsupdate: reboot required")
+	ErrAccessDenied      = errors.New("windowsupdate: access denied")
+	ErrNoConnection      = errors.New("windowsupdate: no network connection")
+	ErrCanceled          = errors.New("windowsupdate: operation canceled")
+)
+
+var hresultSentinels = map[HRESULT]error{
+	0x80240024: ErrNoUpdate, // WU_E_NO_UPDATE
+
+	0x80240016: ErrInstallNotAllowed, // WU_E_INSTALL_NOT_ALLOWED
+	0x80070652: ErrInstallNotAllowed, // ERROR_INSTALL_ALREADY_RUNNING
+
+	0x00240005: ErrRebootRequired, // WU_S_REBOOT_REQUIRED
+	0x8024D00C: ErrRebootRequired, // WU_E_SETUP_REBOOT_TO_FIX
+	0x8024D00E: ErrRebootRequired, // WU_E_SETUP_REBOOTREQUIRED
+	0x80070BC2: ErrRebootRequired, // ERROR_SUCCESS_REBOOT_REQUIRED
+	0x80070BC9: ErrRebootRequired, // ERROR_FAIL_REBOOT_REQUIRED
+
+	0x80070005: ErrAccessDenied, // E_ACCESSDENIED
+	0x80240025: ErrAccessDenied, // WU_E_USER_ACCESS_DISABLED
+	0x80240044: ErrAccessDenied, // WU_E_PER_MACHINE_UPDATE_ACCESS_DENIED
+
+	0x8024001F: ErrNoConnection, // WU_E_NO_CONNECTION
+	0x8024402C: ErrNoConnection, // WU_E_PT_WINHTTP_NAME_NOT_RESOLVED
+	0x80246005: ErrNoConnection, // WU_E_DM_NONETWORK
+	0x80072EE2: ErrNoConnection, // ERROR_INTERNET_TIMEOUT
+	0x80072EE7: ErrNoConnection, // ERROR_INTERNET_NAME_NOT_RESOLVED
+	0x80072EFD: ErrNoConnection, // ERROR_INTERNET_CANNOT_CONNECT
+	0x80072EFE: ErrNoConnection, // ERROR_INTERNET_CONNECTION_ABORTED
+
+	0x8024000B: ErrCanceled, // WU_E_CALL_CANCELLED
+	0x80242008: ErrCanceled, // WU_E_UH_OPERATIONCANCELLED
+	0x80004004: ErrCanceled, // E_ABORT
+}
+
+type hresultInfo struct {
+	name        string
+	description string
+}
+
+var (
+	hresultsByCode = make(map[HRESULT]hresultInfo, len(hresultCatalog))
+	hresultsByName = make(map[string]HRESULT, len(hresultCatalog))
+)
+
+func init() {
+	for _, entry := range hresultCatalog {
+		hresultsByCode[entry.hr] = hresultInfo{name: entry.name, description: entry.description}
+		hresultsByName[entry.name] = entry.hr
+	}
+}
+
+// ParseHRESULT parses a symbolic name such as "WU_E_NO_UPDATE" or a
+// hexadecimal or decimal code such as "0x80240024" or "-2145124316".
+func ParseHRESULT(s string) (HRESULT, error) {
+	s = strings.TrimSpace(s)
+	if hr, ok := hresultsByName[strings.ToUpper(s)]; ok {
+		return hr, nil
+	}
+	if n, err := strconv.ParseUint(s, 0, 32); err == nil {
+		return HRESULT(n), nil
+	}
+	if n, err := strconv.ParseInt(s, 0, 32); err == nil {
+		return HRESULT(uint32(int32(n))), nil
+	}
+	return 0, fmt.Errorf("windowsupdate: unknown HRESULT %q", s)
+}
+
+// Failed reports whether the severity bit of hr is set.
+func (hr HRESULT) Failed() bool {
+	return hr&0x80000000 != 0
+}
+
+// Facility returns the facility of hr.
+func (hr HRESULT) Facility() Facility {
+	return Facility(hr >> 16 & 0x7FF)
+}
+
+// Code returns the facility-specific code of hr, e.g. the Win32 error code of
+// FACILITY_WIN32 HRESULTs.
+func (hr HRESULT) Code() uint16 {
+	return uint16(hr)
+}
+
+// Name returns the symbolic name of hr, or "" if hr is not in the catalog.
+func (hr HRESULT) Name() string {
+	return hresultsByCode[hr].name
+}
+
+// Description returns the documented meaning of hr, or "" if hr is not in the
+// catalog.
+func (hr HRESULT) Description() string {
+	return hresultsByCode[hr].description
+}
+
+// String returns the name and code of hr, e.g. "WU_E_NO_UPDATE (0x80240024)".
+func (hr HRESULT) String() string {
+	if name := hr.Name(); name != "" {
+		return fmt.Sprintf("%s (0x%08X)", name, uint32(hr))
+	}
+	return fmt.Sprintf("HRESULT 0x%08X", uint32(hr))
+}
+
+// Error implements error.
+func (hr HRESULT) Error() string {
+	if description := hr.Description(); description != "" {
+		return "windowsupdate: " + hr.String() + ": " + description
+	}
+	return "windowsupdate: " + hr.String()
+}
+
+// Is reports whether target is the sentinel error covering hr.
+func (hr HRESULT) Is(target error) bool {
+	sentinel, ok := hresultSentinels[hr]
+	return ok && sentinel == target
+}
+
+// Facility is the facility field of an HRESULT.
+type Facility uint16
+
+// Facilities of the HRESULTs in the catalog.
+const (
+	FacilityNull          Facility = 0
+	FacilityRPC           Facility = 1
+	FacilityDispatch      Facility = 2
+	FacilityITF           Facility = 4
+	FacilityWin32         Facility = 7
+	FacilityInternet      Facility = 12
+	FacilitySetupAPI      Facility = 15
+	FacilityHTTP          Facility = 25
+	FacilityWindowsUpdate Facility = 36
+)
+
+var facilityNames = map[Facility]string{
+	FacilityNull:          "FACILITY_NULL",
+	FacilityRPC:           "FACILITY_RPC",
+	FacilityDispatch:      "FACILITY_DISPATCH",
+	FacilityITF:           "FACILITY_ITF",
+	FacilityWin32:         "FACILITY_WIN32",
+	FacilityInternet:      "FACILITY_INTERNET",
+	FacilitySetupAPI:      "FACILITY_SETUPAPI",
+	FacilityHTTP:          "FACILITY_HTTP",
+	FacilityWindowsUpdate: "FACILITY_WINDOWSUPDATE",
+}
+
+// String returns the symbolic name of f, or its number.
+func (f Facility) String() string {
+	if name, ok := facilityNames[f]; ok {
+		return name
+	}
+	return "FACILITY_" + strconv.Itoa(int(f))
+}
+
+// ComError is the error of a failed WUA call. It wraps the error reported by
+// go-ole, so both errors.Is with the sentinel errors and errors.As with
+// *ole.OleError work on it.
+type ComError struct {
+	HRESULT HRESULT
+	Err     error
+}
+
+func (e *ComError) Error() string {
+	return e.HRESULT.String() + ": " + e.Err.Error()
+}
+
+func (e *ComError) Unwrap() []error {
+	return []error{e.HRESULT, e.Err}
+}
+
+// newComError wraps the error of a go-ole call, or returns err as is if it
+// carries no HRESULT.
+func newComError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var comErr *ComError
+	if errors.As(err, &comErr) {
+		return err
+	}
+	hr, ok := HResultOf(err)
+	if !ok {
+		return err
+	}
+	return &ComError{HRESULT: hr, Err: err}
+}
+
+// HResultOf returns the HRESULT carried by err. For DISP_E_EXCEPTION, which
+// IDispatch reports for every failed WUA call, it is the scode of the
+// exception rather than DISP_E_EXCEPTION itself.
+func HResultOf(err error) (HRESULT, bool) {
+	var hr HRESULT
+	if errors.As(err, &hr) {
+		return hr, true
+	}
+	var oleErr *ole.OleError
+	if !errors.As(err, &oleErr) {
+		return 0, false
+	}
+	if excepInfo, ok := oleErr.SubError().(interface{ SCODE() uint32 }); ok && excepInfo.SCODE() != 0 {
+		return HRESULT(excepInfo.SCODE()), true
+	}
+	return HRESULT(oleErr.Code()), true
+}
+
+// HResultError returns the HResult property of a result as an error, or nil if
+// it is zero.
+func HResultError(hr int32) error {
+	if hr == 0 {
+		return nil
+	}
+	return HRESULT(uint32(hr))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-ole/go-ole"
+)
+
+func TestHRESULT_Catalog(t *testing.T) {
+	names := make(map[string]bool)
+	for _, entry := range hresultCatalog {
+		if entry.name == "" || entry.description == "" {
+			t.Errorf("catalog entry %#x has no name or description", uint32(entry.hr))
+		}
+		if names[entry.name] {
+			t.Errorf("catalog name %s is duplicated", entry.name)
+		}
+		names[entry.name] = true
+		if hresultsByCode[entry.hr].name != entry.name {
+			t.Errorf("catalog code %#x is duplicated", uint32(entry.hr))
+		}
+	}
+	for hr := range hresultSentinels {
+		if hr.Name() == "" {
+			t.Errorf("sentinel code %#x is not in the catalog", uint32(hr))
+		}
+	}
+}
+
+func TestHRESULT_Fields(t *testing.T) {
+	tests := []struct {
+		hr       HRESULT
+		failed   bool
+		facility Facility
+		code     uint16
+		str      string
+	}{
+		{0x80240024, true, FacilityWindowsUpdate, 0x0024, "WU_E_NO_UPDATE (0x80240024)"},
+		{0x00240005, false, FacilityWindowsUpdate, 0x0005, "WU_S_REBOOT_REQUIRED (0x00240005)"},
+		{0x80070005, true, FacilityWin32, 5, "E_ACCESSDENIED (0x80070005)"},
+		{0x80072EFD, true, FacilityWin32, 12029, "ERROR_INTERNET_CANNOT_CONNECT (0x80072EFD)"},
+		{0x80020009, true, FacilityDispatch, 9, "DISP_E_EXCEPTION (0x80020009)"},
+		{0x8007FFFF, true, FacilityWin32, 0xFFFF, "HRESULT 0x8007FFFF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.str, func(t *testing.T) {
+			if got := tt.hr.Failed(); got != tt.failed {
+				t.Errorf("Failed() = %v, want %v", got, tt.failed)
+			}
+			if got := tt.hr.Facility(); got != tt.facility {
+				t.Errorf("Facility() = %v, want %v", got, tt.facility)
+			}
+			if got := tt.hr.Code(); got != tt.code {
+				t.Errorf("Code() = %d, want %d", got, tt.code)
+			}
+			if got := tt.hr.String(); got != tt.str {
+				t.Errorf("String() = %q, want %q", got, tt.str)
+			}
+		})
+	}
+
+	if got, want := HRESULT(0x80240024).Error(), "windowsupdate: WU_E_NO_UPDATE (0x80240024): There are no updates."; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := Facility(99).String(), "FACILITY_99"; got != want {
+		t.Errorf("Facility(99).String() = %q, want %q", got, want)
+	}
+}
+
+func TestHRESULT_Is(t *testing.T) {
+	tests := []struct {
+		err    error
+		target error
+		want   bool
+	}{
+		{HRESULT(0x80240024), ErrNoUpdate, true},
+		{HRESULT(0x80240016), ErrInstallNotAllowed, true},
+		{HRESULT(0x00240005), ErrRebootRequired, true},
+		{HRESULT(0x80070BC2), ErrRebootRequired, true},
+		{HRESULT(0x80072EE2), ErrNoConnection, true},
+		{HRESULT(0x80240024), ErrRebootRequired, false},
+		{HRESULT(0x80004005), ErrNoUpdate, false},
+		{fmt.Errorf("search: %w", &ComError{HRESULT: 0x80240024, Err: ole.NewError(0x80240024)}), ErrNoUpdate, true},
+		{fmt.Errorf("search: %w", &ComError{HRESULT: 0x80240024, Err: ole.NewError(0x80240024)}), HRESULT(0x80240024), true},
+	}
+	for _, tt := range tests {
+		if got := errors.Is(tt.err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+		}
+	}
+}
+
+// fakeExcepInfo stands in for the ole.EXCEPINFO of a DISP_E_EXCEPTION.
+type fakeExcepInfo uint32
+
+func (e fakeExcepInfo) SCODE() uint32 { return uint32(e) }
+func (e fakeExcepInfo) Error() string { return "exception" }
+
+func TestHResultOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   HRESULT
+		wantOK bool
+	}{
+		{"nil", nil, 0, false},
+		{"plain", errors.New("boom"), 0, false},
+		{"HRESULT", fmt.Errorf("x: %w", HRESULT(0x80240024)), 0x80240024, true},
+		{"OleError", ole.NewError(0x80070005), 0x80070005, true},
+		{"DISP_E_EXCEPTION", ole.NewErrorWithSubError(0x80020009, "", fakeExcepInfo(0x8024402C)), 0x8024402C, true},
+		{"DISP_E_EXCEPTION without scode", ole.NewErrorWithSubError(0x80020009, "", fakeExcepInfo(0)), 0x80020009, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := HResultOf(tt.err)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("HResultOf() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewComError(t *testing.T) {
+	oleErr := ole.NewErrorWithSubError(0x80020009, "", fakeExcepInfo(0x80240016))
+	err := newComError(oleErr)
+	if !errors.Is(err, ErrInstallNotAllowed) {
+		t.Errorf("errors.Is(%v, ErrInstallNotAllowed) = false, want true", err)
+	}
+	var got *ole.OleError
+	if !errors.As(err, &got) || got != oleErr {
+		t.Errorf("errors.As(%v, *ole.OleError) did not return the go-ole error", err)
+	}
+	if newComError(err) != err {
+		t.Error("newComError wrapped a *ComError again")
+	}
+	plain := errors.New("boom")
+	if newComError(plain) != plain || newComError(nil) != nil {
+		t.Error("newComError wrapped an error without HRESULT")
+	}
+}
+
+func TestParseHRESULT(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    HRESULT
+		wantErr bool
+	}{
+		{"WU_E_NO_UPDATE", 0x80240024, false},
+		{"wu_e_no_update", 0x80240024, false},
+		{"0x80240024", 0x80240024, false},
+		{"-2145124316", 0x80240024, false},
+		{"5", 5, false},
+		{"WU_E_BOGUS", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseHRESULT(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseHRESULT(%q) = %v, %v, want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestResultErr(t *testing.T) {
+	if err := (&IInstallationResult{}).Err(); err != nil {
+		t.Errorf("Err() of a zero HResult = %v, want nil", err)
+	}
+	noUpdate := int32(-2145124316) // WU_E_NO_UPDATE
+	errs := []error{
+		(&IDownloadResult{HResult: noUpdate}).Err(),
+		(&IUpdateDownloadResult{HResult: noUpdate}).Err(),
+		(&IInstallationResult{HResult: noUpdate}).Err(),
+		(&IUpdateInstallationResult{HResult: noUpdate}).Err(),
+		(&IUpdateHistoryEntry{HResult: noUpdate}).Err(),
+		(&IUpdateException{HResult: int64(noUpdate)}).Err(),
+		(&IUpdateException{HResult: 0x80240024}).Err(),
+	}
+	for i, err := range errs {
+		if !errors.Is(err, ErrNoUpdate) {
+			t.Errorf("Err() #%d = %v, want ErrNoUpdate", i, err)
+		}
+	}
+}
+
+func TestFromOleVariant_ComError(t *testing.T) {
+	_, err := fromOleVariant(nil, ole.NewError(0x8024001F))
+	if !errors.Is(err, ErrNoConnection) {
+		t.Errorf("fromOleVariant error = %v, want ErrNoConnection", err)
+	}
+}
@@ -15,26 +15,18 @@ package windowsupdate
 
 import (
 	"time"
-
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
 )
 
 // IAutomaticUpdates contains the functionality of Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iautomaticupdates
 type IAutomaticUpdates struct {
-	disp           *ole.IDispatch
+	disp           dispatcher
 	ServiceEnabled bool
 }
 
 // NewAutomaticUpdates creates a new IAutomaticUpdates instance.
 func NewAutomaticUpdates() (*IAutomaticUpdates, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.AutoUpdate")
-	if err != nil {
-		return nil, err
-	}
-
-	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	disp, err := newComObject("Microsoft.Update.AutoUpdate")
 	if err != nil {
 		return nil, err
 	}
@@ -42,13 +34,13 @@ func NewAutomaticUpdates() (*IAutomaticUpdates, error) {
 	return toIAutomaticUpdates(disp)
 }
 
-func toIAutomaticUpdates(autoUpdatesDisp *ole.IDispatch) (*IAutomaticUpdates, error) {
+func toIAutomaticUpdates(autoUpdatesDisp dispatcher) (*IAutomaticUpdates, error) {
 	var err error
 	iAutoUpdates := &IAutomaticUpdates{
 		disp: autoUpdatesDisp,
 	}
 
-	if iAutoUpdates.ServiceEnabled, err = toBoolErr(oleutil.GetProperty(autoUpdatesDisp, "ServiceEnabled")); err != nil {
+	if iAutoUpdates.ServiceEnabled, err = toBoolErr(autoUpdatesDisp.GetProperty("ServiceEnabled")); err != nil {
 		return nil, err
 	}
 
@@ -59,14 +51,14 @@ func toIAutomaticUpdates(autoUpdatesDisp *ole.IDispatch) (*IAutomaticUpdates, er
 // The DetectNow method returns immediately without waiting for the detection to complete.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdates-detectnow
 func (a *IAutomaticUpdates) DetectNow() error {
-	_, err := oleutil.CallMethod(a.disp, "DetectNow")
+	_, err := a.disp.CallMethod("DetectNow")
 	return err
 }
 
 // EnableService enables all the components that Automatic Updates requires.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdates-enableservice
 func (a *IAutomaticUpdates) EnableService() error {
-	_, err := oleutil.CallMethod(a.disp, "EnableService")
+	_, err := a.disp.CallMethod("EnableService")
 	if err != nil {
 		return err
 	}
@@ -77,28 +69,28 @@ func (a *IAutomaticUpdates) EnableService() error {
 // Pause pauses automatic updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdates-pause
 func (a *IAutomaticUpdates) Pause() error {
-	_, err := oleutil.CallMethod(a.disp, "Pause")
+	_, err := a.disp.CallMethod("Pause")
 	return err
 }
 
 // Resume restarts automatic updates if paused.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdates-resume
 func (a *IAutomaticUpdates) Resume() error {
-	_, err := oleutil.CallMethod(a.disp, "Resume")
+	_, err := a.disp.CallMethod("Resume")
 	return err
 }
 
 // ShowSettingsDialog displays a dialog box that contains settings for Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdates-showsettingsdialog
 func (a *IAutomaticUpdates) ShowSettingsDialog() error {
-	_, err := oleutil.CallMethod(a.disp, "ShowSettingsDialog")
+	_, err := a.disp.CallMethod("ShowSettingsDialog")
 	return err
 }
 
 // GetSettings retrieves the configuration settings for Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdates-get_settings
 func (a *IAutomaticUpdates) GetSettings() (*IAutomaticUpdatesSettings, error) {
-	settingsDisp, err := toIDispatchErr(oleutil.GetProperty(a.disp, "Settings"))
+	settingsDisp, err := toIDispatchErr(a.disp.GetProperty("Settings"))
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +100,7 @@ func (a *IAutomaticUpdates) GetSettings() (*IAutomaticUpdatesSettings, error) {
 // GetResults retrieves the results of the last Automatic Updates search. (IAutomaticUpdates2)
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdates2-get_results
 func (a *IAutomaticUpdates) GetResults() (*IAutomaticUpdatesResults, error) {
-	resultsDisp, err := toIDispatchErr(oleutil.GetProperty(a.disp, "Results"))
+	resultsDisp, err := toIDispatchErr(a.disp.GetProperty("Results"))
 	if err != nil {
 		return nil, err
 	}
@@ -118,12 +110,12 @@ func (a *IAutomaticUpdates) GetResults() (*IAutomaticUpdatesResults, error) {
 // IAutomaticUpdatesResults contains the read-only properties that describe Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iautomaticupdatesresults
 type IAutomaticUpdatesResults struct {
-	disp                        *ole.IDispatch
+	disp                        dispatcher
 	LastInstallationSuccessDate *time.Time
 	LastSearchSuccessDate       *time.Time
 }
 
-func toIAutomaticUpdatesResults(disp *ole.IDispatch) (*IAutomaticUpdatesResults, error) {
+func toIAutomaticUpdatesResults(disp dispatcher) (*IAutomaticUpdatesResults, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -131,13 +123,29 @@ func toIAutomaticUpdatesResults(disp *ole.IDispatch) (*IAutomaticUpdatesResults,
 	var err error
 	r := &IAutomaticUpdatesResults{disp: disp}
 
-	if r.LastInstallationSuccessDate, err = toTimeErr(oleutil.GetProperty(disp, "LastInstallationSuccessDate")); err != nil {
+	if r.LastInstallationSuccessDate, err = toTimeErr(disp.GetProperty("LastInstallationSuccessDate")); err != nil {
 		return nil, err
 	}
 
-	if r.LastSearchSuccessDate, err = toTimeErr(oleutil.GetProperty(disp, "LastSearchSuccessDate")); err != nil {
+	if r.LastSearchSuccessDate, err = toTimeErr(disp.GetProperty("LastSearchSuccessDate")); err != nil {
 		return nil, err
 	}
 
 	return r, nil
 }
+
+// Release releases the COM object.
+func (a *IAutomaticUpdates) Release() {
+	if a == nil {
+		return
+	}
+	releaseDispatcher(a.disp)
+}
+
+// Release releases the COM object.
+func (iAutomaticUpdatesResults *IAutomaticUpdatesResults) Release() {
+	if iAutomaticUpdatesResults == nil {
+		return
+	}
+	releaseDispatcher(iAutomaticUpdatesResults.disp)
+}
@@ -13,45 +13,40 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IAutomaticUpdatesSettings contains the settings that are available in Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iautomaticupdatessettings
 type IAutomaticUpdatesSettings struct {
-	disp                      *ole.IDispatch
-	NotificationLevel         int32 // AutomaticUpdatesNotificationLevel enum
+	disp                      dispatcher
+	NotificationLevel         AutomaticUpdatesNotificationLevel
 	ReadOnly                  bool
 	Required                  bool
-	ScheduledInstallationDay  int32 // AutomaticUpdatesScheduledInstallationDay enum (not supported on Windows 8+)
-	ScheduledInstallationTime int32 // Hour of the day (0-23) (not supported on Windows 8+)
+	ScheduledInstallationDay  AutomaticUpdatesScheduledInstallationDay // (not supported on Windows 8+)
+	ScheduledInstallationTime int32                                    // Hour of the day (0-23) (not supported on Windows 8+)
 }
 
-func toIAutomaticUpdatesSettings(settingsDisp *ole.IDispatch) (*IAutomaticUpdatesSettings, error) {
+func toIAutomaticUpdatesSettings(settingsDisp dispatcher) (*IAutomaticUpdatesSettings, error) {
 	var err error
 	iSettings := &IAutomaticUpdatesSettings{
 		disp: settingsDisp,
 	}
 
-	if iSettings.NotificationLevel, err = toInt32Err(oleutil.GetProperty(settingsDisp, "NotificationLevel")); err != nil {
+	if iSettings.NotificationLevel, err = toEnumErr[AutomaticUpdatesNotificationLevel](settingsDisp.GetProperty("NotificationLevel")); err != nil {
 		return nil, err
 	}
 
-	if iSettings.ReadOnly, err = toBoolErr(oleutil.GetProperty(settingsDisp, "ReadOnly")); err != nil {
+	if iSettings.ReadOnly, err = toBoolErr(settingsDisp.GetProperty("ReadOnly")); err != nil {
 		return nil, err
 	}
 
-	if iSettings.Required, err = toBoolErr(oleutil.GetProperty(settingsDisp, "Required")); err != nil {
+	if iSettings.Required, err = toBoolErr(settingsDisp.GetProperty("Required")); err != nil {
 		return nil, err
 	}
 
-	if iSettings.ScheduledInstallationDay, err = toInt32Err(oleutil.GetProperty(settingsDisp, "ScheduledInstallationDay")); err != nil {
+	if iSettings.ScheduledInstallationDay, err = toEnumErr[AutomaticUpdatesScheduledInstallationDay](settingsDisp.GetProperty("ScheduledInstallationDay")); err != nil {
 		return nil, err
 	}
 
-	if iSettings.ScheduledInstallationTime, err = toInt32Err(oleutil.GetProperty(settingsDisp, "ScheduledInstallationTime")); err != nil {
+	if iSettings.ScheduledInstallationTime, err = toInt32Err(settingsDisp.GetProperty("ScheduledInstallationTime")); err != nil {
 		return nil, err
 	}
 
@@ -61,7 +56,7 @@ func toIAutomaticUpdatesSettings(settingsDisp *ole.IDispatch) (*IAutomaticUpdate
 // Refresh reads the latest Automatic Updates settings.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdatessettings-refresh
 func (s *IAutomaticUpdatesSettings) Refresh() error {
-	_, err := oleutil.CallMethod(s.disp, "Refresh")
+	_, err := s.disp.CallMethod("Refresh")
 	if err != nil {
 		return err
 	}
@@ -84,14 +79,14 @@ func (s *IAutomaticUpdatesSettings) Refresh() error {
 // Save applies the current Automatic Updates settings.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdatessettings-save
 func (s *IAutomaticUpdatesSettings) Save() error {
-	_, err := oleutil.CallMethod(s.disp, "Save")
+	_, err := s.disp.CallMethod("Save")
 	return err
 }
 
 // PutNotificationLevel sets the notification level for Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdatessettings-put_notificationlevel
-func (s *IAutomaticUpdatesSettings) PutNotificationLevel(level int32) error {
-	_, err := oleutil.PutProperty(s.disp, "NotificationLevel", level)
+func (s *IAutomaticUpdatesSettings) PutNotificationLevel(level AutomaticUpdatesNotificationLevel) error {
+	_, err := s.disp.PutProperty("NotificationLevel", int32(level))
 	if err != nil {
 		return err
 	}
@@ -102,8 +97,8 @@ func (s *IAutomaticUpdatesSettings) PutNotificationLevel(level int32) error {
 // PutScheduledInstallationDay sets the scheduled installation day.
 // Note: Not supported on Windows 8 and later.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdatessettings-put_scheduledinstallationday
-func (s *IAutomaticUpdatesSettings) PutScheduledInstallationDay(day int32) error {
-	_, err := oleutil.PutProperty(s.disp, "ScheduledInstallationDay", day)
+func (s *IAutomaticUpdatesSettings) PutScheduledInstallationDay(day AutomaticUpdatesScheduledInstallationDay) error {
+	_, err := s.disp.PutProperty("ScheduledInstallationDay", int32(day))
 	if err != nil {
 		return err
 	}
@@ -115,10 +110,18 @@ func (s *IAutomaticUpdatesSettings) PutScheduledInstallationDay(day int32) error
 // Note: Not supported on Windows 8 and later.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iautomaticupdatessettings-put_scheduledinstallationtime
 func (s *IAutomaticUpdatesSettings) PutScheduledInstallationTime(hour int32) error {
-	_, err := oleutil.PutProperty(s.disp, "ScheduledInstallationTime", hour)
+	_, err := s.disp.PutProperty("ScheduledInstallationTime", hour)
 	if err != nil {
 		return err
 	}
 	s.ScheduledInstallationTime = hour
 	return nil
 }
+
+// Release releases the COM object.
+func (s *IAutomaticUpdatesSettings) Release() {
+	if s == nil {
+		return
+	}
+	releaseDispatcher(s.disp)
+}
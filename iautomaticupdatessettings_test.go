@@ -116,7 +116,7 @@ func TestIAutomaticUpdatesSettings_PutNotificationLevel(t *testing.T) {
 	}
 
 	oldLevel := settings.NotificationLevel
-	newLevel := int32(2)
+	newLevel := AutomaticUpdatesNotificationLevelAunlNotifyBeforeDownload
 
 	err = settings.PutNotificationLevel(newLevel)
 	if err != nil {
@@ -152,7 +152,7 @@ func TestIAutomaticUpdatesSettings_PutScheduledInstallationDay(t *testing.T) {
 	}
 
 	oldDay := settings.ScheduledInstallationDay
-	newDay := int32(2)
+	newDay := AutomaticUpdatesScheduledInstallationDayAuisdEveryMonday
 
 	err = settings.PutScheduledInstallationDay(newDay)
 	if err != nil {
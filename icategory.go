@@ -13,15 +13,10 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // ICategory represents the category to which an update belongs.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-icategory
 type ICategory struct {
-	disp        *ole.IDispatch
+	disp        dispatcher
 	CategoryID  string
 	Children    []*ICategory
 	Description string
@@ -33,15 +28,17 @@ type ICategory struct {
 	Updates     []*IUpdate
 }
 
-func toICategories(categoriesDisp *ole.IDispatch) ([]*ICategory, error) {
-	count, err := toInt32Err(oleutil.GetProperty(categoriesDisp, "Count"))
+func toICategories(categoriesDisp dispatcher) ([]*ICategory, error) {
+	defer categoriesDisp.Release()
+
+	count, err := toInt32Err(categoriesDisp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
 
 	categories := make([]*ICategory, 0, count)
 	for i := 0; i < int(count); i++ {
-		categoryDisp, err := toIDispatchErr(oleutil.GetProperty(categoriesDisp, "Item", i))
+		categoryDisp, err := toIDispatchErr(categoriesDisp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
@@ -56,17 +53,17 @@ func toICategories(categoriesDisp *ole.IDispatch) ([]*ICategory, error) {
 	return categories, nil
 }
 
-func toICategory(categoryDisp *ole.IDispatch) (*ICategory, error) {
+func toICategory(categoryDisp dispatcher) (*ICategory, error) {
 	var err error
 	iCategory := &ICategory{
 		disp: categoryDisp,
 	}
 
-	if iCategory.CategoryID, err = toStringErr(oleutil.GetProperty(categoryDisp, "CategoryID")); err != nil {
+	if iCategory.CategoryID, err = toStringErr(categoryDisp.GetProperty("CategoryID")); err != nil {
 		return nil, err
 	}
 
-	childrenDisp, err := toIDispatchErr(oleutil.GetProperty(categoryDisp, "Children"))
+	childrenDisp, err := toIDispatchErr(categoryDisp.GetProperty("Children"))
 	if err != nil {
 		return nil, err
 	}
@@ -76,11 +73,11 @@ func toICategory(categoryDisp *ole.IDispatch) (*ICategory, error) {
 		}
 	}
 
-	if iCategory.Description, err = toStringErr(oleutil.GetProperty(categoryDisp, "Description")); err != nil {
+	if iCategory.Description, err = toStringErr(categoryDisp.GetProperty("Description")); err != nil {
 		return nil, err
 	}
 
-	imageDisp, err := toIDispatchErr(oleutil.GetProperty(categoryDisp, "Image"))
+	imageDisp, err := toIDispatchErr(categoryDisp.GetProperty("Image"))
 	if err != nil {
 		return nil, err
 	}
@@ -90,16 +87,16 @@ func toICategory(categoryDisp *ole.IDispatch) (*ICategory, error) {
 		}
 	}
 
-	if iCategory.Name, err = toStringErr(oleutil.GetProperty(categoryDisp, "Name")); err != nil {
+	if iCategory.Name, err = toStringErr(categoryDisp.GetProperty("Name")); err != nil {
 		return nil, err
 	}
 
-	if iCategory.Order, err = toInt32Err(oleutil.GetProperty(categoryDisp, "Order")); err != nil {
+	if iCategory.Order, err = toInt32Err(categoryDisp.GetProperty("Order")); err != nil {
 		return nil, err
 	}
 
 	/*
-		parentDisp, err := toIDispatchErr(oleutil.GetProperty(categoryDisp, "Parent"))
+		parentDisp, err := toIDispatchErr(categoryDisp.GetProperty("Parent"))
 		if err != nil {
 			return nil, err
 		}
@@ -110,12 +107,12 @@ func toICategory(categoryDisp *ole.IDispatch) (*ICategory, error) {
 		}
 	*/
 
-	if iCategory.Type, err = toStringErr(oleutil.GetProperty(categoryDisp, "Type")); err != nil {
+	if iCategory.Type, err = toStringErr(categoryDisp.GetProperty("Type")); err != nil {
 		return nil, err
 	}
 
 	/*
-		updatesDisp, err := toIDispatchErr(oleutil.GetProperty(categoryDisp, "Updates"))
+		updatesDisp, err := toIDispatchErr(categoryDisp.GetProperty("Updates"))
 		if err != nil {
 			return nil, err
 		}
@@ -128,3 +125,14 @@ func toICategory(categoryDisp *ole.IDispatch) (*ICategory, error) {
 
 	return iCategory, nil
 }
+
+// Release releases the COM object and those of Children, Image and Updates.
+func (iCategory *ICategory) Release() {
+	if iCategory == nil {
+		return
+	}
+	releaseEach(iCategory.Children)
+	iCategory.Image.Release()
+	releaseEach(iCategory.Updates)
+	releaseDispatcher(iCategory.disp)
+}
@@ -13,18 +13,13 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IDownloadProgress represents the progress of an asynchronous download operation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-idownloadprogress
 type IDownloadProgress struct {
-	disp                         *ole.IDispatch
+	disp                         dispatcher
 	CurrentUpdateBytesDownloaded int64
 	CurrentUpdateBytesToDownload int64
-	CurrentUpdateDownloadPhase   int32 // DownloadPhase enum
+	CurrentUpdateDownloadPhase   DownloadPhase
 	CurrentUpdateIndex           int32
 	CurrentUpdatePercentComplete int32
 	PercentComplete              int32
@@ -32,7 +27,7 @@ type IDownloadProgress struct {
 	TotalBytesToDownload         int64
 }
 
-func toIDownloadProgress(disp *ole.IDispatch) (*IDownloadProgress, error) {
+func toIDownloadProgress(disp dispatcher) (*IDownloadProgress, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -40,35 +35,35 @@ func toIDownloadProgress(disp *ole.IDispatch) (*IDownloadProgress, error) {
 	var err error
 	p := &IDownloadProgress{disp: disp}
 
-	if p.CurrentUpdateBytesDownloaded, err = toInt64Err(oleutil.GetProperty(disp, "CurrentUpdateBytesDownloaded")); err != nil {
+	if p.CurrentUpdateBytesDownloaded, err = toInt64Err(disp.GetProperty("CurrentUpdateBytesDownloaded")); err != nil {
 		return nil, err
 	}
 
-	if p.CurrentUpdateBytesToDownload, err = toInt64Err(oleutil.GetProperty(disp, "CurrentUpdateBytesToDownload")); err != nil {
+	if p.CurrentUpdateBytesToDownload, err = toInt64Err(disp.GetProperty("CurrentUpdateBytesToDownload")); err != nil {
 		return nil, err
 	}
 
-	if p.CurrentUpdateDownloadPhase, err = toInt32Err(oleutil.GetProperty(disp, "CurrentUpdateDownloadPhase")); err != nil {
+	if p.CurrentUpdateDownloadPhase, err = toEnumErr[DownloadPhase](disp.GetProperty("CurrentUpdateDownloadPhase")); err != nil {
 		return nil, err
 	}
 
-	if p.CurrentUpdateIndex, err = toInt32Err(oleutil.GetProperty(disp, "CurrentUpdateIndex")); err != nil {
+	if p.CurrentUpdateIndex, err = toInt32Err(disp.GetProperty("CurrentUpdateIndex")); err != nil {
 		return nil, err
 	}
 
-	if p.CurrentUpdatePercentComplete, err = toInt32Err(oleutil.GetProperty(disp, "CurrentUpdatePercentComplete")); err != nil {
+	if p.CurrentUpdatePercentComplete, err = toInt32Err(disp.GetProperty("CurrentUpdatePercentComplete")); err != nil {
 		return nil, err
 	}
 
-	if p.PercentComplete, err = toInt32Err(oleutil.GetProperty(disp, "PercentComplete")); err != nil {
+	if p.PercentComplete, err = toInt32Err(disp.GetProperty("PercentComplete")); err != nil {
 		return nil, err
 	}
 
-	if p.TotalBytesDownloaded, err = toInt64Err(oleutil.GetProperty(disp, "TotalBytesDownloaded")); err != nil {
+	if p.TotalBytesDownloaded, err = toInt64Err(disp.GetProperty("TotalBytesDownloaded")); err != nil {
 		return nil, err
 	}
 
-	if p.TotalBytesToDownload, err = toInt64Err(oleutil.GetProperty(disp, "TotalBytesToDownload")); err != nil {
+	if p.TotalBytesToDownload, err = toInt64Err(disp.GetProperty("TotalBytesToDownload")); err != nil {
 		return nil, err
 	}
 
@@ -78,9 +73,34 @@ func toIDownloadProgress(disp *ole.IDispatch) (*IDownloadProgress, error) {
 // GetUpdateResult returns the result of the download for a specified update.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-idownloadprogress-getupdateresult
 func (p *IDownloadProgress) GetUpdateResult(updateIndex int32) (*IUpdateDownloadResult, error) {
-	resultDisp, err := toIDispatchErr(oleutil.CallMethod(p.disp, "GetUpdateResult", updateIndex))
+	resultDisp, err := toIDispatchErr(p.disp.CallMethod("GetUpdateResult", updateIndex))
 	if err != nil {
 		return nil, err
 	}
 	return toIUpdateDownloadResult(resultDisp)
 }
+
+// toProgress returns the Progress snapshot reported for p.
+func (p *IDownloadProgress) toProgress() Progress {
+	if p == nil {
+		return Progress{}
+	}
+	return Progress{
+		PercentComplete:              p.PercentComplete,
+		CurrentUpdateIndex:           p.CurrentUpdateIndex,
+		CurrentUpdatePercentComplete: p.CurrentUpdatePercentComplete,
+		CurrentUpdateBytesDownloaded: p.CurrentUpdateBytesDownloaded,
+		CurrentUpdateBytesToDownload: p.CurrentUpdateBytesToDownload,
+		TotalBytesDownloaded:         p.TotalBytesDownloaded,
+		TotalBytesToDownload:         p.TotalBytesToDownload,
+		DownloadPhase:                p.CurrentUpdateDownloadPhase,
+	}
+}
+
+// Release releases the COM object.
+func (p *IDownloadProgress) Release() {
+	if p == nil {
+		return
+	}
+	releaseDispatcher(p.disp)
+}
@@ -13,30 +13,28 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IDownloadResult represents the result of a download operation.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-idownloadresult
 type IDownloadResult struct {
-	disp       *ole.IDispatch
+	disp       dispatcher
 	HResult    int32
-	ResultCode int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-operationresultcode
+	ResultCode OperationResultCode
+
+	// outcomes are the per-update results of NewDownloadResult.
+	outcomes UpdateOutcomes
 }
 
-func toIDownloadResult(downloadResultDisp *ole.IDispatch) (*IDownloadResult, error) {
+func toIDownloadResult(downloadResultDisp dispatcher) (*IDownloadResult, error) {
 	var err error
 	iDownloadResult := &IDownloadResult{
 		disp: downloadResultDisp,
 	}
 
-	if iDownloadResult.HResult, err = toInt32Err(oleutil.GetProperty(downloadResultDisp, "HResult")); err != nil {
+	if iDownloadResult.HResult, err = toHResultErr(downloadResultDisp.GetProperty("HResult")); err != nil {
 		return nil, err
 	}
 
-	if iDownloadResult.ResultCode, err = toInt32Err(oleutil.GetProperty(downloadResultDisp, "ResultCode")); err != nil {
+	if iDownloadResult.ResultCode, err = toEnumErr[OperationResultCode](downloadResultDisp.GetProperty("ResultCode")); err != nil {
 		return nil, err
 	}
 
@@ -46,21 +44,30 @@ func toIDownloadResult(downloadResultDisp *ole.IDispatch) (*IDownloadResult, err
 // GetUpdateResult returns an IUpdateDownloadResult interface that contains the download information for a specified update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-idownloadresult-getupdateresult
 func (iDownloadResult *IDownloadResult) GetUpdateResult(updateIndex int32) (*IUpdateDownloadResult, error) {
-	var err error
-	iUpdateDownloadResult := &IUpdateDownloadResult{
-		disp: iDownloadResult.disp,
+	if iDownloadResult.disp == nil {
+		outcome, err := storedOutcome(iDownloadResult.outcomes, updateIndex)
+		if err != nil {
+			return nil, err
+		}
+		return &IUpdateDownloadResult{HResult: outcome.HResult, ResultCode: outcome.ResultCode}, nil
 	}
-	updatesDisp, err := toIDispatchErr(oleutil.CallMethod(iDownloadResult.disp, "GetUpdateResult", updateIndex))
+	updateResultDisp, err := toIDispatchErr(iDownloadResult.disp.CallMethod("GetUpdateResult", updateIndex))
 	if err != nil {
 		return nil, err
 	}
+	return toIUpdateDownloadResult(updateResultDisp)
+}
 
-	if iUpdateDownloadResult.HResult, err = toInt32Err(oleutil.GetProperty(updatesDisp, "HResult")); err != nil {
-		return nil, err
-	}
+// Err returns the HResult of the download as an HRESULT error, or nil if it is
+// zero.
+func (iDownloadResult *IDownloadResult) Err() error {
+	return HResultError(iDownloadResult.HResult)
+}
 
-	if iUpdateDownloadResult.ResultCode, err = toInt32Err(oleutil.GetProperty(updatesDisp, "ResultCode")); err != nil {
-		return nil, err
+// Release releases the COM object.
+func (iDownloadResult *IDownloadResult) Release() {
+	if iDownloadResult == nil {
+		return
 	}
-	return iUpdateDownloadResult, nil
+	releaseDispatcher(iDownloadResult.disp)
 }
@@ -43,7 +43,7 @@ func TestIDownloadResult_HResult(t *testing.T) {
 func TestIDownloadResult_ResultCodes(t *testing.T) {
 	testCases := []struct {
 		name       string
-		resultCode int32
+		resultCode OperationResultCode
 	}{
 		{"Succeeded", OperationResultCodeOrcSucceeded},
 		{"InProgress", OperationResultCodeOrcInProgress},
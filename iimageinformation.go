@@ -13,42 +13,45 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IImageInformation contains information about a localized image that is associated with an update or a category.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iimageinformation
 type IImageInformation struct {
-	disp    *ole.IDispatch
+	disp    dispatcher
 	AltText string
 	Height  int64
 	Source  string
 	Width   int64
 }
 
-func toIImageInformation(imageInformationDisp *ole.IDispatch) (*IImageInformation, error) {
+func toIImageInformation(imageInformationDisp dispatcher) (*IImageInformation, error) {
 	var err error
 	iImageInformation := &IImageInformation{
 		disp: imageInformationDisp,
 	}
 
-	if iImageInformation.AltText, err = toStringErr(oleutil.GetProperty(imageInformationDisp, "AltText")); err != nil {
+	if iImageInformation.AltText, err = toStringErr(imageInformationDisp.GetProperty("AltText")); err != nil {
 		return nil, err
 	}
 
-	if iImageInformation.Height, err = toInt64Err(oleutil.GetProperty(imageInformationDisp, "Height")); err != nil {
+	if iImageInformation.Height, err = toInt64Err(imageInformationDisp.GetProperty("Height")); err != nil {
 		return nil, err
 	}
 
-	if iImageInformation.Source, err = toStringErr(oleutil.GetProperty(imageInformationDisp, "Source")); err != nil {
+	if iImageInformation.Source, err = toStringErr(imageInformationDisp.GetProperty("Source")); err != nil {
 		return nil, err
 	}
 
-	if iImageInformation.Width, err = toInt64Err(oleutil.GetProperty(imageInformationDisp, "Width")); err != nil {
+	if iImageInformation.Width, err = toInt64Err(imageInformationDisp.GetProperty("Width")); err != nil {
 		return nil, err
 	}
 
 	return iImageInformation, nil
 }
+
+// Release releases the COM object.
+func (iImageInformation *IImageInformation) Release() {
+	if iImageInformation == nil {
+		return
+	}
+	releaseDispatcher(iImageInformation.disp)
+}
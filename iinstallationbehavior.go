@@ -13,42 +13,45 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IInstallationBehavior represents the installation and uninstallation options of an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iinstallationbehavior
 type IInstallationBehavior struct {
-	disp                        *ole.IDispatch
+	disp                        dispatcher
 	CanRequestUserInput         bool
-	Impact                      int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-installationimpact
-	RebootBehavior              int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-installationrebootbehavior
+	Impact                      InstallationImpact
+	RebootBehavior              InstallationRebootBehavior
 	RequiresNetworkConnectivity bool
 }
 
-func toIInstallationBehavior(installationBehaviorDisp *ole.IDispatch) (*IInstallationBehavior, error) {
+func toIInstallationBehavior(installationBehaviorDisp dispatcher) (*IInstallationBehavior, error) {
 	var err error
 	iInstallationBehavior := &IInstallationBehavior{
 		disp: installationBehaviorDisp,
 	}
 
-	if iInstallationBehavior.CanRequestUserInput, err = toBoolErr(oleutil.GetProperty(installationBehaviorDisp, "CanRequestUserInput")); err != nil {
+	if iInstallationBehavior.CanRequestUserInput, err = toBoolErr(installationBehaviorDisp.GetProperty("CanRequestUserInput")); err != nil {
 		return nil, err
 	}
 
-	if iInstallationBehavior.Impact, err = toInt32Err(oleutil.GetProperty(installationBehaviorDisp, "Impact")); err != nil {
+	if iInstallationBehavior.Impact, err = toEnumErr[InstallationImpact](installationBehaviorDisp.GetProperty("Impact")); err != nil {
 		return nil, err
 	}
 
-	if iInstallationBehavior.RebootBehavior, err = toInt32Err(oleutil.GetProperty(installationBehaviorDisp, "RebootBehavior")); err != nil {
+	if iInstallationBehavior.RebootBehavior, err = toEnumErr[InstallationRebootBehavior](installationBehaviorDisp.GetProperty("RebootBehavior")); err != nil {
 		return nil, err
 	}
 
-	if iInstallationBehavior.RequiresNetworkConnectivity, err = toBoolErr(oleutil.GetProperty(installationBehaviorDisp, "RequiresNetworkConnectivity")); err != nil {
+	if iInstallationBehavior.RequiresNetworkConnectivity, err = toBoolErr(installationBehaviorDisp.GetProperty("RequiresNetworkConnectivity")); err != nil {
 		return nil, err
 	}
 
 	return iInstallationBehavior, nil
 }
+
+// Release releases the COM object.
+func (iInstallationBehavior *IInstallationBehavior) Release() {
+	if iInstallationBehavior == nil {
+		return
+	}
+	releaseDispatcher(iInstallationBehavior.disp)
+}
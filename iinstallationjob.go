@@ -13,21 +13,19 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IInstallationJob contains properties and methods that are available to an installation operation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iinstallationjob
 type IInstallationJob struct {
-	disp        *ole.IDispatch
+	disp        dispatcher
 	AsyncState  interface{}
 	IsCompleted bool
 	Updates     []*IUpdate
+
+	// releaseCallbacks unregisters the callbacks passed to BeginXxx.
+	releaseCallbacks func()
 }
 
-func toIInstallationJob(disp *ole.IDispatch) (*IInstallationJob, error) {
+func toIInstallationJob(disp dispatcher) (*IInstallationJob, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -35,11 +33,11 @@ func toIInstallationJob(disp *ole.IDispatch) (*IInstallationJob, error) {
 	var err error
 	j := &IInstallationJob{disp: disp}
 
-	if j.IsCompleted, err = toBoolErr(oleutil.GetProperty(disp, "IsCompleted")); err != nil {
+	if j.IsCompleted, err = toBoolErr(disp.GetProperty("IsCompleted")); err != nil {
 		return nil, err
 	}
 
-	updatesDisp, err := toIDispatchErr(oleutil.GetProperty(disp, "Updates"))
+	updatesDisp, err := toIDispatchErr(disp.GetProperty("Updates"))
 	if err != nil {
 		return nil, err
 	}
@@ -55,14 +53,14 @@ func toIInstallationJob(disp *ole.IDispatch) (*IInstallationJob, error) {
 // CleanUp releases the resources held by the installation job.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iinstallationjob-cleanup
 func (j *IInstallationJob) CleanUp() error {
-	_, err := oleutil.CallMethod(j.disp, "CleanUp")
+	_, err := j.disp.CallMethod("CleanUp")
 	return err
 }
 
 // RequestAbort requests that the installation job be canceled.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iinstallationjob-requestabort
 func (j *IInstallationJob) RequestAbort() error {
-	_, err := oleutil.CallMethod(j.disp, "RequestAbort")
+	_, err := j.disp.CallMethod("RequestAbort")
 	return err
 }
 
@@ -72,15 +70,39 @@ func (j *IInstallationJob) RequestAbort() error {
 // authoritative completion signal for the async installation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iinstallationjob-get_iscompleted
 func (j *IInstallationJob) GetIsCompleted() (bool, error) {
-	return toBoolErr(oleutil.GetProperty(j.disp, "IsCompleted"))
+	return toBoolErr(j.disp.GetProperty("IsCompleted"))
 }
 
 // GetProgress returns the current progress of the installation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iinstallationjob-getprogress
 func (j *IInstallationJob) GetProgress() (*IInstallationProgress, error) {
-	progressDisp, err := toIDispatchErr(oleutil.CallMethod(j.disp, "GetProgress"))
+	progressDisp, err := toIDispatchErr(j.disp.CallMethod("GetProgress"))
 	if err != nil {
 		return nil, err
 	}
 	return toIInstallationProgress(progressDisp)
 }
+
+// progress reads GetProgress as a Progress and releases the progress object.
+func (j *IInstallationJob) progress() (Progress, error) {
+	p, err := j.GetProgress()
+	if err != nil {
+		return Progress{}, err
+	}
+	defer p.Release()
+	return p.toProgress(), nil
+}
+
+// Release releases the COM object and those of Updates, and unregisters the
+// callbacks passed to BeginXxx.
+func (j *IInstallationJob) Release() {
+	if j == nil {
+		return
+	}
+	if j.releaseCallbacks != nil {
+		j.releaseCallbacks()
+		j.releaseCallbacks = nil
+	}
+	releaseEach(j.Updates)
+	releaseDispatcher(j.disp)
+}
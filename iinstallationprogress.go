@@ -13,21 +13,16 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IInstallationProgress represents the progress of an asynchronous installation operation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iinstallationprogress
 type IInstallationProgress struct {
-	disp                         *ole.IDispatch
+	disp                         dispatcher
 	CurrentUpdateIndex           int32
 	CurrentUpdatePercentComplete int32
 	PercentComplete              int32
 }
 
-func toIInstallationProgress(disp *ole.IDispatch) (*IInstallationProgress, error) {
+func toIInstallationProgress(disp dispatcher) (*IInstallationProgress, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -35,15 +30,15 @@ func toIInstallationProgress(disp *ole.IDispatch) (*IInstallationProgress, error
 	var err error
 	p := &IInstallationProgress{disp: disp}
 
-	if p.CurrentUpdateIndex, err = toInt32Err(oleutil.GetProperty(disp, "CurrentUpdateIndex")); err != nil {
+	if p.CurrentUpdateIndex, err = toInt32Err(disp.GetProperty("CurrentUpdateIndex")); err != nil {
 		return nil, err
 	}
 
-	if p.CurrentUpdatePercentComplete, err = toInt32Err(oleutil.GetProperty(disp, "CurrentUpdatePercentComplete")); err != nil {
+	if p.CurrentUpdatePercentComplete, err = toInt32Err(disp.GetProperty("CurrentUpdatePercentComplete")); err != nil {
 		return nil, err
 	}
 
-	if p.PercentComplete, err = toInt32Err(oleutil.GetProperty(disp, "PercentComplete")); err != nil {
+	if p.PercentComplete, err = toInt32Err(disp.GetProperty("PercentComplete")); err != nil {
 		return nil, err
 	}
 
@@ -53,9 +48,29 @@ func toIInstallationProgress(disp *ole.IDispatch) (*IInstallationProgress, error
 // GetUpdateResult returns the result of the installation for a specified update.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iinstallationprogress-getupdateresult
 func (p *IInstallationProgress) GetUpdateResult(updateIndex int32) (*IUpdateInstallationResult, error) {
-	resultDisp, err := toIDispatchErr(oleutil.CallMethod(p.disp, "GetUpdateResult", updateIndex))
+	resultDisp, err := toIDispatchErr(p.disp.CallMethod("GetUpdateResult", updateIndex))
 	if err != nil {
 		return nil, err
 	}
 	return toIUpdateInstallationResult(resultDisp)
 }
+
+// toProgress returns the Progress snapshot reported for p.
+func (p *IInstallationProgress) toProgress() Progress {
+	if p == nil {
+		return Progress{}
+	}
+	return Progress{
+		PercentComplete:              p.PercentComplete,
+		CurrentUpdateIndex:           p.CurrentUpdateIndex,
+		CurrentUpdatePercentComplete: p.CurrentUpdatePercentComplete,
+	}
+}
+
+// Release releases the COM object.
+func (p *IInstallationProgress) Release() {
+	if p == nil {
+		return
+	}
+	releaseDispatcher(p.disp)
+}
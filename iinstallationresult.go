@@ -13,59 +13,83 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IInstallationResult represents the result of an installation or uninstallation.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iinstallationresult
 type IInstallationResult struct {
-	disp           *ole.IDispatch
+	disp           dispatcher
 	HResult        int32
 	RebootRequired bool
-	ResultCode     int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-operationresultcode
+	ResultCode     OperationResultCode
+
+	// outcomes are the per-update results of NewInstallationResult.
+	outcomes UpdateOutcomes
 }
 
-func toIInstallationResult(installationResultDisp *ole.IDispatch) (*IInstallationResult, error) {
+func toIInstallationResult(installationResultDisp dispatcher) (*IInstallationResult, error) {
 	var err error
 	iInstallationResult := &IInstallationResult{
 		disp: installationResultDisp,
 	}
 
-	if iInstallationResult.HResult, err = toInt32Err(oleutil.GetProperty(installationResultDisp, "HResult")); err != nil {
+	if iInstallationResult.HResult, err = toHResultErr(installationResultDisp.GetProperty("HResult")); err != nil {
 		return nil, err
 	}
 
-	if iInstallationResult.RebootRequired, err = toBoolErr(oleutil.GetProperty(installationResultDisp, "RebootRequired")); err != nil {
+	if iInstallationResult.RebootRequired, err = toBoolErr(installationResultDisp.GetProperty("RebootRequired")); err != nil {
 		return nil, err
 	}
 
-	if iInstallationResult.ResultCode, err = toInt32Err(oleutil.GetProperty(installationResultDisp, "ResultCode")); err != nil {
+	if iInstallationResult.ResultCode, err = toEnumErr[OperationResultCode](installationResultDisp.GetProperty("ResultCode")); err != nil {
 		return nil, err
 	}
 
 	return iInstallationResult, nil
 }
 
-// GetUpdateResult returns an IInstallationResult interface that contains the installation information for a specified update.
-// https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iinstallationresult
-func (iInstallationResult *IInstallationResult) GetUpdateResult(updateIndex int32) (*IInstallationResult, error) {
-	var err error
-	iUpdateInstallationResult := &IInstallationResult{
-		disp: iInstallationResult.disp,
+// GetUpdateInstallationResult returns an IUpdateInstallationResult interface that contains the installation information for a specified update.
+// https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iinstallationresult-getupdateresult
+func (iInstallationResult *IInstallationResult) GetUpdateInstallationResult(updateIndex int32) (*IUpdateInstallationResult, error) {
+	if iInstallationResult.disp == nil {
+		outcome, err := storedOutcome(iInstallationResult.outcomes, updateIndex)
+		if err != nil {
+			return nil, err
+		}
+		return &IUpdateInstallationResult{HResult: outcome.HResult, RebootRequired: outcome.RebootRequired, ResultCode: outcome.ResultCode}, nil
 	}
-	updatesDisp, err := toIDispatchErr(oleutil.CallMethod(iInstallationResult.disp, "GetUpdateResult", updateIndex))
+	updateResultDisp, err := toIDispatchErr(iInstallationResult.disp.CallMethod("GetUpdateResult", updateIndex))
 	if err != nil {
 		return nil, err
 	}
+	return toIUpdateInstallationResult(updateResultDisp)
+}
 
-	if iUpdateInstallationResult.HResult, err = toInt32Err(oleutil.GetProperty(updatesDisp, "HResult")); err != nil {
+// GetUpdateResult returns the installation information for a specified update.
+//
+// Deprecated: GetUpdateResult returns an IInstallationResult rather than the
+// IUpdateInstallationResult WUA returns; use GetUpdateInstallationResult.
+func (iInstallationResult *IInstallationResult) GetUpdateResult(updateIndex int32) (*IInstallationResult, error) {
+	r, err := iInstallationResult.GetUpdateInstallationResult(updateIndex)
+	if err != nil {
 		return nil, err
 	}
+	return &IInstallationResult{
+		disp:           r.disp,
+		HResult:        r.HResult,
+		RebootRequired: r.RebootRequired,
+		ResultCode:     r.ResultCode,
+	}, nil
+}
 
-	if iUpdateInstallationResult.ResultCode, err = toInt32Err(oleutil.GetProperty(updatesDisp, "ResultCode")); err != nil {
-		return nil, err
+// Err returns the HResult of the installation or uninstallation as an HRESULT
+// error, or nil if it is zero.
+func (iInstallationResult *IInstallationResult) Err() error {
+	return HResultError(iInstallationResult.HResult)
+}
+
+// Release releases the COM object.
+func (iInstallationResult *IInstallationResult) Release() {
+	if iInstallationResult == nil {
+		return
 	}
-	return iUpdateInstallationResult, nil
+	releaseDispatcher(iInstallationResult.disp)
 }
@@ -48,7 +48,7 @@ func TestIInstallationResult_ErrorScenarios(t *testing.T) {
 	testCases := []struct {
 		name           string
 		hresult        int32
-		resultCode     int32
+		resultCode     OperationResultCode
 		rebootRequired bool
 	}{
 		{"Success_NoReboot", 0, OperationResultCodeOrcSucceeded, false},
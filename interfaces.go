@@ -0,0 +1,76 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+
+	"github.com/ceshihao/windowsupdate/criteria"
+)
+
+// Session is the behaviour of an IUpdateSession that orchestration code depends on.
+// It is satisfied by *IUpdateSession and by the in-memory backend in package wufake.
+type Session interface {
+	CreateSearcher() (Searcher, error)
+	CreateDownloader() (Downloader, error)
+	CreateInstaller() (Installer, error)
+	Release()
+}
+
+// Searcher is the method set of IUpdateSearcher.
+type Searcher interface {
+	Search(criteria string) (*ISearchResult, error)
+	SearchStream(criteria string) (*ISearchResult, error)
+	SearchCriteria(expr criteria.Expr) (*ISearchResult, error)
+	SearchContext(ctx context.Context, criteria string, options ...JobOption) (*ISearchResult, error)
+	SearchCriteriaContext(ctx context.Context, expr criteria.Expr, options ...JobOption) (*ISearchResult, error)
+	BeginSearch(criteria string, options ...JobOption) (*ISearchJob, error)
+	BeginSearchCriteria(expr criteria.Expr, options ...JobOption) (*ISearchJob, error)
+	EndSearch(searchJob *ISearchJob) (*ISearchResult, error)
+	QueryHistory(startIndex int32, count int32) ([]*IUpdateHistoryEntry, error)
+	QueryHistoryAll() ([]*IUpdateHistoryEntry, error)
+	QueryHistoryContext(ctx context.Context, startIndex int32, count int32) ([]*IUpdateHistoryEntry, error)
+	GetTotalHistoryCount() (int32, error)
+	EscapeString(unescaped string) (string, error)
+	Release()
+}
+
+// Downloader is the method set of IUpdateDownloader.
+type Downloader interface {
+	Download(updates []*IUpdate) (*IDownloadResult, error)
+	DownloadContext(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IDownloadResult, error)
+	BeginDownload(updates []*IUpdate, options ...JobOption) (*IDownloadJob, error)
+	EndDownload(downloadJob *IDownloadJob) (*IDownloadResult, error)
+	Release()
+}
+
+// Installer is the method set of IUpdateInstaller.
+type Installer interface {
+	Install(updates []*IUpdate) (*IInstallationResult, error)
+	InstallContext(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IInstallationResult, error)
+	BeginInstall(updates []*IUpdate, options ...JobOption) (*IInstallationJob, error)
+	EndInstall(installationJob *IInstallationJob) (*IInstallationResult, error)
+	Uninstall(updates []*IUpdate) (*IInstallationResult, error)
+	UninstallContext(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IInstallationResult, error)
+	BeginUninstall(updates []*IUpdate, options ...JobOption) (*IInstallationJob, error)
+	EndUninstall(installationJob *IInstallationJob) (*IInstallationResult, error)
+	Release()
+}
+
+var (
+	_ Session    = (*IUpdateSession)(nil)
+	_ Searcher   = (*IUpdateSearcher)(nil)
+	_ Downloader = (*IUpdateDownloader)(nil)
+	_ Installer  = (*IUpdateInstaller)(nil)
+)
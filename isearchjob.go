@@ -13,20 +13,21 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // ISearchJob contains properties and methods that are available to a search operation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-isearchjob
 type ISearchJob struct {
-	disp        *ole.IDispatch
+	disp        dispatcher
 	AsyncState  interface{}
 	IsCompleted bool
+
+	// releaseCallbacks unregisters the callbacks passed to BeginXxx.
+	releaseCallbacks func()
+	// stream makes EndSearch return a streamed result, as set by
+	// WithStreamedUpdates.
+	stream bool
 }
 
-func toISearchJob(disp *ole.IDispatch) (*ISearchJob, error) {
+func toISearchJob(disp dispatcher) (*ISearchJob, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -34,7 +35,7 @@ func toISearchJob(disp *ole.IDispatch) (*ISearchJob, error) {
 	var err error
 	j := &ISearchJob{disp: disp}
 
-	if j.IsCompleted, err = toBoolErr(oleutil.GetProperty(disp, "IsCompleted")); err != nil {
+	if j.IsCompleted, err = toBoolErr(disp.GetProperty("IsCompleted")); err != nil {
 		return nil, err
 	}
 
@@ -44,13 +45,33 @@ func toISearchJob(disp *ole.IDispatch) (*ISearchJob, error) {
 // CleanUp releases the resources held by the search job.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-isearchjob-cleanup
 func (j *ISearchJob) CleanUp() error {
-	_, err := oleutil.CallMethod(j.disp, "CleanUp")
+	_, err := j.disp.CallMethod("CleanUp")
 	return err
 }
 
 // RequestAbort requests that the search job be canceled.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-isearchjob-requestabort
 func (j *ISearchJob) RequestAbort() error {
-	_, err := oleutil.CallMethod(j.disp, "RequestAbort")
+	_, err := j.disp.CallMethod("RequestAbort")
 	return err
 }
+
+// GetIsCompleted reads the live IsCompleted property of the job, unlike the
+// IsCompleted field captured when the job was started.
+// https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-isearchjob-get_iscompleted
+func (j *ISearchJob) GetIsCompleted() (bool, error) {
+	return toBoolErr(j.disp.GetProperty("IsCompleted"))
+}
+
+// Release releases the COM object and unregisters the callbacks passed to
+// BeginSearch.
+func (j *ISearchJob) Release() {
+	if j == nil {
+		return
+	}
+	if j.releaseCallbacks != nil {
+		j.releaseCallbacks()
+		j.releaseCallbacks = nil
+	}
+	releaseDispatcher(j.disp)
+}
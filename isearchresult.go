@@ -13,32 +13,60 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
+import "iter"
 
 // ISearchResult represents the result of a search.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-isearchresult
 type ISearchResult struct {
-	disp           *ole.IDispatch
-	ResultCode     int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-operationresultcode
+	disp           dispatcher
+	ResultCode     OperationResultCode
 	RootCategories []*ICategory
 	Updates        []*IUpdate
 	Warnings       []*IUpdateException
+
+	// updatesDisp is the Updates collection of a streamed result, whose
+	// Updates are converted by All instead.
+	updatesDisp  dispatcher
+	updateFields UpdateFields
+}
+
+func toISearchResult(searchResultDisp dispatcher) (*ISearchResult, error) {
+	return toISearchResultFields(searchResultDisp, FullUpdateFields)
 }
 
-func toISearchResult(searchResultDisp *ole.IDispatch) (*ISearchResult, error) {
+// toISearchResultFields converts a search result, reading only fields of each update.
+func toISearchResultFields(searchResultDisp dispatcher, fields UpdateFields) (*ISearchResult, error) {
+	return newISearchResult(searchResultDisp, fields, false)
+}
+
+// toISearchResultStream converts a search result but leaves Updates nil, for
+// All to convert them one at a time.
+func toISearchResultStream(searchResultDisp dispatcher, fields UpdateFields) (*ISearchResult, error) {
+	return newISearchResult(searchResultDisp, fields, true)
+}
+
+func newISearchResult(searchResultDisp dispatcher, fields UpdateFields, stream bool) (*ISearchResult, error) {
+	iSearchResult, err := readISearchResult(searchResultDisp, fields, stream)
+	if err != nil {
+		// Release the result with the objects read before the failure.
+		releaseTree(searchResultDisp)
+		return nil, err
+	}
+	return iSearchResult, nil
+}
+
+func readISearchResult(searchResultDisp dispatcher, fields UpdateFields, stream bool) (*ISearchResult, error) {
 	var err error
 	iSearchResult := &ISearchResult{
-		disp: searchResultDisp,
+		disp:         searchResultDisp,
+		updateFields: fields,
 	}
 
-	if iSearchResult.ResultCode, err = toInt32Err(oleutil.GetProperty(searchResultDisp, "ResultCode")); err != nil {
+	if iSearchResult.ResultCode, err = toEnumErr[OperationResultCode](searchResultDisp.GetProperty("ResultCode")); err != nil {
 		return nil, err
 	}
 
-	rootCategoriesDisp, err := toIDispatchErr(oleutil.GetProperty(searchResultDisp, "RootCategories"))
+	rootCategoriesDisp, err := toIDispatchErr(searchResultDisp.GetProperty("RootCategories"))
 	if err != nil {
 		return nil, err
 	}
@@ -48,17 +76,19 @@ func toISearchResult(searchResultDisp *ole.IDispatch) (*ISearchResult, error) {
 		}
 	}
 
-	updatesDisp, err := toIDispatchErr(oleutil.GetProperty(searchResultDisp, "Updates"))
+	updatesDisp, err := toIDispatchErr(searchResultDisp.GetProperty("Updates"))
 	if err != nil {
 		return nil, err
 	}
-	if updatesDisp != nil {
-		if iSearchResult.Updates, err = toIUpdates(updatesDisp); err != nil {
+	if updatesDisp != nil && stream {
+		iSearchResult.updatesDisp = updatesDisp
+	} else if updatesDisp != nil {
+		if iSearchResult.Updates, err = toIUpdatesFields(updatesDisp, fields); err != nil {
 			return nil, err
 		}
 	}
 
-	warningsDisp, err := toIDispatchErr(oleutil.GetProperty(searchResultDisp, "Warnings"))
+	warningsDisp, err := toIDispatchErr(searchResultDisp.GetProperty("Warnings"))
 	if err != nil {
 		return nil, err
 	}
@@ -70,3 +100,46 @@ func toISearchResult(searchResultDisp *ole.IDispatch) (*ISearchResult, error) {
 
 	return iSearchResult, nil
 }
+
+// All returns an iterator over the updates found. For a result returned by
+// IUpdateSearcher.SearchStream or with WithStreamedUpdates, each update is
+// converted only when the loop reaches it, and breaking out of the loop skips
+// the remaining conversions. A conversion failure is yielded with a nil update
+// and ends the iteration.
+//
+// The updates yielded are not owned by the result: each one holds its COM
+// objects until its Release or the Close of the session. Memory only stays
+// bounded if the loop releases the updates it does not keep:
+//
+//	for update, err := range result.All() {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(update.Title)
+//		update.Release()
+//	}
+func (iSearchResult *ISearchResult) All() iter.Seq2[*IUpdate, error] {
+	if iSearchResult.updatesDisp != nil {
+		return seqIUpdates(iSearchResult.updatesDisp, iSearchResult.updateFields)
+	}
+	return func(yield func(*IUpdate, error) bool) {
+		for _, update := range iSearchResult.Updates {
+			if !yield(update, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Release releases the COM object and those of RootCategories, Updates and
+// Warnings.
+func (iSearchResult *ISearchResult) Release() {
+	if iSearchResult == nil {
+		return
+	}
+	releaseEach(iSearchResult.RootCategories)
+	releaseEach(iSearchResult.Updates)
+	releaseEach(iSearchResult.Warnings)
+	releaseDispatcher(iSearchResult.updatesDisp)
+	releaseDispatcher(iSearchResult.disp)
+}
@@ -13,27 +13,17 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IStringCollection represents a collection of strings.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-istringcollection
 type IStringCollection struct {
-	disp     *ole.IDispatch
+	disp     dispatcher
 	Count    int32
 	ReadOnly bool
 }
 
 // NewStringCollection creates a new empty IStringCollection.
 func NewStringCollection() (*IStringCollection, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.StringColl")
-	if err != nil {
-		return nil, err
-	}
-
-	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	disp, err := newComObject("Microsoft.Update.StringColl")
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +31,7 @@ func NewStringCollection() (*IStringCollection, error) {
 	return toIStringCollection(disp)
 }
 
-func toIStringCollection(disp *ole.IDispatch) (*IStringCollection, error) {
+func toIStringCollection(disp dispatcher) (*IStringCollection, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -49,11 +39,11 @@ func toIStringCollection(disp *ole.IDispatch) (*IStringCollection, error) {
 	var err error
 	sc := &IStringCollection{disp: disp}
 
-	if sc.Count, err = toInt32Err(oleutil.GetProperty(disp, "Count")); err != nil {
+	if sc.Count, err = toInt32Err(disp.GetProperty("Count")); err != nil {
 		return nil, err
 	}
 
-	if sc.ReadOnly, err = toBoolErr(oleutil.GetProperty(disp, "ReadOnly")); err != nil {
+	if sc.ReadOnly, err = toBoolErr(disp.GetProperty("ReadOnly")); err != nil {
 		return nil, err
 	}
 
@@ -62,22 +52,22 @@ func toIStringCollection(disp *ole.IDispatch) (*IStringCollection, error) {
 
 // Item gets a string from the collection at the specified index.
 func (sc *IStringCollection) Item(index int32) (string, error) {
-	return toStringErr(oleutil.GetProperty(sc.disp, "Item", index))
+	return toStringErr(sc.disp.GetProperty("Item", index))
 }
 
 // Add adds a string to the collection.
 func (sc *IStringCollection) Add(value string) (int32, error) {
-	result, err := oleutil.CallMethod(sc.disp, "Add", value)
+	result, err := sc.disp.CallMethod("Add", value)
 	if err != nil {
 		return 0, err
 	}
 	sc.Count++
-	return variantToInt32(result), nil
+	return variantToInt32(result)
 }
 
 // Clear removes all items from the collection.
 func (sc *IStringCollection) Clear() error {
-	_, err := oleutil.CallMethod(sc.disp, "Clear")
+	_, err := sc.disp.CallMethod("Clear")
 	if err == nil {
 		sc.Count = 0
 	}
@@ -86,7 +76,7 @@ func (sc *IStringCollection) Clear() error {
 
 // Insert inserts a string at the specified position.
 func (sc *IStringCollection) Insert(index int32, value string) error {
-	_, err := oleutil.CallMethod(sc.disp, "Insert", index, value)
+	_, err := sc.disp.CallMethod("Insert", index, value)
 	if err == nil {
 		sc.Count++
 	}
@@ -95,7 +85,7 @@ func (sc *IStringCollection) Insert(index int32, value string) error {
 
 // RemoveAt removes the item at the specified index.
 func (sc *IStringCollection) RemoveAt(index int32) error {
-	_, err := oleutil.CallMethod(sc.disp, "RemoveAt", index)
+	_, err := sc.disp.CallMethod("RemoveAt", index)
 	if err == nil {
 		sc.Count--
 	}
@@ -116,7 +106,7 @@ func (sc *IStringCollection) ToSlice() ([]string, error) {
 }
 
 // iStringCollectionToStringArrayErr is a helper function for backward compatibility.
-func iStringCollectionToStringArrayErr(disp *ole.IDispatch, err error) ([]string, error) {
+func iStringCollectionToStringArrayErr(disp dispatcher, err error) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -124,8 +114,9 @@ func iStringCollectionToStringArrayErr(disp *ole.IDispatch, err error) ([]string
 	if disp == nil {
 		return nil, nil
 	}
+	defer disp.Release()
 
-	count, err := toInt32Err(oleutil.GetProperty(disp, "Count"))
+	count, err := toInt32Err(disp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +124,7 @@ func iStringCollectionToStringArrayErr(disp *ole.IDispatch, err error) ([]string
 	stringCollection := make([]string, count)
 
 	for i := 0; i < int(count); i++ {
-		str, err := toStringErr(oleutil.GetProperty(disp, "Item", i))
+		str, err := toStringErr(disp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
@@ -142,3 +133,11 @@ func iStringCollectionToStringArrayErr(disp *ole.IDispatch, err error) ([]string
 	}
 	return stringCollection, nil
 }
+
+// Release releases the COM object.
+func (sc *IStringCollection) Release() {
+	if sc == nil {
+		return
+	}
+	releaseDispatcher(sc.disp)
+}
@@ -13,27 +13,17 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // ISystemInformation contains information about the specified computer.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-isysteminformation
 type ISystemInformation struct {
-	disp                   *ole.IDispatch
+	disp                   dispatcher
 	OemHardwareSupportLink string
 	RebootRequired         bool
 }
 
 // NewSystemInformation creates a new ISystemInformation instance.
 func NewSystemInformation() (*ISystemInformation, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.SystemInfo")
-	if err != nil {
-		return nil, err
-	}
-
-	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	disp, err := newComObject("Microsoft.Update.SystemInfo")
 	if err != nil {
 		return nil, err
 	}
@@ -41,19 +31,27 @@ func NewSystemInformation() (*ISystemInformation, error) {
 	return toISystemInformation(disp)
 }
 
-func toISystemInformation(systemInfoDisp *ole.IDispatch) (*ISystemInformation, error) {
+func toISystemInformation(systemInfoDisp dispatcher) (*ISystemInformation, error) {
 	var err error
 	iSystemInformation := &ISystemInformation{
 		disp: systemInfoDisp,
 	}
 
-	if iSystemInformation.OemHardwareSupportLink, err = toStringErr(oleutil.GetProperty(systemInfoDisp, "OemHardwareSupportLink")); err != nil {
+	if iSystemInformation.OemHardwareSupportLink, err = toStringErr(systemInfoDisp.GetProperty("OemHardwareSupportLink")); err != nil {
 		return nil, err
 	}
 
-	if iSystemInformation.RebootRequired, err = toBoolErr(oleutil.GetProperty(systemInfoDisp, "RebootRequired")); err != nil {
+	if iSystemInformation.RebootRequired, err = toBoolErr(systemInfoDisp.GetProperty("RebootRequired")); err != nil {
 		return nil, err
 	}
 
 	return iSystemInformation, nil
 }
+
+// Release releases the COM object.
+func (iSystemInformation *ISystemInformation) Release() {
+	if iSystemInformation == nil {
+		return
+	}
+	releaseDispatcher(iSystemInformation.disp)
+}
@@ -14,16 +14,16 @@ limitations under the License.
 package windowsupdate
 
 import (
+	"iter"
 	"time"
 
 	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
 )
 
 // IUpdate contains the properties and methods that are available to an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdate
 type IUpdate struct {
-	disp                            *ole.IDispatch
+	disp                            dispatcher
 	AutoSelectOnWebSites            bool
 	BundledUpdates                  []*IUpdateIdentity
 	CanRequireSource                bool
@@ -31,10 +31,10 @@ type IUpdate struct {
 	Deadline                        *time.Time
 	DeltaCompressedContentAvailable bool
 	DeltaCompressedContentPreferred bool
-	DeploymentAction                int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-deploymentaction
+	DeploymentAction                DeploymentAction
 	Description                     string
 	DownloadContents                []*IUpdateDownloadContent
-	DownloadPriority                int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-downloadpriority
+	DownloadPriority                DownloadPriority
 	EulaAccepted                    bool
 	EulaText                        string
 	HandlerID                       string
@@ -74,53 +74,87 @@ type IUpdate struct {
 	// IUpdate4 properties
 	PerUser bool // Indicates whether this is a per-user update
 	// IUpdate5 properties
-	AutoDownload  int32 // AutoDownload setting
-	AutoSelection int32 // AutoSelection setting
+	AutoDownload  AutoDownloadMode  // AutoDownload setting
+	AutoSelection AutoSelectionMode // AutoSelection setting
+
+	loaded UpdateFields
 }
 
-func toIUpdates(updatesDisp *ole.IDispatch) ([]*IUpdate, error) {
-	count, err := toInt32Err(oleutil.GetProperty(updatesDisp, "Count"))
-	if err != nil {
-		return nil, err
-	}
+func toIUpdates(updatesDisp dispatcher) ([]*IUpdate, error) {
+	return toIUpdatesFields(updatesDisp, FullUpdateFields)
+}
 
-	updates := make([]*IUpdate, 0, count)
-	for i := 0; i < int(count); i++ {
-		updateDisp, err := toIDispatchErr(oleutil.GetProperty(updatesDisp, "Item", i))
+func toIUpdatesFields(updatesDisp dispatcher, fields UpdateFields) ([]*IUpdate, error) {
+	defer updatesDisp.Release()
+
+	var updates []*IUpdate
+	for update, err := range seqIUpdates(updatesDisp, fields) {
 		if err != nil {
 			return nil, err
 		}
+		updates = append(updates, update)
+	}
+	if updates == nil {
+		updates = []*IUpdate{}
+	}
+	return updates, nil
+}
 
-		update, err := toIUpdate(updateDisp)
+// seqIUpdates iterates over a IUpdateCollection, converting each IUpdate only
+// when it is reached. It yields a nil update and the error, then stops, on failure.
+func seqIUpdates(updatesDisp dispatcher, fields UpdateFields) iter.Seq2[*IUpdate, error] {
+	return func(yield func(*IUpdate, error) bool) {
+		count, err := toInt32Err(updatesDisp.GetProperty("Count"))
 		if err != nil {
-			return nil, err
+			yield(nil, err)
+			return
 		}
 
-		updates = append(updates, update)
+		for i := 0; i < int(count); i++ {
+			update, err := updateAt(updatesDisp, i, fields)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(update, nil) {
+				return
+			}
+		}
 	}
-	return updates, nil
+}
+
+// updateAt converts the IUpdate at index in a IUpdateCollection.
+func updateAt(updatesDisp dispatcher, index int, fields UpdateFields) (*IUpdate, error) {
+	updateDisp, err := toIDispatchErr(updatesDisp.GetProperty("Item", index))
+	if err != nil {
+		return nil, err
+	}
+	return toIUpdateFields(updateDisp, fields)
 }
 
 // toIUpdates takes a IUpdateCollection and returns the a
 // []*IUpdateIdentity of the contained IUpdates. This is *not* recursive, though possible should be
-func toIUpdatesIdentities(updatesDisp *ole.IDispatch) ([]*IUpdateIdentity, error) {
+func toIUpdatesIdentities(updatesDisp dispatcher) ([]*IUpdateIdentity, error) {
 	if updatesDisp == nil {
 		return nil, nil
 	}
+	defer updatesDisp.Release()
 
-	count, err := toInt32Err(oleutil.GetProperty(updatesDisp, "Count"))
+	count, err := toInt32Err(updatesDisp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
 
 	identities := make([]*IUpdateIdentity, count)
 	for i := 0; i < int(count); i++ {
-		updateDisp, err := toIDispatchErr(oleutil.GetProperty(updatesDisp, "Item", i))
+		updateDisp, err := toIDispatchErr(updatesDisp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
 
-		identityDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "Identity"))
+		identityDisp, err := toIDispatchErr(updateDisp.GetProperty("Identity"))
+		updateDisp.Release()
 		if err != nil {
 			return nil, err
 		}
@@ -133,262 +167,34 @@ func toIUpdatesIdentities(updatesDisp *ole.IDispatch) ([]*IUpdateIdentity, error
 	return identities, nil
 }
 
-func toIUpdate(updateDisp *ole.IDispatch) (*IUpdate, error) {
-	var err error
+func toIUpdate(updateDisp dispatcher) (*IUpdate, error) {
+	return toIUpdateFields(updateDisp, FullUpdateFields)
+}
+
+// toIUpdateFields converts an IUpdate reading only the properties in fields.
+func toIUpdateFields(updateDisp dispatcher, fields UpdateFields) (*IUpdate, error) {
 	iUpdate := &IUpdate{
 		disp: updateDisp,
 	}
-
-	if iUpdate.AutoSelectOnWebSites, err = toBoolErr(oleutil.GetProperty(updateDisp, "AutoSelectOnWebSites")); err != nil {
-		return nil, err
-	}
-
-	bundledUpdatesDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "BundledUpdates"))
-	if err != nil {
-		return nil, err
-	}
-	if bundledUpdatesDisp != nil {
-		if iUpdate.BundledUpdates, err = toIUpdatesIdentities(bundledUpdatesDisp); err != nil {
-			return nil, err
-		}
-	}
-
-	if iUpdate.CanRequireSource, err = toBoolErr(oleutil.GetProperty(updateDisp, "CanRequireSource")); err != nil {
-		return nil, err
-	}
-
-	categoriesDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "Categories"))
-	if err != nil {
-		return nil, err
-	}
-	if categoriesDisp != nil {
-		if iUpdate.Categories, err = toICategories(categoriesDisp); err != nil {
-			return nil, err
-		}
-	}
-
-	if iUpdate.Deadline, err = toTimeErr(oleutil.GetProperty(updateDisp, "Deadline")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.DeltaCompressedContentAvailable, err = toBoolErr(oleutil.GetProperty(updateDisp, "DeltaCompressedContentAvailable")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.DeltaCompressedContentPreferred, err = toBoolErr(oleutil.GetProperty(updateDisp, "DeltaCompressedContentPreferred")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.DeploymentAction, err = toInt32Err(oleutil.GetProperty(updateDisp, "DeploymentAction")); err != nil {
+	if err := iUpdate.Load(fields); err != nil {
+		// Release the update with the children read before the failure.
+		releaseTree(updateDisp)
 		return nil, err
 	}
-
-	if iUpdate.Description, err = toStringErr(oleutil.GetProperty(updateDisp, "Description")); err != nil {
-		return nil, err
-	}
-
-	downloadContentsDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "DownloadContents"))
-	if err != nil {
-		return nil, err
-	}
-	if downloadContentsDisp != nil {
-		if iUpdate.DownloadContents, err = toIUpdateDownloadContents(downloadContentsDisp); err != nil {
-			return nil, err
-		}
-	}
-
-	if iUpdate.DownloadPriority, err = toInt32Err(oleutil.GetProperty(updateDisp, "DownloadPriority")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.EulaAccepted, err = toBoolErr(oleutil.GetProperty(updateDisp, "EulaAccepted")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.EulaText, err = toStringErr(oleutil.GetProperty(updateDisp, "EulaText")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.HandlerID, err = toStringErr(oleutil.GetProperty(updateDisp, "HandlerID")); err != nil {
-		return nil, err
-	}
-
-	identityDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "Identity"))
-	if err != nil {
-		return nil, err
-	}
-	if identityDisp != nil {
-		if iUpdate.Identity, err = toIUpdateIdentity(identityDisp); err != nil {
-			return nil, err
-		}
-	}
-
-	imageDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "Image"))
-	if err != nil {
-		return nil, err
-	}
-	if imageDisp != nil {
-		if iUpdate.Image, err = toIImageInformation(imageDisp); err != nil {
-			return nil, err
-		}
-	}
-
-	installationBehaviorDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "InstallationBehavior"))
-	if err != nil {
-		return nil, err
-	}
-	if installationBehaviorDisp != nil {
-		if iUpdate.InstallationBehavior, err = toIInstallationBehavior(installationBehaviorDisp); err != nil {
-			return nil, err
-		}
-	}
-
-	if iUpdate.IsBeta, err = toBoolErr(oleutil.GetProperty(updateDisp, "IsBeta")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.IsDownloaded, err = toBoolErr(oleutil.GetProperty(updateDisp, "IsDownloaded")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.IsHidden, err = toBoolErr(oleutil.GetProperty(updateDisp, "IsHidden")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.IsInstalled, err = toBoolErr(oleutil.GetProperty(updateDisp, "IsInstalled")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.IsMandatory, err = toBoolErr(oleutil.GetProperty(updateDisp, "IsMandatory")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.IsUninstallable, err = toBoolErr(oleutil.GetProperty(updateDisp, "IsUninstallable")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.KBArticleIDs, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateDisp, "KBArticleIDs"))); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.Languages, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateDisp, "Languages"))); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.LastDeploymentChangeTime, err = toTimeErr(oleutil.GetProperty(updateDisp, "LastDeploymentChangeTime")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.MaxDownloadSize, err = toInt64Err(oleutil.GetProperty(updateDisp, "MaxDownloadSize")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.MinDownloadSize, err = toInt64Err(oleutil.GetProperty(updateDisp, "MinDownloadSize")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.MoreInfoUrls, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateDisp, "MoreInfoUrls"))); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.MsrcSeverity, err = toStringErr(oleutil.GetProperty(updateDisp, "MsrcSeverity")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.RecommendedCpuSpeed, err = toInt32Err(oleutil.GetProperty(updateDisp, "RecommendedCpuSpeed")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.RecommendedHardDiskSpace, err = toInt32Err(oleutil.GetProperty(updateDisp, "RecommendedHardDiskSpace")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.RecommendedMemory, err = toInt32Err(oleutil.GetProperty(updateDisp, "RecommendedMemory")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.ReleaseNotes, err = toStringErr(oleutil.GetProperty(updateDisp, "ReleaseNotes")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.SecurityBulletinIDs, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateDisp, "SecurityBulletinIDs"))); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.SupersededUpdateIDs, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateDisp, "SupersededUpdateIDs"))); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.SupportUrl, err = toStringErr(oleutil.GetProperty(updateDisp, "SupportUrl")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.Title, err = toStringErr(oleutil.GetProperty(updateDisp, "Title")); err != nil {
-		return nil, err
-	}
-
-	uninstallationBehaviorDisp, err := toIDispatchErr(oleutil.GetProperty(updateDisp, "UninstallationBehavior"))
-	if err != nil {
-		return nil, err
-	}
-	if uninstallationBehaviorDisp != nil {
-		if iUpdate.UninstallationBehavior, err = toIInstallationBehavior(uninstallationBehaviorDisp); err != nil {
-			return nil, err
-		}
-	}
-
-	if iUpdate.UninstallationNotes, err = toStringErr(oleutil.GetProperty(updateDisp, "UninstallationNotes")); err != nil {
-		return nil, err
-	}
-
-	if iUpdate.UninstallationSteps, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateDisp, "UninstallationSteps"))); err != nil {
-		return nil, err
-	}
-
-	// IUpdate2 properties (may fail on older systems)
-	if cveIDs, err := iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateDisp, "CveIDs"))); err == nil {
-		iUpdate.CveIDs = cveIDs
-	}
-	if isPresent, err := toBoolErr(oleutil.GetProperty(updateDisp, "IsPresent")); err == nil {
-		iUpdate.IsPresent = isPresent
-	}
-	if rebootRequired, err := toBoolErr(oleutil.GetProperty(updateDisp, "RebootRequired")); err == nil {
-		iUpdate.RebootRequired = rebootRequired
-	}
-
-	// IUpdate3 properties
-	if browseOnly, err := toBoolErr(oleutil.GetProperty(updateDisp, "BrowseOnly")); err == nil {
-		iUpdate.BrowseOnly = browseOnly
-	}
-
-	// IUpdate4 properties
-	if perUser, err := toBoolErr(oleutil.GetProperty(updateDisp, "PerUser")); err == nil {
-		iUpdate.PerUser = perUser
-	}
-
-	// IUpdate5 properties
-	if autoDownload, err := toInt32Err(oleutil.GetProperty(updateDisp, "AutoDownload")); err == nil {
-		iUpdate.AutoDownload = autoDownload
-	}
-	if autoSelection, err := toInt32Err(oleutil.GetProperty(updateDisp, "AutoSelection")); err == nil {
-		iUpdate.AutoSelection = autoSelection
-	}
-
 	return iUpdate, nil
 }
 
-func toIUpdateCollection(updates []*IUpdate) (*ole.IDispatch, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.UpdateColl")
-	if err != nil {
-		return nil, err
-	}
-	coll, err := unknown.QueryInterface(ole.IID_IDispatch)
+// toIUpdateCollection builds an IUpdateCollection holding updates, created by the
+// same backend as owner.
+func toIUpdateCollection(owner dispatcher, updates []*IUpdate) (dispatcher, error) {
+	coll, err := owner.CreateObject("Microsoft.Update.UpdateColl")
 	if err != nil {
 		return nil, err
 	}
 	for _, update := range updates {
-		_, err := oleutil.CallMethod(coll, "Add", update.disp)
+		_, err := coll.CallMethod("Add", update.disp)
 		if err != nil {
+			coll.Release()
 			return nil, err
 		}
 	}
@@ -398,18 +204,34 @@ func toIUpdateCollection(updates []*IUpdate) (*ole.IDispatch, error) {
 // AcceptEula accepts the Microsoft Software License Terms that are associated with Windows Update. Administrators and power users can call this method.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdate-accepteula
 func (iUpdate *IUpdate) AcceptEula() error {
-	_, err := oleutil.CallMethod(iUpdate.disp, "AcceptEula")
+	_, err := iUpdate.disp.CallMethod("AcceptEula")
 	return err
 }
 
 // CopyToCache copies the contents of an update to the Windows Update Agent (WUA) cache. (IUpdate2)
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdate2-copytocache
 func (iUpdate *IUpdate) CopyToCache(files *IStringCollection) error {
-	_, err := oleutil.CallMethod(iUpdate.disp, "CopyToCache", files.disp)
+	_, err := iUpdate.disp.CallMethod("CopyToCache", files.disp)
 	return err
 }
 
 // GetDispatch returns the underlying IDispatch interface.
 func (iUpdate *IUpdate) GetDispatch() *ole.IDispatch {
-	return iUpdate.disp
+	return dispatchOf(iUpdate.disp)
+}
+
+// Release releases the COM object of the update and those of the identities,
+// categories, download contents, image and behaviors it holds.
+func (iUpdate *IUpdate) Release() {
+	if iUpdate == nil {
+		return
+	}
+	releaseEach(iUpdate.BundledUpdates)
+	releaseEach(iUpdate.Categories)
+	releaseEach(iUpdate.DownloadContents)
+	iUpdate.Identity.Release()
+	iUpdate.Image.Release()
+	iUpdate.InstallationBehavior.Release()
+	iUpdate.UninstallationBehavior.Release()
+	releaseTree(iUpdate.disp)
 }
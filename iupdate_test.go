@@ -147,7 +147,7 @@ func TestToIUpdateCollection(t *testing.T) {
 	defer ole.CoUninitialize()
 
 	// Test with empty slice
-	disp, err := toIUpdateCollection([]*IUpdate{})
+	disp, err := toIUpdateCollection(&comDispatcher{}, []*IUpdate{})
 	if err != nil {
 		t.Fatalf("toIUpdateCollection with empty slice failed: %v", err)
 	}
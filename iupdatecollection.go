@@ -14,26 +14,22 @@ limitations under the License.
 package windowsupdate
 
 import (
+	"iter"
+
 	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
 )
 
 // IUpdateCollection represents a collection of updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdatecollection
 type IUpdateCollection struct {
-	disp     *ole.IDispatch
+	disp     dispatcher
 	Count    int32
 	ReadOnly bool
 }
 
 // NewUpdateCollection creates a new empty IUpdateCollection.
 func NewUpdateCollection() (*IUpdateCollection, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.UpdateColl")
-	if err != nil {
-		return nil, err
-	}
-
-	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	disp, err := newComObject("Microsoft.Update.UpdateColl")
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +37,7 @@ func NewUpdateCollection() (*IUpdateCollection, error) {
 	return toIUpdateCollection2(disp)
 }
 
-func toIUpdateCollection2(disp *ole.IDispatch) (*IUpdateCollection, error) {
+func toIUpdateCollection2(disp dispatcher) (*IUpdateCollection, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -49,11 +45,11 @@ func toIUpdateCollection2(disp *ole.IDispatch) (*IUpdateCollection, error) {
 	var err error
 	uc := &IUpdateCollection{disp: disp}
 
-	if uc.Count, err = toInt32Err(oleutil.GetProperty(disp, "Count")); err != nil {
+	if uc.Count, err = toInt32Err(disp.GetProperty("Count")); err != nil {
 		return nil, err
 	}
 
-	if uc.ReadOnly, err = toBoolErr(oleutil.GetProperty(disp, "ReadOnly")); err != nil {
+	if uc.ReadOnly, err = toBoolErr(disp.GetProperty("ReadOnly")); err != nil {
 		return nil, err
 	}
 
@@ -62,7 +58,7 @@ func toIUpdateCollection2(disp *ole.IDispatch) (*IUpdateCollection, error) {
 
 // Item gets an update from the collection at the specified index.
 func (uc *IUpdateCollection) Item(index int32) (*IUpdate, error) {
-	itemDisp, err := toIDispatchErr(oleutil.GetProperty(uc.disp, "Item", index))
+	itemDisp, err := toIDispatchErr(uc.disp.GetProperty("Item", index))
 	if err != nil {
 		return nil, err
 	}
@@ -71,17 +67,17 @@ func (uc *IUpdateCollection) Item(index int32) (*IUpdate, error) {
 
 // Add adds an update to the collection.
 func (uc *IUpdateCollection) Add(update *IUpdate) (int32, error) {
-	result, err := oleutil.CallMethod(uc.disp, "Add", update.disp)
+	result, err := uc.disp.CallMethod("Add", update.disp)
 	if err != nil {
 		return 0, err
 	}
 	uc.Count++
-	return variantToInt32(result), nil
+	return variantToInt32(result)
 }
 
 // Clear removes all items from the collection.
 func (uc *IUpdateCollection) Clear() error {
-	_, err := oleutil.CallMethod(uc.disp, "Clear")
+	_, err := uc.disp.CallMethod("Clear")
 	if err == nil {
 		uc.Count = 0
 	}
@@ -90,7 +86,7 @@ func (uc *IUpdateCollection) Clear() error {
 
 // Copy creates a shallow copy of the collection.
 func (uc *IUpdateCollection) Copy() (*IUpdateCollection, error) {
-	copyDisp, err := toIDispatchErr(oleutil.CallMethod(uc.disp, "Copy"))
+	copyDisp, err := toIDispatchErr(uc.disp.CallMethod("Copy"))
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +95,7 @@ func (uc *IUpdateCollection) Copy() (*IUpdateCollection, error) {
 
 // Insert inserts an update at the specified position.
 func (uc *IUpdateCollection) Insert(index int32, update *IUpdate) error {
-	_, err := oleutil.CallMethod(uc.disp, "Insert", index, update.disp)
+	_, err := uc.disp.CallMethod("Insert", index, update.disp)
 	if err == nil {
 		uc.Count++
 	}
@@ -108,7 +104,7 @@ func (uc *IUpdateCollection) Insert(index int32, update *IUpdate) error {
 
 // RemoveAt removes the item at the specified index.
 func (uc *IUpdateCollection) RemoveAt(index int32) error {
-	_, err := oleutil.CallMethod(uc.disp, "RemoveAt", index)
+	_, err := uc.disp.CallMethod("RemoveAt", index)
 	if err == nil {
 		uc.Count--
 	}
@@ -128,7 +124,23 @@ func (uc *IUpdateCollection) ToSlice() ([]*IUpdate, error) {
 	return result, nil
 }
 
+// All returns an iterator over the updates of the collection, converting each
+// one only when the loop reaches it. A conversion failure is yielded with a nil
+// update and ends the iteration. As with ISearchResult.All, each update holds
+// its COM objects until it is released.
+func (uc *IUpdateCollection) All() iter.Seq2[*IUpdate, error] {
+	return seqIUpdates(uc.disp, FullUpdateFields)
+}
+
 // GetDispatch returns the underlying IDispatch for use with other WUA methods.
 func (uc *IUpdateCollection) GetDispatch() *ole.IDispatch {
-	return uc.disp
+	return dispatchOf(uc.disp)
+}
+
+// Release releases the COM object.
+func (uc *IUpdateCollection) Release() {
+	if uc == nil {
+		return
+	}
+	releaseDispatcher(uc.disp)
 }
@@ -13,27 +13,24 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateDownloadContent represents the download content of an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdatedownloadcontent
 type IUpdateDownloadContent struct {
-	disp        *ole.IDispatch
+	disp        dispatcher
 	DownloadUrl string
 }
 
-func toIUpdateDownloadContents(updateDownloadContentsDisp *ole.IDispatch) ([]*IUpdateDownloadContent, error) {
-	count, err := toInt32Err(oleutil.GetProperty(updateDownloadContentsDisp, "Count"))
+func toIUpdateDownloadContents(updateDownloadContentsDisp dispatcher) ([]*IUpdateDownloadContent, error) {
+	defer updateDownloadContentsDisp.Release()
+
+	count, err := toInt32Err(updateDownloadContentsDisp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
 
 	contents := make([]*IUpdateDownloadContent, 0, count)
 	for i := 0; i < int(count); i++ {
-		contentDisp, err := toIDispatchErr(oleutil.GetProperty(updateDownloadContentsDisp, "Item", i))
+		contentDisp, err := toIDispatchErr(updateDownloadContentsDisp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
@@ -48,15 +45,23 @@ func toIUpdateDownloadContents(updateDownloadContentsDisp *ole.IDispatch) ([]*IU
 	return contents, nil
 }
 
-func toIUpdateDownloadContent(updateDownloadContentDisp *ole.IDispatch) (*IUpdateDownloadContent, error) {
+func toIUpdateDownloadContent(updateDownloadContentDisp dispatcher) (*IUpdateDownloadContent, error) {
 	var err error
 	iUpdateDownloadContent := &IUpdateDownloadContent{
 		disp: updateDownloadContentDisp,
 	}
 
-	if iUpdateDownloadContent.DownloadUrl, err = toStringErr(oleutil.GetProperty(updateDownloadContentDisp, "DownloadUrl")); err != nil {
+	if iUpdateDownloadContent.DownloadUrl, err = toStringErr(updateDownloadContentDisp.GetProperty("DownloadUrl")); err != nil {
 		return nil, err
 	}
 
 	return iUpdateDownloadContent, nil
 }
+
+// Release releases the COM object.
+func (iUpdateDownloadContent *IUpdateDownloadContent) Release() {
+	if iUpdateDownloadContent == nil {
+		return
+	}
+	releaseDispatcher(iUpdateDownloadContent.disp)
+}
@@ -14,39 +14,39 @@ limitations under the License.
 package windowsupdate
 
 import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
+	"context"
+	"errors"
 )
 
 // IUpdateDownloader downloads updates from the server.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdatedownloaders
 type IUpdateDownloader struct {
-	disp                *ole.IDispatch
+	disp                dispatcher
 	ClientApplicationID string
 	IsForced            bool
-	Priority            int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-downloadpriority
+	Priority            DownloadPriority
 	Updates             []*IUpdate
 }
 
-func toIUpdateDownloader(updateDownloaderDisp *ole.IDispatch) (*IUpdateDownloader, error) {
+func toIUpdateDownloader(updateDownloaderDisp dispatcher) (*IUpdateDownloader, error) {
 	var err error
 	iUpdateDownloader := &IUpdateDownloader{
 		disp: updateDownloaderDisp,
 	}
 
-	if iUpdateDownloader.ClientApplicationID, err = toStringErr(oleutil.GetProperty(updateDownloaderDisp, "ClientApplicationID")); err != nil {
+	if iUpdateDownloader.ClientApplicationID, err = toStringErr(updateDownloaderDisp.GetProperty("ClientApplicationID")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateDownloader.IsForced, err = toBoolErr(oleutil.GetProperty(updateDownloaderDisp, "IsForced")); err != nil {
+	if iUpdateDownloader.IsForced, err = toBoolErr(updateDownloaderDisp.GetProperty("IsForced")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateDownloader.Priority, err = toInt32Err(oleutil.GetProperty(updateDownloaderDisp, "Priority")); err != nil {
+	if iUpdateDownloader.Priority, err = toEnumErr[DownloadPriority](updateDownloaderDisp.GetProperty("Priority")); err != nil {
 		return nil, err
 	}
 
-	updatesDisp, err := toIDispatchErr(oleutil.GetProperty(updateDownloaderDisp, "Updates"))
+	updatesDisp, err := toIDispatchErr(updateDownloaderDisp.GetProperty("Updates"))
 	if err != nil {
 		return nil, err
 	}
@@ -62,15 +62,16 @@ func toIUpdateDownloader(updateDownloaderDisp *ole.IDispatch) (*IUpdateDownloade
 // Download starts a synchronous download of the content files that are associated with the updates.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatedownloader-download
 func (iUpdateDownloader *IUpdateDownloader) Download(updates []*IUpdate) (*IDownloadResult, error) {
-	updatesDisp, err := toIUpdateCollection(updates)
+	updatesDisp, err := toIUpdateCollection(iUpdateDownloader.disp, updates)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = oleutil.PutProperty(iUpdateDownloader.disp, "Updates", updatesDisp); err != nil {
+	defer updatesDisp.Release()
+	if _, err = iUpdateDownloader.disp.PutProperty("Updates", updatesDisp); err != nil {
 		return nil, err
 	}
 
-	downloadResultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateDownloader.disp, "Download"))
+	downloadResultDisp, err := toIDispatchErr(iUpdateDownloader.disp.CallMethod("Download"))
 	if err != nil {
 		return nil, err
 	}
@@ -78,36 +79,78 @@ func (iUpdateDownloader *IUpdateDownloader) Download(updates []*IUpdate) (*IDown
 }
 
 // BeginDownload begins an asynchronous download of the content files associated with the updates.
+// WithCallbacks sets the handlers of IDownloadProgressChangedCallback and
+// IDownloadCompletedCallback; other options are ignored.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatedownloader-begindownload
-func (iUpdateDownloader *IUpdateDownloader) BeginDownload(updates []*IUpdate) (*IDownloadJob, error) {
-	updatesDisp, err := toIUpdateCollection(updates)
+func (iUpdateDownloader *IUpdateDownloader) BeginDownload(updates []*IUpdate, options ...JobOption) (*IDownloadJob, error) {
+	updatesDisp, err := toIUpdateCollection(iUpdateDownloader.disp, updates)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = oleutil.PutProperty(iUpdateDownloader.disp, "Updates", updatesDisp); err != nil {
+	defer updatesDisp.Release()
+	if _, err = iUpdateDownloader.disp.PutProperty("Updates", updatesDisp); err != nil {
 		return nil, err
 	}
 
-	jobDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateDownloader.disp, "BeginDownload", newNoopCallback(), newNoopCallback(), nil))
+	callbacks, releaseCallbacks := jobCallbackArgs(CallbacksOf(options...), downloadProgressChangedCallback, downloadCompletedCallback)
+	jobDisp, err := toIDispatchErr(iUpdateDownloader.disp.CallMethod("BeginDownload", callbacks[0], callbacks[1], nil))
 	if err != nil {
+		releaseCallbacks()
 		return nil, err
 	}
-	return toIDownloadJob(jobDisp)
+	job, err := toIDownloadJob(jobDisp)
+	if err != nil || job == nil {
+		releaseCallbacks()
+		return nil, err
+	}
+	job.releaseCallbacks = releaseCallbacks
+	return job, nil
 }
 
 // EndDownload completes an asynchronous download.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatedownloader-enddownload
 func (iUpdateDownloader *IUpdateDownloader) EndDownload(downloadJob *IDownloadJob) (*IDownloadResult, error) {
-	resultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateDownloader.disp, "EndDownload", downloadJob.disp))
+	resultDisp, err := toIDispatchErr(iUpdateDownloader.disp.CallMethod("EndDownload", downloadJob.disp))
 	if err != nil {
 		return nil, err
 	}
 	return toIDownloadResult(resultDisp)
 }
 
+// DownloadContext performs Download with BeginDownload, polling the job every
+// poll interval until it completes and then calling EndDownload. Progress is
+// reported as set with WithProgress or WithProgressFunc. If ctx is done first,
+// the download is aborted with RequestAbort and a *DownloadAbortedError wrapping
+// ctx.Err() is returned with whatever EndDownload reported. The job is cleaned
+// up in every case.
+func (iUpdateDownloader *IUpdateDownloader) DownloadContext(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IDownloadResult, error) {
+	o := newJobOptions(options)
+	defer o.done()
+	o.notifyCompleted()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	job, err := iUpdateDownloader.BeginDownload(updates, o.beginOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	defer job.Release()
+	defer job.CleanUp()
+
+	if err := runJob(ctx, job, o, job.progress); err != nil {
+		if !errors.Is(err, ctx.Err()) {
+			return nil, err
+		}
+		aborted := &DownloadAbortedError{Err: err}
+		aborted.Result, _ = iUpdateDownloader.EndDownload(job)
+		return nil, aborted
+	}
+	return iUpdateDownloader.EndDownload(job)
+}
+
 // PutClientApplicationID sets the identifier of the current client application.
 func (iUpdateDownloader *IUpdateDownloader) PutClientApplicationID(value string) error {
-	_, err := oleutil.PutProperty(iUpdateDownloader.disp, "ClientApplicationID", value)
+	_, err := iUpdateDownloader.disp.PutProperty("ClientApplicationID", value)
 	if err != nil {
 		return err
 	}
@@ -117,7 +160,7 @@ func (iUpdateDownloader *IUpdateDownloader) PutClientApplicationID(value string)
 
 // PutIsForced sets whether the download is forced.
 func (iUpdateDownloader *IUpdateDownloader) PutIsForced(value bool) error {
-	_, err := oleutil.PutProperty(iUpdateDownloader.disp, "IsForced", value)
+	_, err := iUpdateDownloader.disp.PutProperty("IsForced", value)
 	if err != nil {
 		return err
 	}
@@ -126,11 +169,20 @@ func (iUpdateDownloader *IUpdateDownloader) PutIsForced(value bool) error {
 }
 
 // PutPriority sets the download priority.
-func (iUpdateDownloader *IUpdateDownloader) PutPriority(value int32) error {
-	_, err := oleutil.PutProperty(iUpdateDownloader.disp, "Priority", value)
+func (iUpdateDownloader *IUpdateDownloader) PutPriority(value DownloadPriority) error {
+	_, err := iUpdateDownloader.disp.PutProperty("Priority", int32(value))
 	if err != nil {
 		return err
 	}
 	iUpdateDownloader.Priority = value
 	return nil
 }
+
+// Release releases the COM object and those of Updates.
+func (iUpdateDownloader *IUpdateDownloader) Release() {
+	if iUpdateDownloader == nil {
+		return
+	}
+	releaseEach(iUpdateDownloader.Updates)
+	releaseDispatcher(iUpdateDownloader.disp)
+}
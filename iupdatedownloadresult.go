@@ -13,32 +13,41 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateDownloadResult contains the properties that indicate the status of a download operation for an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdatedownloadresult
 type IUpdateDownloadResult struct {
-	disp       *ole.IDispatch
+	disp       dispatcher
 	HResult    int32
-	ResultCode int32
+	ResultCode OperationResultCode
 }
 
-func toIUpdateDownloadResult(iUpdateDownloadResultDisp *ole.IDispatch) (*IUpdateDownloadResult, error) {
+func toIUpdateDownloadResult(iUpdateDownloadResultDisp dispatcher) (*IUpdateDownloadResult, error) {
 	var err error
 	iUpdateDownloadResult := &IUpdateDownloadResult{
 		disp: iUpdateDownloadResultDisp,
 	}
 
-	if iUpdateDownloadResult.HResult, err = toInt32Err(oleutil.GetProperty(iUpdateDownloadResultDisp, "HResult")); err != nil {
+	if iUpdateDownloadResult.HResult, err = toHResultErr(iUpdateDownloadResultDisp.GetProperty("HResult")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateDownloadResult.ResultCode, err = toInt32Err(oleutil.GetProperty(iUpdateDownloadResultDisp, "ResultCode")); err != nil {
+	if iUpdateDownloadResult.ResultCode, err = toEnumErr[OperationResultCode](iUpdateDownloadResultDisp.GetProperty("ResultCode")); err != nil {
 		return nil, err
 	}
 
 	return iUpdateDownloadResult, nil
 }
+
+// Err returns the HResult of the download of the update as an HRESULT error, or
+// nil if it is zero.
+func (iUpdateDownloadResult *IUpdateDownloadResult) Err() error {
+	return HResultError(iUpdateDownloadResult.HResult)
+}
+
+// Release releases the COM object.
+func (iUpdateDownloadResult *IUpdateDownloadResult) Release() {
+	if iUpdateDownloadResult == nil {
+		return
+	}
+	releaseDispatcher(iUpdateDownloadResult.disp)
+}
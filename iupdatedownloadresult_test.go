@@ -36,7 +36,7 @@ func TestIUpdateDownloadResult_ErrorCodes(t *testing.T) {
 	testCases := []struct {
 		name       string
 		hresult    int32
-		resultCode int32
+		resultCode OperationResultCode
 	}{
 		{"Success", 0, OperationResultCodeOrcSucceeded},
 		{"Failed", -2147024891, OperationResultCodeOrcFailed},
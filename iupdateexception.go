@@ -13,29 +13,26 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateException represents info about the aspects of search results returned in the ISearchResult object that were incomplete. For more info, see Remarks.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdateexception
 type IUpdateException struct {
-	disp    *ole.IDispatch
-	Context int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-updateexceptioncontext
+	disp    dispatcher
+	Context UpdateExceptionContext
 	HResult int64
 	Message string
 }
 
-func toIUpdateExceptions(updateExceptionsDisp *ole.IDispatch) ([]*IUpdateException, error) {
-	count, err := toInt32Err(oleutil.GetProperty(updateExceptionsDisp, "Count"))
+func toIUpdateExceptions(updateExceptionsDisp dispatcher) ([]*IUpdateException, error) {
+	defer updateExceptionsDisp.Release()
+
+	count, err := toInt32Err(updateExceptionsDisp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
 
 	exceptions := make([]*IUpdateException, 0, count)
 	for i := 0; i < int(count); i++ {
-		exceptionDisp, err := toIDispatchErr(oleutil.GetProperty(updateExceptionsDisp, "Item", i))
+		exceptionDisp, err := toIDispatchErr(updateExceptionsDisp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
@@ -50,23 +47,37 @@ func toIUpdateExceptions(updateExceptionsDisp *ole.IDispatch) ([]*IUpdateExcepti
 	return exceptions, nil
 }
 
-func toIUpdateException(updateExceptionDisp *ole.IDispatch) (*IUpdateException, error) {
+func toIUpdateException(updateExceptionDisp dispatcher) (*IUpdateException, error) {
 	var err error
 	iUpdateException := &IUpdateException{
 		disp: updateExceptionDisp,
 	}
 
-	if iUpdateException.Context, err = toInt32Err(oleutil.GetProperty(updateExceptionDisp, "Context")); err != nil {
+	if iUpdateException.Context, err = toEnumErr[UpdateExceptionContext](updateExceptionDisp.GetProperty("Context")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateException.HResult, err = toInt64Err(oleutil.GetProperty(updateExceptionDisp, "HResult")); err != nil {
+	if iUpdateException.HResult, err = toInt64Err(updateExceptionDisp.GetProperty("HResult")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateException.Message, err = toStringErr(oleutil.GetProperty(updateExceptionDisp, "Message")); err != nil {
+	if iUpdateException.Message, err = toStringErr(updateExceptionDisp.GetProperty("Message")); err != nil {
 		return nil, err
 	}
 
 	return iUpdateException, nil
 }
+
+// Err returns the HResult of the failed operation as an HRESULT error, or nil
+// if it is zero.
+func (iUpdateException *IUpdateException) Err() error {
+	return HResultError(int32(iUpdateException.HResult))
+}
+
+// Release releases the COM object.
+func (iUpdateException *IUpdateException) Release() {
+	if iUpdateException == nil {
+		return
+	}
+	releaseDispatcher(iUpdateException.disp)
+}
@@ -15,22 +15,19 @@ package windowsupdate
 
 import (
 	"time"
-
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
 )
 
 // IUpdateHistoryEntry represents the recorded history of an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdatehistoryentry
 type IUpdateHistoryEntry struct {
-	disp                *ole.IDispatch
+	disp                dispatcher
 	ClientApplicationID string
 	Date                *time.Time
 	Description         string
 	HResult             int32
-	Operation           int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-updateoperation
-	ResultCode          int32 // enum https://docs.microsoft.com/en-us/windows/win32/api/wuapi/ne-wuapi-operationresultcode
-	ServerSelection     int32 // enum
+	Operation           UpdateOperation
+	ResultCode          OperationResultCode
+	ServerSelection     ServerSelection
 	ServiceID           string
 	SupportUrl          string
 	Title               string
@@ -40,15 +37,17 @@ type IUpdateHistoryEntry struct {
 	UpdateIdentity      *IUpdateIdentity
 }
 
-func toIUpdateHistoryEntries(updateHistoryEntriesDisp *ole.IDispatch) ([]*IUpdateHistoryEntry, error) {
-	count, err := toInt32Err(oleutil.GetProperty(updateHistoryEntriesDisp, "Count"))
+func toIUpdateHistoryEntries(updateHistoryEntriesDisp dispatcher) ([]*IUpdateHistoryEntry, error) {
+	defer updateHistoryEntriesDisp.Release()
+
+	count, err := toInt32Err(updateHistoryEntriesDisp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
 
 	updateHistoryEntries := make([]*IUpdateHistoryEntry, 0, count)
 	for i := 0; i < int(count); i++ {
-		updateHistoryEntryDisp, err := toIDispatchErr(oleutil.GetProperty(updateHistoryEntriesDisp, "Item", i))
+		updateHistoryEntryDisp, err := toIDispatchErr(updateHistoryEntriesDisp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
@@ -63,65 +62,65 @@ func toIUpdateHistoryEntries(updateHistoryEntriesDisp *ole.IDispatch) ([]*IUpdat
 	return updateHistoryEntries, nil
 }
 
-func toIUpdateHistoryEntry(updateHistoryEntryDisp *ole.IDispatch) (*IUpdateHistoryEntry, error) {
+func toIUpdateHistoryEntry(updateHistoryEntryDisp dispatcher) (*IUpdateHistoryEntry, error) {
 	var err error
 	iUpdateHistoryEntry := &IUpdateHistoryEntry{
 		disp: updateHistoryEntryDisp,
 	}
 
-	if iUpdateHistoryEntry.ClientApplicationID, err = toStringErr(oleutil.GetProperty(updateHistoryEntryDisp, "ClientApplicationID")); err != nil {
+	if iUpdateHistoryEntry.ClientApplicationID, err = toStringErr(updateHistoryEntryDisp.GetProperty("ClientApplicationID")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.Date, err = toTimeErr(oleutil.GetProperty(updateHistoryEntryDisp, "Date")); err != nil {
+	if iUpdateHistoryEntry.Date, err = toTimeErr(updateHistoryEntryDisp.GetProperty("Date")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.Description, err = toStringErr(oleutil.GetProperty(updateHistoryEntryDisp, "Description")); err != nil {
+	if iUpdateHistoryEntry.Description, err = toStringErr(updateHistoryEntryDisp.GetProperty("Description")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.HResult, err = toInt32Err(oleutil.GetProperty(updateHistoryEntryDisp, "HResult")); err != nil {
+	if iUpdateHistoryEntry.HResult, err = toHResultErr(updateHistoryEntryDisp.GetProperty("HResult")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.Operation, err = toInt32Err(oleutil.GetProperty(updateHistoryEntryDisp, "Operation")); err != nil {
+	if iUpdateHistoryEntry.Operation, err = toEnumErr[UpdateOperation](updateHistoryEntryDisp.GetProperty("Operation")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.ResultCode, err = toInt32Err(oleutil.GetProperty(updateHistoryEntryDisp, "ResultCode")); err != nil {
+	if iUpdateHistoryEntry.ResultCode, err = toEnumErr[OperationResultCode](updateHistoryEntryDisp.GetProperty("ResultCode")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.ServerSelection, err = toInt32Err(oleutil.GetProperty(updateHistoryEntryDisp, "ServerSelection")); err != nil {
+	if iUpdateHistoryEntry.ServerSelection, err = toEnumErr[ServerSelection](updateHistoryEntryDisp.GetProperty("ServerSelection")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.ServiceID, err = toStringErr(oleutil.GetProperty(updateHistoryEntryDisp, "ServiceID")); err != nil {
+	if iUpdateHistoryEntry.ServiceID, err = toStringErr(updateHistoryEntryDisp.GetProperty("ServiceID")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.SupportUrl, err = toStringErr(oleutil.GetProperty(updateHistoryEntryDisp, "SupportUrl")); err != nil {
+	if iUpdateHistoryEntry.SupportUrl, err = toStringErr(updateHistoryEntryDisp.GetProperty("SupportUrl")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.Title, err = toStringErr(oleutil.GetProperty(updateHistoryEntryDisp, "Title")); err != nil {
+	if iUpdateHistoryEntry.Title, err = toStringErr(updateHistoryEntryDisp.GetProperty("Title")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.UninstallationNotes, err = toStringErr(oleutil.GetProperty(updateHistoryEntryDisp, "UninstallationNotes")); err != nil {
+	if iUpdateHistoryEntry.UninstallationNotes, err = toStringErr(updateHistoryEntryDisp.GetProperty("UninstallationNotes")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.UninstallationSteps, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(updateHistoryEntryDisp, "UninstallationSteps"))); err != nil {
+	if iUpdateHistoryEntry.UninstallationSteps, err = iStringCollectionToStringArrayErr(toIDispatchErr(updateHistoryEntryDisp.GetProperty("UninstallationSteps"))); err != nil {
 		return nil, err
 	}
 
-	if iUpdateHistoryEntry.UnmappedResultCode, err = toInt32Err(oleutil.GetProperty(updateHistoryEntryDisp, "UnmappedResultCode")); err != nil {
+	if iUpdateHistoryEntry.UnmappedResultCode, err = toInt32Err(updateHistoryEntryDisp.GetProperty("UnmappedResultCode")); err != nil {
 		return nil, err
 	}
 
-	updateIdentityDisp, err := toIDispatchErr(oleutil.GetProperty(updateHistoryEntryDisp, "UpdateIdentity"))
+	updateIdentityDisp, err := toIDispatchErr(updateHistoryEntryDisp.GetProperty("UpdateIdentity"))
 	if err != nil {
 		return nil, err
 	}
@@ -133,3 +132,18 @@ func toIUpdateHistoryEntry(updateHistoryEntryDisp *ole.IDispatch) (*IUpdateHisto
 
 	return iUpdateHistoryEntry, nil
 }
+
+// Err returns the HResult of the recorded operation as an HRESULT error, or nil
+// if it is zero.
+func (iUpdateHistoryEntry *IUpdateHistoryEntry) Err() error {
+	return HResultError(iUpdateHistoryEntry.HResult)
+}
+
+// Release releases the COM object and those of UpdateIdentity.
+func (iUpdateHistoryEntry *IUpdateHistoryEntry) Release() {
+	if iUpdateHistoryEntry == nil {
+		return
+	}
+	iUpdateHistoryEntry.UpdateIdentity.Release()
+	releaseDispatcher(iUpdateHistoryEntry.disp)
+}
@@ -13,32 +13,35 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateIdentity represents the unique identifier of an update.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdateidentity
 type IUpdateIdentity struct {
-	disp           *ole.IDispatch
+	disp           dispatcher
 	RevisionNumber int32
 	UpdateID       string
 }
 
-func toIUpdateIdentity(updateIdentityDisp *ole.IDispatch) (*IUpdateIdentity, error) {
+func toIUpdateIdentity(updateIdentityDisp dispatcher) (*IUpdateIdentity, error) {
 	var err error
 	iUpdateIdentity := &IUpdateIdentity{
 		disp: updateIdentityDisp,
 	}
 
-	if iUpdateIdentity.RevisionNumber, err = toInt32Err(oleutil.GetProperty(updateIdentityDisp, "RevisionNumber")); err != nil {
+	if iUpdateIdentity.RevisionNumber, err = toInt32Err(updateIdentityDisp.GetProperty("RevisionNumber")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateIdentity.UpdateID, err = toStringErr(oleutil.GetProperty(updateIdentityDisp, "UpdateID")); err != nil {
+	if iUpdateIdentity.UpdateID, err = toStringErr(updateIdentityDisp.GetProperty("UpdateID")); err != nil {
 		return nil, err
 	}
 
 	return iUpdateIdentity, nil
 }
+
+// Release releases the COM object.
+func (iUpdateIdentity *IUpdateIdentity) Release() {
+	if iUpdateIdentity == nil {
+		return
+	}
+	releaseDispatcher(iUpdateIdentity.disp)
+}
@@ -13,21 +13,16 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateInstallationResult represents the result of an installation or uninstallation for an update.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdateinstallationresult
 type IUpdateInstallationResult struct {
-	disp           *ole.IDispatch
+	disp           dispatcher
 	HResult        int32
 	RebootRequired bool
-	ResultCode     int32 // OperationResultCode enum
+	ResultCode     OperationResultCode
 }
 
-func toIUpdateInstallationResult(disp *ole.IDispatch) (*IUpdateInstallationResult, error) {
+func toIUpdateInstallationResult(disp dispatcher) (*IUpdateInstallationResult, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -35,17 +30,31 @@ func toIUpdateInstallationResult(disp *ole.IDispatch) (*IUpdateInstallationResul
 	var err error
 	r := &IUpdateInstallationResult{disp: disp}
 
-	if r.HResult, err = toInt32Err(oleutil.GetProperty(disp, "HResult")); err != nil {
+	if r.HResult, err = toHResultErr(disp.GetProperty("HResult")); err != nil {
 		return nil, err
 	}
 
-	if r.RebootRequired, err = toBoolErr(oleutil.GetProperty(disp, "RebootRequired")); err != nil {
+	if r.RebootRequired, err = toBoolErr(disp.GetProperty("RebootRequired")); err != nil {
 		return nil, err
 	}
 
-	if r.ResultCode, err = toInt32Err(oleutil.GetProperty(disp, "ResultCode")); err != nil {
+	if r.ResultCode, err = toEnumErr[OperationResultCode](disp.GetProperty("ResultCode")); err != nil {
 		return nil, err
 	}
 
 	return r, nil
 }
+
+// Err returns the HResult of the installation or uninstallation of the update
+// as an HRESULT error, or nil if it is zero.
+func (iUpdateInstallationResult *IUpdateInstallationResult) Err() error {
+	return HResultError(iUpdateInstallationResult.HResult)
+}
+
+// Release releases the COM object.
+func (iUpdateInstallationResult *IUpdateInstallationResult) Release() {
+	if iUpdateInstallationResult == nil {
+		return
+	}
+	releaseDispatcher(iUpdateInstallationResult.disp)
+}
@@ -40,7 +40,7 @@ func TestIUpdateInstallationResult_Variants(t *testing.T) {
 	testCases := []struct {
 		name           string
 		hresult        int32
-		resultCode     int32
+		resultCode     OperationResultCode
 		rebootRequired bool
 	}{
 		{"Success_NoReboot", 0, OperationResultCodeOrcSucceeded, false},
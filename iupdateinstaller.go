@@ -14,14 +14,14 @@ limitations under the License.
 package windowsupdate
 
 import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
+	"context"
+	"errors"
 )
 
 // IUpdateInstaller installs or uninstalls updates from or onto a computer.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdateinstaller
 type IUpdateInstaller struct {
-	disp                *ole.IDispatch
+	disp                dispatcher
 	AllowSourcePrompts  bool
 	ClientApplicationID string
 	IsBusy              bool
@@ -33,37 +33,37 @@ type IUpdateInstaller struct {
 	Updates                          []*IUpdate
 }
 
-func toIUpdateInstaller(updateInstallerDisp *ole.IDispatch) (*IUpdateInstaller, error) {
+func toIUpdateInstaller(updateInstallerDisp dispatcher) (*IUpdateInstaller, error) {
 	var err error
 	iUpdateInstaller := &IUpdateInstaller{
 		disp: updateInstallerDisp,
 	}
 
-	if iUpdateInstaller.AllowSourcePrompts, err = toBoolErr(oleutil.GetProperty(updateInstallerDisp, "AllowSourcePrompts")); err != nil {
+	if iUpdateInstaller.AllowSourcePrompts, err = toBoolErr(updateInstallerDisp.GetProperty("AllowSourcePrompts")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateInstaller.ClientApplicationID, err = toStringErr(oleutil.GetProperty(updateInstallerDisp, "ClientApplicationID")); err != nil {
+	if iUpdateInstaller.ClientApplicationID, err = toStringErr(updateInstallerDisp.GetProperty("ClientApplicationID")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateInstaller.IsBusy, err = toBoolErr(oleutil.GetProperty(updateInstallerDisp, "IsBusy")); err != nil {
+	if iUpdateInstaller.IsBusy, err = toBoolErr(updateInstallerDisp.GetProperty("IsBusy")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateInstaller.IsForced, err = toBoolErr(oleutil.GetProperty(updateInstallerDisp, "IsForced")); err != nil {
+	if iUpdateInstaller.IsForced, err = toBoolErr(updateInstallerDisp.GetProperty("IsForced")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateInstaller.ForceQuiet, err = toBoolErr(oleutil.GetProperty(updateInstallerDisp, "ForceQuiet")); err != nil {
+	if iUpdateInstaller.ForceQuiet, err = toBoolErr(updateInstallerDisp.GetProperty("ForceQuiet")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateInstaller.RebootRequiredBeforeInstallation, err = toBoolErr(oleutil.GetProperty(updateInstallerDisp, "RebootRequiredBeforeInstallation")); err != nil {
+	if iUpdateInstaller.RebootRequiredBeforeInstallation, err = toBoolErr(updateInstallerDisp.GetProperty("RebootRequiredBeforeInstallation")); err != nil {
 		return nil, err
 	}
 
-	updatesDisp, err := toIDispatchErr(oleutil.GetProperty(updateInstallerDisp, "Updates"))
+	updatesDisp, err := toIDispatchErr(updateInstallerDisp.GetProperty("Updates"))
 	if err != nil {
 		return nil, err
 	}
@@ -79,15 +79,16 @@ func toIUpdateInstaller(updateInstallerDisp *ole.IDispatch) (*IUpdateInstaller,
 // Install starts a synchronous installation of the updates.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller-install
 func (iUpdateInstaller *IUpdateInstaller) Install(updates []*IUpdate) (*IInstallationResult, error) {
-	updatesDisp, err := toIUpdateCollection(updates)
+	updatesDisp, err := toIUpdateCollection(iUpdateInstaller.disp, updates)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = oleutil.PutProperty(iUpdateInstaller.disp, "Updates", updatesDisp); err != nil {
+	defer updatesDisp.Release()
+	if _, err = iUpdateInstaller.disp.PutProperty("Updates", updatesDisp); err != nil {
 		return nil, err
 	}
 
-	installationResultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateInstaller.disp, "Install"))
+	installationResultDisp, err := toIDispatchErr(iUpdateInstaller.disp.CallMethod("Install"))
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +98,7 @@ func (iUpdateInstaller *IUpdateInstaller) Install(updates []*IUpdate) (*IInstall
 // Finalizes updates that were previously staged or installed.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller4-commit
 func (iUpdateInstaller *IUpdateInstaller) Commit(dwFlags int32) error {
-	_, err := toIDispatchErr(oleutil.CallMethod(iUpdateInstaller.disp, "Commit", dwFlags))
+	_, err := toIDispatchErr(iUpdateInstaller.disp.CallMethod("Commit", dwFlags))
 	if err != nil {
 		return err
 	}
@@ -107,7 +108,7 @@ func (iUpdateInstaller *IUpdateInstaller) Commit(dwFlags int32) error {
 // Sets a Boolean value that indicates whether Windows Installer is forced to install the updates without user interaction.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller2-put_forcequiet
 func (iUpdateInstaller *IUpdateInstaller) PutForceQuiet(value bool) error {
-	_, err := toIDispatchErr(oleutil.PutProperty(iUpdateInstaller.disp, "ForceQuiet", value))
+	_, err := toIDispatchErr(iUpdateInstaller.disp.PutProperty("ForceQuiet", value))
 	if err != nil {
 		return err
 	}
@@ -118,7 +119,7 @@ func (iUpdateInstaller *IUpdateInstaller) PutForceQuiet(value bool) error {
 // Sets a Boolean value that indicates whether to forcibly install or uninstall an update.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller-put_isforced
 func (iUpdateInstaller *IUpdateInstaller) PutIsForced(value bool) error {
-	_, err := toIDispatchErr(oleutil.PutProperty(iUpdateInstaller.disp, "IsForced", value))
+	_, err := toIDispatchErr(iUpdateInstaller.disp.PutProperty("IsForced", value))
 	if err != nil {
 		return err
 	}
@@ -129,80 +130,141 @@ func (iUpdateInstaller *IUpdateInstaller) PutIsForced(value bool) error {
 // Uninstall starts a synchronous uninstallation of the updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller-uninstall
 func (iUpdateInstaller *IUpdateInstaller) Uninstall(updates []*IUpdate) (*IInstallationResult, error) {
-	updatesDisp, err := toIUpdateCollection(updates)
+	updatesDisp, err := toIUpdateCollection(iUpdateInstaller.disp, updates)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = oleutil.PutProperty(iUpdateInstaller.disp, "Updates", updatesDisp); err != nil {
+	defer updatesDisp.Release()
+	if _, err = iUpdateInstaller.disp.PutProperty("Updates", updatesDisp); err != nil {
 		return nil, err
 	}
 
-	uninstallationResultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateInstaller.disp, "Uninstall"))
+	uninstallationResultDisp, err := toIDispatchErr(iUpdateInstaller.disp.CallMethod("Uninstall"))
 	if err != nil {
 		return nil, err
 	}
 	return toIInstallationResult(uninstallationResultDisp)
 }
 
-// BeginInstall begins an asynchronous installation of the updates.
+// BeginInstall begins an asynchronous installation of the updates. WithCallbacks sets the
+// handlers of IInstallationProgressChangedCallback and
+// IInstallationCompletedCallback; other options are ignored.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller-begininstall
-func (iUpdateInstaller *IUpdateInstaller) BeginInstall(updates []*IUpdate) (*IInstallationJob, error) {
-	updatesDisp, err := toIUpdateCollection(updates)
+func (iUpdateInstaller *IUpdateInstaller) BeginInstall(updates []*IUpdate, options ...JobOption) (*IInstallationJob, error) {
+	updatesDisp, err := toIUpdateCollection(iUpdateInstaller.disp, updates)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = oleutil.PutProperty(iUpdateInstaller.disp, "Updates", updatesDisp); err != nil {
+	defer updatesDisp.Release()
+	if _, err = iUpdateInstaller.disp.PutProperty("Updates", updatesDisp); err != nil {
 		return nil, err
 	}
 
-	jobDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateInstaller.disp, "BeginInstall", newNoopCallback(), newNoopCallback(), nil))
+	return iUpdateInstaller.beginJob("BeginInstall", options)
+}
+
+// beginJob calls BeginInstall or BeginUninstall with the callbacks set by options.
+func (iUpdateInstaller *IUpdateInstaller) beginJob(method string, options []JobOption) (*IInstallationJob, error) {
+	callbacks, releaseCallbacks := jobCallbackArgs(CallbacksOf(options...), installationProgressChangedCallback, installationCompletedCallback)
+	jobDisp, err := toIDispatchErr(iUpdateInstaller.disp.CallMethod(method, callbacks[0], callbacks[1], nil))
 	if err != nil {
+		releaseCallbacks()
+		return nil, err
+	}
+	job, err := toIInstallationJob(jobDisp)
+	if err != nil || job == nil {
+		releaseCallbacks()
 		return nil, err
 	}
-	return toIInstallationJob(jobDisp)
+	job.releaseCallbacks = releaseCallbacks
+	return job, nil
 }
 
 // EndInstall completes an asynchronous installation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller-endinstall
 func (iUpdateInstaller *IUpdateInstaller) EndInstall(installationJob *IInstallationJob) (*IInstallationResult, error) {
-	resultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateInstaller.disp, "EndInstall", installationJob.disp))
+	resultDisp, err := toIDispatchErr(iUpdateInstaller.disp.CallMethod("EndInstall", installationJob.disp))
 	if err != nil {
 		return nil, err
 	}
 	return toIInstallationResult(resultDisp)
 }
 
-// BeginUninstall begins an asynchronous uninstallation of the updates.
+// BeginUninstall begins an asynchronous uninstallation of the updates. WithCallbacks sets the
+// handlers of IInstallationProgressChangedCallback and
+// IInstallationCompletedCallback; other options are ignored.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller-beginuninstall
-func (iUpdateInstaller *IUpdateInstaller) BeginUninstall(updates []*IUpdate) (*IInstallationJob, error) {
-	updatesDisp, err := toIUpdateCollection(updates)
+func (iUpdateInstaller *IUpdateInstaller) BeginUninstall(updates []*IUpdate, options ...JobOption) (*IInstallationJob, error) {
+	updatesDisp, err := toIUpdateCollection(iUpdateInstaller.disp, updates)
 	if err != nil {
 		return nil, err
 	}
-	if _, err = oleutil.PutProperty(iUpdateInstaller.disp, "Updates", updatesDisp); err != nil {
+	defer updatesDisp.Release()
+	if _, err = iUpdateInstaller.disp.PutProperty("Updates", updatesDisp); err != nil {
 		return nil, err
 	}
 
-	jobDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateInstaller.disp, "BeginUninstall", newNoopCallback(), newNoopCallback(), nil))
-	if err != nil {
-		return nil, err
-	}
-	return toIInstallationJob(jobDisp)
+	return iUpdateInstaller.beginJob("BeginUninstall", options)
 }
 
 // EndUninstall completes an asynchronous uninstallation.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateinstaller-enduninstall
 func (iUpdateInstaller *IUpdateInstaller) EndUninstall(installationJob *IInstallationJob) (*IInstallationResult, error) {
-	resultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateInstaller.disp, "EndUninstall", installationJob.disp))
+	resultDisp, err := toIDispatchErr(iUpdateInstaller.disp.CallMethod("EndUninstall", installationJob.disp))
 	if err != nil {
 		return nil, err
 	}
 	return toIInstallationResult(resultDisp)
 }
 
+// InstallContext performs Install with BeginInstall, polling the job every poll
+// interval until it completes and then calling EndInstall. Progress is reported
+// as set with WithProgress or WithProgressFunc. If ctx is done first, the
+// installation is aborted with RequestAbort and an *InstallationAbortedError
+// wrapping ctx.Err() is returned with whatever EndInstall reported. The job is
+// cleaned up in every case.
+func (iUpdateInstaller *IUpdateInstaller) InstallContext(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IInstallationResult, error) {
+	return runInstallationJob(ctx, options, func(options ...JobOption) (*IInstallationJob, error) {
+		return iUpdateInstaller.BeginInstall(updates, options...)
+	}, iUpdateInstaller.EndInstall)
+}
+
+// UninstallContext is InstallContext for Uninstall, with BeginUninstall and
+// EndUninstall.
+func (iUpdateInstaller *IUpdateInstaller) UninstallContext(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IInstallationResult, error) {
+	return runInstallationJob(ctx, options, func(options ...JobOption) (*IInstallationJob, error) {
+		return iUpdateInstaller.BeginUninstall(updates, options...)
+	}, iUpdateInstaller.EndUninstall)
+}
+
+func runInstallationJob(ctx context.Context, options []JobOption, begin func(options ...JobOption) (*IInstallationJob, error), end func(*IInstallationJob) (*IInstallationResult, error)) (*IInstallationResult, error) {
+	o := newJobOptions(options)
+	defer o.done()
+	o.notifyCompleted()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	job, err := begin(o.beginOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	defer job.Release()
+	defer job.CleanUp()
+
+	if err := runJob(ctx, job, o, job.progress); err != nil {
+		if !errors.Is(err, ctx.Err()) {
+			return nil, err
+		}
+		aborted := &InstallationAbortedError{Err: err}
+		aborted.Result, _ = end(job)
+		return nil, aborted
+	}
+	return end(job)
+}
+
 // PutAllowSourcePrompts sets whether prompts are allowed during installation.
 func (iUpdateInstaller *IUpdateInstaller) PutAllowSourcePrompts(value bool) error {
-	_, err := oleutil.PutProperty(iUpdateInstaller.disp, "AllowSourcePrompts", value)
+	_, err := iUpdateInstaller.disp.PutProperty("AllowSourcePrompts", value)
 	if err != nil {
 		return err
 	}
@@ -212,10 +274,19 @@ func (iUpdateInstaller *IUpdateInstaller) PutAllowSourcePrompts(value bool) erro
 
 // PutClientApplicationID sets the identifier of the current client application.
 func (iUpdateInstaller *IUpdateInstaller) PutClientApplicationID(value string) error {
-	_, err := oleutil.PutProperty(iUpdateInstaller.disp, "ClientApplicationID", value)
+	_, err := iUpdateInstaller.disp.PutProperty("ClientApplicationID", value)
 	if err != nil {
 		return err
 	}
 	iUpdateInstaller.ClientApplicationID = value
 	return nil
 }
+
+// Release releases the COM object and those of Updates.
+func (iUpdateInstaller *IUpdateInstaller) Release() {
+	if iUpdateInstaller == nil {
+		return
+	}
+	releaseEach(iUpdateInstaller.Updates)
+	releaseDispatcher(iUpdateInstaller.disp)
+}
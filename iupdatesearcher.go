@@ -14,49 +14,54 @@ limitations under the License.
 package windowsupdate
 
 import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ceshihao/windowsupdate/criteria"
 )
 
 // IUpdateSearcher searches for updates on a server.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdatesearcher
 type IUpdateSearcher struct {
-	disp                                *ole.IDispatch
+	disp                                dispatcher
 	CanAutomaticallyUpgradeService      bool
 	ClientApplicationID                 string
 	IncludePotentiallySupersededUpdates bool
 	Online                              bool
-	ServerSelection                     int32
+	ServerSelection                     ServerSelection
 	ServiceID                           string
+
+	updateFields UpdateFields
 }
 
-func toIUpdateSearcher(updateSearcherDisp *ole.IDispatch) (*IUpdateSearcher, error) {
+func toIUpdateSearcher(updateSearcherDisp dispatcher) (*IUpdateSearcher, error) {
 	var err error
 	iUpdateSearcher := &IUpdateSearcher{
 		disp: updateSearcherDisp,
 	}
 
-	if iUpdateSearcher.CanAutomaticallyUpgradeService, err = toBoolErr(oleutil.GetProperty(updateSearcherDisp, "CanAutomaticallyUpgradeService")); err != nil {
+	if iUpdateSearcher.CanAutomaticallyUpgradeService, err = toBoolErr(updateSearcherDisp.GetProperty("CanAutomaticallyUpgradeService")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateSearcher.ClientApplicationID, err = toStringErr(oleutil.GetProperty(updateSearcherDisp, "ClientApplicationID")); err != nil {
+	if iUpdateSearcher.ClientApplicationID, err = toStringErr(updateSearcherDisp.GetProperty("ClientApplicationID")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateSearcher.IncludePotentiallySupersededUpdates, err = toBoolErr(oleutil.GetProperty(updateSearcherDisp, "IncludePotentiallySupersededUpdates")); err != nil {
+	if iUpdateSearcher.IncludePotentiallySupersededUpdates, err = toBoolErr(updateSearcherDisp.GetProperty("IncludePotentiallySupersededUpdates")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateSearcher.Online, err = toBoolErr(oleutil.GetProperty(updateSearcherDisp, "Online")); err != nil {
+	if iUpdateSearcher.Online, err = toBoolErr(updateSearcherDisp.GetProperty("Online")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateSearcher.ServerSelection, err = toInt32Err(oleutil.GetProperty(updateSearcherDisp, "ServerSelection")); err != nil {
+	if iUpdateSearcher.ServerSelection, err = toEnumErr[ServerSelection](updateSearcherDisp.GetProperty("ServerSelection")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateSearcher.ServiceID, err = toStringErr(oleutil.GetProperty(updateSearcherDisp, "ServiceID")); err != nil {
+	if iUpdateSearcher.ServiceID, err = toStringErr(updateSearcherDisp.GetProperty("ServiceID")); err != nil {
 		return nil, err
 	}
 
@@ -66,29 +71,80 @@ func toIUpdateSearcher(updateSearcherDisp *ole.IDispatch) (*IUpdateSearcher, err
 // Search performs a synchronous search for updates. The search uses the search options that are currently configured.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-search
 func (iUpdateSearcher *IUpdateSearcher) Search(criteria string) (*ISearchResult, error) {
-	searchResultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateSearcher.disp, "Search", criteria))
+	searchResultDisp, err := toIDispatchErr(iUpdateSearcher.disp.CallMethod("Search", criteria))
+	if err != nil {
+		return nil, err
+	}
+	return toISearchResultFields(searchResultDisp, iUpdateSearcher.UpdateFields())
+}
+
+// SearchStream performs Search but does not convert the updates found: they
+// are converted one at a time while ranging over ISearchResult.All, and
+// ISearchResult.Updates stays nil. SearchContext and BeginSearch do the same
+// with WithStreamedUpdates.
+func (iUpdateSearcher *IUpdateSearcher) SearchStream(criteria string) (*ISearchResult, error) {
+	searchResultDisp, err := toIDispatchErr(iUpdateSearcher.disp.CallMethod("Search", criteria))
 	if err != nil {
 		return nil, err
 	}
-	return toISearchResult(searchResultDisp)
+	return toISearchResultStream(searchResultDisp, iUpdateSearcher.UpdateFields())
+}
+
+// SearchCriteria performs Search with a criteria built with package criteria.
+func (iUpdateSearcher *IUpdateSearcher) SearchCriteria(expr criteria.Expr) (*ISearchResult, error) {
+	return iUpdateSearcher.Search(expr.String())
 }
 
 // QueryHistory synchronously queries the computer for the history of the update events.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-queryhistory
 func (iUpdateSearcher *IUpdateSearcher) QueryHistory(startIndex int32, count int32) ([]*IUpdateHistoryEntry, error) {
-	updateHistoryEntriesDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateSearcher.disp, "QueryHistory", startIndex, count))
+	updateHistoryEntriesDisp, err := toIDispatchErr(iUpdateSearcher.disp.CallMethod("QueryHistory", startIndex, count))
 	if err != nil {
 		return nil, err
 	}
 	return toIUpdateHistoryEntries(updateHistoryEntriesDisp)
 }
 
+// QueryHistoryContext is QueryHistory reading the entries in pages and
+// checking ctx between them, as WUA offers no asynchronous history query. A
+// negative count reads up to the last entry. If ctx is done first, it returns a
+// *HistoryCanceledError holding the entries read so far.
+func (iUpdateSearcher *IUpdateSearcher) QueryHistoryContext(ctx context.Context, startIndex int32, count int32) ([]*IUpdateHistoryEntry, error) {
+	if startIndex < 0 {
+		return nil, fmt.Errorf("windowsupdate: negative history start index %d", startIndex)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	total, err := iUpdateSearcher.GetTotalHistoryCount()
+	if err != nil {
+		return nil, err
+	}
+	end := total
+	if count >= 0 {
+		end = int32(min(int64(startIndex)+int64(count), int64(total)))
+	}
+
+	entries := []*IUpdateHistoryEntry{}
+	for index := startIndex; index < end; index += historyPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, &HistoryCanceledError{Err: err, Entries: entries}
+		}
+		page, err := iUpdateSearcher.QueryHistory(index, min(historyPageSize, end-index))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+	}
+	return entries, nil
+}
+
 // GetTotalHistoryCount returns the number of update events on the computer.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-gettotalhistorycount
 func (iUpdateSearcher *IUpdateSearcher) GetTotalHistoryCount() (int32, error) {
 	// According to MSDN, this is a method with an [out, retval] parameter
 	// In COM automation through IDispatch, such methods return the value directly
-	return toInt32Err(oleutil.CallMethod(iUpdateSearcher.disp, "GetTotalHistoryCount"))
+	return toInt32Err(iUpdateSearcher.disp.CallMethod("GetTotalHistoryCount"))
 }
 
 // QueryHistoryAll synchronously queries the computer for the history of all update events.
@@ -100,35 +156,110 @@ func (iUpdateSearcher *IUpdateSearcher) QueryHistoryAll() ([]*IUpdateHistoryEntr
 	return iUpdateSearcher.QueryHistory(0, count)
 }
 
-// BeginSearch begins an asynchronous search for updates.
+// SearchContext performs Search with BeginSearch, polling the job until it
+// completes and then calling EndSearch. If ctx is done first, the search is
+// aborted with RequestAbort and a *SearchAbortedError wrapping ctx.Err() is
+// returned with whatever EndSearch reported. The job is cleaned up in every case.
+// A search reports no progress, so WithProgress and WithProgressFunc are ignored.
+func (iUpdateSearcher *IUpdateSearcher) SearchContext(ctx context.Context, criteria string, options ...JobOption) (*ISearchResult, error) {
+	o := newJobOptions(options)
+	o.notifyCompleted()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	job, err := iUpdateSearcher.BeginSearch(criteria, o.beginOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	defer job.Release()
+	defer job.CleanUp()
+
+	if err := runJob(ctx, job, o, nil); err != nil {
+		if !errors.Is(err, ctx.Err()) {
+			return nil, err
+		}
+		aborted := &SearchAbortedError{Err: err}
+		aborted.Result, _ = iUpdateSearcher.EndSearch(job)
+		return nil, aborted
+	}
+	return iUpdateSearcher.EndSearch(job)
+}
+
+// SearchCriteriaContext is SearchContext with a criteria built with package criteria.
+func (iUpdateSearcher *IUpdateSearcher) SearchCriteriaContext(ctx context.Context, expr criteria.Expr, options ...JobOption) (*ISearchResult, error) {
+	return iUpdateSearcher.SearchContext(ctx, expr.String(), options...)
+}
+
+// BeginSearch begins an asynchronous search for updates. WithCallbacks sets the
+// handler of ISearchCompletedCallback and WithStreamedUpdates makes EndSearch
+// return a streamed result; other options are ignored.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-beginsearch
-func (iUpdateSearcher *IUpdateSearcher) BeginSearch(criteria string) (*ISearchJob, error) {
-	jobDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateSearcher.disp, "BeginSearch", criteria, newNoopCallback(), nil))
+func (iUpdateSearcher *IUpdateSearcher) BeginSearch(criteria string, options ...JobOption) (*ISearchJob, error) {
+	o := newJobOptions(options)
+	callbacks, releaseCallbacks := jobCallbackArgs(o.callbacks, searchCompletedCallback)
+	jobDisp, err := toIDispatchErr(iUpdateSearcher.disp.CallMethod("BeginSearch", criteria, callbacks[0], nil))
 	if err != nil {
+		releaseCallbacks()
+		return nil, err
+	}
+	job, err := toISearchJob(jobDisp)
+	if err != nil || job == nil {
+		releaseCallbacks()
 		return nil, err
 	}
-	return toISearchJob(jobDisp)
+	job.releaseCallbacks = releaseCallbacks
+	job.stream = o.stream
+	return job, nil
+}
+
+// BeginSearchCriteria performs BeginSearch with a criteria built with package criteria.
+func (iUpdateSearcher *IUpdateSearcher) BeginSearchCriteria(expr criteria.Expr, options ...JobOption) (*ISearchJob, error) {
+	return iUpdateSearcher.BeginSearch(expr.String(), options...)
 }
 
 // EndSearch completes an asynchronous search.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-endsearch
 func (iUpdateSearcher *IUpdateSearcher) EndSearch(searchJob *ISearchJob) (*ISearchResult, error) {
-	resultDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateSearcher.disp, "EndSearch", searchJob.disp))
+	resultDisp, err := toIDispatchErr(iUpdateSearcher.disp.CallMethod("EndSearch", searchJob.disp))
 	if err != nil {
 		return nil, err
 	}
-	return toISearchResult(resultDisp)
+	if searchJob.stream {
+		return toISearchResultStream(resultDisp, iUpdateSearcher.UpdateFields())
+	}
+	return toISearchResultFields(resultDisp, iUpdateSearcher.UpdateFields())
 }
 
 // EscapeString converts a string into a string that can be used as a literal value in a search criteria string.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesearcher-escapestring
 func (iUpdateSearcher *IUpdateSearcher) EscapeString(unescaped string) (string, error) {
-	return toStringErr(oleutil.CallMethod(iUpdateSearcher.disp, "EscapeString", unescaped))
+	return toStringErr(iUpdateSearcher.disp.CallMethod("EscapeString", unescaped))
+}
+
+// SetUpdateFields selects the properties read for the updates returned by
+// Search and EndSearch. The others can be loaded later with IUpdate.Load.
+func (iUpdateSearcher *IUpdateSearcher) SetUpdateFields(fields UpdateFields) {
+	iUpdateSearcher.updateFields = fields
+}
+
+// UpdateFields returns the properties read for the updates returned by Search
+// and EndSearch, FullUpdateFields unless changed with SetUpdateFields.
+func (iUpdateSearcher *IUpdateSearcher) UpdateFields() UpdateFields {
+	if iUpdateSearcher.updateFields == 0 {
+		return FullUpdateFields
+	}
+	return iUpdateSearcher.updateFields
+}
+
+// EscapeString is the package-level equivalent of IUpdateSearcher.EscapeString.
+// It needs no COM searcher, so it also works outside Windows.
+func EscapeString(unescaped string) string {
+	return criteria.Escape(unescaped)
 }
 
 // PutClientApplicationID sets the identifier of the current client application.
 func (iUpdateSearcher *IUpdateSearcher) PutClientApplicationID(value string) error {
-	_, err := oleutil.PutProperty(iUpdateSearcher.disp, "ClientApplicationID", value)
+	_, err := iUpdateSearcher.disp.PutProperty("ClientApplicationID", value)
 	if err != nil {
 		return err
 	}
@@ -137,8 +268,8 @@ func (iUpdateSearcher *IUpdateSearcher) PutClientApplicationID(value string) err
 }
 
 // PutServerSelection sets the server to search.
-func (iUpdateSearcher *IUpdateSearcher) PutServerSelection(value int32) error {
-	_, err := oleutil.PutProperty(iUpdateSearcher.disp, "ServerSelection", value)
+func (iUpdateSearcher *IUpdateSearcher) PutServerSelection(value ServerSelection) error {
+	_, err := iUpdateSearcher.disp.PutProperty("ServerSelection", int32(value))
 	if err != nil {
 		return err
 	}
@@ -148,7 +279,7 @@ func (iUpdateSearcher *IUpdateSearcher) PutServerSelection(value int32) error {
 
 // PutServiceID sets the ServiceID to search.
 func (iUpdateSearcher *IUpdateSearcher) PutServiceID(value string) error {
-	_, err := oleutil.PutProperty(iUpdateSearcher.disp, "ServiceID", value)
+	_, err := iUpdateSearcher.disp.PutProperty("ServiceID", value)
 	if err != nil {
 		return err
 	}
@@ -158,7 +289,7 @@ func (iUpdateSearcher *IUpdateSearcher) PutServiceID(value string) error {
 
 // PutOnline sets whether to search online.
 func (iUpdateSearcher *IUpdateSearcher) PutOnline(value bool) error {
-	_, err := oleutil.PutProperty(iUpdateSearcher.disp, "Online", value)
+	_, err := iUpdateSearcher.disp.PutProperty("Online", value)
 	if err != nil {
 		return err
 	}
@@ -168,10 +299,18 @@ func (iUpdateSearcher *IUpdateSearcher) PutOnline(value bool) error {
 
 // PutIncludePotentiallySupersededUpdates sets whether to include potentially superseded updates.
 func (iUpdateSearcher *IUpdateSearcher) PutIncludePotentiallySupersededUpdates(value bool) error {
-	_, err := oleutil.PutProperty(iUpdateSearcher.disp, "IncludePotentiallySupersededUpdates", value)
+	_, err := iUpdateSearcher.disp.PutProperty("IncludePotentiallySupersededUpdates", value)
 	if err != nil {
 		return err
 	}
 	iUpdateSearcher.IncludePotentiallySupersededUpdates = value
 	return nil
 }
+
+// Release releases the COM object.
+func (iUpdateSearcher *IUpdateSearcher) Release() {
+	if iUpdateSearcher == nil {
+		return
+	}
+	releaseDispatcher(iUpdateSearcher.disp)
+}
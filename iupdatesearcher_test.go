@@ -202,6 +202,8 @@ func TestIUpdateSearcher_EscapeString(t *testing.T) {
 		"with space",
 		"with'quote",
 		`with"double`,
+		`with\backslash`,
+		`it's a \'mixed\' one`,
 	}
 
 	for _, str := range testStrings {
@@ -212,6 +214,9 @@ func TestIUpdateSearcher_EscapeString(t *testing.T) {
 		if escaped == "" && str != "" {
 			t.Errorf("EscapeString(%q) returned empty string", str)
 		}
+		if offline := EscapeString(str); offline != escaped {
+			t.Errorf("EscapeString(%q) = %q offline, %q from WUA", str, offline, escaped)
+		}
 	}
 }
 
@@ -15,15 +15,12 @@ package windowsupdate
 
 import (
 	"time"
-
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
 )
 
 // IUpdateService contains information about a service that is registered with Windows Update Agent (WUA).
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdateservice
 type IUpdateService struct {
-	disp                  *ole.IDispatch
+	disp                  dispatcher
 	CanRegisterWithAU     bool
 	ContentValidationCert []byte
 	ExpirationDate        *time.Time
@@ -41,7 +38,7 @@ type IUpdateService struct {
 	IsDefaultAUService bool
 }
 
-func toIUpdateService(disp *ole.IDispatch) (*IUpdateService, error) {
+func toIUpdateService(disp dispatcher) (*IUpdateService, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -49,75 +46,76 @@ func toIUpdateService(disp *ole.IDispatch) (*IUpdateService, error) {
 	var err error
 	s := &IUpdateService{disp: disp}
 
-	if s.CanRegisterWithAU, err = toBoolErr(oleutil.GetProperty(disp, "CanRegisterWithAU")); err != nil {
+	if s.CanRegisterWithAU, err = toBoolErr(disp.GetProperty("CanRegisterWithAU")); err != nil {
 		return nil, err
 	}
 
-	if s.IsManaged, err = toBoolErr(oleutil.GetProperty(disp, "IsManaged")); err != nil {
+	if s.IsManaged, err = toBoolErr(disp.GetProperty("IsManaged")); err != nil {
 		return nil, err
 	}
 
-	if s.IsRegisteredWithAU, err = toBoolErr(oleutil.GetProperty(disp, "IsRegisteredWithAU")); err != nil {
+	if s.IsRegisteredWithAU, err = toBoolErr(disp.GetProperty("IsRegisteredWithAU")); err != nil {
 		return nil, err
 	}
 
-	if s.IsScanPackageService, err = toBoolErr(oleutil.GetProperty(disp, "IsScanPackageService")); err != nil {
+	if s.IsScanPackageService, err = toBoolErr(disp.GetProperty("IsScanPackageService")); err != nil {
 		return nil, err
 	}
 
-	if s.IssueDate, err = toTimeErr(oleutil.GetProperty(disp, "IssueDate")); err != nil {
+	if s.IssueDate, err = toTimeErr(disp.GetProperty("IssueDate")); err != nil {
 		return nil, err
 	}
 
-	if s.ExpirationDate, err = toTimeErr(oleutil.GetProperty(disp, "ExpirationDate")); err != nil {
+	if s.ExpirationDate, err = toTimeErr(disp.GetProperty("ExpirationDate")); err != nil {
 		return nil, err
 	}
 
-	if s.Name, err = toStringErr(oleutil.GetProperty(disp, "Name")); err != nil {
+	if s.Name, err = toStringErr(disp.GetProperty("Name")); err != nil {
 		return nil, err
 	}
 
-	if s.OffersWindowsUpdates, err = toBoolErr(oleutil.GetProperty(disp, "OffersWindowsUpdates")); err != nil {
+	if s.OffersWindowsUpdates, err = toBoolErr(disp.GetProperty("OffersWindowsUpdates")); err != nil {
 		return nil, err
 	}
 
-	if s.RedirectUrls, err = iStringCollectionToStringArrayErr(toIDispatchErr(oleutil.GetProperty(disp, "RedirectUrls"))); err != nil {
+	if s.RedirectUrls, err = iStringCollectionToStringArrayErr(toIDispatchErr(disp.GetProperty("RedirectUrls"))); err != nil {
 		return nil, err
 	}
 
-	if s.ServiceID, err = toStringErr(oleutil.GetProperty(disp, "ServiceID")); err != nil {
+	if s.ServiceID, err = toStringErr(disp.GetProperty("ServiceID")); err != nil {
 		return nil, err
 	}
 
-	if s.ServiceUrl, err = toStringErr(oleutil.GetProperty(disp, "ServiceUrl")); err != nil {
+	if s.ServiceUrl, err = toStringErr(disp.GetProperty("ServiceUrl")); err != nil {
 		return nil, err
 	}
 
-	if s.SetupPrefix, err = toStringErr(oleutil.GetProperty(disp, "SetupPrefix")); err != nil {
+	if s.SetupPrefix, err = toStringErr(disp.GetProperty("SetupPrefix")); err != nil {
 		return nil, err
 	}
 
 	// IUpdateService2 property
-	if isDefault, err := toBoolErr(oleutil.GetProperty(disp, "IsDefaultAUService")); err == nil {
+	if isDefault, err := toBoolErr(disp.GetProperty("IsDefaultAUService")); err == nil {
 		s.IsDefaultAUService = isDefault
 	}
 
 	return s, nil
 }
 
-func toIUpdateServices(disp *ole.IDispatch) ([]*IUpdateService, error) {
+func toIUpdateServices(disp dispatcher) ([]*IUpdateService, error) {
 	if disp == nil {
 		return nil, nil
 	}
+	defer disp.Release()
 
-	count, err := toInt32Err(oleutil.GetProperty(disp, "Count"))
+	count, err := toInt32Err(disp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
 
 	services := make([]*IUpdateService, 0, count)
 	for i := int32(0); i < count; i++ {
-		serviceDisp, err := toIDispatchErr(oleutil.GetProperty(disp, "Item", i))
+		serviceDisp, err := toIDispatchErr(disp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
@@ -131,3 +129,11 @@ func toIUpdateServices(disp *ole.IDispatch) ([]*IUpdateService, error) {
 	}
 	return services, nil
 }
+
+// Release releases the COM object.
+func (iUpdateService *IUpdateService) Release() {
+	if iUpdateService == nil {
+		return
+	}
+	releaseDispatcher(iUpdateService.disp)
+}
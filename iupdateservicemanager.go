@@ -13,15 +13,10 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateServiceManager adds or removes the registration of an update service with Windows Update Agent.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdateservicemanager
 type IUpdateServiceManager struct {
-	disp     *ole.IDispatch
+	disp     dispatcher
 	Services []*IUpdateService
 	// IUpdateServiceManager2 properties
 	ClientApplicationID string
@@ -29,12 +24,7 @@ type IUpdateServiceManager struct {
 
 // NewUpdateServiceManager creates a new IUpdateServiceManager instance.
 func NewUpdateServiceManager() (*IUpdateServiceManager, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.ServiceManager")
-	if err != nil {
-		return nil, err
-	}
-
-	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	disp, err := newComObject("Microsoft.Update.ServiceManager")
 	if err != nil {
 		return nil, err
 	}
@@ -42,14 +32,14 @@ func NewUpdateServiceManager() (*IUpdateServiceManager, error) {
 	return toIUpdateServiceManager(disp)
 }
 
-func toIUpdateServiceManager(disp *ole.IDispatch) (*IUpdateServiceManager, error) {
+func toIUpdateServiceManager(disp dispatcher) (*IUpdateServiceManager, error) {
 	if disp == nil {
 		return nil, nil
 	}
 
 	sm := &IUpdateServiceManager{disp: disp}
 
-	servicesDisp, err := toIDispatchErr(oleutil.GetProperty(disp, "Services"))
+	servicesDisp, err := toIDispatchErr(disp.GetProperty("Services"))
 	if err == nil && servicesDisp != nil {
 		services, err := toIUpdateServices(servicesDisp)
 		if err == nil {
@@ -64,7 +54,7 @@ func toIUpdateServiceManager(disp *ole.IDispatch) (*IUpdateServiceManager, error
 	}
 
 	// IUpdateServiceManager2 property
-	if clientAppID, err := toStringErr(oleutil.GetProperty(disp, "ClientApplicationID")); err == nil {
+	if clientAppID, err := toStringErr(disp.GetProperty("ClientApplicationID")); err == nil {
 		sm.ClientApplicationID = clientAppID
 	}
 
@@ -74,7 +64,7 @@ func toIUpdateServiceManager(disp *ole.IDispatch) (*IUpdateServiceManager, error
 // AddService registers a service with Windows Update Agent (WUA).
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager-addservice
 func (sm *IUpdateServiceManager) AddService(serviceID string, authorizationCabPath string) (*IUpdateService, error) {
-	serviceDisp, err := toIDispatchErr(oleutil.CallMethod(sm.disp, "AddService", serviceID, authorizationCabPath))
+	serviceDisp, err := toIDispatchErr(sm.disp.CallMethod("AddService", serviceID, authorizationCabPath))
 	if err != nil {
 		return nil, err
 	}
@@ -84,35 +74,35 @@ func (sm *IUpdateServiceManager) AddService(serviceID string, authorizationCabPa
 // RegisterServiceWithAU registers a service with Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager-registerservicewithau
 func (sm *IUpdateServiceManager) RegisterServiceWithAU(serviceID string) error {
-	_, err := oleutil.CallMethod(sm.disp, "RegisterServiceWithAU", serviceID)
+	_, err := sm.disp.CallMethod("RegisterServiceWithAU", serviceID)
 	return err
 }
 
 // RemoveService removes a service registration from Windows Update Agent (WUA).
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager-removeservice
 func (sm *IUpdateServiceManager) RemoveService(serviceID string) error {
-	_, err := oleutil.CallMethod(sm.disp, "RemoveService", serviceID)
+	_, err := sm.disp.CallMethod("RemoveService", serviceID)
 	return err
 }
 
 // UnregisterServiceWithAU unregisters a service with Automatic Updates.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager-unregisterservicewithau
 func (sm *IUpdateServiceManager) UnregisterServiceWithAU(serviceID string) error {
-	_, err := oleutil.CallMethod(sm.disp, "UnregisterServiceWithAU", serviceID)
+	_, err := sm.disp.CallMethod("UnregisterServiceWithAU", serviceID)
 	return err
 }
 
 // SetOption sets options for the update service manager.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager-setoption
 func (sm *IUpdateServiceManager) SetOption(optionName string, optionValue interface{}) error {
-	_, err := oleutil.CallMethod(sm.disp, "SetOption", optionName, optionValue)
+	_, err := sm.disp.CallMethod("SetOption", optionName, optionValue)
 	return err
 }
 
 // AddScanPackageService registers a scan package service.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager-addscanpackageservice
 func (sm *IUpdateServiceManager) AddScanPackageService(serviceName string, scanFileLocation string, flags int32) (*IUpdateService, error) {
-	serviceDisp, err := toIDispatchErr(oleutil.CallMethod(sm.disp, "AddScanPackageService", serviceName, scanFileLocation, flags))
+	serviceDisp, err := toIDispatchErr(sm.disp.CallMethod("AddScanPackageService", serviceName, scanFileLocation, flags))
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +112,7 @@ func (sm *IUpdateServiceManager) AddScanPackageService(serviceName string, scanF
 // PutClientApplicationID sets the identifier of the current client application. (IUpdateServiceManager2)
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager2-put_clientapplicationid
 func (sm *IUpdateServiceManager) PutClientApplicationID(value string) error {
-	_, err := oleutil.PutProperty(sm.disp, "ClientApplicationID", value)
+	_, err := sm.disp.PutProperty("ClientApplicationID", value)
 	if err != nil {
 		return err
 	}
@@ -132,10 +122,19 @@ func (sm *IUpdateServiceManager) PutClientApplicationID(value string) error {
 
 // AddService2 registers a service with Windows Update Agent (WUA). (IUpdateServiceManager2)
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdateservicemanager2-addservice2
-func (sm *IUpdateServiceManager) AddService2(serviceID string, flags int32, authorizationCabPath string) (*IUpdateServiceRegistration, error) {
-	regDisp, err := toIDispatchErr(oleutil.CallMethod(sm.disp, "AddService2", serviceID, flags, authorizationCabPath))
+func (sm *IUpdateServiceManager) AddService2(serviceID string, flags AddServiceFlag, authorizationCabPath string) (*IUpdateServiceRegistration, error) {
+	regDisp, err := toIDispatchErr(sm.disp.CallMethod("AddService2", serviceID, int32(flags), authorizationCabPath))
 	if err != nil {
 		return nil, err
 	}
 	return toIUpdateServiceRegistration(regDisp)
 }
+
+// Release releases the COM object and those of Services.
+func (sm *IUpdateServiceManager) Release() {
+	if sm == nil {
+		return
+	}
+	releaseEach(sm.Services)
+	releaseDispatcher(sm.disp)
+}
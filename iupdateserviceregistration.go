@@ -13,21 +13,16 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateServiceRegistration represents the registration status of an update service.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdateserviceregistration
 type IUpdateServiceRegistration struct {
-	disp                        *ole.IDispatch
+	disp                        dispatcher
 	IsPendingRegistrationWithAU bool
-	RegistrationState           int32
+	RegistrationState           UpdateServiceRegistrationState
 	Service                     *IUpdateService
 }
 
-func toIUpdateServiceRegistration(disp *ole.IDispatch) (*IUpdateServiceRegistration, error) {
+func toIUpdateServiceRegistration(disp dispatcher) (*IUpdateServiceRegistration, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -35,15 +30,15 @@ func toIUpdateServiceRegistration(disp *ole.IDispatch) (*IUpdateServiceRegistrat
 	var err error
 	reg := &IUpdateServiceRegistration{disp: disp}
 
-	if reg.IsPendingRegistrationWithAU, err = toBoolErr(oleutil.GetProperty(disp, "IsPendingRegistrationWithAU")); err != nil {
+	if reg.IsPendingRegistrationWithAU, err = toBoolErr(disp.GetProperty("IsPendingRegistrationWithAU")); err != nil {
 		return nil, err
 	}
 
-	if reg.RegistrationState, err = toInt32Err(oleutil.GetProperty(disp, "RegistrationState")); err != nil {
+	if reg.RegistrationState, err = toEnumErr[UpdateServiceRegistrationState](disp.GetProperty("RegistrationState")); err != nil {
 		return nil, err
 	}
 
-	serviceDisp, err := toIDispatchErr(oleutil.GetProperty(disp, "Service"))
+	serviceDisp, err := toIDispatchErr(disp.GetProperty("Service"))
 	if err != nil {
 		return nil, err
 	}
@@ -55,3 +50,12 @@ func toIUpdateServiceRegistration(disp *ole.IDispatch) (*IUpdateServiceRegistrat
 
 	return reg, nil
 }
+
+// Release releases the COM object and those of Service.
+func (iUpdateServiceRegistration *IUpdateServiceRegistration) Release() {
+	if iUpdateServiceRegistration == nil {
+		return
+	}
+	iUpdateServiceRegistration.Service.Release()
+	releaseDispatcher(iUpdateServiceRegistration.disp)
+}
@@ -13,36 +13,34 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IUpdateSession represents a session in which the caller can perform operations that involve updates.
 // For example, this interface represents sessions in which the caller performs a search, download, installation, or uninstallation operation.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iupdatesession
 type IUpdateSession struct {
-	disp                *ole.IDispatch
+	disp                dispatcher
 	ClientApplicationID string
 	ReadOnly            bool
 	WebProxy            *IWebProxy
+
+	updateFields UpdateFields
+	arena        *arena
 }
 
-func toIUpdateSession(updateSessionDisp *ole.IDispatch) (*IUpdateSession, error) {
+func toIUpdateSession(updateSessionDisp dispatcher) (*IUpdateSession, error) {
 	var err error
 	iUpdateSession := &IUpdateSession{
 		disp: updateSessionDisp,
 	}
 
-	if iUpdateSession.ClientApplicationID, err = toStringErr(oleutil.GetProperty(updateSessionDisp, "ClientApplicationID")); err != nil {
+	if iUpdateSession.ClientApplicationID, err = toStringErr(updateSessionDisp.GetProperty("ClientApplicationID")); err != nil {
 		return nil, err
 	}
 
-	if iUpdateSession.ReadOnly, err = toBoolErr(oleutil.GetProperty(updateSessionDisp, "ReadOnly")); err != nil {
+	if iUpdateSession.ReadOnly, err = toBoolErr(updateSessionDisp.GetProperty("ReadOnly")); err != nil {
 		return nil, err
 	}
 
-	webProxyDisp, err := toIDispatchErr(oleutil.GetProperty(updateSessionDisp, "WebProxy"))
+	webProxyDisp, err := toIDispatchErr(updateSessionDisp.GetProperty("WebProxy"))
 	if err != nil {
 		return nil, err
 	}
@@ -55,23 +53,98 @@ func toIUpdateSession(updateSessionDisp *ole.IDispatch) (*IUpdateSession, error)
 	return iUpdateSession, nil
 }
 
-// NewUpdateSession creates a new IUpdateSession interface.
-func NewUpdateSession() (*IUpdateSession, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.Session")
+// SessionOption configures NewUpdateSession.
+type SessionOption func(*sessionOptions)
+
+type sessionOptions struct {
+	recorder     *Recorder
+	updateFields UpdateFields
+	tracker      *LeakTracker
+}
+
+// WithRecorder records every property read and method call made through the
+// session, and through every object obtained from it, into recorder.
+func WithRecorder(recorder *Recorder) SessionOption {
+	return func(o *sessionOptions) {
+		o.recorder = recorder
+	}
+}
+
+// WithUpdateFields sets the properties read for the updates found by the
+// searchers of the session. See IUpdateSearcher.SetUpdateFields.
+func WithUpdateFields(fields UpdateFields) SessionOption {
+	return func(o *sessionOptions) {
+		o.updateFields = fields
+	}
+}
+
+// WithLeakTracker records the objects obtained through the session in tracker
+// until they are released.
+func WithLeakTracker(tracker *LeakTracker) SessionOption {
+	return func(o *sessionOptions) {
+		o.tracker = tracker
+	}
+}
+
+// NewUpdateSession creates a new IUpdateSession interface. Close it to release
+// every COM object obtained through it.
+func NewUpdateSession(options ...SessionOption) (*IUpdateSession, error) {
+	var o sessionOptions
+	for _, option := range options {
+		option(&o)
+	}
+
+	disp, err := newComObject("Microsoft.Update.Session")
 	if err != nil {
 		return nil, err
 	}
-	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	return newIUpdateSession(disp, o)
+}
+
+// newIUpdateSession converts the session object disp, owning it and everything
+// obtained through it in a new arena.
+func newIUpdateSession(disp dispatcher, o sessionOptions) (*IUpdateSession, error) {
+	if o.recorder != nil {
+		disp = o.recorder.wrap(disp)
+	}
+	a := newArena(o.tracker)
+	session, err := toIUpdateSession(a.wrap(disp, "IUpdateSession", nil))
 	if err != nil {
+		a.releaseAll()
 		return nil, err
 	}
-	return toIUpdateSession(disp)
+	session.updateFields = o.updateFields
+	session.arena = a
+	return session, nil
+}
+
+// Close releases the session and every COM object obtained through it: the
+// searchers, downloaders and installers it created and all the updates,
+// categories, results and collections they returned. None of them can be used
+// afterwards. Close is safe to call more than once.
+func (iUpdateSession *IUpdateSession) Close() error {
+	if iUpdateSession.arena != nil {
+		iUpdateSession.arena.releaseAll()
+		return nil
+	}
+	iUpdateSession.Release()
+	return nil
+}
+
+// Release releases the session object and its WebProxy. Objects obtained
+// through the session are only released by Close or their own Release.
+func (iUpdateSession *IUpdateSession) Release() {
+	if iUpdateSession == nil {
+		return
+	}
+	iUpdateSession.WebProxy.Release()
+	releaseDispatcher(iUpdateSession.disp)
 }
 
 // CreateUpdateDownloader returns an IUpdateDownloader interface for this session.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesession-createupdatedownloader
 func (iUpdateSession *IUpdateSession) CreateUpdateDownloader() (*IUpdateDownloader, error) {
-	updateDownloaderDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateSession.disp, "CreateUpdateDownloader"))
+	updateDownloaderDisp, err := toIDispatchErr(iUpdateSession.disp.CallMethod("CreateUpdateDownloader"))
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +154,7 @@ func (iUpdateSession *IUpdateSession) CreateUpdateDownloader() (*IUpdateDownload
 // CreateUpdateInstaller returns an IUpdateInstaller interface for this session.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesession-createupdateinstaller
 func (iUpdateSession *IUpdateSession) CreateUpdateInstaller() (*IUpdateInstaller, error) {
-	updateInstallerDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateSession.disp, "CreateUpdateInstaller"))
+	updateInstallerDisp, err := toIDispatchErr(iUpdateSession.disp.CallMethod("CreateUpdateInstaller"))
 	if err != nil {
 		return nil, err
 	}
@@ -91,10 +164,42 @@ func (iUpdateSession *IUpdateSession) CreateUpdateInstaller() (*IUpdateInstaller
 // CreateUpdateSearcher returns an IUpdateSearcher interface for this session.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iupdatesession-createupdatesearcher
 func (iUpdateSession *IUpdateSession) CreateUpdateSearcher() (*IUpdateSearcher, error) {
-	updateSearcherDisp, err := toIDispatchErr(oleutil.CallMethod(iUpdateSession.disp, "CreateUpdateSearcher"))
+	updateSearcherDisp, err := toIDispatchErr(iUpdateSession.disp.CallMethod("CreateUpdateSearcher"))
 	if err != nil {
 		return nil, err
 	}
 
-	return toIUpdateSearcher(updateSearcherDisp)
+	searcher, err := toIUpdateSearcher(updateSearcherDisp)
+	if err != nil {
+		return nil, err
+	}
+	searcher.updateFields = iUpdateSession.updateFields
+	return searcher, nil
+}
+
+// CreateSearcher is CreateUpdateSearcher returning the Searcher interface.
+func (iUpdateSession *IUpdateSession) CreateSearcher() (Searcher, error) {
+	searcher, err := iUpdateSession.CreateUpdateSearcher()
+	if err != nil {
+		return nil, err
+	}
+	return searcher, nil
+}
+
+// CreateDownloader is CreateUpdateDownloader returning the Downloader interface.
+func (iUpdateSession *IUpdateSession) CreateDownloader() (Downloader, error) {
+	downloader, err := iUpdateSession.CreateUpdateDownloader()
+	if err != nil {
+		return nil, err
+	}
+	return downloader, nil
+}
+
+// CreateInstaller is CreateUpdateInstaller returning the Installer interface.
+func (iUpdateSession *IUpdateSession) CreateInstaller() (Installer, error) {
+	installer, err := iUpdateSession.CreateUpdateInstaller()
+	if err != nil {
+		return nil, err
+	}
+	return installer, nil
 }
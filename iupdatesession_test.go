@@ -75,7 +75,7 @@ func TestToIUpdateSession(t *testing.T) {
 	}
 	defer disp.Release()
 
-	session, err := toIUpdateSession(disp)
+	session, err := toIUpdateSession(newComDispatcher(disp))
 	if err != nil {
 		t.Fatalf("toIUpdateSession failed: %v", err)
 	}
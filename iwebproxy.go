@@ -13,15 +13,10 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IWebProxy contains the HTTP proxy settings.
 // https://docs.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iwebproxy
 type IWebProxy struct {
-	disp               *ole.IDispatch
+	disp               dispatcher
 	Address            string
 	AutoDetect         bool
 	BypassList         []string
@@ -30,21 +25,21 @@ type IWebProxy struct {
 	UserName           string
 }
 
-func toIWebProxy(webProxyDisp *ole.IDispatch) (*IWebProxy, error) {
+func toIWebProxy(webProxyDisp dispatcher) (*IWebProxy, error) {
 	var err error
 	iWebProxy := &IWebProxy{
 		disp: webProxyDisp,
 	}
 
-	if iWebProxy.Address, err = toStringErr(oleutil.GetProperty(webProxyDisp, "Address")); err != nil {
+	if iWebProxy.Address, err = toStringErr(webProxyDisp.GetProperty("Address")); err != nil {
 		return nil, err
 	}
 
-	if iWebProxy.AutoDetect, err = toBoolErr(oleutil.GetProperty(webProxyDisp, "AutoDetect")); err != nil {
+	if iWebProxy.AutoDetect, err = toBoolErr(webProxyDisp.GetProperty("AutoDetect")); err != nil {
 		return nil, err
 	}
 
-	bypassListDisp, err := toIDispatchErr(oleutil.GetProperty(webProxyDisp, "BypassList"))
+	bypassListDisp, err := toIDispatchErr(webProxyDisp.GetProperty("BypassList"))
 	if err != nil {
 		return nil, err
 	}
@@ -54,17 +49,25 @@ func toIWebProxy(webProxyDisp *ole.IDispatch) (*IWebProxy, error) {
 		}
 	}
 
-	if iWebProxy.BypassProxyOnLocal, err = toBoolErr(oleutil.GetProperty(webProxyDisp, "BypassProxyOnLocal")); err != nil {
+	if iWebProxy.BypassProxyOnLocal, err = toBoolErr(webProxyDisp.GetProperty("BypassProxyOnLocal")); err != nil {
 		return nil, err
 	}
 
-	if iWebProxy.ReadOnly, err = toBoolErr(oleutil.GetProperty(webProxyDisp, "ReadOnly")); err != nil {
+	if iWebProxy.ReadOnly, err = toBoolErr(webProxyDisp.GetProperty("ReadOnly")); err != nil {
 		return nil, err
 	}
 
-	if iWebProxy.UserName, err = toStringErr(oleutil.GetProperty(webProxyDisp, "UserName")); err != nil {
+	if iWebProxy.UserName, err = toStringErr(webProxyDisp.GetProperty("UserName")); err != nil {
 		return nil, err
 	}
 
 	return iWebProxy, nil
 }
+
+// Release releases the COM object.
+func (iWebProxy *IWebProxy) Release() {
+	if iWebProxy == nil {
+		return
+	}
+	releaseDispatcher(iWebProxy.disp)
+}
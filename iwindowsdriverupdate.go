@@ -15,9 +15,6 @@ package windowsupdate
 
 import (
 	"time"
-
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
 )
 
 // IWindowsDriverUpdate contains the properties and methods available to an update that installs a Windows driver.
@@ -40,8 +37,8 @@ type IWindowsDriverUpdate struct {
 	// IWindowsDriverUpdate4 properties
 	WindowsDriverUpdateEntries []*IWindowsDriverUpdateEntry
 	// IWindowsDriverUpdate5 properties
-	AutoDownload2  int32
-	AutoSelection2 int32
+	AutoDownload2  AutoDownloadMode
+	AutoSelection2 AutoSelectionMode
 }
 
 // ToWindowsDriverUpdate attempts to cast an IUpdate to IWindowsDriverUpdate.
@@ -52,40 +49,40 @@ func (u *IUpdate) ToWindowsDriverUpdate() (*IWindowsDriverUpdate, error) {
 	var err error
 
 	// Try to get driver-specific properties
-	if wdu.DeviceProblemNumber, err = toInt32Err(oleutil.GetProperty(u.disp, "DeviceProblemNumber")); err != nil {
+	if wdu.DeviceProblemNumber, err = toInt32Err(u.disp.GetProperty("DeviceProblemNumber")); err != nil {
 		return nil, nil // Not a driver update
 	}
 
-	if wdu.DeviceStatus, err = toInt32Err(oleutil.GetProperty(u.disp, "DeviceStatus")); err != nil {
+	if wdu.DeviceStatus, err = toInt32Err(u.disp.GetProperty("DeviceStatus")); err != nil {
 		return nil, err
 	}
 
-	if wdu.DriverClass, err = toStringErr(oleutil.GetProperty(u.disp, "DriverClass")); err != nil {
+	if wdu.DriverClass, err = toStringErr(u.disp.GetProperty("DriverClass")); err != nil {
 		return nil, err
 	}
 
-	if wdu.DriverHardwareID, err = toStringErr(oleutil.GetProperty(u.disp, "DriverHardwareID")); err != nil {
+	if wdu.DriverHardwareID, err = toStringErr(u.disp.GetProperty("DriverHardwareID")); err != nil {
 		return nil, err
 	}
 
-	if wdu.DriverManufacturer, err = toStringErr(oleutil.GetProperty(u.disp, "DriverManufacturer")); err != nil {
+	if wdu.DriverManufacturer, err = toStringErr(u.disp.GetProperty("DriverManufacturer")); err != nil {
 		return nil, err
 	}
 
-	if wdu.DriverModel, err = toStringErr(oleutil.GetProperty(u.disp, "DriverModel")); err != nil {
+	if wdu.DriverModel, err = toStringErr(u.disp.GetProperty("DriverModel")); err != nil {
 		return nil, err
 	}
 
-	if wdu.DriverProvider, err = toStringErr(oleutil.GetProperty(u.disp, "DriverProvider")); err != nil {
+	if wdu.DriverProvider, err = toStringErr(u.disp.GetProperty("DriverProvider")); err != nil {
 		return nil, err
 	}
 
-	if wdu.DriverVerDate, err = toTimeErr(oleutil.GetProperty(u.disp, "DriverVerDate")); err != nil {
+	if wdu.DriverVerDate, err = toTimeErr(u.disp.GetProperty("DriverVerDate")); err != nil {
 		return nil, err
 	}
 
 	// IWindowsDriverUpdate4 - WindowsDriverUpdateEntries
-	entriesDisp, err := toIDispatchErr(oleutil.GetProperty(u.disp, "WindowsDriverUpdateEntries"))
+	entriesDisp, err := toIDispatchErr(u.disp.GetProperty("WindowsDriverUpdateEntries"))
 	if err == nil && entriesDisp != nil {
 		wdu.WindowsDriverUpdateEntries, _ = toIWindowsDriverUpdateEntries(entriesDisp)
 	}
@@ -96,7 +93,7 @@ func (u *IUpdate) ToWindowsDriverUpdate() (*IWindowsDriverUpdate, error) {
 // IWindowsDriverUpdateEntry contains the properties that are available to a Windows driver update entry.
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iwindowsdriverupdateentry
 type IWindowsDriverUpdateEntry struct {
-	disp                *ole.IDispatch
+	disp                dispatcher
 	DeviceProblemNumber int32
 	DeviceStatus        int32
 	DriverClass         string
@@ -107,7 +104,7 @@ type IWindowsDriverUpdateEntry struct {
 	DriverVerDate       *time.Time
 }
 
-func toIWindowsDriverUpdateEntry(disp *ole.IDispatch) (*IWindowsDriverUpdateEntry, error) {
+func toIWindowsDriverUpdateEntry(disp dispatcher) (*IWindowsDriverUpdateEntry, error) {
 	if disp == nil {
 		return nil, nil
 	}
@@ -115,54 +112,55 @@ func toIWindowsDriverUpdateEntry(disp *ole.IDispatch) (*IWindowsDriverUpdateEntr
 	var err error
 	entry := &IWindowsDriverUpdateEntry{disp: disp}
 
-	if entry.DeviceProblemNumber, err = toInt32Err(oleutil.GetProperty(disp, "DeviceProblemNumber")); err != nil {
+	if entry.DeviceProblemNumber, err = toInt32Err(disp.GetProperty("DeviceProblemNumber")); err != nil {
 		return nil, err
 	}
 
-	if entry.DeviceStatus, err = toInt32Err(oleutil.GetProperty(disp, "DeviceStatus")); err != nil {
+	if entry.DeviceStatus, err = toInt32Err(disp.GetProperty("DeviceStatus")); err != nil {
 		return nil, err
 	}
 
-	if entry.DriverClass, err = toStringErr(oleutil.GetProperty(disp, "DriverClass")); err != nil {
+	if entry.DriverClass, err = toStringErr(disp.GetProperty("DriverClass")); err != nil {
 		return nil, err
 	}
 
-	if entry.DriverHardwareID, err = toStringErr(oleutil.GetProperty(disp, "DriverHardwareID")); err != nil {
+	if entry.DriverHardwareID, err = toStringErr(disp.GetProperty("DriverHardwareID")); err != nil {
 		return nil, err
 	}
 
-	if entry.DriverManufacturer, err = toStringErr(oleutil.GetProperty(disp, "DriverManufacturer")); err != nil {
+	if entry.DriverManufacturer, err = toStringErr(disp.GetProperty("DriverManufacturer")); err != nil {
 		return nil, err
 	}
 
-	if entry.DriverModel, err = toStringErr(oleutil.GetProperty(disp, "DriverModel")); err != nil {
+	if entry.DriverModel, err = toStringErr(disp.GetProperty("DriverModel")); err != nil {
 		return nil, err
 	}
 
-	if entry.DriverProvider, err = toStringErr(oleutil.GetProperty(disp, "DriverProvider")); err != nil {
+	if entry.DriverProvider, err = toStringErr(disp.GetProperty("DriverProvider")); err != nil {
 		return nil, err
 	}
 
-	if entry.DriverVerDate, err = toTimeErr(oleutil.GetProperty(disp, "DriverVerDate")); err != nil {
+	if entry.DriverVerDate, err = toTimeErr(disp.GetProperty("DriverVerDate")); err != nil {
 		return nil, err
 	}
 
 	return entry, nil
 }
 
-func toIWindowsDriverUpdateEntries(disp *ole.IDispatch) ([]*IWindowsDriverUpdateEntry, error) {
+func toIWindowsDriverUpdateEntries(disp dispatcher) ([]*IWindowsDriverUpdateEntry, error) {
 	if disp == nil {
 		return nil, nil
 	}
+	defer disp.Release()
 
-	count, err := toInt32Err(oleutil.GetProperty(disp, "Count"))
+	count, err := toInt32Err(disp.GetProperty("Count"))
 	if err != nil {
 		return nil, err
 	}
 
 	entries := make([]*IWindowsDriverUpdateEntry, 0, count)
 	for i := int32(0); i < count; i++ {
-		entryDisp, err := toIDispatchErr(oleutil.GetProperty(disp, "Item", i))
+		entryDisp, err := toIDispatchErr(disp.GetProperty("Item", i))
 		if err != nil {
 			return nil, err
 		}
@@ -176,3 +174,21 @@ func toIWindowsDriverUpdateEntries(disp *ole.IDispatch) ([]*IWindowsDriverUpdate
 	}
 	return entries, nil
 }
+
+// Release releases the COM object.
+func (iWindowsDriverUpdateEntry *IWindowsDriverUpdateEntry) Release() {
+	if iWindowsDriverUpdateEntry == nil {
+		return
+	}
+	releaseDispatcher(iWindowsDriverUpdateEntry.disp)
+}
+
+// Release releases the COM object and those of the update and of
+// WindowsDriverUpdateEntries.
+func (u *IWindowsDriverUpdate) Release() {
+	if u == nil {
+		return
+	}
+	releaseEach(u.WindowsDriverUpdateEntries)
+	u.IUpdate.Release()
+}
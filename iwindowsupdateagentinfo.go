@@ -13,25 +13,15 @@ limitations under the License.
 
 package windowsupdate
 
-import (
-	"github.com/go-ole/go-ole"
-	"github.com/go-ole/go-ole/oleutil"
-)
-
 // IWindowsUpdateAgentInfo retrieves information about the version of Windows Update Agent (WUA).
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nn-wuapi-iwindowsupdateagentinfo
 type IWindowsUpdateAgentInfo struct {
-	disp *ole.IDispatch
+	disp dispatcher
 }
 
 // NewWindowsUpdateAgentInfo creates a new IWindowsUpdateAgentInfo instance.
 func NewWindowsUpdateAgentInfo() (*IWindowsUpdateAgentInfo, error) {
-	unknown, err := oleutil.CreateObject("Microsoft.Update.AgentInfo")
-	if err != nil {
-		return nil, err
-	}
-
-	disp, err := unknown.QueryInterface(ole.IID_IDispatch)
+	disp, err := newComObject("Microsoft.Update.AgentInfo")
 	if err != nil {
 		return nil, err
 	}
@@ -43,11 +33,11 @@ func NewWindowsUpdateAgentInfo() (*IWindowsUpdateAgentInfo, error) {
 // varInfoIdentifier can be one of: "ApiMajorVersion", "ApiMinorVersion", "ProductVersionString"
 // https://learn.microsoft.com/en-us/windows/win32/api/wuapi/nf-wuapi-iwindowsupdateagentinfo-getinfo
 func (info *IWindowsUpdateAgentInfo) GetInfo(varInfoIdentifier string) (interface{}, error) {
-	result, err := oleutil.CallMethod(info.disp, "GetInfo", varInfoIdentifier)
+	result, err := info.disp.CallMethod("GetInfo", varInfoIdentifier)
 	if err != nil {
 		return nil, err
 	}
-	return result.Value(), nil
+	return result.Value, nil
 }
 
 // GetApiMajorVersion returns the major version of the WUA API.
@@ -99,3 +89,11 @@ func (info *IWindowsUpdateAgentInfo) GetProductVersionString() (string, error) {
 	}
 	return "", nil
 }
+
+// Release releases the COM object.
+func (info *IWindowsUpdateAgentInfo) Release() {
+	if info == nil {
+		return
+	}
+	releaseDispatcher(info.disp)
+}
@@ -0,0 +1,358 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-ole/go-ole"
+)
+
+// ErrReleased is returned by the properties and methods of a COM object after
+// it has been released.
+var ErrReleased = errors.New("windowsupdate: object released")
+
+// arena owns every object obtained through a session, including the
+// collections read while converting results, so that IUpdateSession.Close can
+// release them in one call.
+type arena struct {
+	mu      sync.Mutex
+	next    int
+	objects map[int]*arenaDispatcher
+	tracker *LeakTracker
+}
+
+func newArena(tracker *LeakTracker) *arena {
+	return &arena{objects: make(map[int]*arenaDispatcher), tracker: tracker}
+}
+
+// wrap registers d, reached through path from parent, with the arena. parent
+// is nil for the session and the objects it creates.
+func (a *arena) wrap(d dispatcher, path string, parent *arenaDispatcher) dispatcher {
+	if d == nil {
+		return nil
+	}
+	if owned, ok := d.(*arenaDispatcher); ok && owned.arena == a {
+		return owned
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.next++
+	owned := &arenaDispatcher{inner: d, arena: a, id: a.next, path: path, parent: parent}
+	a.objects[owned.id] = owned
+	if a.tracker != nil {
+		owned.trackerID = a.tracker.track(path)
+	}
+	return owned
+}
+
+// wrapResult registers the object held by result, if any.
+func (a *arena) wrapResult(result *variant, path string, parent *arenaDispatcher) *variant {
+	if result == nil {
+		return nil
+	}
+	if d, ok := result.Value.(dispatcher); ok {
+		return &variant{VT: result.VT, Value: a.wrap(d, path, parent), Name: result.Name}
+	}
+	return result
+}
+
+// release releases d unless it already is, and reports whether it did.
+func (a *arena) release(d *arenaDispatcher) bool {
+	a.mu.Lock()
+	if _, ok := a.objects[d.id]; !ok {
+		a.mu.Unlock()
+		return false
+	}
+	delete(a.objects, d.id)
+	if a.tracker != nil {
+		a.tracker.untrack(d.trackerID)
+	}
+	a.mu.Unlock()
+	d.inner.Release()
+	return true
+}
+
+// releaseTree releases d and every object obtained through it, directly or
+// not, that is still live, the most recent first.
+func (a *arena) releaseTree(d *arenaDispatcher) {
+	a.mu.Lock()
+	var descendants []*arenaDispatcher
+	for _, object := range a.objects {
+		if object.descends(d) {
+			descendants = append(descendants, object)
+		}
+	}
+	a.mu.Unlock()
+
+	sort.Slice(descendants, func(i, j int) bool { return descendants[i].id > descendants[j].id })
+	for _, object := range descendants {
+		a.release(object)
+	}
+	a.release(d)
+}
+
+// releaseAll releases every object not released yet, the most recent first, and
+// returns how many there were.
+func (a *arena) releaseAll() int {
+	a.mu.Lock()
+	ids := make([]int, 0, len(a.objects))
+	for id := range a.objects {
+		ids = append(ids, id)
+	}
+	objects := a.objects
+	a.mu.Unlock()
+
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	released := 0
+	for _, id := range ids {
+		if a.release(objects[id]) {
+			released++
+		}
+	}
+	return released
+}
+
+// mark returns a position in the arena for releaseSince.
+func (a *arena) mark() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.next
+}
+
+// releaseSince releases the objects registered after mark that are still live,
+// the most recent first.
+func (a *arena) releaseSince(mark int) {
+	a.mu.Lock()
+	var ids []int
+	for id := range a.objects {
+		if id > mark {
+			ids = append(ids, id)
+		}
+	}
+	objects := a.objects
+	a.mu.Unlock()
+
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	for _, id := range ids {
+		a.release(objects[id])
+	}
+}
+
+// live returns the number of objects not released yet.
+func (a *arena) live() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.objects)
+}
+
+// arenaDispatcher forwards to inner and registers the objects it returns with
+// its arena.
+type arenaDispatcher struct {
+	inner     dispatcher
+	arena     *arena
+	id        int
+	trackerID int
+	path      string
+	// parent is the object this one was obtained through, if any.
+	parent *arenaDispatcher
+}
+
+// descends reports whether d was obtained through ancestor, directly or not.
+func (d *arenaDispatcher) descends(ancestor *arenaDispatcher) bool {
+	for p := d.parent; p != nil; p = p.parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *arenaDispatcher) released() bool {
+	d.arena.mu.Lock()
+	defer d.arena.mu.Unlock()
+	_, ok := d.arena.objects[d.id]
+	return !ok
+}
+
+func (d *arenaDispatcher) GetProperty(name string, params ...interface{}) (*variant, error) {
+	if d.released() {
+		return nil, ErrReleased
+	}
+	result, err := d.inner.GetProperty(name, arenaParams(params)...)
+	if err != nil {
+		return nil, err
+	}
+	return d.arena.wrapResult(result, d.path+"."+memberPath(name, params, false), d), nil
+}
+
+func (d *arenaDispatcher) PutProperty(name string, params ...interface{}) (*variant, error) {
+	if d.released() {
+		return nil, ErrReleased
+	}
+	return d.inner.PutProperty(name, arenaParams(params)...)
+}
+
+func (d *arenaDispatcher) CallMethod(name string, params ...interface{}) (*variant, error) {
+	if d.released() {
+		return nil, ErrReleased
+	}
+	result, err := d.inner.CallMethod(name, arenaParams(params)...)
+	if err != nil {
+		return nil, err
+	}
+	return d.arena.wrapResult(result, d.path+"."+memberPath(name, params, true), d), nil
+}
+
+func (d *arenaDispatcher) CreateObject(programID string) (dispatcher, error) {
+	created, err := d.inner.CreateObject(programID)
+	if err != nil {
+		return nil, err
+	}
+	return d.arena.wrap(created, programID, nil), nil
+}
+
+func (d *arenaDispatcher) IDispatch() *ole.IDispatch {
+	return d.inner.IDispatch()
+}
+
+func (d *arenaDispatcher) Release() {
+	d.arena.release(d)
+}
+
+// arenaParams replaces the objects of the arena passed as arguments with the
+// dispatchers they wrap, which the inner dispatcher knows.
+func arenaParams(params []interface{}) []interface{} {
+	converted := make([]interface{}, len(params))
+	for i, param := range params {
+		if owned, ok := param.(*arenaDispatcher); ok {
+			converted[i] = owned.inner
+			continue
+		}
+		converted[i] = param
+	}
+	return converted
+}
+
+// memberPath describes a member access for LeakTracker, e.g. "Item(0)". Only
+// integer and string arguments are shown.
+func memberPath(name string, params []interface{}, method bool) string {
+	if len(params) == 0 && !method {
+		return name
+	}
+	args := make([]string, len(params))
+	for i, param := range params {
+		switch p := param.(type) {
+		case int, int32, int64:
+			args[i] = fmt.Sprint(p)
+		case string:
+			args[i] = fmt.Sprintf("%q", p)
+		default:
+			args[i] = "?"
+		}
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// TrackedObject is a COM object a LeakTracker saw obtained and not released.
+type TrackedObject struct {
+	// ID orders the objects by the time they were obtained.
+	ID int
+	// Path is how the object was reached from its session, e.g.
+	// "IUpdateSession.CreateUpdateSearcher().Search(\"IsInstalled=0\").Updates".
+	Path string
+}
+
+// LeakTracker is a debugging aid recording the COM objects obtained through the
+// sessions created with WithLeakTracker that have not been released yet, by
+// Release or IUpdateSession.Close. It is safe for concurrent use.
+type LeakTracker struct {
+	mu   sync.Mutex
+	next int
+	live map[int]string
+}
+
+// NewLeakTracker returns a tracker with no objects.
+func NewLeakTracker() *LeakTracker {
+	return &LeakTracker{live: make(map[int]string)}
+}
+
+func (t *LeakTracker) track(path string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	t.live[t.next] = path
+	return t.next
+}
+
+func (t *LeakTracker) untrack(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.live, id)
+}
+
+// Unreleased returns the objects not released yet, oldest first.
+func (t *LeakTracker) Unreleased() []TrackedObject {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	objects := make([]TrackedObject, 0, len(t.live))
+	for id, path := range t.live {
+		objects = append(objects, TrackedObject{ID: id, Path: path})
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].ID < objects[j].ID })
+	return objects
+}
+
+// Err returns an error listing the objects not released yet, or nil if there
+// are none.
+func (t *LeakTracker) Err() error {
+	objects := t.Unreleased()
+	if len(objects) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "windowsupdate: %d COM objects not released:", len(objects))
+	for _, object := range objects {
+		fmt.Fprintf(&b, "\n\t#%d %s", object.ID, object.Path)
+	}
+	return errors.New(b.String())
+}
+
+// releaseDispatcher releases d if it is set; wrappers built by hand have none.
+func releaseDispatcher(d dispatcher) {
+	if d != nil {
+		d.Release()
+	}
+}
+
+// releaseTree releases d and, for an object of a session, the objects obtained
+// through it that are still live, e.g. the children read while converting it.
+func releaseTree(d dispatcher) {
+	if owned, ok := d.(*arenaDispatcher); ok {
+		owned.arena.releaseTree(owned)
+		return
+	}
+	releaseDispatcher(d)
+}
+
+// releaseEach releases every object of a slice field.
+func releaseEach[T interface{ Release() }](objects []T) {
+	for _, object := range objects {
+		object.Release()
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIUpdateSession_Close(t *testing.T) {
+	tracker := NewLeakTracker()
+	update := newFakeUpdate(nil)
+	root := newFakeSession(update)
+	session, err := newIUpdateSession(root, sessionOptions{tracker: tracker})
+	if err != nil {
+		t.Fatalf("newIUpdateSession failed: %v", err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+	result, err := searcher.Search("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	paths := map[string]bool{}
+	for _, object := range tracker.Unreleased() {
+		paths[object.Path] = true
+	}
+	for _, path := range []string{
+		"IUpdateSession",
+		"IUpdateSession.CreateUpdateSearcher()",
+		`IUpdateSession.CreateUpdateSearcher().Search("IsInstalled=0")`,
+		`IUpdateSession.CreateUpdateSearcher().Search("IsInstalled=0").Updates.Item(0)`,
+	} {
+		if !paths[path] {
+			t.Errorf("%s not tracked; tracked %v", path, paths)
+		}
+	}
+	if paths[`IUpdateSession.CreateUpdateSearcher().Search("IsInstalled=0").Updates`] {
+		t.Error("the Updates collection was not released after conversion")
+	}
+
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := tracker.Err(); err != nil {
+		t.Errorf("objects left after Close: %v", err)
+	}
+	if root.Releases() != 1 || update.Releases() != 1 {
+		t.Errorf("session released %d times, update %d times; want once each", root.Releases(), update.Releases())
+	}
+	if _, err := searcher.Search("IsInstalled=0"); err != ErrReleased {
+		t.Errorf("Search after Close error = %v, want ErrReleased", err)
+	}
+	if err := result.Updates[0].AcceptEula(); err != ErrReleased {
+		t.Errorf("AcceptEula after Close error = %v, want ErrReleased", err)
+	}
+	if err := session.Close(); err != nil || root.Releases() != 1 {
+		t.Errorf("second Close = %v, session released %d times", err, root.Releases())
+	}
+}
+
+func TestIUpdateSession_CloseAfterRelease(t *testing.T) {
+	tracker := NewLeakTracker()
+	update := newFakeUpdate(nil)
+	session, err := newIUpdateSession(newFakeSession(update), sessionOptions{tracker: tracker})
+	if err != nil {
+		t.Fatalf("newIUpdateSession failed: %v", err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+	result, err := searcher.Search("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	before := len(tracker.Unreleased())
+
+	result.Release()
+	if update.Releases() != 1 {
+		t.Errorf("update released %d times, want once", update.Releases())
+	}
+	if after := len(tracker.Unreleased()); after >= before {
+		t.Errorf("%d objects tracked after Release, %d before", after, before)
+	}
+	searcher.Release()
+	if got := tracker.Unreleased(); len(got) != 1 || got[0].Path != "IUpdateSession" {
+		t.Errorf("Unreleased() = %+v after releasing the result and the searcher, want only the session", got)
+	}
+	session.Close()
+	if update.Releases() != 1 {
+		t.Errorf("update released %d times after Close, want once", update.Releases())
+	}
+}
+
+func TestIUpdateSession_ConversionFailureReleases(t *testing.T) {
+	tracker := NewLeakTracker()
+	// Identity is read, and wrapped, before Title fails.
+	update := newFakeUpdate(map[string]interface{}{"Title": errors.New("access denied")})
+	session, err := newIUpdateSession(newFakeSession(update), sessionOptions{tracker: tracker})
+	if err != nil {
+		t.Fatalf("newIUpdateSession failed: %v", err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+	if _, err := searcher.Search("IsInstalled=0"); err == nil {
+		t.Fatal("Search succeeded, want the error of Title")
+	}
+	result, err := searcher.SearchStream("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("SearchStream failed: %v", err)
+	}
+	for _, err := range result.All() {
+		if err == nil {
+			t.Fatal("All yielded an update, want the error of Title")
+		}
+	}
+	result.Release()
+	searcher.Release()
+	if got := tracker.Unreleased(); len(got) != 1 || got[0].Path != "IUpdateSession" {
+		t.Errorf("Unreleased() = %+v after the failed conversions, want only the session", got)
+	}
+	if update.Releases() != 2 {
+		t.Errorf("update released %d times, want once per search", update.Releases())
+	}
+}
+
+func TestIUpdate_Release(t *testing.T) {
+	identity := newFakeDispatcher(map[string]interface{}{"RevisionNumber": int32(1), "UpdateID": "id"})
+	category := newFakeCategory("0fa1201d-4330-4fa8-8ae9-b877473b6441", "Security Updates", "UpdateClassification")
+	categories := newFakeCollection(category)
+	kbs := newFakeCollection("5034441")
+	disp := newFakeUpdate(map[string]interface{}{
+		"Identity":     identity,
+		"Categories":   categories,
+		"KBArticleIDs": kbs,
+	})
+	update, err := toIUpdate(disp)
+	if err != nil {
+		t.Fatalf("toIUpdate failed: %v", err)
+	}
+	if categories.Releases() != 1 || kbs.Releases() != 1 {
+		t.Errorf("collections released %d and %d times during conversion, want once", categories.Releases(), kbs.Releases())
+	}
+
+	update.Release()
+	for name, d := range map[string]*fakeDispatcher{"update": disp, "identity": identity, "category": category} {
+		if d.Releases() != 1 {
+			t.Errorf("%s released %d times, want once", name, d.Releases())
+		}
+	}
+
+	// Wrappers built by hand, as by package wufake, hold no object.
+	(&IUpdate{Title: "manual"}).Release()
+	(*IUpdate)(nil).Release()
+}
+
+func TestIUpdateDownloader_ReleasesUpdateCollection(t *testing.T) {
+	update, err := toIUpdate(newFakeUpdate(nil))
+	if err != nil {
+		t.Fatalf("toIUpdate failed: %v", err)
+	}
+	var collection *fakeDispatcher
+	owner := &collectionRecorder{created: &collection}
+	downloader := &IUpdateDownloader{disp: newFakeDispatcher(map[string]interface{}{
+		"Download": fakeMember(func(params ...interface{}) (interface{}, error) {
+			return nil, errors.New("download failed")
+		}),
+	})}
+	owner.fakeDispatcher = downloader.disp.(*fakeDispatcher)
+	downloader.disp = owner
+
+	if _, err := downloader.Download([]*IUpdate{update}); err == nil {
+		t.Fatal("Download succeeded")
+	}
+	if collection == nil || collection.Releases() != 1 {
+		t.Errorf("update collection = %v, want released once", collection)
+	}
+}
+
+// collectionRecorder is a fakeDispatcher keeping the objects it creates.
+type collectionRecorder struct {
+	*fakeDispatcher
+	created **fakeDispatcher
+}
+
+func (d *collectionRecorder) CreateObject(programID string) (dispatcher, error) {
+	created, err := d.fakeDispatcher.CreateObject(programID)
+	if err == nil {
+		*d.created = created.(*fakeDispatcher)
+	}
+	return created, err
+}
+
+func TestArena_ReleaseOnce(t *testing.T) {
+	a := newArena(nil)
+	inner := newFakeDispatcher(map[string]interface{}{"Name": "x"})
+	d := a.wrap(inner, "Root", nil)
+	if a.wrap(d, "Again", nil) != d {
+		t.Error("wrapping an object of the arena again returned a new one")
+	}
+	d.Release()
+	d.Release()
+	if inner.Releases() != 1 {
+		t.Errorf("released %d times, want once", inner.Releases())
+	}
+	if _, err := d.GetProperty("Name"); err != ErrReleased {
+		t.Errorf("GetProperty after Release error = %v, want ErrReleased", err)
+	}
+	if n := a.releaseAll(); n != 0 || a.live() != 0 {
+		t.Errorf("releaseAll released %d, %d live; want 0", n, a.live())
+	}
+}
+
+func TestArena_ReleaseAllNewestFirst(t *testing.T) {
+	var order []string
+	a := newArena(nil)
+	for _, name := range []string{"first", "second", "third"} {
+		a.wrap(&releaseLogger{fakeDispatcher: newFakeDispatcher(nil), name: name, order: &order}, name, nil)
+	}
+	if n := a.releaseAll(); n != 3 {
+		t.Errorf("releaseAll released %d, want 3", n)
+	}
+	if strings.Join(order, ",") != "third,second,first" {
+		t.Errorf("release order = %v", order)
+	}
+}
+
+type releaseLogger struct {
+	*fakeDispatcher
+	name  string
+	order *[]string
+}
+
+func (d *releaseLogger) Release() {
+	*d.order = append(*d.order, d.name)
+}
+
+func TestLeakTracker(t *testing.T) {
+	tracker := NewLeakTracker()
+	if err := tracker.Err(); err != nil {
+		t.Errorf("Err() = %v for an empty tracker", err)
+	}
+	first := tracker.track("IUpdateSession")
+	second := tracker.track("IUpdateSession.CreateUpdateSearcher()")
+	third := tracker.track(`IUpdateSession.WebProxy`)
+	tracker.untrack(second)
+	tracker.untrack(second)
+
+	got := tracker.Unreleased()
+	if len(got) != 2 || got[0].ID != first || got[1].ID != third || got[1].Path != "IUpdateSession.WebProxy" {
+		t.Errorf("Unreleased() = %+v", got)
+	}
+	want := "windowsupdate: 2 COM objects not released:\n\t#1 IUpdateSession\n\t#3 IUpdateSession.WebProxy"
+	if err := tracker.Err(); err == nil || err.Error() != want {
+		t.Errorf("Err() = %v, want %q", err, want)
+	}
+}
+
+func TestMemberPath(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		params []interface{}
+		method bool
+		want   string
+	}{
+		{"Updates", nil, false, "Updates"},
+		{"Item", []interface{}{int32(3)}, false, "Item(3)"},
+		{"CreateUpdateSearcher", nil, true, "CreateUpdateSearcher()"},
+		{"Search", []interface{}{"IsInstalled=0"}, true, `Search("IsInstalled=0")`},
+		{"EndSearch", []interface{}{newFakeDispatcher(nil)}, true, "EndSearch(?)"},
+	} {
+		if got := memberPath(test.name, test.params, test.method); got != test.want {
+			t.Errorf("memberPath(%s, %v, %v) = %q, want %q", test.name, test.params, test.method, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+var (
+	// ErrOutsideWindow is the error of a Guard asked to install outside a
+	// maintenance window.
+	ErrOutsideWindow = errors.New("outside a maintenance window")
+	// ErrWindowTooShort is the error of a Guard asked to install when the time
+	// left in the window, or in its budget, is shorter than the estimate.
+	ErrWindowTooShort = errors.New("not enough time left in the maintenance window")
+)
+
+// WindowError is returned by a Guard that refuses an installation. It wraps
+// ErrOutsideWindow or ErrWindowTooShort.
+type WindowError struct {
+	Err error
+	At  time.Time
+	// Current is the window open at At, if any.
+	Current *Occurrence
+	// Remaining is the time left in Current and its budget, and Estimate the
+	// time the installation was expected to take.
+	Remaining time.Duration
+	Estimate  time.Duration
+	// Next is the next window that could fit the installation, if any.
+	Next *Occurrence
+}
+
+func (e *WindowError) Error() string {
+	msg := "maintenance: " + e.Err.Error()
+	if e.Current != nil {
+		msg += fmt.Sprintf(" (%v left, %v needed)", e.Remaining, e.Estimate)
+	}
+	if e.Next != nil {
+		msg += "; next window: " + e.Next.String()
+	}
+	return msg
+}
+
+func (e *WindowError) Unwrap() error {
+	return e.Err
+}
+
+// Estimator returns the time expected to install updates.
+type Estimator func(updates []*windowsupdate.IUpdate) time.Duration
+
+// PerUpdate estimates d per update.
+func PerUpdate(d time.Duration) Estimator {
+	return func(updates []*windowsupdate.IUpdate) time.Duration {
+		return d * time.Duration(len(updates))
+	}
+}
+
+// Guard is a windowsupdate.Installer that only starts installations and
+// uninstallations inside a window of Schedule, and only when the time left in
+// the window and in its budget is at least the Estimate of the updates. The
+// estimate is reserved from the budget of the window when a call is admitted,
+// so that concurrent calls cannot overrun it, and is replaced by the time the
+// call took when it returns.
+//
+// Outside a window, or when the window is too short, calls fail with a
+// *WindowError, unless Defer is set: then Install, InstallContext,
+// InstallBatches, Uninstall and UninstallContext wait for the next window that
+// fits instead. BeginInstall and BeginUninstall never wait.
+type Guard struct {
+	Installer windowsupdate.Installer
+	Schedule  *Schedule
+	// Estimate is the expected duration of a call; nil means zero, so only
+	// the window is checked.
+	Estimate Estimator
+	// Defer waits for the next window rather than refusing a call.
+	Defer bool
+	// Clock is the source of time and of waits; nil means
+	// windowsupdate.SystemClock.
+	Clock windowsupdate.Clock
+
+	mu   sync.Mutex
+	used map[occurrenceKey]*usage
+	jobs map[*windowsupdate.IInstallationJob]reservation
+}
+
+// NewGuard returns a Guard of installer that refuses calls outside the
+// windows of schedule.
+func NewGuard(installer windowsupdate.Installer, schedule *Schedule) *Guard {
+	return &Guard{Installer: installer, Schedule: schedule}
+}
+
+var _ windowsupdate.Installer = (*Guard)(nil)
+
+type occurrenceKey struct {
+	window string
+	start  int64
+}
+
+// usage is the budget used in an occurrence, including the estimates of the
+// calls still running.
+type usage struct {
+	end  time.Time
+	used time.Duration
+}
+
+// reservation is the estimate of an admitted call, reserved from the budget
+// of its occurrence at the time the call started.
+type reservation struct {
+	occurrence Occurrence
+	estimate   time.Duration
+	at         time.Time
+}
+
+func keyOf(o Occurrence) occurrenceKey {
+	return occurrenceKey{o.Window, o.Start.UnixNano()}
+}
+
+func (g *Guard) clock() windowsupdate.Clock {
+	if g.Clock == nil {
+		return windowsupdate.SystemClock
+	}
+	return g.Clock
+}
+
+func (g *Guard) estimate(updates []*windowsupdate.IUpdate) time.Duration {
+	if g.Estimate == nil {
+		return 0
+	}
+	return g.Estimate(updates)
+}
+
+// Remaining returns the window open now and the time left in it and in its
+// budget.
+func (g *Guard) Remaining() (Occurrence, time.Duration, bool) {
+	now := g.clock().Now()
+	o, ok := g.Schedule.Current(now)
+	if !ok {
+		return Occurrence{}, 0, false
+	}
+	return o, g.remaining(o, now), true
+}
+
+func (g *Guard) remaining(o Occurrence, now time.Time) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.remainingLocked(o, now)
+}
+
+func (g *Guard) remainingLocked(o Occurrence, now time.Time) time.Duration {
+	var used time.Duration
+	if u := g.used[keyOf(o)]; u != nil {
+		used = u.used
+	}
+	return max(min(o.Remaining(now), o.Budget-used), 0)
+}
+
+// reserveLocked reserves estimate from the budget of o if it fits in the time
+// left at now, and forgets the occurrences that ended before now.
+func (g *Guard) reserveLocked(o Occurrence, estimate time.Duration, now time.Time) (time.Duration, bool) {
+	remaining := g.remainingLocked(o, now)
+	if remaining < estimate {
+		return remaining, false
+	}
+	for key, u := range g.used {
+		if !u.end.After(now) {
+			delete(g.used, key)
+		}
+	}
+	if g.used == nil {
+		g.used = make(map[occurrenceKey]*usage)
+	}
+	u := g.used[keyOf(o)]
+	if u == nil {
+		u = &usage{end: o.End}
+		g.used[keyOf(o)] = u
+	}
+	u.used += estimate
+	return remaining, true
+}
+
+// Admit returns the window open now if updates fit in it. Otherwise it returns
+// a *WindowError, or with Defer waits for the next window that fits. It does
+// not reserve anything from the budget of the window.
+func (g *Guard) Admit(ctx context.Context, updates []*windowsupdate.IUpdate) (Occurrence, error) {
+	r, err := g.admit(ctx, updates, g.Defer, false)
+	return r.occurrence, err
+}
+
+// admit returns the window open now if updates fit in it, reserving their
+// estimate from its budget if reserve is set. The reservation must then be
+// settled once the call returns.
+func (g *Guard) admit(ctx context.Context, updates []*windowsupdate.IUpdate, wait, reserve bool) (reservation, error) {
+	clock, estimate := g.clock(), g.estimate(updates)
+	for {
+		if err := ctx.Err(); err != nil {
+			return reservation{}, err
+		}
+		now := clock.Now()
+		werr := &WindowError{Err: ErrOutsideWindow, At: now, Estimate: estimate}
+		if o, ok := g.Schedule.Current(now); ok {
+			var remaining time.Duration
+			var fits bool
+			if reserve {
+				g.mu.Lock()
+				remaining, fits = g.reserveLocked(o, estimate, now)
+				g.mu.Unlock()
+			} else {
+				remaining = g.remaining(o, now)
+				fits = remaining >= estimate
+			}
+			if fits {
+				return reservation{occurrence: o, estimate: estimate, at: now}, nil
+			}
+			werr.Err, werr.Current, werr.Remaining = ErrWindowTooShort, &o, remaining
+		}
+		// A window that opened at now is current, so look from just after.
+		t := now.Add(1)
+		for i := 0; i < maxOccurrences; i++ {
+			next, ok := g.Schedule.Next(t)
+			if !ok {
+				break
+			}
+			if next.Budget >= estimate {
+				werr.Next = &next
+				break
+			}
+			t = next.Start.Add(1)
+		}
+		if !wait || werr.Next == nil {
+			return reservation{}, werr
+		}
+		select {
+		case <-ctx.Done():
+			return reservation{}, ctx.Err()
+		case <-clock.After(werr.Next.Start.Sub(now)):
+		}
+	}
+}
+
+// settle replaces the estimate reserved by r with the time elapsed since the
+// call started. Occurrences already forgotten are left alone.
+func (g *Guard) settle(r reservation) {
+	elapsed := g.clock().Now().Sub(r.at)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if u := g.used[keyOf(r.occurrence)]; u != nil {
+		u.used += elapsed - r.estimate
+	}
+}
+
+// Install installs updates if they fit in the window open now.
+func (g *Guard) Install(updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+	return g.InstallContext(context.Background(), updates)
+}
+
+// InstallContext installs updates if they fit in the window open now.
+func (g *Guard) InstallContext(ctx context.Context, updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationResult, error) {
+	r, err := g.admit(ctx, updates, g.Defer, true)
+	if err != nil {
+		return nil, err
+	}
+	defer g.settle(r)
+	return g.Installer.InstallContext(ctx, updates, options...)
+}
+
+// InstallBatches installs batches in order, admitting each on its own, and
+// returns the results of the batches installed. It stops before a batch that
+// does not fit in the time left, returning a *WindowError, or with Defer
+// waits for the next window to install it.
+func (g *Guard) InstallBatches(ctx context.Context, batches [][]*windowsupdate.IUpdate, options ...windowsupdate.JobOption) ([]*windowsupdate.IInstallationResult, error) {
+	var results []*windowsupdate.IInstallationResult
+	for _, batch := range batches {
+		result, err := g.InstallContext(ctx, batch, options...)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// BeginInstall starts installing updates if they fit in the window open now.
+// It never waits for a window. The time until EndInstall is charged to the
+// window.
+func (g *Guard) BeginInstall(updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationJob, error) {
+	return g.begin(updates, func() (*windowsupdate.IInstallationJob, error) {
+		return g.Installer.BeginInstall(updates, options...)
+	})
+}
+
+// EndInstall returns the result of a job started by BeginInstall.
+func (g *Guard) EndInstall(installationJob *windowsupdate.IInstallationJob) (*windowsupdate.IInstallationResult, error) {
+	defer g.end(installationJob)
+	return g.Installer.EndInstall(installationJob)
+}
+
+// Uninstall uninstalls updates if they fit in the window open now.
+func (g *Guard) Uninstall(updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+	return g.UninstallContext(context.Background(), updates)
+}
+
+// UninstallContext uninstalls updates if they fit in the window open now.
+func (g *Guard) UninstallContext(ctx context.Context, updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationResult, error) {
+	r, err := g.admit(ctx, updates, g.Defer, true)
+	if err != nil {
+		return nil, err
+	}
+	defer g.settle(r)
+	return g.Installer.UninstallContext(ctx, updates, options...)
+}
+
+// BeginUninstall starts uninstalling updates if they fit in the window open
+// now. It never waits for a window.
+func (g *Guard) BeginUninstall(updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationJob, error) {
+	return g.begin(updates, func() (*windowsupdate.IInstallationJob, error) {
+		return g.Installer.BeginUninstall(updates, options...)
+	})
+}
+
+// EndUninstall returns the result of a job started by BeginUninstall.
+func (g *Guard) EndUninstall(installationJob *windowsupdate.IInstallationJob) (*windowsupdate.IInstallationResult, error) {
+	defer g.end(installationJob)
+	return g.Installer.EndUninstall(installationJob)
+}
+
+// Release releases Installer.
+func (g *Guard) Release() {
+	g.Installer.Release()
+}
+
+func (g *Guard) begin(updates []*windowsupdate.IUpdate, begin func() (*windowsupdate.IInstallationJob, error)) (*windowsupdate.IInstallationJob, error) {
+	r, err := g.admit(context.Background(), updates, false, true)
+	if err != nil {
+		return nil, err
+	}
+	job, err := begin()
+	if err != nil {
+		g.settle(r)
+		return nil, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.jobs == nil {
+		g.jobs = make(map[*windowsupdate.IInstallationJob]reservation)
+	}
+	g.jobs[job] = r
+	return job, nil
+}
+
+func (g *Guard) end(job *windowsupdate.IInstallationJob) {
+	g.mu.Lock()
+	r, ok := g.jobs[job]
+	delete(g.jobs, job)
+	g.mu.Unlock()
+	if ok {
+		g.settle(r)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/wufake"
+)
+
+// slowInstaller is a wufake installer whose installations take perUpdate of
+// the fake clock each.
+type slowInstaller struct {
+	windowsupdate.Installer
+	clock     *wufake.Clock
+	perUpdate time.Duration
+}
+
+func (i *slowInstaller) InstallContext(ctx context.Context, updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationResult, error) {
+	i.clock.Advance(i.perUpdate * time.Duration(len(updates)))
+	return i.Installer.InstallContext(ctx, updates, options...)
+}
+
+func newUpdates(ids ...string) []*windowsupdate.IUpdate {
+	updates := make([]*windowsupdate.IUpdate, len(ids))
+	for i, id := range ids {
+		updates[i] = wufake.NewUpdate(id)
+	}
+	return updates
+}
+
+// newGuard returns a guard of newSchedule at now, whose installations take and
+// are estimated to take 30 minutes per update.
+func newGuard(t *testing.T, now time.Time) (*Guard, *wufake.Catalog, *wufake.Clock) {
+	catalog := &wufake.Catalog{}
+	installer, _ := wufake.NewSession(catalog).CreateInstaller()
+	clock := wufake.NewClock(now)
+	g := NewGuard(&slowInstaller{Installer: installer, clock: clock, perUpdate: 30 * time.Minute}, newSchedule(t))
+	g.Estimate = PerUpdate(30 * time.Minute)
+	g.Clock = clock
+	return g, catalog, clock
+}
+
+func TestGuard_OutsideWindow(t *testing.T) {
+	g, catalog, _ := newGuard(t, time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC))
+	_, err := g.Install(newUpdates("a"))
+	var werr *WindowError
+	if !errors.Is(err, ErrOutsideWindow) || !errors.As(err, &werr) {
+		t.Fatalf("Install() error = %v, want ErrOutsideWindow", err)
+	}
+	if werr.Next == nil || !werr.Next.Start.Equal(time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Next = %v, want the window of 2026-01-10", werr.Next)
+	}
+	if want := "maintenance: outside a maintenance window; next window: patch 2026-01-10 02:00 to 05:00 UTC"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err, want)
+	}
+	if _, err := g.BeginInstall(newUpdates("a")); !errors.Is(err, ErrOutsideWindow) {
+		t.Errorf("BeginInstall() error = %v, want ErrOutsideWindow", err)
+	}
+	if calls := catalog.Calls(); len(calls) != 0 {
+		t.Errorf("catalog calls = %+v, want none", calls)
+	}
+}
+
+func TestGuard_Defer(t *testing.T) {
+	g, catalog, clock := newGuard(t, time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC))
+	g.Defer = true
+	if _, err := g.Install(newUpdates("a")); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if waits := clock.Waits(); !slices.Equal(waits, []time.Duration{14 * time.Hour}) {
+		t.Errorf("waits = %v, want 14h until the window opens", waits)
+	}
+	if calls := catalog.Calls(); len(calls) != 1 {
+		t.Errorf("catalog calls = %+v, want the install", calls)
+	}
+}
+
+func TestGuard_DeferCanceled(t *testing.T) {
+	g, _, _ := newGuard(t, time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC))
+	g.Defer = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := g.InstallContext(ctx, newUpdates("a")); !errors.Is(err, context.Canceled) {
+		t.Errorf("InstallContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestGuard_InstallBatches(t *testing.T) {
+	g, catalog, _ := newGuard(t, time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC))
+	batches := [][]*windowsupdate.IUpdate{newUpdates("a", "b"), newUpdates("c"), newUpdates("d", "e")}
+	results, err := g.InstallBatches(context.Background(), batches)
+
+	// The budget is 2h: a and b take 1h, c 30m, leaving 30m for d and e.
+	var werr *WindowError
+	if !errors.Is(err, ErrWindowTooShort) || !errors.As(err, &werr) {
+		t.Fatalf("InstallBatches() error = %v, want ErrWindowTooShort", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("InstallBatches() returned %d results, want 2", len(results))
+	}
+	if werr.Remaining != 30*time.Minute || werr.Estimate != time.Hour || werr.Current == nil {
+		t.Errorf("WindowError = %+v, want 30m left for 1h", werr)
+	}
+	if werr.Next == nil || !werr.Next.Start.Equal(time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Next = %v, want March past the February freeze", werr.Next)
+	}
+	if calls := catalog.Calls(); len(calls) != 2 {
+		t.Errorf("catalog calls = %+v, want 2 installs", calls)
+	}
+	if o, left, ok := g.Remaining(); !ok || left != 30*time.Minute || o.Window != "patch" {
+		t.Errorf("Remaining() = %v, %v, %v", o, left, ok)
+	}
+}
+
+func TestGuard_InstallBatchesDefer(t *testing.T) {
+	g, catalog, clock := newGuard(t, time.Date(2026, 1, 10, 4, 0, 0, 0, time.UTC))
+	g.Defer = true
+	results, err := g.InstallBatches(context.Background(), [][]*windowsupdate.IUpdate{newUpdates("a"), newUpdates("b", "c")})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("InstallBatches() = %d results, %v", len(results), err)
+	}
+	// a fits in the last hour; b and c wait for March, past the freeze.
+	if want := time.Date(2026, 3, 14, 3, 0, 0, 0, time.UTC); !clock.Now().Equal(want) {
+		t.Errorf("finished at %v, want %v", clock.Now(), want)
+	}
+	if len(clock.Waits()) != 1 || len(catalog.Calls()) != 2 {
+		t.Errorf("waits = %v, calls = %+v", clock.Waits(), catalog.Calls())
+	}
+}
+
+func TestGuard_NeverFits(t *testing.T) {
+	g, _, clock := newGuard(t, time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC))
+	g.Defer = true
+	_, err := g.Install(newUpdates("a", "b", "c", "d", "e"))
+	var werr *WindowError
+	if !errors.As(err, &werr) || werr.Next != nil {
+		t.Errorf("Install() error = %v, want no window fitting 2h30m", err)
+	}
+	if len(clock.Waits()) != 0 {
+		t.Errorf("waits = %v, want none", clock.Waits())
+	}
+}
+
+func TestGuard_BeginInstallCharges(t *testing.T) {
+	g, _, clock := newGuard(t, time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC))
+	g.Estimate = nil
+	job, err := g.BeginInstall(newUpdates("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(90 * time.Minute)
+	if _, err := g.EndInstall(job); err != nil {
+		t.Fatal(err)
+	}
+	if _, left, _ := g.Remaining(); left != 30*time.Minute {
+		t.Errorf("Remaining() = %v, want 30m of the budget", left)
+	}
+	g.Estimate = PerUpdate(time.Hour)
+	if _, err := g.BeginUninstall(newUpdates("a")); !errors.Is(err, ErrWindowTooShort) {
+		t.Errorf("BeginUninstall() error = %v, want ErrWindowTooShort", err)
+	}
+}
+
+func TestGuard_ReservesEstimate(t *testing.T) {
+	g, _, clock := newGuard(t, time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC))
+	job, err := g.BeginInstall(newUpdates("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The 1h30m estimate of the running job is reserved from the 2h budget.
+	if _, left, _ := g.Remaining(); left != 30*time.Minute {
+		t.Errorf("Remaining() = %v, want 30m while the job runs", left)
+	}
+	if _, err := g.BeginInstall(newUpdates("d", "e")); !errors.Is(err, ErrWindowTooShort) {
+		t.Errorf("BeginInstall() error = %v, want ErrWindowTooShort", err)
+	}
+	clock.Advance(20 * time.Minute)
+	if _, err := g.EndInstall(job); err != nil {
+		t.Fatal(err)
+	}
+	if _, left, _ := g.Remaining(); left != 100*time.Minute {
+		t.Errorf("Remaining() = %v, want 1h40m once the job took 20m", left)
+	}
+
+	clock.Advance(time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC).Sub(clock.Now()))
+	if _, err := g.Install(newUpdates("a")); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.used) != 1 {
+		t.Errorf("used = %v, want only the March window", g.used)
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchDays bounds the days Recurrence.Next looks ahead: eight years cover
+// every recurrence that matches at all, such as February 29 on a Monday.
+const maxSearchDays = 8 * 366
+
+// Recurrence is a cron-like set of times given by five fields: minute, hour,
+// day of month, month and day of week, e.g. "0 2 * * SAT#2" for 02:00 on the
+// second Saturday of each month.
+//
+// Each field is * or a list of values, ranges such as 1-5, and steps such as
+// */15 or 0-30/10. Months and days of week may be named (JAN, SAT), and Sunday
+// is 0 or 7. The day of week also accepts d#n for the nth weekday d of the
+// month and dL for the last one. As in cron, when both the day of month and
+// the day of week are restricted, a day matching either matches.
+type Recurrence struct {
+	source   string
+	minutes  uint64
+	hours    uint64
+	days     uint64
+	months   uint64
+	weekdays uint64
+	// nth holds, for each weekday, bit n for d#n and bit 6 for dL.
+	nth         [7]uint8
+	anyDay      bool
+	anyWeekdays bool
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var weekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// ParseRecurrence parses a recurrence such as "0 2 * * SAT#2".
+func ParseRecurrence(s string) (Recurrence, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return Recurrence{}, fmt.Errorf("maintenance: recurrence %q: want 5 fields, got %d", s, len(fields))
+	}
+	r := Recurrence{source: strings.Join(fields, " "), anyDay: fields[2] == "*", anyWeekdays: fields[4] == "*"}
+	var err error
+	if r.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return Recurrence{}, fmt.Errorf("maintenance: recurrence %q: minute: %w", s, err)
+	}
+	if r.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return Recurrence{}, fmt.Errorf("maintenance: recurrence %q: hour: %w", s, err)
+	}
+	if r.days, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return Recurrence{}, fmt.Errorf("maintenance: recurrence %q: day of month: %w", s, err)
+	}
+	if r.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return Recurrence{}, fmt.Errorf("maintenance: recurrence %q: month: %w", s, err)
+	}
+	if err = r.parseWeekdays(fields[4]); err != nil {
+		return Recurrence{}, fmt.Errorf("maintenance: recurrence %q: day of week: %w", s, err)
+	}
+	return r, nil
+}
+
+// MustParseRecurrence is like ParseRecurrence but panics if s cannot be parsed.
+func MustParseRecurrence(s string) Recurrence {
+	r, err := ParseRecurrence(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r *Recurrence) parseWeekdays(field string) error {
+	for _, item := range strings.Split(field, ",") {
+		day, suffix, nth := item, "", ""
+		if i := strings.IndexByte(item, '#'); i >= 0 {
+			day, nth = item[:i], item[i+1:]
+		} else if strings.HasSuffix(strings.ToUpper(item), "L") {
+			day, suffix = item[:len(item)-1], "L"
+		}
+		if nth == "" && suffix == "" {
+			set, err := parseField(item, 0, 7, weekdayNames)
+			if err != nil {
+				return err
+			}
+			r.weekdays |= set
+			continue
+		}
+		d, err := parseValue(day, 0, 7, weekdayNames)
+		if err != nil {
+			return err
+		}
+		d %= 7
+		if suffix == "L" {
+			r.nth[d] |= 1 << 6
+			continue
+		}
+		n, err := strconv.Atoi(nth)
+		if err != nil || n < 1 || n > 5 {
+			return fmt.Errorf("invalid %q: the week must be 1 to 5", item)
+		}
+		r.nth[d] |= 1 << n
+	}
+	// Sunday is both 0 and 7.
+	if r.weekdays&(1<<7) != 0 {
+		r.weekdays = r.weekdays&^(1<<7) | 1
+	}
+	return nil
+}
+
+// parseField parses a comma-separated list of values, ranges and steps into a
+// set with bit v for each value v.
+func parseField(field string, lo, hi int, names map[string]int) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+			rng = item[:i]
+		}
+		first, last := lo, hi
+		switch i := strings.IndexByte(rng, '-'); {
+		case rng == "*":
+		case i >= 0:
+			var err error
+			if first, err = parseValue(rng[:i], lo, hi, names); err != nil {
+				return 0, err
+			}
+			if last, err = parseValue(rng[i+1:], lo, hi, names); err != nil {
+				return 0, err
+			}
+			if first > last {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			var err error
+			if first, err = parseValue(rng, lo, hi, names); err != nil {
+				return 0, err
+			}
+			if step == 1 {
+				last = first
+			}
+		}
+		for v := first; v <= last; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("invalid value %q, want %d to %d", s, lo, hi)
+	}
+	return v, nil
+}
+
+// String returns the source of the recurrence.
+func (r Recurrence) String() string {
+	return r.source
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r Recurrence) MarshalText() ([]byte, error) {
+	return []byte(r.source), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Recurrence) UnmarshalText(text []byte) (err error) {
+	*r, err = ParseRecurrence(string(text))
+	return err
+}
+
+// IsZero reports whether r is the zero Recurrence, which never matches.
+func (r Recurrence) IsZero() bool {
+	return r.source == ""
+}
+
+// matchDay reports whether the date of day matches r.
+func (r Recurrence) matchDay(day time.Time) bool {
+	if r.months&(1<<int(day.Month())) == 0 {
+		return false
+	}
+	dayMatch := r.days&(1<<day.Day()) != 0
+	weekday := day.Weekday()
+	nth := r.nth[weekday]
+	weekdayMatch := r.weekdays&(1<<int(weekday)) != 0 ||
+		nth&(1<<((day.Day()-1)/7+1)) != 0 ||
+		nth&(1<<6) != 0 && day.AddDate(0, 0, 7).Month() != day.Month()
+	switch {
+	case r.anyDay && r.anyWeekdays:
+		return true
+	case r.anyDay:
+		return weekdayMatch
+	case r.anyWeekdays:
+		return dayMatch
+	}
+	return dayMatch || weekdayMatch
+}
+
+// Next returns the first time of r at or after t, in loc. It returns the zero
+// time if there is none.
+func (r Recurrence) Next(t time.Time, loc *time.Location) time.Time {
+	if r.IsZero() {
+		return time.Time{}
+	}
+	t = t.In(loc)
+	year, month, day := t.Date()
+	for i := 0; i < maxSearchDays; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, loc)
+		if !r.matchDay(date) {
+			continue
+		}
+		for hours := r.hours; hours != 0; hours &= hours - 1 {
+			hour := bits.TrailingZeros64(hours)
+			for minutes := r.minutes; minutes != 0; minutes &= minutes - 1 {
+				minute := bits.TrailingZeros64(minutes)
+				start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+				if !start.Before(t) {
+					return start
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
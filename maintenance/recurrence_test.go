@@ -0,0 +1,107 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRecurrence_Next(t *testing.T) {
+	utc := func(s string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+	tests := []struct {
+		spec string
+		from string
+		want string
+	}{
+		{"0 2 * * SAT#2", "2026-02-01 00:00", "2026-02-14 02:00"},
+		{"0 2 * * SAT#2", "2026-02-14 02:00", "2026-02-14 02:00"},
+		{"0 2 * * SAT#2", "2026-02-14 02:01", "2026-03-14 02:00"},
+		{"0 2 * * 6#2", "2026-12-20 00:00", "2027-01-09 02:00"},
+		{"30 22 * * FRIL", "2026-01-01 00:00", "2026-01-30 22:30"},
+		{"*/15 1-2 * * *", "2026-01-01 02:50", "2026-01-02 01:00"},
+		{"0 0 29 FEB *", "2026-01-01 00:00", "2028-02-29 00:00"},
+		{"0 3 1 * MON", "2026-06-01 04:00", "2026-06-08 03:00"},
+		{"0 0 * * 7", "2026-01-01 00:00", "2026-01-04 00:00"},
+		{"0 0 30 2 *", "2026-01-01 00:00", ""},
+	}
+	for _, tt := range tests {
+		got := MustParseRecurrence(tt.spec).Next(utc(tt.from), time.UTC)
+		if tt.want == "" {
+			if !got.IsZero() {
+				t.Errorf("%q.Next(%s) = %v, want none", tt.spec, tt.from, got)
+			}
+			continue
+		}
+		if !got.Equal(utc(tt.want)) {
+			t.Errorf("%q.Next(%s) = %v, want %s", tt.spec, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrence_NextLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	got := MustParseRecurrence("0 2 * * SAT#2").Next(from, berlin)
+	if want := time.Date(2026, 3, 14, 1, 0, 0, 0, time.UTC); !got.Equal(want) || got.Location() != berlin {
+		t.Errorf("Next() = %v, want %v in Europe/Berlin", got, want)
+	}
+}
+
+func TestParseRecurrence_Errors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"0 2 * *",
+		"60 * * * *",
+		"0 24 * * *",
+		"0 0 0 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"0 0 * * SAT#6",
+		"0 0 * * XYZ",
+		"5-1 * * * *",
+		"*/0 * * * *",
+	} {
+		if _, err := ParseRecurrence(spec); err == nil {
+			t.Errorf("ParseRecurrence(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestRecurrence_JSON(t *testing.T) {
+	var w struct{ Start Recurrence }
+	if err := json.Unmarshal([]byte(`{"Start":"0  2 * *  SAT#2"}`), &w); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Start":"0 2 * * SAT#2"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+	if err := json.Unmarshal([]byte(`{"Start":"bad"}`), &w); err == nil {
+		t.Error("Unmarshal of a bad recurrence succeeded")
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package maintenance decides when updates may be installed. A Schedule is a
+// set of recurring maintenance windows, such as the second Saturday of each
+// month from 02:00 to 05:00 local time, minus blackout periods such as freeze
+// dates. A Guard wraps a windowsupdate.Installer so that installations only
+// start inside a window, and only when they are expected to finish in it.
+//
+// Everything is plain Go: times come from a windowsupdate.Clock, so schedules
+// and guards are tested with a wufake.Clock on any platform.
+package maintenance
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxOccurrences bounds the occurrences Schedule.Next skips over while they
+// overlap blackouts.
+const maxOccurrences = 1000
+
+// Window is a recurring maintenance window.
+type Window struct {
+	Name string
+	// Start is when each occurrence of the window opens.
+	Start Recurrence
+	// Duration is how long each occurrence stays open.
+	Duration time.Duration
+	// Budget caps the installation time of one occurrence; zero means the
+	// whole Duration.
+	Budget time.Duration
+	// Location is the time zone of Start and of the blackout dates; nil means
+	// time.Local.
+	Location *time.Location
+}
+
+// Blackout is a period in which no window opens, such as a change freeze.
+type Blackout struct {
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+// BlackoutDates returns the blackout from the start of the first date to the
+// end of the last one, both given as "2006-01-02" in loc, or time.Local if
+// loc is nil.
+func BlackoutDates(first, last string, loc *time.Location, reason string) (Blackout, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	start, err := time.ParseInLocation(time.DateOnly, first, loc)
+	if err != nil {
+		return Blackout{}, fmt.Errorf("maintenance: blackout: %w", err)
+	}
+	end, err := time.ParseInLocation(time.DateOnly, last, loc)
+	if err != nil {
+		return Blackout{}, fmt.Errorf("maintenance: blackout: %w", err)
+	}
+	if end.Before(start) {
+		return Blackout{}, fmt.Errorf("maintenance: blackout %s to %s ends before it starts", first, last)
+	}
+	return Blackout{Start: start, End: end.AddDate(0, 0, 1), Reason: reason}, nil
+}
+
+// overlaps reports whether b overlaps the period from start to end.
+func (b Blackout) overlaps(start, end time.Time) bool {
+	return b.Start.Before(end) && start.Before(b.End)
+}
+
+// Schedule is a set of maintenance windows and blackouts. An occurrence of a
+// window that overlaps a blackout is skipped as a whole.
+type Schedule struct {
+	Windows   []Window
+	Blackouts []Blackout
+}
+
+// Occurrence is one opening of a Window.
+type Occurrence struct {
+	Window string
+	Start  time.Time
+	End    time.Time
+	// Budget is the installation time allowed in the occurrence.
+	Budget time.Duration
+}
+
+// Contains reports whether t is in the occurrence.
+func (o Occurrence) Contains(t time.Time) bool {
+	return !t.Before(o.Start) && t.Before(o.End)
+}
+
+// Remaining returns the time left in the occurrence at t.
+func (o Occurrence) Remaining(t time.Time) time.Duration {
+	return max(o.End.Sub(t), 0)
+}
+
+// String returns the occurrence as "patch 2026-02-14 02:00 to 05:00 CET".
+func (o Occurrence) String() string {
+	end := o.End.Format("15:04 MST")
+	if y, m, d := o.End.Date(); y != o.Start.Year() || m != o.Start.Month() || d != o.Start.Day() {
+		end = o.End.Format("2006-01-02 15:04 MST")
+	}
+	s := o.Start.Format("2006-01-02 15:04") + " to " + end
+	if o.Window != "" {
+		s = o.Window + " " + s
+	}
+	return s
+}
+
+// Current returns the occurrence open at t, if any. When occurrences of
+// several windows are open, it returns the one that closes last.
+func (s *Schedule) Current(t time.Time) (Occurrence, bool) {
+	var current Occurrence
+	var found bool
+	for i := range s.Windows {
+		w := &s.Windows[i]
+		o, ok := s.next(w, t.Add(-w.Duration+1))
+		if ok && o.Contains(t) && (!found || o.End.After(current.End)) {
+			current, found = o, true
+		}
+	}
+	return current, found
+}
+
+// Next returns the first occurrence that opens at or after t, if any.
+func (s *Schedule) Next(t time.Time) (Occurrence, bool) {
+	var next Occurrence
+	var found bool
+	for i := range s.Windows {
+		o, ok := s.next(&s.Windows[i], t)
+		if ok && (!found || o.Start.Before(next.Start)) {
+			next, found = o, true
+		}
+	}
+	return next, found
+}
+
+// Blackout returns the blackout in effect at t, if any.
+func (s *Schedule) Blackout(t time.Time) (Blackout, bool) {
+	for _, b := range s.Blackouts {
+		if !t.Before(b.Start) && t.Before(b.End) {
+			return b, true
+		}
+	}
+	return Blackout{}, false
+}
+
+// next returns the first occurrence of w opening at or after t that does not
+// overlap a blackout.
+func (s *Schedule) next(w *Window, t time.Time) (Occurrence, bool) {
+	loc := w.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	budget := w.Budget
+	if budget <= 0 || budget > w.Duration {
+		budget = w.Duration
+	}
+	for i := 0; i < maxOccurrences && w.Duration > 0; i++ {
+		start := w.Start.Next(t, loc)
+		if start.IsZero() {
+			break
+		}
+		o := Occurrence{Window: w.Name, Start: start, End: start.Add(w.Duration), Budget: budget}
+		if !s.blackedOut(o) {
+			return o, true
+		}
+		t = start.Add(time.Minute)
+	}
+	return Occurrence{}, false
+}
+
+func (s *Schedule) blackedOut(o Occurrence) bool {
+	for _, b := range s.Blackouts {
+		if b.overlaps(o.Start, o.End) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+// newSchedule returns a window from 02:00 to 05:00 UTC on the second Saturday
+// of each month, with a two hour budget, and a freeze in February 2026.
+func newSchedule(t *testing.T) *Schedule {
+	t.Helper()
+	freeze, err := BlackoutDates("2026-02-10", "2026-02-16", time.UTC, "release freeze")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Schedule{
+		Windows: []Window{{
+			Name:     "patch",
+			Start:    MustParseRecurrence("0 2 * * SAT#2"),
+			Duration: 3 * time.Hour,
+			Budget:   2 * time.Hour,
+			Location: time.UTC,
+		}},
+		Blackouts: []Blackout{freeze},
+	}
+}
+
+func TestSchedule_Current(t *testing.T) {
+	s := newSchedule(t)
+	tests := []struct {
+		at   time.Time
+		want time.Time // Start of the current occurrence, zero if none.
+	}{
+		{time.Date(2026, 1, 10, 1, 59, 0, 0, time.UTC), time.Time{}},
+		{time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC), time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 10, 4, 59, 0, 0, time.UTC), time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 1, 10, 5, 0, 0, 0, time.UTC), time.Time{}},
+		{time.Date(2026, 2, 14, 3, 0, 0, 0, time.UTC), time.Time{}}, // Frozen.
+	}
+	for _, tt := range tests {
+		o, ok := s.Current(tt.at)
+		if ok != !tt.want.IsZero() || ok && !o.Start.Equal(tt.want) {
+			t.Errorf("Current(%v) = %v, %v, want start %v", tt.at, o, ok, tt.want)
+		}
+	}
+	if o, _ := s.Current(time.Date(2026, 1, 10, 3, 0, 0, 0, time.UTC)); o.Budget != 2*time.Hour || o.Remaining(o.Start) != 3*time.Hour {
+		t.Errorf("occurrence %+v, want a 3h window with a 2h budget", o)
+	}
+}
+
+func TestSchedule_NextSkipsBlackout(t *testing.T) {
+	s := newSchedule(t)
+	o, ok := s.Next(time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 14, 2, 0, 0, 0, time.UTC); !ok || !o.Start.Equal(want) {
+		t.Errorf("Next() = %v, %v, want %v", o, ok, want)
+	}
+	if got, want := o.String(), "patch 2026-03-14 02:00 to 05:00 UTC"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if b, ok := s.Blackout(time.Date(2026, 2, 16, 23, 0, 0, 0, time.UTC)); !ok || b.Reason != "release freeze" {
+		t.Errorf("Blackout() = %+v, %v, want the freeze", b, ok)
+	}
+	if _, ok := s.Blackout(time.Date(2026, 2, 17, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("Blackout() the day after the freeze = true")
+	}
+}
+
+func TestSchedule_SeveralWindows(t *testing.T) {
+	s := &Schedule{Windows: []Window{
+		{Name: "nightly", Start: MustParseRecurrence("0 1 * * *"), Duration: time.Hour, Location: time.UTC},
+		{Name: "weekend", Start: MustParseRecurrence("0 0 * * SAT"), Duration: 6 * time.Hour, Location: time.UTC},
+	}}
+	// Saturday 2026-01-03 01:30: both windows are open, weekend closes last.
+	o, ok := s.Current(time.Date(2026, 1, 3, 1, 30, 0, 0, time.UTC))
+	if !ok || o.Window != "weekend" {
+		t.Errorf("Current() = %v, %v, want weekend", o, ok)
+	}
+	o, ok = s.Next(time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC))
+	if !ok || o.Window != "weekend" || o.Budget != 6*time.Hour {
+		t.Errorf("Next() = %+v, %v, want weekend", o, ok)
+	}
+}
+
+func TestBlackoutDates_Errors(t *testing.T) {
+	for _, dates := range [][2]string{{"2026-02-30", "2026-03-01"}, {"2026-02-01", "soon"}, {"2026-02-02", "2026-02-01"}} {
+		if _, err := BlackoutDates(dates[0], dates[1], time.UTC, ""); err == nil {
+			t.Errorf("BlackoutDates(%q, %q) succeeded", dates[0], dates[1])
+		}
+	}
+}
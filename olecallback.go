@@ -44,15 +44,22 @@ import (
 // the process (the service then restarts).
 //
 // AGILITY: WUA runs the asynchronous operation on its own worker thread and
-// invokes the callback from there. Our STA session lives on a different COM
-// apartment, so COM must marshal the callback across apartments. A raw Go vtable
-// object has no marshaler, which made BeginXxx fail with DISP_E_EXCEPTION
-// ("Une exception s'est produite"). To fix that we aggregate the COM
-// Free-Threaded Marshaler (FTM): QueryInterface(IID_IMarshal) is delegated to the
-// FTM, which makes the object agile (callable directly from any apartment, no
-// proxy). BeginXxx then succeeds and progress is read by polling IXxxJob.GetProgress().
+// invokes the callback from there. The session may live in a different COM
+// apartment, such as the STA of the examples, so COM must marshal the callback
+// across apartments. A raw Go vtable object has no marshaler, which made
+// BeginXxx fail with DISP_E_EXCEPTION ("Une exception s'est produite"). To fix
+// that we aggregate the COM Free-Threaded Marshaler (FTM):
+// QueryInterface(IID_IMarshal) is delegated to the FTM, which makes the object
+// agile (callable directly from any apartment, no proxy). BeginXxx then
+// succeeds.
 // The handler signatures are 100% uintptr because that is required by
 // syscall.NewCallback.
+//
+// Calls that set WithCallbacks get a jobCallback per callback argument instead of
+// the shared no-op. It has the same layout plus the kind of callback it implements
+// and the handle of its handlers in jobCallbackRouter; its Invoke decodes the
+// callback args and routes them there. It stays alive, and its FTM is released,
+// until the last COM reference is dropped.
 
 type noopCallbackVtbl struct {
 	pQueryInterface uintptr
@@ -149,6 +156,126 @@ func comQueryInterface(unk uintptr, iid *ole.GUID, out *uintptr) uintptr {
 	return ret
 }
 
+// jobCallback is a per-call callback object. noopCallback MUST be the first
+// field: ncQueryInterface and ncAddRef read it through the same pointer.
+type jobCallback struct {
+	noopCallback
+	kind   callbackKind
+	handle uintptr
+}
+
+// liveJobCallbacks keeps the jobCallbacks referenced by COM reachable for the
+// garbage collector until their last Release.
+var (
+	liveJobCallbacksMu sync.Mutex
+	liveJobCallbacks   = map[*jobCallback]struct{}{}
+)
+
+func jcRelease(this uintptr) uintptr {
+	p := (*jobCallback)(unsafe.Pointer(this))
+	ref := atomic.AddInt32(&p.ref, -1)
+	if ref == 0 {
+		if p.ftm != 0 {
+			comRelease(p.ftm)
+			p.ftm = 0
+		}
+		liveJobCallbacksMu.Lock()
+		delete(liveJobCallbacks, p)
+		liveJobCallbacksMu.Unlock()
+	}
+	return uintptr(uint32(ref))
+}
+
+// jcInvoke is the slot-3 method of a jobCallback, e.g.
+// IDownloadProgressChangedCallback::Invoke(IDownloadJob*,
+// IDownloadProgressChangedCallbackArgs*). It runs on a WUA thread, so a panic
+// in a handler is recovered rather than crashing the process, and a failure to
+// decode the args drops the event.
+func jcInvoke(this, job, args uintptr) (hr uintptr) {
+	defer func() {
+		if recover() != nil {
+			hr = hrSOK
+		}
+	}()
+	p := (*jobCallback)(unsafe.Pointer(this))
+
+	var argsDisp dispatcher
+	if args != 0 && (p.kind == downloadProgressChangedCallback || p.kind == installationProgressChangedCallback) {
+		var out uintptr
+		if comQueryInterface(args, ole.IID_IDispatch, &out) != hrSOK || out == 0 {
+			return hrSOK
+		}
+		argsDisp = newComDispatcher((*ole.IDispatch)(unsafe.Pointer(out)))
+		defer argsDisp.Release()
+	}
+	event, err := decodeCallbackArgs(p.kind, argsDisp)
+	if err != nil {
+		return hrSOK
+	}
+	jobCallbackRouter.route(p.handle, event)
+	return hrSOK
+}
+
+// comRelease calls IUnknown::Release (vtable slot 2) on a raw COM object pointer.
+func comRelease(unk uintptr) {
+	vtbl := *(*uintptr)(unsafe.Pointer(unk))
+	pRelease := *(*uintptr)(unsafe.Pointer(vtbl + 2*unsafe.Sizeof(uintptr(0))))
+	syscall.SyscallN(pRelease, unk)
+}
+
+var (
+	jobVtbl     *noopCallbackVtbl
+	jobVtblOnce sync.Once
+)
+
+func getJobVtbl() *noopCallbackVtbl {
+	jobVtblOnce.Do(func() {
+		jobVtbl = &noopCallbackVtbl{
+			pQueryInterface: getNoopVtbl().pQueryInterface,
+			pAddRef:         getNoopVtbl().pAddRef,
+			pRelease:        syscall.NewCallback(jcRelease),
+			pInvoke:         syscall.NewCallback(jcInvoke),
+		}
+	})
+	return jobVtbl
+}
+
+// newJobCallback returns a callback object of kind routing to the handlers
+// registered under handle, and the function dropping the reference held by the
+// caller. COM must already be initialized on the calling thread.
+func newJobCallback(kind callbackKind, handle uintptr) (*ole.IDispatch, func()) {
+	cb := &jobCallback{
+		noopCallback: noopCallback{lpVtbl: getJobVtbl(), ref: 1},
+		kind:         kind,
+		handle:       handle,
+	}
+	cb.ftm = newFreeThreadedMarshaler(unsafe.Pointer(cb))
+	liveJobCallbacksMu.Lock()
+	liveJobCallbacks[cb] = struct{}{}
+	liveJobCallbacksMu.Unlock()
+
+	var once sync.Once
+	return (*ole.IDispatch)(unsafe.Pointer(cb)), func() {
+		once.Do(func() {
+			jcRelease(uintptr(unsafe.Pointer(cb)))
+		})
+	}
+}
+
+// newFreeThreadedMarshaler aggregates the COM free-threaded marshaler into the
+// callback object outer and returns its IUnknown, or 0 if that fails.
+func newFreeThreadedMarshaler(outer unsafe.Pointer) uintptr {
+	var ftm uintptr
+	if procCoCreateFreeThreadedMarshaler.Find() != nil {
+		return 0
+	}
+	ret, _, _ := procCoCreateFreeThreadedMarshaler.Call(uintptr(outer), uintptr(unsafe.Pointer(&ftm)))
+	if ret != 0 {
+		return 0
+	}
+	return ftm
+}
+
 var (
 	noopVtbl     *noopCallbackVtbl
 	noopOnce     sync.Once
@@ -191,16 +318,7 @@ func newNoopCallback() *ole.IDispatch {
 		// QueryInterface calls back to us. If this fails we leave ftm=0 and fall back
 		// to standard marshaling (BeginXxx may then fail and the caller falls back to
 		// the synchronous path).
-		var ftm uintptr
-		if procCoCreateFreeThreadedMarshaler.Find() == nil {
-			ret, _, _ := procCoCreateFreeThreadedMarshaler.Call(
-				uintptr(unsafe.Pointer(globalNoopCb)),
-				uintptr(unsafe.Pointer(&ftm)),
-			)
-			if ret == 0 {
-				globalNoopCb.ftm = ftm
-			}
-		}
+		globalNoopCb.ftm = newFreeThreadedMarshaler(unsafe.Pointer(globalNoopCb))
 	})
 	return (*ole.IDispatch)(unsafe.Pointer(globalNoopCb))
 }
@@ -22,3 +22,9 @@ import "github.com/go-ole/go-ole"
 func newNoopCallback() *ole.IDispatch {
 	return nil
 }
+
+// newJobCallback is a stub on non-Windows platforms, where no callback is
+// ever invoked.
+func newJobCallback(kind callbackKind, handle uintptr) (*ole.IDispatch, func()) {
+	return nil, func() {}
+}
@@ -87,3 +87,39 @@ func TestNoopCallback_AddRefRelease(t *testing.T) {
 		t.Errorf("Release = %d, want 1", got)
 	}
 }
+
+func TestJobCallback_InvokeAndRelease(t *testing.T) {
+	table := newCallbackTable()
+	saved := jobCallbackRouter
+	jobCallbackRouter = table
+	defer func() { jobCallbackRouter = saved }()
+
+	completed := 0
+	handle := table.register(JobCallbacks{Completed: func() { completed++ }})
+	disp, release := newJobCallback(searchCompletedCallback, handle)
+	this := uintptr(unsafe.Pointer(disp))
+
+	if hr := jcInvoke(this, 0, 0); hr != hrSOK || completed != 1 {
+		t.Errorf("Invoke = 0x%x with %d completions, want S_OK and 1", hr, completed)
+	}
+
+	// A reference taken by WUA keeps the object alive after ours is dropped.
+	ncAddRef(this)
+	release()
+	release()
+	liveJobCallbacksMu.Lock()
+	_, live := liveJobCallbacks[(*jobCallback)(unsafe.Pointer(disp))]
+	liveJobCallbacksMu.Unlock()
+	if !live {
+		t.Fatal("callback dropped while still referenced")
+	}
+	if got := jcRelease(this); got != 0 {
+		t.Errorf("last Release = %d, want 0", got)
+	}
+	liveJobCallbacksMu.Lock()
+	_, live = liveJobCallbacks[(*jobCallback)(unsafe.Pointer(disp))]
+	liveJobCallbacksMu.Unlock()
+	if live {
+		t.Error("callback still kept alive after its last Release")
+	}
+}
@@ -15,127 +15,107 @@ package windowsupdate
 
 import (
 	"time"
-
-	"github.com/go-ole/go-ole"
 )
 
-func toIDispatchErr(result *ole.VARIANT, err error) (*ole.IDispatch, error) {
+// The toXxxErr functions convert the result of a dispatcher call, passing its
+// error through. Like the variantToXxx functions they never panic: values that
+// do not fit the type are reported as a *ConversionError.
+
+func toIDispatchErr(result *variant, err error) (dispatcher, error) {
 	if err != nil {
 		return nil, err
 	}
-	return variantToIDispatch(result), nil
+	return variantToIDispatch(result)
 }
 
-func toInt64Err(result *ole.VARIANT, err error) (int64, error) {
+func toInt64Err(result *variant, err error) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return variantToInt64(result), nil
+	return variantToInt64(result)
 }
 
-func toInt32Err(result *ole.VARIANT, err error) (int32, error) {
+func toInt32Err(result *variant, err error) (int32, error) {
 	if err != nil {
 		return 0, err
 	}
-	return variantToInt32(result), nil
+	return variantToInt32(result)
 }
 
-func toFloat64Err(result *ole.VARIANT, err error) (float64, error) {
+func toHResultErr(result *variant, err error) (int32, error) {
 	if err != nil {
 		return 0, err
 	}
-	return variantToFloat64(result), nil
+	return variantToHResult(result)
 }
 
-func toFloat32Err(result *ole.VARIANT, err error) (float32, error) {
+func toFloat64Err(result *variant, err error) (float64, error) {
 	if err != nil {
 		return 0, err
 	}
-	return variantToFloat32(result), nil
+	return variantToFloat64(result)
 }
 
-func toStringErr(result *ole.VARIANT, err error) (string, error) {
+func toFloat32Err(result *variant, err error) (float32, error) {
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-	return variantToString(result), nil
+	return variantToFloat32(result)
 }
 
-func toBoolErr(result *ole.VARIANT, err error) (bool, error) {
+func toStringErr(result *variant, err error) (string, error) {
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	return variantToBool(result), nil
+	return variantToString(result)
 }
 
-func toTimeErr(result *ole.VARIANT, err error) (*time.Time, error) {
+func toBoolErr(result *variant, err error) (bool, error) {
 	if err != nil {
-		return nil, err
-	}
-	return variantToTime(result), nil
-}
-
-func variantToIDispatch(v *ole.VARIANT) *ole.IDispatch {
-	value := v.Value()
-	if value == nil {
-		return nil
+		return false, err
 	}
-	return v.ToIDispatch()
+	return variantToBool(result)
 }
 
-func variantToInt64(v *ole.VARIANT) int64 {
-	value := v.Value()
-	if value == nil {
-		return 0
+func toTimeErr(result *variant, err error) (*time.Time, error) {
+	if err != nil {
+		return nil, err
 	}
-	return value.(int64)
+	return variantToTime(result)
 }
 
-func variantToInt32(v *ole.VARIANT) int32 {
-	value := v.Value()
-	if value == nil {
-		return 0
-	}
-	return value.(int32)
+func variantToInt64(v *variant) (int64, error) {
+	return variantInteger(v, 64, "int64")
 }
 
-func variantToFloat64(v *ole.VARIANT) float64 {
-	value := v.Value()
-	if value == nil {
-		return 0
-	}
-	return value.(float64)
+func variantToInt32(v *variant) (int32, error) {
+	n, err := variantInteger(v, 32, "int32")
+	return int32(n), err
 }
 
-func variantToFloat32(v *ole.VARIANT) float32 {
-	value := v.Value()
-	if value == nil {
-		return 0
+// variantToHResult converts an HResult property. WUA returns them as VT_I4,
+// VT_ERROR or VT_UI4; an unsigned value is the bit pattern of the HRESULT, so
+// failure codes from 0x80000000 up become negative instead of overflowing.
+func variantToHResult(v *variant) (int32, error) {
+	if !variantMissing(v) {
+		if x, ok := v.Value.(uint32); ok {
+			return int32(x), nil
+		}
 	}
-	return value.(float32)
+	return variantToInt32(v)
 }
 
-func variantToString(v *ole.VARIANT) string {
-	value := v.Value()
-	if value == nil {
-		return ""
-	}
-	return value.(string)
+func variantToFloat64(v *variant) (float64, error) {
+	return variantFloat(v, 64, "float64")
 }
 
-func variantToBool(v *ole.VARIANT) bool {
-	value := v.Value()
-	if value == nil {
-		return false
-	}
-	return value.(bool)
+func variantToFloat32(v *variant) (float32, error) {
+	f, err := variantFloat(v, 32, "float32")
+	return float32(f), err
 }
 
-func variantToTime(v *ole.VARIANT) *time.Time {
-	value := v.Value()
-	if value == nil {
-		return nil
-	}
-	valueTime := value.(time.Time)
-	return &valueTime
+// toEnumErr reads an enum property as its named type.
+func toEnumErr[T ~int32](result *variant, err error) (T, error) {
+	value, err := toInt32Err(result, err)
+	return T(value), err
 }
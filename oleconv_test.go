@@ -20,8 +20,6 @@ import (
 	"errors"
 	"testing"
 	"time"
-
-	"github.com/go-ole/go-ole"
 )
 
 func TestToIDispatchErr(t *testing.T) {
@@ -37,7 +35,7 @@ func TestToIDispatchErr(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toIDispatchErr(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -61,7 +59,7 @@ func TestToInt64Err(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toInt64Err(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -85,7 +83,7 @@ func TestToInt32Err(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toInt32Err(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -109,7 +107,7 @@ func TestToFloat64Err(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toFloat64Err(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -133,7 +131,7 @@ func TestToFloat32Err(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toFloat32Err(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -157,7 +155,7 @@ func TestToStringErr(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toStringErr(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -181,7 +179,7 @@ func TestToBoolErr(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toBoolErr(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -205,7 +203,7 @@ func TestToTimeErr(t *testing.T) {
 	})
 
 	t.Run("WithNilVariant", func(t *testing.T) {
-		v := &ole.VARIANT{}
+		v := &variant{}
 		result, err := toTimeErr(v, nil)
 		if err != nil {
 			t.Errorf("expected no error, got %v", err)
@@ -218,8 +216,11 @@ func TestToTimeErr(t *testing.T) {
 
 func TestVariantToIDispatch(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToIDispatch(v)
+		v := &variant{}
+		result, err := variantToIDispatch(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != nil {
 			t.Errorf("expected nil for nil value variant, got %v", result)
 		}
@@ -228,8 +229,11 @@ func TestVariantToIDispatch(t *testing.T) {
 
 func TestVariantToInt64(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToInt64(v)
+		v := &variant{}
+		result, err := variantToInt64(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != 0 {
 			t.Errorf("expected 0 for nil value variant, got %v", result)
 		}
@@ -238,8 +242,11 @@ func TestVariantToInt64(t *testing.T) {
 
 func TestVariantToInt32(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToInt32(v)
+		v := &variant{}
+		result, err := variantToInt32(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != 0 {
 			t.Errorf("expected 0 for nil value variant, got %v", result)
 		}
@@ -248,8 +255,11 @@ func TestVariantToInt32(t *testing.T) {
 
 func TestVariantToFloat64(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToFloat64(v)
+		v := &variant{}
+		result, err := variantToFloat64(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != 0 {
 			t.Errorf("expected 0 for nil value variant, got %v", result)
 		}
@@ -258,8 +268,11 @@ func TestVariantToFloat64(t *testing.T) {
 
 func TestVariantToFloat32(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToFloat32(v)
+		v := &variant{}
+		result, err := variantToFloat32(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != 0 {
 			t.Errorf("expected 0 for nil value variant, got %v", result)
 		}
@@ -268,8 +281,11 @@ func TestVariantToFloat32(t *testing.T) {
 
 func TestVariantToString(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToString(v)
+		v := &variant{}
+		result, err := variantToString(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != "" {
 			t.Errorf("expected empty string for nil value variant, got %v", result)
 		}
@@ -278,8 +294,11 @@ func TestVariantToString(t *testing.T) {
 
 func TestVariantToBool(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToBool(v)
+		v := &variant{}
+		result, err := variantToBool(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != false {
 			t.Errorf("expected false for nil value variant, got %v", result)
 		}
@@ -288,8 +307,11 @@ func TestVariantToBool(t *testing.T) {
 
 func TestVariantToTime(t *testing.T) {
 	t.Run("WithNilValue", func(t *testing.T) {
-		v := &ole.VARIANT{}
-		result := variantToTime(v)
+		v := &variant{}
+		result, err := variantToTime(v)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
 		if result != nil {
 			t.Errorf("expected nil for nil value variant, got %v", result)
 		}
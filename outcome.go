@@ -0,0 +1,188 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpdateOutcome is the result of a download, installation or uninstallation
+// for one of its updates.
+type UpdateOutcome struct {
+	Update         *IUpdate
+	ResultCode     OperationResultCode
+	HResult        int32
+	RebootRequired bool // Always false for downloads.
+}
+
+// Failed reports whether the update was not downloaded, installed or
+// uninstalled: its result code is not Succeeded or SucceededWithErrors, which
+// includes the updates left NotStarted or InProgress by an operation that
+// stopped early, or its HResult is a failure.
+func (o UpdateOutcome) Failed() bool {
+	switch o.ResultCode {
+	case OperationResultCodeOrcSucceeded, OperationResultCodeOrcSucceededWithErrors:
+		return HRESULT(uint32(o.HResult)).Failed()
+	}
+	return true
+}
+
+// Err returns the error of a failed outcome: its HResult, or its result code
+// if WUA reported none. It returns nil if the outcome did not fail.
+func (o UpdateOutcome) Err() error {
+	if !o.Failed() {
+		return nil
+	}
+	if o.HResult != 0 {
+		return HRESULT(uint32(o.HResult))
+	}
+	return fmt.Errorf("windowsupdate: result code %v", o.ResultCode)
+}
+
+// label names the update of o in error messages.
+func (o UpdateOutcome) label() string {
+	switch {
+	case o.Update == nil:
+		return "update"
+	case o.Update.Title != "":
+		return o.Update.Title
+	case o.Update.Identity != nil:
+		return o.Update.Identity.UpdateID
+	}
+	return "update"
+}
+
+// UpdateOutcomes are the per-update results of an operation, in the order of
+// its updates.
+type UpdateOutcomes []UpdateOutcome
+
+// Failures returns the outcomes that failed.
+func (outcomes UpdateOutcomes) Failures() UpdateOutcomes {
+	var failures UpdateOutcomes
+	for _, o := range outcomes {
+		if o.Failed() {
+			failures = append(failures, o)
+		}
+	}
+	return failures
+}
+
+// RebootRequired reports whether any update requires a reboot.
+func (outcomes UpdateOutcomes) RebootRequired() bool {
+	for _, o := range outcomes {
+		if o.RebootRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns an *OutcomeError listing the failed outcomes, or nil if none
+// failed.
+func (outcomes UpdateOutcomes) Err() error {
+	failures := outcomes.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return &OutcomeError{Failures: failures, Total: len(outcomes)}
+}
+
+// OutcomeError is the error of an operation in which some updates failed.
+// errors.Is and errors.As match the errors of each failure, e.g.
+// errors.Is(err, ErrRebootRequired).
+type OutcomeError struct {
+	Failures UpdateOutcomes
+	Total    int // Number of updates in the operation.
+}
+
+func (e *OutcomeError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "windowsupdate: %d of %d updates failed", len(e.Failures), e.Total)
+	for i, o := range e.Failures {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "%s: %v", o.label(), o.Err())
+	}
+	return b.String()
+}
+
+func (e *OutcomeError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, o := range e.Failures {
+		errs[i] = o.Err()
+	}
+	return errs
+}
+
+// NewDownloadResult returns an IDownloadResult holding outcomes instead of a
+// COM object, for other implementations of Downloader such as package wufake.
+func NewDownloadResult(resultCode OperationResultCode, hResult int32, outcomes UpdateOutcomes) *IDownloadResult {
+	return &IDownloadResult{HResult: hResult, ResultCode: resultCode, outcomes: outcomes}
+}
+
+// NewInstallationResult returns an IInstallationResult holding outcomes instead
+// of a COM object, for other implementations of Installer such as package
+// wufake. RebootRequired is that of outcomes.
+func NewInstallationResult(resultCode OperationResultCode, hResult int32, outcomes UpdateOutcomes) *IInstallationResult {
+	return &IInstallationResult{
+		HResult:        hResult,
+		RebootRequired: outcomes.RebootRequired(),
+		ResultCode:     resultCode,
+		outcomes:       outcomes,
+	}
+}
+
+// Outcomes returns the result of the download for each of updates, which must
+// be the updates passed to Download, BeginDownload or DownloadContext.
+func (iDownloadResult *IDownloadResult) Outcomes(updates []*IUpdate) (UpdateOutcomes, error) {
+	outcomes := make(UpdateOutcomes, len(updates))
+	for i, update := range updates {
+		r, err := iDownloadResult.GetUpdateResult(int32(i))
+		if err != nil {
+			return nil, err
+		}
+		outcomes[i] = UpdateOutcome{Update: update, ResultCode: r.ResultCode, HResult: r.HResult}
+		r.Release()
+	}
+	return outcomes, nil
+}
+
+// Outcomes returns the result of the installation or uninstallation for each
+// of updates, which must be the updates passed to the Install or Uninstall
+// call.
+func (iInstallationResult *IInstallationResult) Outcomes(updates []*IUpdate) (UpdateOutcomes, error) {
+	outcomes := make(UpdateOutcomes, len(updates))
+	for i, update := range updates {
+		r, err := iInstallationResult.GetUpdateInstallationResult(int32(i))
+		if err != nil {
+			return nil, err
+		}
+		outcomes[i] = UpdateOutcome{Update: update, ResultCode: r.ResultCode, HResult: r.HResult, RebootRequired: r.RebootRequired}
+		r.Release()
+	}
+	return outcomes, nil
+}
+
+// storedOutcome returns the outcome at updateIndex of a result created by
+// NewDownloadResult or NewInstallationResult, which have no COM object.
+func storedOutcome(outcomes UpdateOutcomes, updateIndex int32) (UpdateOutcome, error) {
+	if updateIndex < 0 || int(updateIndex) >= len(outcomes) {
+		return UpdateOutcome{}, HRESULT(0x80240007) // WU_E_INVALIDINDEX
+	}
+	return outcomes[updateIndex], nil
+}
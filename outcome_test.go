@@ -0,0 +1,175 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"testing"
+)
+
+// newFakeResult returns an IDownloadResult or IInstallationResult COM object
+// whose GetUpdateResult returns the given per-update results.
+func newFakeResult(updateResults ...*fakeDispatcher) *fakeDispatcher {
+	return newFakeDispatcher(map[string]interface{}{
+		"HResult":        int32(0),
+		"RebootRequired": false,
+		"ResultCode":     OperationResultCodeOrcSucceededWithErrors,
+		"GetUpdateResult": fakeMember(func(params ...interface{}) (interface{}, error) {
+			return updateResults[params[0].(int32)], nil
+		}),
+	})
+}
+
+func newFakeUpdateResult(resultCode OperationResultCode, hResult int32, rebootRequired bool) *fakeDispatcher {
+	return newFakeDispatcher(map[string]interface{}{
+		"HResult":        hResult,
+		"RebootRequired": rebootRequired,
+		"ResultCode":     resultCode,
+	})
+}
+
+func TestUpdateOutcome_Failed(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome UpdateOutcome
+		failed  bool
+	}{
+		{"Succeeded", UpdateOutcome{ResultCode: OperationResultCodeOrcSucceeded}, false},
+		{"SucceededWithErrors", UpdateOutcome{ResultCode: OperationResultCodeOrcSucceededWithErrors}, false},
+		{"Succeeded with a failing HResult", UpdateOutcome{ResultCode: OperationResultCodeOrcSucceeded, HResult: -2145124330}, true},
+		{"NotStarted", UpdateOutcome{ResultCode: OperationResultCodeOrcNotStarted}, true},
+		{"InProgress", UpdateOutcome{ResultCode: OperationResultCodeOrcInProgress}, true},
+		{"Failed", UpdateOutcome{ResultCode: OperationResultCodeOrcFailed, HResult: -2145124330}, true},
+		{"Aborted", UpdateOutcome{ResultCode: OperationResultCodeOrcAborted}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.outcome.Failed(); got != tt.failed {
+			t.Errorf("%s: Failed() = %v, want %v", tt.name, got, tt.failed)
+		}
+		if err := tt.outcome.Err(); (err != nil) != tt.failed {
+			t.Errorf("%s: Err() = %v", tt.name, err)
+		}
+	}
+	err := UpdateOutcomes{{ResultCode: OperationResultCodeOrcSucceeded}, {ResultCode: OperationResultCodeOrcNotStarted}}.Err()
+	if want := "windowsupdate: 1 of 2 updates failed: update: windowsupdate: result code NotStarted"; err == nil || err.Error() != want {
+		t.Errorf("Err() = %v, want %s", err, want)
+	}
+}
+
+func TestIInstallationResult_Outcomes(t *testing.T) {
+	updates := []*IUpdate{{Title: "a"}, {Title: "b"}, {Title: "c"}}
+	perUpdate := []*fakeDispatcher{
+		newFakeUpdateResult(OperationResultCodeOrcSucceeded, 0, true),
+		newFakeUpdateResult(OperationResultCodeOrcFailed, -2145124330, false), // WU_E_INSTALL_NOT_ALLOWED
+		newFakeUpdateResult(OperationResultCodeOrcSucceeded, 0, false),
+	}
+	result, err := toIInstallationResult(newFakeResult(perUpdate...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outcomes, err := result.Outcomes(updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outcomes) != 3 || outcomes[1].Update != updates[1] || !outcomes[0].RebootRequired || outcomes[1].ResultCode != OperationResultCodeOrcFailed {
+		t.Errorf("Outcomes() = %+v", outcomes)
+	}
+	if !outcomes.RebootRequired() {
+		t.Error("RebootRequired() = false, want true")
+	}
+	for i, d := range perUpdate {
+		if d.Releases() != 1 {
+			t.Errorf("update result %d released %d times, want 1", i, d.Releases())
+		}
+	}
+
+	err = outcomes.Err()
+	var outcomeErr *OutcomeError
+	if !errors.As(err, &outcomeErr) || len(outcomeErr.Failures) != 1 || outcomeErr.Failures[0].Update != updates[1] {
+		t.Fatalf("Err() = %v, want an *OutcomeError for b", err)
+	}
+	if !errors.Is(err, ErrInstallNotAllowed) {
+		t.Errorf("errors.Is(%v, ErrInstallNotAllowed) = false, want true", err)
+	}
+	if want := "windowsupdate: 1 of 3 updates failed: b: windowsupdate: WU_E_INSTALL_NOT_ALLOWED (0x80240016): " + HRESULT(0x80240016).Description(); err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestIDownloadResult_Outcomes(t *testing.T) {
+	updates := []*IUpdate{{Title: "a"}, {Title: "b"}}
+	result, err := toIDownloadResult(newFakeResult(
+		newFakeUpdateResult(OperationResultCodeOrcSucceeded, 0, false),
+		newFakeUpdateResult(OperationResultCodeOrcAborted, 0, false),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcomes, err := result.Outcomes(updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := outcomes.Failures()
+	if len(failures) != 1 || failures[0].Update != updates[1] {
+		t.Fatalf("Failures() = %+v, want b", failures)
+	}
+	if err := failures[0].Err(); err == nil || err.Error() != "windowsupdate: result code Aborted" {
+		t.Errorf("Err() = %v, want result code Aborted", err)
+	}
+}
+
+func TestIInstallationResult_GetUpdateResult(t *testing.T) {
+	updateResult := newFakeUpdateResult(OperationResultCodeOrcSucceeded, 0, true)
+	result, err := toIInstallationResult(newFakeResult(updateResult))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := result.GetUpdateResult(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.RebootRequired || r.ResultCode != OperationResultCodeOrcSucceeded {
+		t.Errorf("GetUpdateResult(0) = %+v, want succeeded with RebootRequired", r)
+	}
+	r.Release()
+	if updateResult.Releases() != 1 {
+		t.Errorf("releasing the update result released %d times, want 1", updateResult.Releases())
+	}
+}
+
+func TestNewInstallationResult(t *testing.T) {
+	updates := []*IUpdate{{Title: "a"}}
+	result := NewInstallationResult(OperationResultCodeOrcSucceeded, 0, UpdateOutcomes{
+		{Update: updates[0], ResultCode: OperationResultCodeOrcSucceeded, RebootRequired: true},
+	})
+	if !result.RebootRequired {
+		t.Error("RebootRequired = false, want that of the outcomes")
+	}
+	outcomes, err := result.Outcomes(updates)
+	if err != nil || len(outcomes) != 1 || !outcomes[0].RebootRequired || outcomes.Err() != nil {
+		t.Errorf("Outcomes() = %+v, %v", outcomes, err)
+	}
+	if _, err := result.GetUpdateInstallationResult(1); !errors.Is(err, HRESULT(0x80240007)) {
+		t.Errorf("GetUpdateInstallationResult(1) error = %v, want WU_E_INVALIDINDEX", err)
+	}
+
+	download := NewDownloadResult(OperationResultCodeOrcFailed, -2145124316, UpdateOutcomes{
+		{Update: updates[0], ResultCode: OperationResultCodeOrcFailed, HResult: -2145124316},
+	})
+	outcomes, err = download.Outcomes(updates)
+	if err != nil || !errors.Is(outcomes.Err(), ErrNoUpdate) {
+		t.Errorf("Outcomes() = %+v, %v, want a failure matching ErrNoUpdate", outcomes, err)
+	}
+}
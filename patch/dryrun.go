@@ -0,0 +1,181 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+// DryRun is what Run would do with the updates of a search, worked out
+// without accepting, downloading or installing anything. It marshals to JSON
+// and renders with WriteMarkdown and WriteTable.
+type DryRun struct {
+	Criteria string `json:",omitempty"`
+	// Updates are the updates found by the search, in its order.
+	Updates []PlannedUpdate
+	Summary DryRunSummary
+}
+
+// PlannedUpdate is what Run would do with one update.
+type PlannedUpdate struct {
+	UpdateID     string
+	Title        string
+	KBArticleIDs []string `json:",omitempty"`
+	Install      bool
+	SkipReason   string `json:",omitempty"`
+	// Downloaded reports whether the update is downloaded already, in which
+	// case Run does not download it again.
+	Downloaded      bool
+	MinDownloadSize int64
+	MaxDownloadSize int64
+	// EulaRequired reports whether Run would accept the EULA of the update.
+	EulaRequired bool
+	// RebootBehavior and Impact are those of InstallationBehavior. An update
+	// whose InstallationBehavior was not loaded is assumed to be able to
+	// request a reboot, with a normal impact.
+	RebootBehavior windowsupdate.InstallationRebootBehavior
+	Impact         windowsupdate.InstallationImpact
+}
+
+// DryRunSummary totals the updates Run would install.
+type DryRunSummary struct {
+	Install int
+	Skip    int
+	// AlreadyDownloaded counts the updates to install that are downloaded.
+	AlreadyDownloaded int
+	EulasToAccept     int
+	// MinDownloadSize and MaxDownloadSize total the updates to download.
+	MinDownloadSize int64
+	MaxDownloadSize int64
+	// RebootBehavior is the most disruptive of the updates to install:
+	// AlwaysRequiresReboot, then CanRequestReboot, then NeverReboots.
+	RebootBehavior windowsupdate.InstallationRebootBehavior
+	// ExclusiveHandling reports whether an update to install has the impact
+	// RequiresExclusiveHandling, meaning it must be installed on its own.
+	ExclusiveHandling bool
+}
+
+// rebootRank orders the reboot behaviors from the least to the most
+// disruptive.
+var rebootRank = map[windowsupdate.InstallationRebootBehavior]int{
+	windowsupdate.InstallationRebootBehaviorIrbNeverReboots:         0,
+	windowsupdate.InstallationRebootBehaviorIrbCanRequestReboot:     1,
+	windowsupdate.InstallationRebootBehaviorIrbAlwaysRequiresReboot: 2,
+}
+
+// dryRunFields are the fields NewDryRun plans with. A search with fewer
+// fields, such as windowsupdate.MinimalUpdateFields, leaves them unloaded.
+const dryRunFields = windowsupdate.UpdateFieldState | windowsupdate.UpdateFieldBehavior
+
+// NewDryRun plans the updates of result as Run would with approve, which is
+// ApproveAll if nil. It first loads the state and installation behavior of
+// updates found without them.
+func NewDryRun(result *windowsupdate.ISearchResult, approve Approver) (*DryRun, error) {
+	if approve == nil {
+		approve = ApproveAll
+	}
+	d := &DryRun{}
+	for _, update := range result.Updates {
+		if err := update.Load(dryRunFields); err != nil {
+			return nil, err
+		}
+		p := PlannedUpdate{
+			Title:           update.Title,
+			KBArticleIDs:    update.KBArticleIDs,
+			Downloaded:      update.IsDownloaded,
+			MinDownloadSize: update.MinDownloadSize,
+			MaxDownloadSize: update.MaxDownloadSize,
+			EulaRequired:    !update.EulaAccepted,
+			RebootBehavior:  windowsupdate.InstallationRebootBehaviorIrbCanRequestReboot,
+		}
+		if update.Identity != nil {
+			p.UpdateID = update.Identity.UpdateID
+		}
+		if b := update.InstallationBehavior; b != nil {
+			p.RebootBehavior, p.Impact = b.RebootBehavior, b.Impact
+		}
+		p.Install, p.SkipReason = approve(update)
+		if !p.Install && p.SkipReason == "" {
+			p.SkipReason = "not approved"
+		}
+		d.Updates = append(d.Updates, p)
+		d.Summary.add(p)
+	}
+	return d, nil
+}
+
+func (s *DryRunSummary) add(p PlannedUpdate) {
+	if !p.Install {
+		s.Skip++
+		return
+	}
+	if s.Install == 0 || rebootRank[p.RebootBehavior] > rebootRank[s.RebootBehavior] {
+		s.RebootBehavior = p.RebootBehavior
+	}
+	s.Install++
+	if p.Downloaded {
+		s.AlreadyDownloaded++
+	} else {
+		s.MinDownloadSize += p.MinDownloadSize
+		s.MaxDownloadSize += p.MaxDownloadSize
+	}
+	if p.EulaRequired {
+		s.EulasToAccept++
+	}
+	if p.Impact == windowsupdate.InstallationImpactIiRequiresExclusiveHandling {
+		s.ExclusiveHandling = true
+	}
+}
+
+// DryRun searches and approves updates as Run would, and returns the plan of
+// what Run would do with them.
+func (p Plan) DryRun(ctx context.Context) (*DryRun, error) {
+	if p.Criteria == "" {
+		p.Criteria = DefaultCriteria
+	}
+	if p.Search == nil {
+		if p.Session == nil {
+			return nil, errNoSession
+		}
+		searcher, err := p.Session.CreateSearcher()
+		if err != nil {
+			return nil, err
+		}
+		defer searcher.Release()
+		p.Search = SearchWith(searcher)
+	}
+	result, err := p.Search(ctx, p.Criteria)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Release()
+	d, err := NewDryRun(result, p.Approve)
+	if err != nil {
+		return nil, err
+	}
+	d.Criteria = p.Criteria
+	return d, nil
+}
+
+// kbs returns the KB article IDs of p as "KB5034441, KB5034439".
+func (p PlannedUpdate) kbs() string {
+	kbs := make([]string, len(p.KBArticleIDs))
+	for i, id := range p.KBArticleIDs {
+		kbs[i] = "KB" + strings.TrimPrefix(id, "KB")
+	}
+	return strings.Join(kbs, ", ")
+}
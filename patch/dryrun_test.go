@@ -0,0 +1,206 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/filter"
+	"github.com/ceshihao/windowsupdate/wufake"
+)
+
+// newPlannedUpdates returns a cumulative update that is downloaded and always
+// reboots, a driver whose EULA is not accepted and a low severity update.
+func newPlannedUpdates() []*windowsupdate.IUpdate {
+	cumulative, driver, low := wufake.NewUpdate("a"), wufake.NewUpdate("b"), wufake.NewUpdate("c")
+	cumulative.Title = "2026-01 Cumulative Update"
+	cumulative.KBArticleIDs = []string{"5034441"}
+	cumulative.IsDownloaded = true
+	cumulative.MinDownloadSize, cumulative.MaxDownloadSize = 300<<20, 800<<20
+	cumulative.InstallationBehavior = &windowsupdate.IInstallationBehavior{
+		RebootBehavior: windowsupdate.InstallationRebootBehaviorIrbAlwaysRequiresReboot,
+	}
+	driver.Title = "Display | driver"
+	driver.EulaAccepted = false
+	driver.MinDownloadSize, driver.MaxDownloadSize = 1536<<10, 20<<20
+	driver.InstallationBehavior = &windowsupdate.IInstallationBehavior{
+		RebootBehavior: windowsupdate.InstallationRebootBehaviorIrbNeverReboots,
+		Impact:         windowsupdate.InstallationImpactIiRequiresExclusiveHandling,
+	}
+	low.Title = "Low severity update"
+	low.MsrcSeverity = "Low"
+	low.MinDownloadSize, low.MaxDownloadSize = 1<<30, 1<<30
+	return []*windowsupdate.IUpdate{cumulative, driver, low}
+}
+
+func newTestDryRun(t *testing.T) *DryRun {
+	t.Helper()
+	d, err := NewDryRun(&windowsupdate.ISearchResult{Updates: newPlannedUpdates()}, ApproveFilter(filter.MustParse("severity!=Low")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Criteria = DefaultCriteria
+	return d
+}
+
+func TestNewDryRun(t *testing.T) {
+	d := newTestDryRun(t)
+	want := DryRunSummary{
+		Install:           2,
+		Skip:              1,
+		AlreadyDownloaded: 1,
+		EulasToAccept:     1,
+		MinDownloadSize:   1536 << 10,
+		MaxDownloadSize:   20 << 20,
+		RebootBehavior:    windowsupdate.InstallationRebootBehaviorIrbAlwaysRequiresReboot,
+		ExclusiveHandling: true,
+	}
+	if d.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", d.Summary, want)
+	}
+	if p := d.Updates[2]; p.Install || p.SkipReason != "does not match severity!=Low" {
+		t.Errorf("low severity update = %+v, want skipped", p)
+	}
+	if p := d.Updates[0]; p.UpdateID != "a" || !p.Downloaded || p.EulaRequired {
+		t.Errorf("cumulative update = %+v", p)
+	}
+}
+
+func TestNewDryRun_UnknownBehavior(t *testing.T) {
+	a := wufake.NewUpdate("a")
+	d, err := NewDryRun(&windowsupdate.ISearchResult{Updates: []*windowsupdate.IUpdate{a}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Summary.RebootBehavior != windowsupdate.InstallationRebootBehaviorIrbCanRequestReboot {
+		t.Errorf("RebootBehavior = %v, want CanRequestReboot without an InstallationBehavior", d.Summary.RebootBehavior)
+	}
+}
+
+func TestPlan_DryRun(t *testing.T) {
+	catalog := &wufake.Catalog{Updates: newPlannedUpdates()}
+	d, err := Plan{Session: wufake.NewSession(catalog)}.DryRun(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Criteria != DefaultCriteria || d.Summary.Install != 3 {
+		t.Errorf("DryRun() = %+v", d)
+	}
+	if calls := catalog.Calls(); len(calls) != 1 || calls[0].Op != "Search" {
+		t.Errorf("catalog calls = %+v, want only a search", calls)
+	}
+	if n := catalog.Open(); n != 0 {
+		t.Errorf("%d objects left open after DryRun", n)
+	}
+}
+
+func TestPlan_DryRunMinimalSearch(t *testing.T) {
+	d, err := Plan{Criteria: "IsInstalled=0", Search: newMinimalSearch(t)}.DryRun(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Updates) != 1 {
+		t.Fatalf("DryRun() planned %d updates, want 1", len(d.Updates))
+	}
+	p := d.Updates[0]
+	if !p.Downloaded || p.EulaRequired || p.RebootBehavior != windowsupdate.InstallationRebootBehaviorIrbAlwaysRequiresReboot {
+		t.Errorf("planned update = %+v, want it downloaded, with its EULA accepted and always requiring a reboot", p)
+	}
+}
+
+func TestDryRun_JSON(t *testing.T) {
+	d := newTestDryRun(t)
+	var buf bytes.Buffer
+	if err := d.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"RebootBehavior": "AlwaysRequiresReboot"`)) {
+		t.Errorf("JSON does not name the reboot behavior:\n%s", buf.Bytes())
+	}
+	var decoded DryRun
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, d) {
+		t.Errorf("decoded = %+v, want %+v", decoded, d)
+	}
+}
+
+func TestDryRun_WriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestDryRun(t).WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "# Update plan\n" +
+		"\n" +
+		"Criteria: `IsInstalled=0 and IsHidden=0`\n" +
+		"\n" +
+		"| Action | Update | KB | Download | Reboot | Impact | Notes |\n" +
+		"|---|---|---|---|---|---|---|\n" +
+		"| install | 2026-01 Cumulative Update | KB5034441 | downloaded | AlwaysRequiresReboot | Normal |  |\n" +
+		"| install | Display \\| driver |  | 1.5 MB to 20 MB | NeverReboots | RequiresExclusiveHandling | EULA to accept |\n" +
+		"| skip | Low severity update |  | 1 GB | CanRequestReboot | Normal | does not match severity!=Low |\n" +
+		"\n" +
+		"- Install 2, skip 1.\n" +
+		"- Download: 1.5 MB to 20 MB (1 already downloaded).\n" +
+		"- EULAs to accept: 1.\n" +
+		"- Reboot: AlwaysRequiresReboot.\n" +
+		"- An update requires exclusive handling and must be installed on its own.\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDryRun_WriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	d := newTestDryRun(t)
+	d.Updates = d.Updates[:1]
+	if err := d.WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "ACTION   UPDATE                     KB         DOWNLOAD    REBOOT                IMPACT  NOTES\n" +
+		"install  2026-01 Cumulative Update  KB5034441  downloaded  AlwaysRequiresReboot  Normal  \n" +
+		"\n" +
+		"Install 2, skip 1.\n" +
+		"Download: 1.5 MB to 20 MB (1 already downloaded).\n" +
+		"EULAs to accept: 1.\n" +
+		"Reboot: AlwaysRequiresReboot.\n" +
+		"An update requires exclusive handling and must be installed on its own.\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTable() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1 KB"},
+		{1536 << 10, "1.5 MB"},
+		{5 << 40, "5 TB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package patch runs a complete patch cycle: it searches for updates, approves
+// some of them, accepts their license terms, downloads and installs them, and
+// reports what happened to each:
+//
+//	report, err := patch.Run(ctx, patch.Plan{
+//		Session: session,
+//		Approve: patch.ApproveFilter(filter.MustParse("severity>=Important")),
+//	})
+//
+// Each stage is a function in the Plan, so any of them can be replaced, e.g.
+// by stand-ins in tests or by the retrying stages RetryDownload and
+// RetryInstall.
+package patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+// DefaultCriteria is the search criteria of a Plan without one.
+const DefaultCriteria = "IsInstalled=0 and IsHidden=0"
+
+// errNoSession is returned for a Plan with neither a Session nor all of the
+// stages it would create.
+var errNoSession = errors.New("patch: the plan has no Session to create its stages")
+
+// Stage is a stage of a Run.
+type Stage int
+
+// The stages of a Run, in order.
+const (
+	StageSearch Stage = iota
+	StageApprove
+	StageEula
+	StageDownload
+	StageInstall
+)
+
+var stageNames = []string{"Search", "Approve", "Eula", "Download", "Install"}
+
+func (s Stage) String() string {
+	if s >= 0 && int(s) < len(stageNames) {
+		return stageNames[s]
+	}
+	return fmt.Sprintf("Stage(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Stage) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Stage) UnmarshalText(text []byte) error {
+	for i, name := range stageNames {
+		if strings.EqualFold(name, string(text)) {
+			*s = Stage(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("patch: unknown stage %q", text)
+}
+
+// Hooks are called around each stage that runs.
+type Hooks struct {
+	// Before is called with the updates the stage is about to process; the
+	// found updates for StageApprove and none for StageSearch. An error stops
+	// the run before the stage.
+	Before func(ctx context.Context, stage Stage, updates []*windowsupdate.IUpdate) error
+	// After is called with the record of the stage, whether it failed or not.
+	After func(ctx context.Context, stage StageReport)
+}
+
+// Plan describes a Run. Only Session is required, to create the searcher,
+// downloader and installer of the stages left nil.
+type Plan struct {
+	Session windowsupdate.Session
+	// Criteria is the search criteria; DefaultCriteria if empty.
+	Criteria string
+
+	// The stages. A nil Search, Download or Install uses SearchWith,
+	// DownloadWith or InstallWith with an object created by Session, a nil
+	// Approve is ApproveAll and a nil AcceptEula is AcceptEula.
+	Search     SearchFunc
+	Approve    Approver
+	AcceptEula EulaFunc
+	Download   DownloadFunc
+	Install    InstallFunc
+
+	Hooks Hooks
+
+	// Now returns the times of the report. If nil, time.Now is used.
+	Now func() time.Time
+}
+
+// withDefaults returns p with its nil stages set, and a function releasing the
+// objects it created for them.
+func (p Plan) withDefaults() (Plan, func(), error) {
+	if p.Criteria == "" {
+		p.Criteria = DefaultCriteria
+	}
+	if p.Approve == nil {
+		p.Approve = ApproveAll
+	}
+	if p.AcceptEula == nil {
+		p.AcceptEula = AcceptEula
+	}
+	if p.Now == nil {
+		p.Now = time.Now
+	}
+	var created []interface{ Release() }
+	release := func() {
+		for _, object := range created {
+			object.Release()
+		}
+	}
+	if (p.Search == nil || p.Download == nil || p.Install == nil) && p.Session == nil {
+		return p, release, errNoSession
+	}
+	if p.Search == nil {
+		searcher, err := p.Session.CreateSearcher()
+		if err != nil {
+			return p, release, err
+		}
+		created = append(created, searcher)
+		p.Search = SearchWith(searcher)
+	}
+	if p.Download == nil {
+		downloader, err := p.Session.CreateDownloader()
+		if err != nil {
+			return p, release, err
+		}
+		created = append(created, downloader)
+		p.Download = DownloadWith(downloader)
+	}
+	if p.Install == nil {
+		installer, err := p.Session.CreateInstaller()
+		if err != nil {
+			return p, release, err
+		}
+		created = append(created, installer)
+		p.Install = InstallWith(installer)
+	}
+	return p, release, nil
+}
+
+// Run searches for updates and installs those approved, after accepting their
+// EULA and downloading those not downloaded yet. Updates whose EULA or
+// download fails are skipped; the others are installed together. The state
+// of updates found without it, e.g. with windowsupdate.MinimalUpdateFields, is
+// loaded by the search stage.
+//
+// It returns the report of the run even when it fails. The error is that of
+// the stage or hook that stopped the run, or else the
+// *windowsupdate.OutcomeError of the updates that failed to download or
+// install, as returned by RunReport.Err.
+//
+// Run releases the objects it created and the search result before it
+// returns. The fields of the updates in the report stay readable, but their
+// methods must not be called.
+func Run(ctx context.Context, plan Plan) (*RunReport, error) {
+	plan, release, err := plan.withDefaults()
+	defer release()
+	r := &runner{plan: plan, report: &RunReport{Started: plan.Now(), Criteria: plan.Criteria}}
+	defer func() { r.result.Release() }()
+	if err == nil {
+		err = r.run(ctx)
+	}
+	r.report.Finished = plan.Now()
+	if err != nil {
+		return r.report, err
+	}
+	return r.report, r.report.Err()
+}
+
+type runner struct {
+	plan   Plan
+	report *RunReport
+	result *windowsupdate.ISearchResult
+}
+
+func (r *runner) run(ctx context.Context) error {
+	err := r.stage(ctx, StageSearch, nil, func(stage *StageReport) error {
+		result, err := r.plan.Search(ctx, r.plan.Criteria)
+		if err != nil {
+			return err
+		}
+		r.result = result
+		for _, update := range result.Updates {
+			if err := update.Load(windowsupdate.UpdateFieldState); err != nil {
+				return err
+			}
+			r.report.Updates = append(r.report.Updates, newUpdateReport(update))
+		}
+		stage.Updates = len(result.Updates)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.stage(ctx, StageApprove, r.updates(func(*UpdateReport) bool { return true }), func(stage *StageReport) error {
+		for _, u := range r.report.Updates {
+			u.Approved, u.SkipReason = r.plan.Approve(u.Update)
+			if !u.Approved && u.SkipReason == "" {
+				u.SkipReason = "not approved"
+			}
+		}
+		stage.Updates = len(r.report.Updates)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	needEula := r.updates(func(u *UpdateReport) bool { return u.Approved && !u.Update.EulaAccepted })
+	err = r.stage(ctx, StageEula, needEula, func(stage *StageReport) error {
+		for _, u := range r.report.Updates {
+			if !u.Approved || u.Update.EulaAccepted {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := r.plan.AcceptEula(ctx, u.Update); err != nil {
+				u.skip(fmt.Sprintf("accepting EULA: %v", err))
+				continue
+			}
+			u.EulaAccepted = true
+		}
+		stage.Updates = len(needEula)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	toDownload := r.updates(func(u *UpdateReport) bool { return u.Approved && !u.Update.IsDownloaded })
+	err = r.stage(ctx, StageDownload, toDownload, func(stage *StageReport) error {
+		result, err := r.plan.Download(ctx, toDownload)
+		if err != nil {
+			return err
+		}
+		defer result.Release()
+		outcomes, err := result.Outcomes(toDownload)
+		if err != nil {
+			return err
+		}
+		for i, o := range outcomes {
+			u := r.report.find(toDownload[i])
+			u.Download = newOutcome(o)
+			if o.Failed() {
+				u.skip(fmt.Sprintf("download failed: %v", o.Err()))
+			}
+		}
+		stage.Updates = len(toDownload)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	toInstall := r.updates(func(u *UpdateReport) bool { return u.Approved })
+	return r.stage(ctx, StageInstall, toInstall, func(stage *StageReport) error {
+		result, err := r.plan.Install(ctx, toInstall)
+		if err != nil {
+			return err
+		}
+		defer result.Release()
+		outcomes, err := result.Outcomes(toInstall)
+		if err != nil {
+			return err
+		}
+		for i, o := range outcomes {
+			r.report.find(toInstall[i]).Install = newOutcome(o)
+		}
+		r.report.RebootRequired = result.RebootRequired
+		stage.Updates = len(toInstall)
+		return nil
+	})
+}
+
+// stage runs a stage with its hooks and records it. Stages other than the
+// search are skipped when they have no updates to process.
+func (r *runner) stage(ctx context.Context, stage Stage, updates []*windowsupdate.IUpdate, run func(*StageReport) error) error {
+	if stage != StageSearch && len(updates) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.plan.Hooks.Before != nil {
+		if err := r.plan.Hooks.Before(ctx, stage, updates); err != nil {
+			return fmt.Errorf("patch: before %s: %w", strings.ToLower(stage.String()), err)
+		}
+	}
+	report := StageReport{Stage: stage, Started: r.plan.Now()}
+	err := run(&report)
+	if err != nil {
+		err = fmt.Errorf("patch: %s: %w", strings.ToLower(stage.String()), err)
+		report.Err, report.Error = err, err.Error()
+	}
+	report.Finished = r.plan.Now()
+	r.report.Stages = append(r.report.Stages, report)
+	if r.plan.Hooks.After != nil {
+		r.plan.Hooks.After(ctx, report)
+	}
+	return err
+}
+
+// updates returns the updates whose report satisfies keep, in order.
+func (r *runner) updates(keep func(*UpdateReport) bool) []*windowsupdate.IUpdate {
+	var updates []*windowsupdate.IUpdate
+	for _, u := range r.report.Updates {
+		if keep(u) {
+			updates = append(updates, u.Update)
+		}
+	}
+	return updates
+}
+
+func (r *RunReport) find(update *windowsupdate.IUpdate) *UpdateReport {
+	for _, u := range r.Updates {
+		if u.Update == update {
+			return u
+		}
+	}
+	return nil
+}
+
+func newUpdateReport(update *windowsupdate.IUpdate) *UpdateReport {
+	u := &UpdateReport{Update: update, Title: update.Title}
+	if update.Identity != nil {
+		u.UpdateID = update.Identity.UpdateID
+	}
+	return u
+}
+
+// skip withdraws the approval of u for reason.
+func (u *UpdateReport) skip(reason string) {
+	u.Approved = false
+	u.SkipReason = reason
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/filter"
+	"github.com/ceshihao/windowsupdate/wufake"
+)
+
+// newMinimalSearch returns a search stage replaying testdata/minimal_search.json,
+// where a searcher with MinimalUpdateFields finds one update. The update is
+// downloaded, its EULA is accepted and it always requires a reboot, but none
+// of that is loaded by the search.
+func newMinimalSearch(t *testing.T) SearchFunc {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "minimal_search.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fixture, err := windowsupdate.ReadFixture(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := windowsupdate.NewReplaySession(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	searcher.SetUpdateFields(windowsupdate.MinimalUpdateFields)
+	return func(ctx context.Context, criteria string) (*windowsupdate.ISearchResult, error) {
+		return searcher.Search(criteria)
+	}
+}
+
+// succeeded returns the outcomes of a stand-in operation in which updates
+// succeeded.
+func succeeded(updates []*windowsupdate.IUpdate) windowsupdate.UpdateOutcomes {
+	outcomes := make(windowsupdate.UpdateOutcomes, len(updates))
+	for i, update := range updates {
+		outcomes[i] = windowsupdate.UpdateOutcome{Update: update, ResultCode: windowsupdate.OperationResultCodeOrcSucceeded}
+	}
+	return outcomes
+}
+
+// acceptEula is a stand-in for IUpdate.AcceptEula, which needs COM, recording
+// the updates it accepted.
+func acceptEula(accepted *[]string) EulaFunc {
+	return func(ctx context.Context, update *windowsupdate.IUpdate) error {
+		*accepted = append(*accepted, update.Identity.UpdateID)
+		update.EulaAccepted = true
+		return nil
+	}
+}
+
+// recordStages returns hooks recording the stages run, as "Before Search" and
+// "After Search".
+func recordStages(stages *[]string) Hooks {
+	return Hooks{
+		Before: func(ctx context.Context, stage Stage, updates []*windowsupdate.IUpdate) error {
+			*stages = append(*stages, "Before "+stage.String())
+			return nil
+		},
+		After: func(ctx context.Context, stage StageReport) {
+			*stages = append(*stages, "After "+stage.Stage.String())
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	a, b, c, d := wufake.NewUpdate("a"), wufake.NewUpdate("b"), wufake.NewUpdate("c"), wufake.NewUpdate("d")
+	a.EulaAccepted = false
+	b.MsrcSeverity = "Low"
+	c.IsDownloaded = true
+	catalog := &wufake.Catalog{
+		Updates: []*windowsupdate.IUpdate{a, b, c, d},
+		DownloadOutcomes: map[string]wufake.Outcome{
+			"d": {ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: -2145107951},
+		},
+		InstallOutcomes: map[string]wufake.Outcome{
+			"c": {ResultCode: windowsupdate.OperationResultCodeOrcSucceeded, RebootRequired: true},
+		},
+	}
+	var accepted, stages []string
+	report, err := Run(context.Background(), Plan{
+		Session:    wufake.NewSession(catalog),
+		Approve:    ApproveFilter(filter.MustParse("severity!=Low")),
+		AcceptEula: acceptEula(&accepted),
+		Hooks:      recordStages(&stages),
+	})
+
+	var outcomeErr *windowsupdate.OutcomeError
+	if !errors.As(err, &outcomeErr) || len(outcomeErr.Failures) != 1 || outcomeErr.Failures[0].Update != d {
+		t.Fatalf("Run() error = %v, want an OutcomeError for the download of d", err)
+	}
+	wantStages := []string{
+		"Before Search", "After Search", "Before Approve", "After Approve", "Before Eula", "After Eula",
+		"Before Download", "After Download", "Before Install", "After Install",
+	}
+	if !slices.Equal(stages, wantStages) {
+		t.Errorf("stages = %v, want %v", stages, wantStages)
+	}
+	if !slices.Equal(accepted, []string{"a"}) {
+		t.Errorf("accepted EULAs of %v, want [a]", accepted)
+	}
+	calls := catalog.Calls()
+	if len(calls) != 3 || !slices.Equal(calls[1].UpdateIDs, []string{"a", "d"}) || !slices.Equal(calls[2].UpdateIDs, []string{"a", "c"}) {
+		t.Errorf("catalog calls = %+v, want a search, a download of a and d and an install of a and c", calls)
+	}
+
+	if len(report.Updates) != 4 {
+		t.Fatalf("report has %d updates, want 4", len(report.Updates))
+	}
+	if u := report.Updates[0]; !u.Approved || !u.EulaAccepted || u.Download == nil || u.Install == nil {
+		t.Errorf("report of a = %+v", u)
+	}
+	if u := report.Updates[1]; u.Approved || !strings.Contains(u.SkipReason, "severity!=Low") || u.Install != nil {
+		t.Errorf("report of b = %+v", u)
+	}
+	if u := report.Updates[2]; !u.Approved || u.Download != nil || u.Install == nil || !u.Install.RebootRequired {
+		t.Errorf("report of c = %+v", u)
+	}
+	if u := report.Updates[3]; u.Approved || !strings.HasPrefix(u.SkipReason, "download failed") || u.Install != nil {
+		t.Errorf("report of d = %+v", u)
+	}
+	if !report.RebootRequired {
+		t.Error("RebootRequired = false, want true")
+	}
+	if got := report.Installed(); len(got) != 2 || got[0].Update != a || got[1].Update != c {
+		t.Errorf("Installed() = %v, want a and c", got)
+	}
+	if n := catalog.Open(); n != 0 {
+		t.Errorf("%d objects left open after Run", n)
+	}
+}
+
+func TestRun_StandIns(t *testing.T) {
+	a, b := wufake.NewUpdate("a"), wufake.NewUpdate("b")
+	var stages []string
+	clock := time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC)
+	report, err := Run(context.Background(), Plan{
+		Search: func(ctx context.Context, criteria string) (*windowsupdate.ISearchResult, error) {
+			stages = append(stages, "search "+criteria)
+			return &windowsupdate.ISearchResult{Updates: []*windowsupdate.IUpdate{a, b}}, nil
+		},
+		Download: func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IDownloadResult, error) {
+			stages = append(stages, "download")
+			return windowsupdate.NewDownloadResult(windowsupdate.OperationResultCodeOrcSucceeded, 0, succeeded(updates)), nil
+		},
+		Install: func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+			stages = append(stages, "install")
+			return windowsupdate.NewInstallationResult(windowsupdate.OperationResultCodeOrcSucceeded, 0, succeeded(updates)), nil
+		},
+		Now: func() time.Time {
+			clock = clock.Add(time.Second)
+			return clock
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{"search " + DefaultCriteria, "download", "install"}; !slices.Equal(stages, want) {
+		t.Errorf("stages = %v, want %v", stages, want)
+	}
+	var names []Stage
+	for _, s := range report.Stages {
+		names = append(names, s.Stage)
+		if !s.Finished.After(s.Started) {
+			t.Errorf("stage %v finished at %v, started at %v", s.Stage, s.Finished, s.Started)
+		}
+	}
+	// No EULA stage: both EULAs are accepted already.
+	if want := []Stage{StageSearch, StageApprove, StageDownload, StageInstall}; !slices.Equal(names, want) {
+		t.Errorf("report stages = %v, want %v", names, want)
+	}
+}
+
+func TestRun_MinimalSearch(t *testing.T) {
+	var accepted, stages []string
+	report, err := Run(context.Background(), Plan{
+		Criteria:   "IsInstalled=0",
+		Search:     newMinimalSearch(t),
+		AcceptEula: acceptEula(&accepted),
+		Download: func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IDownloadResult, error) {
+			stages = append(stages, "download")
+			return windowsupdate.NewDownloadResult(windowsupdate.OperationResultCodeOrcSucceeded, 0, succeeded(updates)), nil
+		},
+		Install: func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+			stages = append(stages, "install")
+			return windowsupdate.NewInstallationResult(windowsupdate.OperationResultCodeOrcSucceeded, 0, succeeded(updates)), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// The update is downloaded and its EULA accepted, as loaded after the search.
+	if len(accepted) != 0 || !slices.Equal(stages, []string{"install"}) {
+		t.Errorf("accepted EULAs of %v and ran %v, want only an install", accepted, stages)
+	}
+	if len(report.Installed()) != 1 {
+		t.Errorf("Installed() = %v, want the update", report.Installed())
+	}
+}
+
+func TestRun_StageError(t *testing.T) {
+	downloadErr := wufake.ComError(0x8024402C)
+	catalog := &wufake.Catalog{Updates: []*windowsupdate.IUpdate{wufake.NewUpdate("a")}, DownloadErr: downloadErr}
+	var after []StageReport
+	report, err := Run(context.Background(), Plan{
+		Session: wufake.NewSession(catalog),
+		Hooks:   Hooks{After: func(ctx context.Context, stage StageReport) { after = append(after, stage) }},
+	})
+	if !errors.Is(err, downloadErr) || !strings.HasPrefix(err.Error(), "patch: download: ") {
+		t.Fatalf("Run() error = %v, want the download error", err)
+	}
+	if len(catalog.Calls()) != 2 {
+		t.Errorf("catalog calls = %+v, want no install after the failed download", catalog.Calls())
+	}
+	last := report.Stages[len(report.Stages)-1]
+	if last.Stage != StageDownload || last.Err != err || last.Error != err.Error() {
+		t.Errorf("last stage = %+v, want the failed download", last)
+	}
+	if len(after) != 3 || after[2].Err != err {
+		t.Errorf("After called with %+v, want the failed download last", after)
+	}
+}
+
+func TestRun_BeforeHookStops(t *testing.T) {
+	catalog := &wufake.Catalog{Updates: []*windowsupdate.IUpdate{wufake.NewUpdate("a")}}
+	stop := errors.New("outside maintenance window")
+	_, err := Run(context.Background(), Plan{
+		Session: wufake.NewSession(catalog),
+		Hooks: Hooks{Before: func(ctx context.Context, stage Stage, updates []*windowsupdate.IUpdate) error {
+			if stage == StageInstall {
+				return stop
+			}
+			return nil
+		}},
+	})
+	if !errors.Is(err, stop) || !strings.HasPrefix(err.Error(), "patch: before install: ") {
+		t.Errorf("Run() error = %v, want the hook error", err)
+	}
+	if calls := catalog.Calls(); calls[len(calls)-1].Op != "Download" {
+		t.Errorf("catalog calls = %+v, want no install", calls)
+	}
+}
+
+func TestRun_NothingFound(t *testing.T) {
+	var stages []string
+	report, err := Run(context.Background(), Plan{Session: wufake.NewSession(&wufake.Catalog{}), Hooks: recordStages(&stages)})
+	if err != nil || len(report.Updates) != 0 {
+		t.Fatalf("Run() = %+v, %v", report, err)
+	}
+	if want := []string{"Before Search", "After Search"}; !slices.Equal(stages, want) {
+		t.Errorf("stages = %v, want %v", stages, want)
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Run(ctx, Plan{Session: wufake.NewSession(&wufake.Catalog{})})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRun_NoSession(t *testing.T) {
+	if _, err := Run(context.Background(), Plan{}); err == nil {
+		t.Error("Run() without a Session or stages succeeded")
+	}
+}
+
+func TestRunReport_JSON(t *testing.T) {
+	report := &RunReport{
+		Stages: []StageReport{{Stage: StageInstall, Updates: 1}},
+		Updates: []*UpdateReport{{
+			UpdateID: "a",
+			Approved: true,
+			Install:  &Outcome{ResultCode: windowsupdate.OperationResultCodeOrcSucceeded},
+		}},
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Stage":"Install"`, `"ResultCode":"Succeeded"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s does not contain %s", data, want)
+		}
+	}
+	var decoded RunReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Stages, report.Stages) {
+		t.Errorf("decoded stages = %+v, want %+v", decoded.Stages, report.Stages)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteJSON writes d as indented JSON.
+func (d *DryRun) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteMarkdown writes d as a Markdown table followed by its summary, e.g. for
+// a change request.
+func (d *DryRun) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Update plan\n\n")
+	if d.Criteria != "" {
+		fmt.Fprintf(&b, "Criteria: `%s`\n\n", d.Criteria)
+	}
+	if len(d.Updates) > 0 {
+		b.WriteString("| Action | Update | KB | Download | Reboot | Impact | Notes |\n")
+		b.WriteString("|---|---|---|---|---|---|---|\n")
+		for _, row := range d.rows() {
+			for i := range row {
+				row[i] = strings.ReplaceAll(row[i], "|", `\|`)
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(row, " | "))
+		}
+		b.WriteString("\n")
+	}
+	for _, line := range d.Summary.lines() {
+		fmt.Fprintf(&b, "- %s\n", line)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteTable writes d as a table aligned for a terminal, followed by its
+// summary.
+func (d *DryRun) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(d.Updates) > 0 {
+		fmt.Fprintln(tw, "ACTION\tUPDATE\tKB\tDOWNLOAD\tREBOOT\tIMPACT\tNOTES")
+		for _, row := range d.rows() {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, line := range d.Summary.lines() {
+		b.WriteString(line + "\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// rows returns the cells of each update in the tables.
+func (d *DryRun) rows() [][]string {
+	rows := make([][]string, len(d.Updates))
+	for i, p := range d.Updates {
+		action, download, notes := "install", formatSizes(p.MinDownloadSize, p.MaxDownloadSize), p.SkipReason
+		if !p.Install {
+			action = "skip"
+		}
+		if p.Downloaded {
+			download = "downloaded"
+		}
+		if p.Install && p.EulaRequired {
+			notes = "EULA to accept"
+		}
+		rows[i] = []string{action, p.Title, p.kbs(), download, p.RebootBehavior.String(), p.Impact.String(), notes}
+	}
+	return rows
+}
+
+// lines returns the summary as sentences.
+func (s DryRunSummary) lines() []string {
+	lines := []string{
+		fmt.Sprintf("Install %d, skip %d.", s.Install, s.Skip),
+		fmt.Sprintf("Download: %s (%d already downloaded).", formatSizes(s.MinDownloadSize, s.MaxDownloadSize), s.AlreadyDownloaded),
+		fmt.Sprintf("EULAs to accept: %d.", s.EulasToAccept),
+	}
+	if s.Install > 0 {
+		lines = append(lines, fmt.Sprintf("Reboot: %s.", s.RebootBehavior))
+	}
+	if s.ExclusiveHandling {
+		lines = append(lines, "An update requires exclusive handling and must be installed on its own.")
+	}
+	return lines
+}
+
+// formatSizes formats a download size range, e.g. "1.5 MB to 20 MB".
+func formatSizes(lo, hi int64) string {
+	if lo == hi {
+		return formatBytes(hi)
+	}
+	return formatBytes(lo) + " to " + formatBytes(hi)
+}
+
+// formatBytes formats n bytes with a binary unit, e.g. "1.5 MB".
+func formatBytes(n int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value, unit := float64(n), 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	return strings.TrimSuffix(strconv.FormatFloat(value, 'f', 1, 64), ".0") + " " + units[unit]
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+// RunReport is the record of a Run. It marshals to JSON.
+type RunReport struct {
+	Started  time.Time
+	Finished time.Time
+	Criteria string
+	// Stages are the stages that ran, in order. Stages with no updates to
+	// process are skipped.
+	Stages []StageReport
+	// Updates are the updates found by the search, in its order.
+	Updates []*UpdateReport
+	// RebootRequired is that of the installation result.
+	RebootRequired bool
+}
+
+// StageReport is the record of one stage of a Run.
+type StageReport struct {
+	Stage    Stage
+	Started  time.Time
+	Finished time.Time
+	Updates  int    // Number of updates processed.
+	Error    string `json:",omitempty"`
+	Err      error  `json:"-"`
+}
+
+// UpdateReport is the record of one update found by the search.
+type UpdateReport struct {
+	Update   *windowsupdate.IUpdate `json:"-"`
+	UpdateID string
+	Title    string
+	Approved bool
+	// SkipReason says why an update found was not installed: not approved,
+	// or its EULA or download failed.
+	SkipReason string `json:",omitempty"`
+	// EulaAccepted reports whether the run accepted the EULA.
+	EulaAccepted bool     `json:",omitempty"`
+	Download     *Outcome `json:",omitempty"`
+	Install      *Outcome `json:",omitempty"`
+}
+
+// Outcome is the result of the download or installation of one update.
+type Outcome struct {
+	ResultCode     windowsupdate.OperationResultCode
+	HResult        int32 `json:",omitempty"`
+	RebootRequired bool  `json:",omitempty"`
+}
+
+func newOutcome(o windowsupdate.UpdateOutcome) *Outcome {
+	return &Outcome{ResultCode: o.ResultCode, HResult: o.HResult, RebootRequired: o.RebootRequired}
+}
+
+// Installed returns the updates installed successfully.
+func (r *RunReport) Installed() []*UpdateReport {
+	var installed []*UpdateReport
+	for _, u := range r.Updates {
+		if u.Install != nil && !u.outcome(u.Install).Failed() {
+			installed = append(installed, u)
+		}
+	}
+	return installed
+}
+
+// Err returns an *windowsupdate.OutcomeError listing the approved updates
+// whose download or installation failed, or nil if none did.
+func (r *RunReport) Err() error {
+	var outcomes windowsupdate.UpdateOutcomes
+	for _, u := range r.Updates {
+		switch {
+		case u.Install != nil:
+			outcomes = append(outcomes, u.outcome(u.Install))
+		case u.Download != nil:
+			outcomes = append(outcomes, u.outcome(u.Download))
+		}
+	}
+	return outcomes.Err()
+}
+
+func (u *UpdateReport) outcome(o *Outcome) windowsupdate.UpdateOutcome {
+	return windowsupdate.UpdateOutcome{Update: u.Update, ResultCode: o.ResultCode, HResult: o.HResult, RebootRequired: o.RebootRequired}
+}
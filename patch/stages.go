@@ -0,0 +1,97 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"context"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/filter"
+)
+
+// SearchFunc runs the search stage.
+type SearchFunc func(ctx context.Context, criteria string) (*windowsupdate.ISearchResult, error)
+
+// An Approver decides whether an update found by the search is installed,
+// giving the reason when it is not.
+type Approver func(update *windowsupdate.IUpdate) (approved bool, reason string)
+
+// EulaFunc accepts the license terms of an update whose EulaAccepted is false.
+type EulaFunc func(ctx context.Context, update *windowsupdate.IUpdate) error
+
+// DownloadFunc runs the download stage.
+type DownloadFunc func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IDownloadResult, error)
+
+// InstallFunc runs the install stage.
+type InstallFunc func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error)
+
+// SearchWith searches with searcher.SearchContext.
+func SearchWith(searcher windowsupdate.Searcher, options ...windowsupdate.JobOption) SearchFunc {
+	return func(ctx context.Context, criteria string) (*windowsupdate.ISearchResult, error) {
+		return searcher.SearchContext(ctx, criteria, options...)
+	}
+}
+
+// DownloadWith downloads with the DownloadContext of downloader.
+func DownloadWith(downloader windowsupdate.Downloader, options ...windowsupdate.JobOption) DownloadFunc {
+	return func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IDownloadResult, error) {
+		return downloader.DownloadContext(ctx, updates, options...)
+	}
+}
+
+// InstallWith installs with the InstallContext of installer.
+func InstallWith(installer windowsupdate.Installer, options ...windowsupdate.JobOption) InstallFunc {
+	return func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+		return installer.InstallContext(ctx, updates, options...)
+	}
+}
+
+// RetryDownload downloads with downloader under policy.
+func RetryDownload(policy windowsupdate.RetryPolicy, downloader windowsupdate.Downloader, options ...windowsupdate.JobOption) DownloadFunc {
+	return func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IDownloadResult, error) {
+		return policy.Download(ctx, downloader, updates, options...)
+	}
+}
+
+// RetryInstall installs with installer under policy.
+func RetryInstall(policy windowsupdate.RetryPolicy, installer windowsupdate.Installer, options ...windowsupdate.JobOption) InstallFunc {
+	return func(ctx context.Context, updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+		return policy.Install(ctx, installer, updates, options...)
+	}
+}
+
+// AcceptEula accepts the license terms with IUpdate.AcceptEula and marks the
+// update as accepted.
+func AcceptEula(ctx context.Context, update *windowsupdate.IUpdate) error {
+	if err := update.AcceptEula(); err != nil {
+		return err
+	}
+	update.EulaAccepted = true
+	return nil
+}
+
+// ApproveAll approves every update.
+func ApproveAll(*windowsupdate.IUpdate) (bool, string) {
+	return true, ""
+}
+
+// ApproveFilter approves the updates matching expr.
+func ApproveFilter(expr *filter.Expr) Approver {
+	return func(update *windowsupdate.IUpdate) (bool, string) {
+		if expr.Match(update) {
+			return true, ""
+		}
+		return false, "does not match " + expr.String()
+	}
+}
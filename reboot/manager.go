@@ -0,0 +1,306 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reboot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/maintenance"
+)
+
+// DefaultDeferralInterval is the wait of Manager.Run between prompts when
+// DeferralInterval is zero.
+const DefaultDeferralInterval = time.Hour
+
+// ErrNoDeferralsLeft is returned by Manager.Defer when the policy does not
+// allow another deferral.
+var ErrNoDeferralsLeft = errors.New("reboot: no deferrals left")
+
+// Policy is when a Manager reboots once a reboot is pending.
+type Policy int
+
+// The policies of a Manager.
+const (
+	// PolicyNever never reboots: the pending reboot is only reported.
+	PolicyNever Policy = iota
+	// PolicyImmediately reboots as soon as a reboot is pending.
+	PolicyImmediately
+	// PolicyNextWindow reboots in the next maintenance window.
+	PolicyNextWindow
+	// PolicyAfterDeferrals prompts the user, who may defer the reboot up to
+	// MaxDeferrals times before it happens anyway.
+	PolicyAfterDeferrals
+)
+
+var policyNames = []string{"Never", "Immediately", "NextWindow", "AfterDeferrals"}
+
+func (p Policy) String() string {
+	if p >= 0 && int(p) < len(policyNames) {
+		return policyNames[p]
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Policy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Policy) UnmarshalText(text []byte) error {
+	for i, name := range policyNames {
+		if strings.EqualFold(name, string(text)) {
+			*p = Policy(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("reboot: unknown policy %q", text)
+}
+
+// Action is what a Decision calls for.
+type Action int
+
+// The actions of a Decision.
+const (
+	// ActionNone does nothing: no reboot is pending, or the policy does not
+	// reboot.
+	ActionNone Action = iota
+	// ActionReboot reboots now.
+	ActionReboot
+	// ActionWait reboots at Decision.At.
+	ActionWait
+	// ActionPrompt asks the user to reboot now or defer.
+	ActionPrompt
+)
+
+var actionNames = []string{"None", "Reboot", "Wait", "Prompt"}
+
+func (a Action) String() string {
+	if a >= 0 && int(a) < len(actionNames) {
+		return actionNames[a]
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Decision is what a Manager decided to do about the pending reboot.
+type Decision struct {
+	Action Action
+	// Why explains the action, e.g. "policy is Never".
+	Why string
+	// At is when ActionWait reboots: the start of the next window.
+	At time.Time `json:",omitempty"`
+	// DeferralsLeft is the number of times the user may still defer.
+	DeferralsLeft int `json:",omitempty"`
+	State         State
+}
+
+// Rebooter reboots the computer. Reboot returns once the reboot is initiated.
+type Rebooter interface {
+	Reboot(ctx context.Context, reason string) error
+}
+
+// RebooterFunc is a function that is a Rebooter.
+type RebooterFunc func(ctx context.Context, reason string) error
+
+// Reboot calls f.
+func (f RebooterFunc) Reboot(ctx context.Context, reason string) error {
+	return f(ctx, reason)
+}
+
+// Manager holds the pending reboot state and applies a Policy to it. Reports
+// of WUA are added with Observe; Decide says what to do, and Apply or Run do
+// it.
+type Manager struct {
+	Policy   Policy
+	Rebooter Rebooter
+	// Schedule has the windows of PolicyNextWindow.
+	Schedule *maintenance.Schedule
+	// MaxDeferrals is the number of times the user may defer the reboot with
+	// PolicyAfterDeferrals.
+	MaxDeferrals int
+	// Prompt asks the user whether to reboot now with PolicyAfterDeferrals. It
+	// returns false to defer. Without Prompt, Apply leaves ActionPrompt to
+	// the caller, who calls Reboot or Defer.
+	Prompt func(ctx context.Context, d Decision) (bool, error)
+	// DeferralInterval is the wait of Run between prompts; zero means
+	// DefaultDeferralInterval.
+	DeferralInterval time.Duration
+	// Clock is the source of time and of waits; nil means
+	// windowsupdate.SystemClock.
+	Clock windowsupdate.Clock
+
+	mu        sync.Mutex
+	state     State
+	deferrals int
+}
+
+// NewManager returns a Manager rebooting with rebooter as policy says.
+func NewManager(policy Policy, rebooter Rebooter) *Manager {
+	return &Manager{Policy: policy, Rebooter: rebooter}
+}
+
+func (m *Manager) clock() windowsupdate.Clock {
+	if m.Clock == nil {
+		return windowsupdate.SystemClock
+	}
+	return m.Clock
+}
+
+// Observe adds the reasons of s to the pending reboot.
+func (m *Manager) Observe(s State) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state.Add(s.Reasons...)
+}
+
+// State returns the pending reboot.
+func (m *Manager) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return State{Reasons: append([]Reason(nil), m.state.Reasons...)}
+}
+
+// Decide returns what the policy calls for now.
+func (m *Manager) Decide() Decision {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.decide()
+}
+
+func (m *Manager) decide() Decision {
+	d := Decision{State: State{Reasons: append([]Reason(nil), m.state.Reasons...)}}
+	if !d.State.Pending() {
+		d.Why = "no reboot pending"
+		return d
+	}
+	switch m.Policy {
+	case PolicyImmediately:
+		d.Action, d.Why = ActionReboot, "policy is Immediately"
+	case PolicyNextWindow:
+		if m.Schedule == nil {
+			d.Why = "no maintenance schedule"
+			break
+		}
+		now := m.clock().Now()
+		if o, ok := m.Schedule.Current(now); ok {
+			d.Action, d.Why = ActionReboot, "in maintenance window "+o.String()
+		} else if o, ok := m.Schedule.Next(now); ok {
+			d.Action, d.At, d.Why = ActionWait, o.Start, "waiting for maintenance window "+o.String()
+		} else {
+			d.Why = "no maintenance window ahead"
+		}
+	case PolicyAfterDeferrals:
+		if left := m.MaxDeferrals - m.deferrals; left > 0 {
+			d.Action, d.DeferralsLeft, d.Why = ActionPrompt, left, fmt.Sprintf("%d deferrals left", left)
+		} else {
+			d.Action, d.Why = ActionReboot, "no deferrals left"
+		}
+	default:
+		d.Why = "policy is " + m.Policy.String()
+	}
+	return d
+}
+
+// Defer records that the user deferred the reboot. It returns
+// ErrNoDeferralsLeft unless the policy is PolicyAfterDeferrals with deferrals
+// left.
+func (m *Manager) Defer() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Policy != PolicyAfterDeferrals || m.deferrals >= m.MaxDeferrals {
+		return ErrNoDeferralsLeft
+	}
+	m.deferrals++
+	return nil
+}
+
+// Reboot reboots now whatever the policy, if a reboot is pending. Once the
+// Rebooter succeeds, the state and the deferrals are reset.
+func (m *Manager) Reboot(ctx context.Context) error {
+	state := m.State()
+	if !state.Pending() {
+		return nil
+	}
+	if m.Rebooter == nil {
+		return errors.New("reboot: the Manager has no Rebooter")
+	}
+	if err := m.Rebooter.Reboot(ctx, state.String()); err != nil {
+		return fmt.Errorf("reboot: %w", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state, m.deferrals = State{}, 0
+	return nil
+}
+
+// Apply decides and carries out the decision: it reboots for ActionReboot,
+// and for ActionPrompt asks Prompt, then reboots or defers. A prompt the user
+// accepted is returned as ActionReboot. ActionWait and ActionNone do nothing.
+func (m *Manager) Apply(ctx context.Context) (Decision, error) {
+	d := m.Decide()
+	if d.Action == ActionPrompt && m.Prompt != nil {
+		reboot, err := m.Prompt(ctx, d)
+		if err != nil {
+			return d, err
+		}
+		if !reboot {
+			return d, m.Defer()
+		}
+		d.Action, d.Why = ActionReboot, "the user accepted"
+	}
+	if d.Action == ActionReboot {
+		return d, m.Reboot(ctx)
+	}
+	return d, nil
+}
+
+// Run applies the policy until the computer is rebooted or there is nothing
+// left to do. It waits for the window of ActionWait, and DeferralInterval
+// after each deferral. It returns the last decision.
+func (m *Manager) Run(ctx context.Context) (Decision, error) {
+	for {
+		d, err := m.Apply(ctx)
+		if err != nil {
+			return d, err
+		}
+		var wait time.Duration
+		switch {
+		case d.Action == ActionWait:
+			wait = d.At.Sub(m.clock().Now())
+		case d.Action == ActionPrompt && m.Prompt != nil:
+			wait = m.DeferralInterval
+			if wait <= 0 {
+				wait = DefaultDeferralInterval
+			}
+		default:
+			return d, nil
+		}
+		select {
+		case <-ctx.Done():
+			return d, ctx.Err()
+		case <-m.clock().After(wait):
+		}
+	}
+}
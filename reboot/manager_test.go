@@ -0,0 +1,178 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reboot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate/maintenance"
+	"github.com/ceshihao/windowsupdate/wufake"
+)
+
+// recorder is a Rebooter recording the reasons it rebooted for.
+type recorder struct {
+	reasons []string
+	err     error
+}
+
+func (r *recorder) Reboot(ctx context.Context, reason string) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.reasons = append(r.reasons, reason)
+	return nil
+}
+
+var pending = State{Reasons: []Reason{{Source: SourceSystem}}}
+
+func newManager(policy Policy) (*Manager, *recorder) {
+	r := &recorder{}
+	m := NewManager(policy, r)
+	m.Observe(pending)
+	return m, r
+}
+
+func TestManager_Never(t *testing.T) {
+	m, r := newManager(PolicyNever)
+	d, err := m.Apply(context.Background())
+	if err != nil || d.Action != ActionNone || d.Why != "policy is Never" || !d.State.Pending() {
+		t.Errorf("Apply() = %+v, %v", d, err)
+	}
+	if len(r.reasons) != 0 {
+		t.Errorf("rebooted for %v", r.reasons)
+	}
+}
+
+func TestManager_Immediately(t *testing.T) {
+	m, r := newManager(PolicyImmediately)
+	d, err := m.Apply(context.Background())
+	if err != nil || d.Action != ActionReboot {
+		t.Fatalf("Apply() = %+v, %v", d, err)
+	}
+	if !slices.Equal(r.reasons, []string{"the system has a reboot pending"}) {
+		t.Errorf("rebooted for %v", r.reasons)
+	}
+	if m.State().Pending() {
+		t.Error("a reboot is still pending after rebooting")
+	}
+	if d := m.Decide(); d.Action != ActionNone || d.Why != "no reboot pending" {
+		t.Errorf("Decide() after the reboot = %+v", d)
+	}
+}
+
+func TestManager_RebooterError(t *testing.T) {
+	m, r := newManager(PolicyImmediately)
+	r.err = errors.New("access denied")
+	if _, err := m.Apply(context.Background()); !errors.Is(err, r.err) {
+		t.Errorf("Apply() error = %v, want the Rebooter error", err)
+	}
+	if !m.State().Pending() {
+		t.Error("the failed reboot cleared the state")
+	}
+}
+
+func TestManager_NextWindow(t *testing.T) {
+	m, r := newManager(PolicyNextWindow)
+	clock := wufake.NewClock(time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC))
+	m.Clock = clock
+	m.Schedule = &maintenance.Schedule{Windows: []maintenance.Window{{
+		Start:    maintenance.MustParseRecurrence("0 2 * * SAT#2"),
+		Duration: 3 * time.Hour,
+		Location: time.UTC,
+	}}}
+
+	if d := m.Decide(); d.Action != ActionWait || !d.At.Equal(time.Date(2026, 1, 10, 2, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Decide() = %+v, want to wait for the window", d)
+	}
+	d, err := m.Run(context.Background())
+	if err != nil || d.Action != ActionReboot {
+		t.Fatalf("Run() = %+v, %v", d, err)
+	}
+	if waits := clock.Waits(); !slices.Equal(waits, []time.Duration{14 * time.Hour}) || len(r.reasons) != 1 {
+		t.Errorf("waits = %v, reboots = %v, want one reboot after 14h", waits, r.reasons)
+	}
+
+	m.Schedule = nil
+	m.Observe(pending)
+	if d := m.Decide(); d.Action != ActionNone || d.Why != "no maintenance schedule" {
+		t.Errorf("Decide() without a schedule = %+v", d)
+	}
+}
+
+func TestManager_AfterDeferrals(t *testing.T) {
+	m, r := newManager(PolicyAfterDeferrals)
+	m.MaxDeferrals = 2
+	var prompts []int
+	m.Prompt = func(ctx context.Context, d Decision) (bool, error) {
+		prompts = append(prompts, d.DeferralsLeft)
+		return false, nil
+	}
+	clock := wufake.NewClock(time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC))
+	m.Clock = clock
+
+	d, err := m.Run(context.Background())
+	if err != nil || d.Action != ActionReboot || d.Why != "no deferrals left" {
+		t.Fatalf("Run() = %+v, %v", d, err)
+	}
+	if !slices.Equal(prompts, []int{2, 1}) || len(r.reasons) != 1 {
+		t.Errorf("prompts = %v, reboots = %v, want 2 deferrals then a reboot", prompts, r.reasons)
+	}
+	if waits := clock.Waits(); !slices.Equal(waits, []time.Duration{time.Hour, time.Hour}) {
+		t.Errorf("waits = %v, want an hour after each deferral", waits)
+	}
+}
+
+func TestManager_PromptAccepted(t *testing.T) {
+	m, r := newManager(PolicyAfterDeferrals)
+	m.MaxDeferrals = 3
+	m.Prompt = func(ctx context.Context, d Decision) (bool, error) { return true, nil }
+	d, err := m.Apply(context.Background())
+	if err != nil || d.Action != ActionReboot || len(r.reasons) != 1 {
+		t.Errorf("Apply() = %+v, %v, reboots = %v", d, err, r.reasons)
+	}
+}
+
+func TestManager_DeferWithoutPrompt(t *testing.T) {
+	m, r := newManager(PolicyAfterDeferrals)
+	m.MaxDeferrals = 1
+	if d, _ := m.Apply(context.Background()); d.Action != ActionPrompt || d.DeferralsLeft != 1 {
+		t.Fatalf("Apply() = %+v, want a prompt left to the caller", d)
+	}
+	if err := m.Defer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Defer(); !errors.Is(err, ErrNoDeferralsLeft) {
+		t.Errorf("second Defer() error = %v, want ErrNoDeferralsLeft", err)
+	}
+	if d, err := m.Apply(context.Background()); err != nil || d.Action != ActionReboot || len(r.reasons) != 1 {
+		t.Errorf("Apply() = %+v, %v, want a reboot", d, err)
+	}
+}
+
+func TestPolicy_Text(t *testing.T) {
+	var p Policy
+	if err := p.UnmarshalText([]byte("nextwindow")); err != nil || p != PolicyNextWindow {
+		t.Errorf("UnmarshalText() = %v, %v", p, err)
+	}
+	if err := p.UnmarshalText([]byte("sometimes")); err == nil {
+		t.Error("UnmarshalText of an unknown policy succeeded")
+	}
+	if got := Policy(9).String(); got != "Policy(9)" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reboot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxShutdownComment is the longest comment shutdown.exe accepts.
+const maxShutdownComment = 512
+
+// shutdownPath returns the absolute path of shutdown.exe, so that the command
+// is not resolved through PATH or the working directory.
+func shutdownPath() string {
+	root := os.Getenv("SystemRoot")
+	if root == "" {
+		root = `C:\Windows`
+	}
+	return filepath.Join(root, "System32", "shutdown.exe")
+}
+
+// Shutdown is the Rebooter of Windows: it runs shutdown.exe to restart the
+// computer after Delay, recording the restart as a planned operating system
+// hotfix with the reason as its comment.
+type Shutdown struct {
+	Delay time.Duration
+}
+
+// Reboot runs "shutdown /r".
+func (s Shutdown) Reboot(ctx context.Context, reason string) error {
+	if len(reason) > maxShutdownComment {
+		reason = strings.ToValidUTF8(reason[:maxShutdownComment], "")
+	}
+	args := []string{"/r", "/t", strconv.Itoa(int(s.Delay / time.Second)), "/d", "p:2:17", "/c", reason}
+	if out, err := exec.CommandContext(ctx, shutdownPath(), args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("shutdown: %w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
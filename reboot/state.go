@@ -0,0 +1,196 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reboot decides when to reboot after updates. WUA reports a pending
+// reboot in several places; a State consolidates them into one list of
+// reasons. A Manager applies a Policy to the state and reboots through a
+// Rebooter, which tests replace to check the decisions on any platform.
+package reboot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+// Source is where WUA reported a pending reboot.
+type Source int
+
+// The sources of a Reason.
+const (
+	// SourceSystem is ISystemInformation.RebootRequired.
+	SourceSystem Source = iota
+	// SourceInstaller is IUpdateInstaller.RebootRequiredBeforeInstallation.
+	SourceInstaller
+	// SourceInstallation is the RebootRequired of an IInstallationResult or of
+	// the result of one of its updates.
+	SourceInstallation
+	// SourceUpdate is IUpdate.RebootRequired.
+	SourceUpdate
+	// SourceBehavior is an installed update whose
+	// InstallationBehavior.RebootBehavior is AlwaysRequiresReboot.
+	SourceBehavior
+)
+
+var sourceNames = []string{"System", "Installer", "Installation", "Update", "Behavior"}
+
+func (s Source) String() string {
+	if s >= 0 && int(s) < len(sourceNames) {
+		return sourceNames[s]
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Source) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Source) UnmarshalText(text []byte) error {
+	for i, name := range sourceNames {
+		if strings.EqualFold(name, string(text)) {
+			*s = Source(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("reboot: unknown source %q", text)
+}
+
+// Reason is one report of a pending reboot. UpdateID and Title name the update
+// of the per-update sources.
+type Reason struct {
+	Source   Source
+	UpdateID string `json:",omitempty"`
+	Title    string `json:",omitempty"`
+}
+
+func (r Reason) String() string {
+	update := r.Title
+	if update == "" {
+		update = r.UpdateID
+	}
+	switch {
+	case r.Source == SourceSystem:
+		return "the system has a reboot pending"
+	case r.Source == SourceInstaller:
+		return "a reboot is required before installing"
+	case r.Source == SourceInstallation && update == "":
+		return "the installation requires a reboot"
+	case r.Source == SourceInstallation:
+		return "installing " + update + " requires a reboot"
+	case r.Source == SourceBehavior:
+		return update + " always requires a reboot"
+	}
+	return update + " requires a reboot"
+}
+
+// State is the pending reboot consolidated from the reports of WUA. The zero
+// value has no reboot pending.
+type State struct {
+	Reasons []Reason
+}
+
+// Pending reports whether a reboot is pending.
+func (s State) Pending() bool {
+	return len(s.Reasons) > 0
+}
+
+// String returns the reasons joined with "; ", or "no reboot pending".
+func (s State) String() string {
+	if !s.Pending() {
+		return "no reboot pending"
+	}
+	reasons := make([]string, len(s.Reasons))
+	for i, r := range s.Reasons {
+		reasons[i] = r.String()
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// Add adds reasons that are not in s already.
+func (s *State) Add(reasons ...Reason) {
+	for _, r := range reasons {
+		if !s.has(r) {
+			s.Reasons = append(s.Reasons, r)
+		}
+	}
+}
+
+func (s *State) has(r Reason) bool {
+	for _, have := range s.Reasons {
+		if have == r {
+			return true
+		}
+	}
+	return false
+}
+
+// AddSystem adds the reboot pending on the computer, if any.
+func (s *State) AddSystem(info *windowsupdate.ISystemInformation) {
+	if info.RebootRequired {
+		s.Add(Reason{Source: SourceSystem})
+	}
+}
+
+// AddInstaller adds the reboot required before installing, if any.
+func (s *State) AddInstaller(installer *windowsupdate.IUpdateInstaller) {
+	if installer.RebootRequiredBeforeInstallation {
+		s.Add(Reason{Source: SourceInstaller})
+	}
+}
+
+// AddInstallation adds the reboots required by an installation and by each of
+// its updates, which must be the updates passed to Install.
+func (s *State) AddInstallation(result *windowsupdate.IInstallationResult, updates []*windowsupdate.IUpdate) error {
+	if result.RebootRequired {
+		s.Add(Reason{Source: SourceInstallation})
+	}
+	outcomes, err := result.Outcomes(updates)
+	if err != nil {
+		return err
+	}
+	for _, o := range outcomes {
+		if o.RebootRequired {
+			s.Add(reasonOf(SourceInstallation, o.Update))
+		}
+	}
+	return nil
+}
+
+// AddUpdates adds the reboots required by installed updates: those whose
+// RebootRequired is set, and those whose reboot behavior is
+// AlwaysRequiresReboot.
+func (s *State) AddUpdates(updates []*windowsupdate.IUpdate) {
+	for _, update := range updates {
+		if update.RebootRequired {
+			s.Add(reasonOf(SourceUpdate, update))
+		}
+		if b := update.InstallationBehavior; b != nil && b.RebootBehavior == windowsupdate.InstallationRebootBehaviorIrbAlwaysRequiresReboot {
+			s.Add(reasonOf(SourceBehavior, update))
+		}
+	}
+}
+
+func reasonOf(source Source, update *windowsupdate.IUpdate) Reason {
+	r := Reason{Source: source}
+	if update == nil {
+		return r
+	}
+	r.Title = update.Title
+	if update.Identity != nil {
+		r.UpdateID = update.Identity.UpdateID
+	}
+	return r
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reboot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/wufake"
+)
+
+func TestState(t *testing.T) {
+	a, b, c := wufake.NewUpdate("a"), wufake.NewUpdate("b"), wufake.NewUpdate("c")
+	b.RebootRequired = true
+	c.InstallationBehavior = &windowsupdate.IInstallationBehavior{
+		RebootBehavior: windowsupdate.InstallationRebootBehaviorIrbAlwaysRequiresReboot,
+	}
+	result := windowsupdate.NewInstallationResult(windowsupdate.OperationResultCodeOrcSucceeded, 0, windowsupdate.UpdateOutcomes{
+		{ResultCode: windowsupdate.OperationResultCodeOrcSucceeded, RebootRequired: true},
+		{ResultCode: windowsupdate.OperationResultCodeOrcSucceeded},
+	})
+	result.RebootRequired = true
+
+	var s State
+	if s.Pending() || s.String() != "no reboot pending" {
+		t.Errorf("zero State = %v, want no reboot pending", s)
+	}
+	s.AddSystem(&windowsupdate.ISystemInformation{RebootRequired: true})
+	s.AddSystem(&windowsupdate.ISystemInformation{RebootRequired: true})
+	s.AddInstaller(&windowsupdate.IUpdateInstaller{})
+	if err := s.AddInstallation(result, []*windowsupdate.IUpdate{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	s.AddUpdates([]*windowsupdate.IUpdate{a, b, c})
+
+	want := []Reason{
+		{Source: SourceSystem},
+		{Source: SourceInstallation},
+		{Source: SourceInstallation, UpdateID: "a", Title: "Update a"},
+		{Source: SourceUpdate, UpdateID: "b", Title: "Update b"},
+		{Source: SourceBehavior, UpdateID: "c", Title: "Update c"},
+	}
+	if len(s.Reasons) != len(want) {
+		t.Fatalf("Reasons = %+v, want %+v", s.Reasons, want)
+	}
+	for i := range want {
+		if s.Reasons[i] != want[i] {
+			t.Errorf("Reasons[%d] = %+v, want %+v", i, s.Reasons[i], want[i])
+		}
+	}
+	wantString := "the system has a reboot pending; the installation requires a reboot; " +
+		"installing Update a requires a reboot; Update b requires a reboot; Update c always requires a reboot"
+	if !s.Pending() || s.String() != wantString {
+		t.Errorf("String() = %q, want %q", s.String(), wantString)
+	}
+}
+
+func TestState_Installer(t *testing.T) {
+	var s State
+	s.AddInstaller(&windowsupdate.IUpdateInstaller{RebootRequiredBeforeInstallation: true})
+	if got := s.String(); got != "a reboot is required before installing" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestState_JSON(t *testing.T) {
+	s := State{Reasons: []Reason{{Source: SourceBehavior, UpdateID: "c"}}}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Reasons":[{"Source":"Behavior","UpdateID":"c"}]}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+	var decoded State
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Reasons[0] != s.Reasons[0] {
+		t.Errorf("Unmarshal() = %+v, %v", decoded, err)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"sync"
+
+	"github.com/go-ole/go-ole"
+)
+
+// Recorder captures the COM traffic of a session into a Fixture. Pass it to
+// NewUpdateSession with WithRecorder, use the session as usual, then save
+// Fixture() to reproduce the session elsewhere with NewReplaySession.
+type Recorder struct {
+	mu      sync.Mutex
+	fixture Fixture
+	ids     map[interface{}]int
+}
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		fixture: Fixture{Version: FixtureVersion},
+		ids:     make(map[interface{}]int),
+	}
+}
+
+// Fixture returns a snapshot of everything recorded so far.
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fixture := &Fixture{Version: r.fixture.Version, Root: r.fixture.Root}
+	for _, object := range r.fixture.Objects {
+		fixture.Objects = append(fixture.Objects, &FixtureObject{
+			ID:    object.ID,
+			Calls: append([]*FixtureCall(nil), object.Calls...),
+		})
+	}
+	return fixture
+}
+
+// wrap returns a dispatcher recording every call made on d. The first object
+// wrapped becomes the fixture root.
+func (r *Recorder) wrap(d dispatcher) dispatcher {
+	if d == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wrapped := r.wrapLocked(d)
+	if r.fixture.Root == 0 {
+		r.fixture.Root = wrapped.id
+	}
+	return wrapped
+}
+
+func (r *Recorder) wrapLocked(d dispatcher) *recordingDispatcher {
+	if recorded, ok := d.(*recordingDispatcher); ok {
+		return recorded
+	}
+	return &recordingDispatcher{inner: d, recorder: r, id: r.objectIDLocked(d)}
+}
+
+// objectIDLocked returns the ID of d, registering it on first sight. COM
+// objects are identified by their interface pointer, so the same object
+// returned twice gets the same ID.
+func (r *Recorder) objectIDLocked(d dispatcher) int {
+	if recorded, ok := d.(*recordingDispatcher); ok {
+		return recorded.id
+	}
+	var key interface{} = d
+	if disp := d.IDispatch(); disp != nil {
+		key = disp
+	}
+	if id, ok := r.ids[key]; ok {
+		return id
+	}
+	id := len(r.fixture.Objects) + 1
+	r.ids[key] = id
+	r.fixture.Objects = append(r.fixture.Objects, &FixtureObject{ID: id})
+	return id
+}
+
+func (r *Recorder) record(id int, kind string, name string, params []interface{}, result *variant, err error) (*variant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	call := &FixtureCall{Kind: kind, Name: name}
+	for _, param := range params {
+		encoded, encodeErr := encodeFixtureParam(param, r.objectIDLocked)
+		if encodeErr != nil {
+			return nil, encodeErr
+		}
+		call.Params = append(call.Params, encoded)
+	}
+
+	if err != nil {
+		call.Error = encodeFixtureError(err)
+	} else {
+		encoded, encodeErr := encodeFixtureValue(result, r.objectIDLocked)
+		if encodeErr != nil {
+			return nil, encodeErr
+		}
+		call.Result = encoded
+		if d, ok := result.Value.(dispatcher); ok {
+			result = &variant{VT: result.VT, Value: r.wrapLocked(d), Name: result.Name}
+		}
+	}
+
+	object := r.fixture.object(id)
+	object.Calls = append(object.Calls, call)
+	return result, err
+}
+
+// recordingDispatcher forwards to inner and records each call.
+type recordingDispatcher struct {
+	inner    dispatcher
+	recorder *Recorder
+	id       int
+}
+
+func (d *recordingDispatcher) GetProperty(name string, params ...interface{}) (*variant, error) {
+	result, err := d.inner.GetProperty(name, params...)
+	return d.recorder.record(d.id, "GetProperty", name, params, result, err)
+}
+
+func (d *recordingDispatcher) PutProperty(name string, params ...interface{}) (*variant, error) {
+	result, err := d.inner.PutProperty(name, params...)
+	return d.recorder.record(d.id, "PutProperty", name, params, result, err)
+}
+
+func (d *recordingDispatcher) CallMethod(name string, params ...interface{}) (*variant, error) {
+	result, err := d.inner.CallMethod(name, params...)
+	return d.recorder.record(d.id, "CallMethod", name, params, result, err)
+}
+
+func (d *recordingDispatcher) CreateObject(programID string) (dispatcher, error) {
+	created, err := d.inner.CreateObject(programID)
+	var result *variant
+	if err == nil {
+		result = &variant{VT: ole.VT_DISPATCH, Value: created}
+	}
+	result, err = d.recorder.record(d.id, "CreateObject", programID, nil, result, err)
+	if err != nil {
+		return nil, err
+	}
+	return variantToIDispatch(result)
+}
+
+func (d *recordingDispatcher) IDispatch() *ole.IDispatch {
+	return d.inner.IDispatch()
+}
+
+// Release is not recorded: a replayed object holds no reference. The
+// interface pointer is forgotten, since COM may reuse it for a new object.
+func (d *recordingDispatcher) Release() {
+	if disp := d.inner.IDispatch(); disp != nil {
+		d.recorder.mu.Lock()
+		if d.recorder.ids[disp] == d.id {
+			delete(d.recorder.ids, disp)
+		}
+		d.recorder.mu.Unlock()
+	}
+	d.inner.Release()
+}
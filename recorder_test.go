@@ -0,0 +1,176 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/go-ole/go-ole"
+)
+
+// newFakeSession returns a fake IUpdateSession whose searcher finds updates.
+func newFakeSession(updates ...interface{}) *fakeDispatcher {
+	search := func(criteria interface{}) (interface{}, error) {
+		if criteria != "IsInstalled=0" {
+			return nil, ole.NewError(0x80240032) // WU_E_INVALID_CRITERIA
+		}
+		return newFakeDispatcher(map[string]interface{}{
+			"ResultCode":     OperationResultCodeOrcSucceeded,
+			"RootCategories": newFakeCollection(),
+			"Updates":        newFakeCollection(updates...),
+			"Warnings":       newFakeCollection(),
+		}), nil
+	}
+	jobs := map[dispatcher]interface{}{}
+	searcher := newFakeDispatcher(map[string]interface{}{
+		"CanAutomaticallyUpgradeService":      false,
+		"ClientApplicationID":                 "",
+		"IncludePotentiallySupersededUpdates": false,
+		"Online":                              true,
+		"ServerSelection":                     ServerSelectionSsDefault,
+		"ServiceID":                           "00000000-0000-0000-0000-000000000000",
+		"GetTotalHistoryCount":                int32(0),
+		"Search": fakeMember(func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, ole.NewError(ole.E_INVALIDARG)
+			}
+			return search(params[0])
+		}),
+		// BeginSearch returns a job that is already completed.
+		"BeginSearch": fakeMember(func(params ...interface{}) (interface{}, error) {
+			if len(params) != 3 {
+				return nil, ole.NewError(ole.E_INVALIDARG)
+			}
+			job := newFakeDispatcher(map[string]interface{}{"IsCompleted": true, "CleanUp": nil, "RequestAbort": nil})
+			jobs[job] = params[0]
+			return job, nil
+		}),
+		"EndSearch": fakeMember(func(params ...interface{}) (interface{}, error) {
+			if len(params) != 1 {
+				return nil, ole.NewError(ole.E_INVALIDARG)
+			}
+			job, _ := params[0].(dispatcher)
+			return search(jobs[job])
+		}),
+	})
+	return newFakeDispatcher(map[string]interface{}{
+		"ClientApplicationID":  "recorder test",
+		"ReadOnly":             false,
+		"WebProxy":             &variant{VT: ole.VT_DISPATCH},
+		"CreateUpdateSearcher": searcher,
+	})
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	recorder := NewRecorder()
+	session, err := toIUpdateSession(recorder.wrap(newFakeSession(newFakeUpdate(map[string]interface{}{"CveIDs": nil}))))
+	if err != nil {
+		t.Fatalf("toIUpdateSession failed: %v", err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+	recorded, err := searcher.Search("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if _, err := searcher.Search("IsInstalled=1"); err == nil {
+		t.Fatal("Search with other criteria succeeded, want an error")
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.Fixture().Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	fixture, err := ReadFixture(&buf)
+	if err != nil {
+		t.Fatalf("ReadFixture failed: %v", err)
+	}
+
+	replayed, err := NewReplaySession(fixture)
+	if err != nil {
+		t.Fatalf("NewReplaySession failed: %v", err)
+	}
+	if replayed.ClientApplicationID != "recorder test" || replayed.WebProxy != nil {
+		t.Errorf("replayed session = %+v", replayed)
+	}
+	replayedSearcher, err := replayed.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("replayed CreateUpdateSearcher failed: %v", err)
+	}
+	result, err := replayedSearcher.Search("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("replayed Search failed: %v", err)
+	}
+	if len(result.Updates) != 1 {
+		t.Fatalf("replayed Search returned %d updates, want 1", len(result.Updates))
+	}
+	got, want := result.Updates[0], recorded.Updates[0]
+	if got.Title != want.Title || !got.Deadline.Equal(*want.Deadline) || got.MaxDownloadSize != want.MaxDownloadSize ||
+		got.Identity.UpdateID != want.Identity.UpdateID || got.Categories[0].Name != want.Categories[0].Name {
+		t.Errorf("replayed update = %+v, want %+v", got, want)
+	}
+	if got.CveIDs != nil {
+		t.Errorf("replayed CveIDs = %v, want nil as recorded", got.CveIDs)
+	}
+
+	_, err = replayedSearcher.Search("IsInstalled=1")
+	var oleErr *ole.OleError
+	if !errors.As(err, &oleErr) || oleErr.Code() != 0x80240032 {
+		t.Errorf("replayed failing Search returned %v, want HRESULT 0x80240032", err)
+	}
+}
+
+func TestRecorder_FirstWrappedIsRoot(t *testing.T) {
+	recorder := NewRecorder()
+	first := recorder.wrap(newFakeDispatcher(nil))
+	recorder.wrap(newFakeDispatcher(nil))
+	if recorder.wrap(first) != first {
+		t.Error("wrapping a recording dispatcher wrapped it again")
+	}
+	if fixture := recorder.Fixture(); fixture.Root != 1 || len(fixture.Objects) != 2 {
+		t.Errorf("fixture root = %d with %d objects, want 1 with 2", fixture.Root, len(fixture.Objects))
+	}
+	if recorder.wrap(nil) != nil {
+		t.Error("wrap(nil) != nil")
+	}
+}
+
+func TestRecorder_CreateObject(t *testing.T) {
+	recorder := NewRecorder()
+	d := recorder.wrap(newFakeDispatcher(nil))
+	collection, err := d.CreateObject("Microsoft.Update.UpdateColl")
+	if err != nil {
+		t.Fatalf("CreateObject failed: %v", err)
+	}
+	if _, err := collection.CallMethod("Add", d); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if _, err := d.CreateObject("Bogus.Object"); err == nil {
+		t.Error("CreateObject of unknown program ID succeeded")
+	}
+
+	fixture := recorder.Fixture()
+	calls := fixture.object(1).Calls
+	if len(calls) != 2 || calls[0].Result.Object != 2 || calls[1].Error == nil {
+		t.Errorf("root calls = %+v", calls)
+	}
+	add := fixture.object(2).Calls[0]
+	if add.Name != "Add" || len(add.Params) != 1 || add.Params[0].VT != ole.VT_DISPATCH || add.Params[0].Object != 1 {
+		t.Errorf("Add call = %+v", add)
+	}
+}
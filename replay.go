@@ -0,0 +1,166 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-ole/go-ole"
+)
+
+// ErrNotRecorded is returned by a replayed object for a call that is not in the fixture.
+var ErrNotRecorded = errors.New("windowsupdate: call not recorded in fixture")
+
+// NewReplaySession returns a session that serves the object graph recorded in
+// fixture instead of talking to COM. It works on every OS. Calls are matched by
+// object, kind, name and arguments; when the same call was recorded several
+// times the results are replayed in order, the last one repeating.
+func NewReplaySession(fixture *Fixture) (*IUpdateSession, error) {
+	if fixture.object(fixture.Root) == nil {
+		return nil, fmt.Errorf("windowsupdate: fixture root object %d not found", fixture.Root)
+	}
+	player := &replayer{
+		fixture: fixture,
+		objects: make(map[int]*replayDispatcher),
+		cursors: make(map[string]int),
+	}
+	return newIUpdateSession(player.object(fixture.Root), sessionOptions{})
+}
+
+// replayer holds the replay state shared by all the objects of a fixture.
+type replayer struct {
+	mu      sync.Mutex
+	fixture *Fixture
+	objects map[int]*replayDispatcher
+	cursors map[string]int
+}
+
+// object returns the dispatcher for a recorded object ID, always the same one
+// for a given ID so that objects passed back as arguments can be matched.
+func (p *replayer) object(id int) dispatcher {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.objectLocked(id)
+}
+
+func (p *replayer) objectLocked(id int) *replayDispatcher {
+	d, ok := p.objects[id]
+	if !ok {
+		d = &replayDispatcher{player: p, id: id}
+		p.objects[id] = d
+	}
+	return d
+}
+
+func (p *replayer) objectID(d dispatcher) int {
+	if replayed, ok := d.(*replayDispatcher); ok && replayed.player == p {
+		return replayed.id
+	}
+	return -1
+}
+
+func (p *replayer) replay(id int, kind string, name string, params []interface{}) (*variant, error) {
+	encoded := make([]*FixtureValue, len(params))
+	for i, param := range params {
+		var err error
+		if encoded[i], err = encodeFixtureParam(param, p.objectID); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.Lock()
+	object := p.fixture.object(id)
+	var matches []*FixtureCall
+	if object != nil {
+		for _, call := range object.Calls {
+			if call.Kind == kind && call.Name == name && sameFixtureParams(call.Params, encoded) {
+				matches = append(matches, call)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s on object %d", ErrNotRecorded, kind, name, id)
+	}
+	paramsKey, _ := json.Marshal(encoded)
+	key := fmt.Sprintf("%d/%s/%s/%s", id, kind, name, paramsKey)
+	n := p.cursors[key]
+	p.cursors[key] = n + 1
+	p.mu.Unlock()
+
+	call := matches[min(n, len(matches)-1)]
+	if call.Error != nil {
+		return nil, call.Error.decode()
+	}
+	if call.Result == nil {
+		return &variant{VT: ole.VT_EMPTY}, nil
+	}
+	result, err := decodeFixtureValue(call.Result, p.object)
+	return result.named(name), err
+}
+
+func sameFixtureParams(recorded, actual []*FixtureValue) bool {
+	if len(recorded) != len(actual) {
+		return false
+	}
+	for i := range recorded {
+		if recorded[i].VT != actual[i].VT || recorded[i].Object != actual[i].Object {
+			return false
+		}
+		if !bytes.Equal(compactJSON(recorded[i].Value), compactJSON(actual[i].Value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func compactJSON(raw json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+// replayDispatcher is a dispatcher serving the calls recorded for one object.
+type replayDispatcher struct {
+	player *replayer
+	id     int
+}
+
+func (d *replayDispatcher) GetProperty(name string, params ...interface{}) (*variant, error) {
+	return d.player.replay(d.id, "GetProperty", name, params)
+}
+
+func (d *replayDispatcher) PutProperty(name string, params ...interface{}) (*variant, error) {
+	return d.player.replay(d.id, "PutProperty", name, params)
+}
+
+func (d *replayDispatcher) CallMethod(name string, params ...interface{}) (*variant, error) {
+	return d.player.replay(d.id, "CallMethod", name, params)
+}
+
+func (d *replayDispatcher) CreateObject(programID string) (dispatcher, error) {
+	return toIDispatchErr(d.player.replay(d.id, "CreateObject", programID, nil))
+}
+
+func (d *replayDispatcher) IDispatch() *ole.IDispatch {
+	return nil
+}
+
+func (d *replayDispatcher) Release() {}
@@ -0,0 +1,66 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/go-ole/go-ole"
+)
+
+func newTestReplayer(fixture *Fixture) *replayer {
+	return &replayer{fixture: fixture, objects: make(map[int]*replayDispatcher), cursors: make(map[string]int)}
+}
+
+func TestReplay_SequentialResults(t *testing.T) {
+	value := func(n int) *FixtureValue {
+		return &FixtureValue{VT: ole.VT_I4, Value: json.RawMessage(strconv.Itoa(n))}
+	}
+	player := newTestReplayer(&Fixture{Version: FixtureVersion, Root: 1, Objects: []*FixtureObject{{ID: 1, Calls: []*FixtureCall{
+		{Kind: "GetProperty", Name: "Count", Result: value(1)},
+		{Kind: "GetProperty", Name: "Count", Result: value(2)},
+		{Kind: "CallMethod", Name: "Item", Params: []*FixtureValue{value(0)}, Result: value(7)},
+	}}}})
+	d := player.object(1)
+
+	for _, want := range []int32{1, 2, 2} {
+		if got, err := toInt32Err(d.GetProperty("Count")); err != nil || got != want {
+			t.Errorf("Count = %d, %v; want %d", got, err, want)
+		}
+	}
+	if got, err := toInt32Err(d.CallMethod("Item", int32(0))); err != nil || got != 7 {
+		t.Errorf("Item(0) = %d, %v; want 7", got, err)
+	}
+	if _, err := d.CallMethod("Item", int32(1)); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Item(1) error = %v, want ErrNotRecorded", err)
+	}
+	if _, err := d.GetProperty("Title"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Title error = %v, want ErrNotRecorded", err)
+	}
+	if _, err := d.PutProperty("Count", int32(3)); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("PutProperty error = %v, want ErrNotRecorded", err)
+	}
+	if player.object(1) != d {
+		t.Error("object(1) returned a different dispatcher")
+	}
+}
+
+func TestNewReplaySession_MissingRoot(t *testing.T) {
+	if _, err := NewReplaySession(&Fixture{Version: FixtureVersion, Root: 3}); err == nil {
+		t.Error("NewReplaySession with a missing root succeeded")
+	}
+}
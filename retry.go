@@ -0,0 +1,323 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Clock is the source of time of retries and schedules. Package wufake has a
+// fake implementation for tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Defaults of the zero fields of a RetryPolicy.
+const (
+	DefaultRetryAttempts       = 5
+	DefaultRetryInitialBackoff = 2 * time.Second
+	DefaultRetryMaxBackoff     = time.Minute
+	DefaultRetryMultiplier     = 2
+)
+
+// RetryPolicy retries WUA calls that fail with transient errors, waiting with
+// exponential backoff between attempts. The zero value is usable: zero fields
+// take the defaults above, and there is no jitter and no limit on the elapsed
+// time.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts including the first one.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed attempt. Each
+	// following wait is Multiplier times longer, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each wait by up to this fraction of it in either
+	// direction, e.g. 0.2 for ±20%.
+	Jitter float64
+	// MaxElapsedTime stops retrying once the next attempt would start this
+	// long after the first one.
+	MaxElapsedTime time.Duration
+
+	// Retryable classifies errors; nil means IsRetryable.
+	Retryable func(error) bool
+	// OnAttempt is called after every attempt, successful or not.
+	OnAttempt func(RetryAttempt)
+	// Clock measures the elapsed time and the waits; nil means SystemClock.
+	Clock Clock
+	// Rand returns the random numbers in [0, 1) of the jitter; nil means
+	// math/rand/v2.
+	Rand func() float64
+}
+
+// RetryAttempt describes an attempt to OnAttempt.
+type RetryAttempt struct {
+	Attempt  int           // 1 for the first attempt.
+	Err      error         // The error of the attempt, nil if it succeeded.
+	Elapsed  time.Duration // Time since the first attempt started.
+	Retrying bool          // Whether another attempt follows.
+	Delay    time.Duration // The wait before the next attempt.
+}
+
+// RetryError is returned when a RetryPolicy gives up. It wraps the error of
+// the last attempt.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("windowsupdate: giving up after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryableHRESULTs are the transient failures, in addition to every WU_E_PT_*
+// protocol talker error.
+var retryableHRESULTs = map[HRESULT]bool{
+	0x80240009: true, // WU_E_OPERATIONINPROGRESS
+	0x8024001B: true, // WU_E_SELFUPDATE_IN_PROGRESS
+	0x8024001E: true, // WU_E_SERVICE_STOP
+	0x8024001F: true, // WU_E_NO_CONNECTION
+	0x80240021: true, // WU_E_TIME_OUT
+	0x80246005: true, // WU_E_DM_NONETWORK
+	0x80246008: true, // WU_E_DM_FAILTOCONNECTTOBITS
+	0x80246009: true, // WU_E_DM_BITSTRANSFERERROR
+	0x80248001: true, // WU_E_DS_INUSE
+	0x8024800C: true, // WU_E_DS_LOCKTIMEOUTEXPIRED
+	0x8024A000: true, // WU_E_AU_NOSERVICE
+	0x8024F004: true, // WU_E_SERVER_BUSY
+	0x800705B4: true, // ERROR_TIMEOUT
+	0x80070652: true, // ERROR_INSTALL_ALREADY_RUNNING
+	0x800706BA: true, // RPC_S_SERVER_UNAVAILABLE
+	0x800706BE: true, // RPC_S_CALL_FAILED
+	0x80072EE2: true, // ERROR_INTERNET_TIMEOUT
+	0x80072EE7: true, // ERROR_INTERNET_NAME_NOT_RESOLVED
+	0x80072EFD: true, // ERROR_INTERNET_CANNOT_CONNECT
+	0x80072EFE: true, // ERROR_INTERNET_CONNECTION_ABORTED
+}
+
+// IsRetryable reports whether err carries an HRESULT of a transient failure:
+// a WU_E_PT_* network error, the service or its data store being busy or
+// restarting, or a timeout. Context errors are never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	hr, ok := HResultOf(err)
+	if !ok {
+		return false
+	}
+	if hr&0xFFFFF000 == 0x80244000 { // WU_E_PT_*
+		return true
+	}
+	return retryableHRESULTs[hr]
+}
+
+// withDefaults returns p with its zero fields set.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryMaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryMultiplier
+	}
+	if p.Retryable == nil {
+		p.Retryable = IsRetryable
+	}
+	if p.Clock == nil {
+		p.Clock = SystemClock
+	}
+	if p.Rand == nil {
+		p.Rand = rand.Float64
+	}
+	return p
+}
+
+// backoff returns the wait after the given failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt && delay < float64(p.MaxBackoff); i++ {
+		delay *= p.Multiplier
+	}
+	delay = min(delay, float64(p.MaxBackoff))
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*p.Rand()-1)
+	}
+	return time.Duration(delay)
+}
+
+// Do calls op until it succeeds, fails with an error that is not retryable,
+// or the policy gives up, in which case it returns a *RetryError. It stops
+// waiting and returns ctx.Err() when ctx is done.
+func (p RetryPolicy) Do(ctx context.Context, op func(ctx context.Context) error) error {
+	p = p.withDefaults()
+	start := p.Clock.Now()
+	for attempt := 1; ; attempt++ {
+		err := op(ctx)
+		report := RetryAttempt{Attempt: attempt, Err: err, Elapsed: p.Clock.Now().Sub(start)}
+		if err != nil && p.Retryable(err) && attempt < p.MaxAttempts && ctx.Err() == nil {
+			report.Delay = p.backoff(attempt)
+			report.Retrying = p.MaxElapsedTime <= 0 || report.Elapsed+report.Delay < p.MaxElapsedTime
+		}
+		if p.OnAttempt != nil {
+			p.OnAttempt(report)
+		}
+		switch {
+		case err == nil:
+			return nil
+		case !report.Retrying && attempt > 1:
+			return &RetryError{Attempts: attempt, Err: err}
+		case !report.Retrying:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.Clock.After(report.Delay):
+		}
+	}
+}
+
+// Retry is Do for an op returning a value.
+func Retry[T any](ctx context.Context, p RetryPolicy, op func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := p.Do(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = op(ctx)
+		return err
+	})
+	return result, err
+}
+
+// Search runs searcher.SearchContext with retries.
+func (p RetryPolicy) Search(ctx context.Context, searcher Searcher, criteria string, options ...JobOption) (*ISearchResult, error) {
+	return Retry(ctx, p, func(ctx context.Context) (*ISearchResult, error) {
+		return searcher.SearchContext(ctx, criteria, options...)
+	})
+}
+
+// retryJobOptions keeps the channel of WithProgress open across attempts, each
+// of which would close it: the attempts send to it from a progress function,
+// and the returned function closes it after the last one.
+func retryJobOptions(ctx context.Context, options []JobOption) ([]JobOption, func()) {
+	o := newJobOptions(options)
+	if o.progress == nil {
+		return options, func() {}
+	}
+	ch, onProgress := o.progress, o.onProgress
+	relay := func(o *jobOptions) {
+		o.progress = nil
+		o.onProgress = func(p Progress) {
+			if onProgress != nil {
+				onProgress(p)
+			}
+			select {
+			case ch <- p:
+			case <-ctx.Done():
+			}
+		}
+	}
+	return append(slices.Clip(options), relay), func() { close(ch) }
+}
+
+// Download downloads updates with retries, with the DownloadContext of
+// downloader. A download that WUA completes with ResultCodeFailed is retried
+// like an error when its HResult is retryable. If the policy gives up on such
+// a result, the last result is returned without an error, as it would be
+// without retries.
+func (p RetryPolicy) Download(ctx context.Context, downloader Downloader, updates []*IUpdate, options ...JobOption) (*IDownloadResult, error) {
+	options, done := retryJobOptions(ctx, options)
+	defer done()
+	return retryResult(ctx, p, func(ctx context.Context) (*IDownloadResult, error) {
+		return downloader.DownloadContext(ctx, updates, options...)
+	}, func(result *IDownloadResult) error {
+		return failedResult(result.ResultCode, result.HResult)
+	})
+}
+
+// Install installs updates with retries, with the InstallContext of installer.
+// Failed results are retried as by Download.
+func (p RetryPolicy) Install(ctx context.Context, installer Installer, updates []*IUpdate, options ...JobOption) (*IInstallationResult, error) {
+	options, done := retryJobOptions(ctx, options)
+	defer done()
+	return retryResult(ctx, p, func(ctx context.Context) (*IInstallationResult, error) {
+		return installer.InstallContext(ctx, updates, options...)
+	}, func(result *IInstallationResult) error {
+		return failedResult(result.ResultCode, result.HResult)
+	})
+}
+
+// retryResult is Retry for an op that can also fail through its result, as
+// reported by failed. The failed results of the attempts retried are released.
+func retryResult[R interface{ Release() }](ctx context.Context, p RetryPolicy, op func(ctx context.Context) (R, error), failed func(R) error) (R, error) {
+	var last R
+	var held bool
+	release := func() {
+		if held {
+			last.Release()
+			held = false
+		}
+	}
+	result, err := Retry(ctx, p, func(ctx context.Context) (R, error) {
+		release()
+		result, err := op(ctx)
+		if err != nil {
+			return result, err
+		}
+		last, held = result, true
+		return result, failed(result)
+	})
+	if err != nil && held {
+		if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			release()
+			var zero R
+			return zero, err
+		}
+		return last, nil
+	}
+	return result, err
+}
+
+// failedResult returns the error of an operation that WUA completed with
+// ResultCodeFailed, for the policy to decide whether to retry it.
+func failedResult(code OperationResultCode, hResult int32) error {
+	if code != OperationResultCodeOrcFailed || hResult >= 0 {
+		return nil
+	}
+	return HResultError(hResult)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose After advances it by the wait and fires at once.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+// failing returns an op failing with errs in turn, then succeeding.
+func failing(calls *int, errs ...error) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+		return nil
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("boom"), false},
+		{HRESULT(0x8024402C), true},  // WU_E_PT_WINHTTP_NAME_NOT_RESOLVED
+		{HRESULT(0x80244022), true},  // WU_E_PT_HTTP_STATUS_SERVICE_UNAVAIL
+		{HRESULT(0x8024001E), true},  // WU_E_SERVICE_STOP
+		{HRESULT(0x8024F004), true},  // WU_E_SERVER_BUSY
+		{HRESULT(0x80072EE2), true},  // ERROR_INTERNET_TIMEOUT
+		{HRESULT(0x80240024), false}, // WU_E_NO_UPDATE
+		{HRESULT(0x80070005), false}, // E_ACCESSDENIED
+		{HRESULT(0x80240016), false}, // WU_E_INSTALL_NOT_ALLOWED, also a pending reboot
+		{fmt.Errorf("search: %w", HRESULT(0x80244010)), true},
+		{&ComError{HRESULT: 0x8024001E, Err: errors.New("restarting")}, true},
+		{context.Canceled, false},
+		{errors.Join(context.DeadlineExceeded, HRESULT(0x8024001E)), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC)}
+	var attempts []RetryAttempt
+	policy := RetryPolicy{
+		MaxAttempts:    6,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+		Clock:          clock,
+		OnAttempt:      func(a RetryAttempt) { attempts = append(attempts, a) },
+	}
+	var calls int
+	busy := HRESULT(0x8024F004)
+	if err := policy.Do(context.Background(), failing(&calls, busy, busy, busy, busy)); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if calls != 5 {
+		t.Errorf("op called %d times, want 5", calls)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	if !slices.Equal(clock.waits, want) {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+	if len(attempts) != 5 {
+		t.Fatalf("OnAttempt called %d times, want 5", len(attempts))
+	}
+	if a := attempts[3]; a.Attempt != 4 || a.Err != busy || !a.Retrying || a.Delay != 5*time.Second || a.Elapsed != 7*time.Second {
+		t.Errorf("attempt 4 = %+v", a)
+	}
+	if a := attempts[4]; a.Attempt != 5 || a.Err != nil || a.Retrying || a.Elapsed != 12*time.Second {
+		t.Errorf("attempt 5 = %+v", a)
+	}
+}
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	for _, tt := range []struct {
+		rand float64
+		want time.Duration
+	}{
+		{0, 8 * time.Second},
+		{0.5, 10 * time.Second},
+		{0.75, 11 * time.Second},
+	} {
+		clock := &fakeClock{}
+		policy := RetryPolicy{MaxAttempts: 2, InitialBackoff: 10 * time.Second, Jitter: 0.2, Clock: clock, Rand: func() float64 { return tt.rand }}
+		var calls int
+		policy.Do(context.Background(), failing(&calls, HRESULT(0x8024001E)))
+		if !slices.Equal(clock.waits, []time.Duration{tt.want}) {
+			t.Errorf("waits with rand %v = %v, want [%v]", tt.rand, clock.waits, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy_GivesUp(t *testing.T) {
+	busy := HRESULT(0x8024F004)
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		wantCalls int
+	}{
+		{"MaxAttempts", RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second}, 3},
+		// Waits of 1s, 2s and 4s: the fourth attempt would start at 7s.
+		{"MaxElapsedTime", RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxElapsedTime: 6 * time.Second}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.policy.Clock = &fakeClock{}
+			var calls int
+			err := tt.policy.Do(context.Background(), failing(&calls, busy, busy, busy, busy, busy))
+			var retryErr *RetryError
+			if !errors.As(err, &retryErr) || retryErr.Attempts != tt.wantCalls || !errors.Is(err, busy) {
+				t.Errorf("Do() error = %v, want *RetryError wrapping %v after %d attempts", err, busy, tt.wantCalls)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("op called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_NotRetryable(t *testing.T) {
+	noUpdate := HRESULT(0x80240024)
+	var calls int
+	err := RetryPolicy{Clock: &fakeClock{}}.Do(context.Background(), failing(&calls, noUpdate))
+	if err != noUpdate || calls != 1 {
+		t.Errorf("Do() error = %v after %d calls, want %v after 1", err, calls, noUpdate)
+	}
+
+	var retryable []error
+	policy := RetryPolicy{
+		Clock:     &fakeClock{},
+		Retryable: func(err error) bool { retryable = append(retryable, err); return errors.Is(err, ErrNoUpdate) },
+	}
+	calls = 0
+	if err := policy.Do(context.Background(), failing(&calls, noUpdate)); err != nil || calls != 2 {
+		t.Errorf("Do() with a custom classifier error = %v after %d calls, want nil after 2", err, calls)
+	}
+	if len(retryable) != 1 {
+		t.Errorf("Retryable called %d times, want 1", len(retryable))
+	}
+}
+
+func TestRetryPolicy_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The wait never fires; the context is canceled while waiting.
+	clock := &cancelingClock{cancel: cancel}
+	var calls int
+	err := RetryPolicy{Clock: clock}.Do(ctx, failing(&calls, HRESULT(0x8024001E)))
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("Do() error = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+type cancelingClock struct {
+	cancel context.CancelFunc
+}
+
+func (c *cancelingClock) Now() time.Time { return time.Time{} }
+
+func (c *cancelingClock) After(time.Duration) <-chan time.Time {
+	c.cancel()
+	return nil
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	got, err := Retry(context.Background(), RetryPolicy{Clock: &fakeClock{}}, func(context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, HRESULT(0x80244010) // WU_E_PT_EXCEEDED_MAX_SERVER_TRIPS
+		}
+		return 42, nil
+	})
+	if got != 42 || err != nil {
+		t.Errorf("Retry() = %d, %v; want 42, nil", got, err)
+	}
+}
+
+// flakyDownloader fails its first DownloadContext after reporting progress.
+type flakyDownloader struct {
+	Downloader
+	calls int
+}
+
+func (d *flakyDownloader) DownloadContext(ctx context.Context, updates []*IUpdate, options ...JobOption) (*IDownloadResult, error) {
+	o := newJobOptions(options)
+	defer o.done()
+	d.calls++
+	p := Progress{PercentComplete: int32(50 * d.calls)}
+	if o.onProgress != nil {
+		o.onProgress(p)
+	}
+	if o.progress != nil {
+		o.progress <- p
+	}
+	if d.calls == 1 {
+		return nil, HRESULT(0x80246008) // WU_E_DM_FAILTOCONNECTTOBITS
+	}
+	return NewDownloadResult(OperationResultCodeOrcSucceeded, 0, nil), nil
+}
+
+func TestRetryPolicy_DownloadProgress(t *testing.T) {
+	ch := make(chan Progress)
+	received := make(chan []int32)
+	go func() {
+		var percents []int32
+		for p := range ch {
+			percents = append(percents, p.PercentComplete)
+		}
+		received <- percents
+	}()
+	var funcCalls int
+	downloader := &flakyDownloader{}
+	result, err := RetryPolicy{Clock: &fakeClock{}}.Download(context.Background(), downloader, nil,
+		WithProgress(ch), WithProgressFunc(func(Progress) { funcCalls++ }))
+	if err != nil || result.ResultCode != OperationResultCodeOrcSucceeded {
+		t.Fatalf("Download() = %+v, %v", result, err)
+	}
+	if got := <-received; !slices.Equal(got, []int32{50, 100}) {
+		t.Errorf("progress = %v, want [50 100] from both attempts", got)
+	}
+	if funcCalls != 2 {
+		t.Errorf("progress func called %d times, want 2", funcCalls)
+	}
+}
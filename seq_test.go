@@ -0,0 +1,184 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIUpdateSearcher_SearchStream(t *testing.T) {
+	first := newFakeUpdate(map[string]interface{}{"Title": "First"})
+	second := newFakeUpdate(map[string]interface{}{"Title": "Second"})
+	session, err := toIUpdateSession(newFakeSession(first, second))
+	if err != nil {
+		t.Fatalf("toIUpdateSession failed: %v", err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+
+	result, err := searcher.SearchStream("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("SearchStream failed: %v", err)
+	}
+	if result.Updates != nil {
+		t.Errorf("Updates = %v, want nil for a streamed result", result.Updates)
+	}
+	if len(first.Calls()) != 0 {
+		t.Errorf("SearchStream converted an update: %v", first.Calls())
+	}
+
+	var titles []string
+	for update, err := range result.All() {
+		if err != nil {
+			t.Fatalf("All yielded %v", err)
+		}
+		titles = append(titles, update.Title)
+		break
+	}
+	if len(titles) != 1 || titles[0] != "First" {
+		t.Errorf("titles = %v", titles)
+	}
+	if len(second.Calls()) != 0 {
+		t.Errorf("the update after break was converted: %v", second.Calls())
+	}
+
+	titles = nil
+	for update, err := range result.All() {
+		if err != nil {
+			t.Fatalf("All yielded %v", err)
+		}
+		titles = append(titles, update.Title)
+	}
+	if len(titles) != 2 || titles[1] != "Second" {
+		t.Errorf("titles = %v", titles)
+	}
+}
+
+func TestIUpdateSearcher_SearchContextStreamed(t *testing.T) {
+	update := newFakeUpdate(map[string]interface{}{"Title": "First"})
+	session, err := toIUpdateSession(newFakeSession(update))
+	if err != nil {
+		t.Fatalf("toIUpdateSession failed: %v", err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+
+	result, err := searcher.SearchContext(context.Background(), "IsInstalled=0", WithStreamedUpdates())
+	if err != nil {
+		t.Fatalf("SearchContext failed: %v", err)
+	}
+	if result.Updates != nil || len(update.Calls()) != 0 {
+		t.Errorf("SearchContext converted the updates: %v", update.Calls())
+	}
+	var titles []string
+	for update, err := range result.All() {
+		if err != nil {
+			t.Fatalf("All yielded %v", err)
+		}
+		titles = append(titles, update.Title)
+	}
+	if len(titles) != 1 || titles[0] != "First" {
+		t.Errorf("titles = %v", titles)
+	}
+}
+
+func TestISearchResult_All_ReleaseBoundsMemory(t *testing.T) {
+	tracker := NewLeakTracker()
+	session, err := newIUpdateSession(newFakeSession(newFakeUpdate(nil), newFakeUpdate(nil), newFakeUpdate(nil)), sessionOptions{tracker: tracker})
+	if err != nil {
+		t.Fatalf("newIUpdateSession failed: %v", err)
+	}
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+	result, err := searcher.SearchStream("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("SearchStream failed: %v", err)
+	}
+
+	before := len(tracker.Unreleased())
+	for update, err := range result.All() {
+		if err != nil {
+			t.Fatalf("All yielded %v", err)
+		}
+		update.Release()
+		if got := len(tracker.Unreleased()); got != before {
+			t.Errorf("%d objects tracked after releasing an update, %d before the loop", got, before)
+		}
+	}
+}
+
+func TestISearchResult_All_Materialized(t *testing.T) {
+	result, err := toISearchResult(newFakeDispatcher(map[string]interface{}{
+		"ResultCode":     OperationResultCodeOrcSucceeded,
+		"RootCategories": newFakeCollection(),
+		"Updates":        newFakeCollection(newFakeUpdate(nil), newFakeUpdate(nil)),
+		"Warnings":       newFakeCollection(),
+	}))
+	if err != nil {
+		t.Fatalf("toISearchResult failed: %v", err)
+	}
+	var n int
+	for update, err := range result.All() {
+		if err != nil || update != result.Updates[n] {
+			t.Errorf("All yielded %p, %v; want Updates[%d]", update, err, n)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("All yielded %d updates, want 2", n)
+	}
+}
+
+func TestIUpdateCollection_All(t *testing.T) {
+	testErr := errors.New("access denied")
+	failing := newFakeUpdate(map[string]interface{}{"Title": testErr})
+	collection, err := toIUpdateCollection2(newFakeCollection(
+		newFakeUpdate(map[string]interface{}{"Title": "First"}),
+		failing,
+		newFakeUpdate(nil),
+	))
+	if err != nil {
+		t.Fatalf("toIUpdateCollection2 failed: %v", err)
+	}
+
+	var titles []string
+	var errs []error
+	for update, err := range collection.All() {
+		if err != nil {
+			if update != nil {
+				t.Errorf("All yielded update %+v with error", update)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		titles = append(titles, update.Title)
+	}
+	if len(titles) != 1 || titles[0] != "First" {
+		t.Errorf("titles = %v", titles)
+	}
+	if len(errs) != 1 || errs[0] != testErr {
+		t.Errorf("errors = %v, want only %v", errs, testErr)
+	}
+	if n := failing.Releases(); n != 1 {
+		t.Errorf("the update that failed to convert was released %d times, want 1", n)
+	}
+}
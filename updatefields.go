@@ -0,0 +1,370 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+// UpdateFields selects the IUpdate properties read when an update is converted.
+// Every property is a COM round-trip, and the nested objects (Categories,
+// DownloadContents, Image...) take several more, so searches that only need a
+// few properties should ask for just those. Fields not loaded keep their zero
+// value until Load or one of the GetXxx accessors fetches them.
+type UpdateFields uint32
+
+// The property groups of IUpdate.
+const (
+	// UpdateFieldIdentity loads Identity.
+	UpdateFieldIdentity UpdateFields = 1 << iota
+	// UpdateFieldTitle loads Title.
+	UpdateFieldTitle
+	// UpdateFieldKBArticleIDs loads KBArticleIDs.
+	UpdateFieldKBArticleIDs
+	// UpdateFieldSize loads MaxDownloadSize and MinDownloadSize.
+	UpdateFieldSize
+	// UpdateFieldState loads AutoSelectOnWebSites, BrowseOnly, CanRequireSource,
+	// DeltaCompressedContentAvailable, DeltaCompressedContentPreferred,
+	// EulaAccepted, IsBeta, IsDownloaded, IsHidden, IsInstalled, IsMandatory,
+	// IsPresent, IsUninstallable, PerUser and RebootRequired.
+	UpdateFieldState
+	// UpdateFieldDeployment loads AutoDownload, AutoSelection, Deadline,
+	// DeploymentAction, DownloadPriority and LastDeploymentChangeTime.
+	UpdateFieldDeployment
+	// UpdateFieldSecurity loads CveIDs, MsrcSeverity and SecurityBulletinIDs.
+	UpdateFieldSecurity
+	// UpdateFieldDetails loads Description, HandlerID, Languages, MoreInfoUrls,
+	// RecommendedCpuSpeed, RecommendedHardDiskSpace, RecommendedMemory,
+	// SupersededUpdateIDs, SupportUrl, UninstallationNotes and
+	// UninstallationSteps.
+	UpdateFieldDetails
+	// UpdateFieldCategories loads Categories.
+	UpdateFieldCategories
+	// UpdateFieldBehavior loads InstallationBehavior and UninstallationBehavior.
+	UpdateFieldBehavior
+	// UpdateFieldEulaText loads EulaText.
+	UpdateFieldEulaText
+	// UpdateFieldReleaseNotes loads ReleaseNotes.
+	UpdateFieldReleaseNotes
+	// UpdateFieldImage loads Image.
+	UpdateFieldImage
+	// UpdateFieldDownloadContents loads DownloadContents.
+	UpdateFieldDownloadContents
+	// UpdateFieldBundledUpdates loads BundledUpdates.
+	UpdateFieldBundledUpdates
+)
+
+// Presets of UpdateFields.
+const (
+	// MinimalUpdateFields is enough to list pending updates: identity, title,
+	// KB articles and size.
+	MinimalUpdateFields = UpdateFieldIdentity | UpdateFieldTitle | UpdateFieldKBArticleIDs | UpdateFieldSize
+	// StandardUpdateFields adds the scalar properties, categories and
+	// installation behavior, leaving out the long texts and rarely used objects.
+	StandardUpdateFields = MinimalUpdateFields | UpdateFieldState | UpdateFieldDeployment | UpdateFieldSecurity |
+		UpdateFieldDetails | UpdateFieldCategories | UpdateFieldBehavior
+	// FullUpdateFields loads every property, and is the default.
+	FullUpdateFields = StandardUpdateFields | UpdateFieldEulaText | UpdateFieldReleaseNotes | UpdateFieldImage |
+		UpdateFieldDownloadContents | UpdateFieldBundledUpdates
+)
+
+// updateLoader reads the properties of one group into iUpdate.
+type updateLoader struct {
+	field UpdateFields
+	load  func(iUpdate *IUpdate, updateDisp dispatcher) error
+}
+
+var updateLoaders = []updateLoader{
+	{UpdateFieldIdentity, func(iUpdate *IUpdate, updateDisp dispatcher) error {
+		identityDisp, err := toIDispatchErr(updateDisp.GetProperty("Identity"))
+		if err != nil {
+			return err
+		}
+		if identityDisp != nil {
+			if iUpdate.Identity, err = toIUpdateIdentity(identityDisp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{UpdateFieldTitle, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		iUpdate.Title, err = toStringErr(updateDisp.GetProperty("Title"))
+		return err
+	}},
+	{UpdateFieldKBArticleIDs, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		iUpdate.KBArticleIDs, err = iStringCollectionToStringArrayErr(toIDispatchErr(updateDisp.GetProperty("KBArticleIDs")))
+		return err
+	}},
+	{UpdateFieldSize, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		if iUpdate.MaxDownloadSize, err = toInt64Err(updateDisp.GetProperty("MaxDownloadSize")); err != nil {
+			return err
+		}
+		iUpdate.MinDownloadSize, err = toInt64Err(updateDisp.GetProperty("MinDownloadSize"))
+		return err
+	}},
+	{UpdateFieldState, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		for _, p := range []struct {
+			name  string
+			value *bool
+		}{
+			{"AutoSelectOnWebSites", &iUpdate.AutoSelectOnWebSites},
+			{"CanRequireSource", &iUpdate.CanRequireSource},
+			{"DeltaCompressedContentAvailable", &iUpdate.DeltaCompressedContentAvailable},
+			{"DeltaCompressedContentPreferred", &iUpdate.DeltaCompressedContentPreferred},
+			{"EulaAccepted", &iUpdate.EulaAccepted},
+			{"IsBeta", &iUpdate.IsBeta},
+			{"IsDownloaded", &iUpdate.IsDownloaded},
+			{"IsHidden", &iUpdate.IsHidden},
+			{"IsInstalled", &iUpdate.IsInstalled},
+			{"IsMandatory", &iUpdate.IsMandatory},
+			{"IsUninstallable", &iUpdate.IsUninstallable},
+		} {
+			if *p.value, err = toBoolErr(updateDisp.GetProperty(p.name)); err != nil {
+				return err
+			}
+		}
+
+		// IUpdate2-4 properties (may fail on older systems)
+		if isPresent, err := toBoolErr(updateDisp.GetProperty("IsPresent")); err == nil {
+			iUpdate.IsPresent = isPresent
+		}
+		if rebootRequired, err := toBoolErr(updateDisp.GetProperty("RebootRequired")); err == nil {
+			iUpdate.RebootRequired = rebootRequired
+		}
+		if browseOnly, err := toBoolErr(updateDisp.GetProperty("BrowseOnly")); err == nil {
+			iUpdate.BrowseOnly = browseOnly
+		}
+		if perUser, err := toBoolErr(updateDisp.GetProperty("PerUser")); err == nil {
+			iUpdate.PerUser = perUser
+		}
+		return nil
+	}},
+	{UpdateFieldDeployment, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		if iUpdate.Deadline, err = toTimeErr(updateDisp.GetProperty("Deadline")); err != nil {
+			return err
+		}
+		if iUpdate.DeploymentAction, err = toEnumErr[DeploymentAction](updateDisp.GetProperty("DeploymentAction")); err != nil {
+			return err
+		}
+		if iUpdate.DownloadPriority, err = toEnumErr[DownloadPriority](updateDisp.GetProperty("DownloadPriority")); err != nil {
+			return err
+		}
+		if iUpdate.LastDeploymentChangeTime, err = toTimeErr(updateDisp.GetProperty("LastDeploymentChangeTime")); err != nil {
+			return err
+		}
+
+		// IUpdate5 properties
+		if autoDownload, err := toEnumErr[AutoDownloadMode](updateDisp.GetProperty("AutoDownload")); err == nil {
+			iUpdate.AutoDownload = autoDownload
+		}
+		if autoSelection, err := toEnumErr[AutoSelectionMode](updateDisp.GetProperty("AutoSelection")); err == nil {
+			iUpdate.AutoSelection = autoSelection
+		}
+		return nil
+	}},
+	{UpdateFieldSecurity, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		if iUpdate.MsrcSeverity, err = toStringErr(updateDisp.GetProperty("MsrcSeverity")); err != nil {
+			return err
+		}
+		if iUpdate.SecurityBulletinIDs, err = iStringCollectionToStringArrayErr(toIDispatchErr(updateDisp.GetProperty("SecurityBulletinIDs"))); err != nil {
+			return err
+		}
+
+		// IUpdate2 property (may fail on older systems)
+		if cveIDs, err := iStringCollectionToStringArrayErr(toIDispatchErr(updateDisp.GetProperty("CveIDs"))); err == nil {
+			iUpdate.CveIDs = cveIDs
+		}
+		return nil
+	}},
+	{UpdateFieldDetails, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		for _, p := range []struct {
+			name  string
+			value *string
+		}{
+			{"Description", &iUpdate.Description},
+			{"HandlerID", &iUpdate.HandlerID},
+			{"SupportUrl", &iUpdate.SupportUrl},
+			{"UninstallationNotes", &iUpdate.UninstallationNotes},
+		} {
+			if *p.value, err = toStringErr(updateDisp.GetProperty(p.name)); err != nil {
+				return err
+			}
+		}
+		for _, p := range []struct {
+			name  string
+			value *int32
+		}{
+			{"RecommendedCpuSpeed", &iUpdate.RecommendedCpuSpeed},
+			{"RecommendedHardDiskSpace", &iUpdate.RecommendedHardDiskSpace},
+			{"RecommendedMemory", &iUpdate.RecommendedMemory},
+		} {
+			if *p.value, err = toInt32Err(updateDisp.GetProperty(p.name)); err != nil {
+				return err
+			}
+		}
+		for _, p := range []struct {
+			name  string
+			value *[]string
+		}{
+			{"Languages", &iUpdate.Languages},
+			{"MoreInfoUrls", &iUpdate.MoreInfoUrls},
+			{"SupersededUpdateIDs", &iUpdate.SupersededUpdateIDs},
+			{"UninstallationSteps", &iUpdate.UninstallationSteps},
+		} {
+			if *p.value, err = iStringCollectionToStringArrayErr(toIDispatchErr(updateDisp.GetProperty(p.name))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{UpdateFieldCategories, func(iUpdate *IUpdate, updateDisp dispatcher) error {
+		categoriesDisp, err := toIDispatchErr(updateDisp.GetProperty("Categories"))
+		if err != nil {
+			return err
+		}
+		if categoriesDisp != nil {
+			if iUpdate.Categories, err = toICategories(categoriesDisp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{UpdateFieldBehavior, func(iUpdate *IUpdate, updateDisp dispatcher) error {
+		installationBehaviorDisp, err := toIDispatchErr(updateDisp.GetProperty("InstallationBehavior"))
+		if err != nil {
+			return err
+		}
+		if installationBehaviorDisp != nil {
+			if iUpdate.InstallationBehavior, err = toIInstallationBehavior(installationBehaviorDisp); err != nil {
+				return err
+			}
+		}
+
+		uninstallationBehaviorDisp, err := toIDispatchErr(updateDisp.GetProperty("UninstallationBehavior"))
+		if err != nil {
+			return err
+		}
+		if uninstallationBehaviorDisp != nil {
+			if iUpdate.UninstallationBehavior, err = toIInstallationBehavior(uninstallationBehaviorDisp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{UpdateFieldEulaText, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		iUpdate.EulaText, err = toStringErr(updateDisp.GetProperty("EulaText"))
+		return err
+	}},
+	{UpdateFieldReleaseNotes, func(iUpdate *IUpdate, updateDisp dispatcher) (err error) {
+		iUpdate.ReleaseNotes, err = toStringErr(updateDisp.GetProperty("ReleaseNotes"))
+		return err
+	}},
+	{UpdateFieldImage, func(iUpdate *IUpdate, updateDisp dispatcher) error {
+		imageDisp, err := toIDispatchErr(updateDisp.GetProperty("Image"))
+		if err != nil {
+			return err
+		}
+		if imageDisp != nil {
+			if iUpdate.Image, err = toIImageInformation(imageDisp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{UpdateFieldDownloadContents, func(iUpdate *IUpdate, updateDisp dispatcher) error {
+		downloadContentsDisp, err := toIDispatchErr(updateDisp.GetProperty("DownloadContents"))
+		if err != nil {
+			return err
+		}
+		if downloadContentsDisp != nil {
+			if iUpdate.DownloadContents, err = toIUpdateDownloadContents(downloadContentsDisp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{UpdateFieldBundledUpdates, func(iUpdate *IUpdate, updateDisp dispatcher) error {
+		bundledUpdatesDisp, err := toIDispatchErr(updateDisp.GetProperty("BundledUpdates"))
+		if err != nil {
+			return err
+		}
+		if bundledUpdatesDisp != nil {
+			if iUpdate.BundledUpdates, err = toIUpdatesIdentities(bundledUpdatesDisp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+// Load reads the properties in fields that are not loaded yet. It is a no-op
+// for an update that was not obtained from WUA.
+func (iUpdate *IUpdate) Load(fields UpdateFields) error {
+	if iUpdate.disp == nil {
+		return nil
+	}
+	for _, loader := range updateLoaders {
+		if fields&loader.field == 0 || iUpdate.loaded&loader.field != 0 {
+			continue
+		}
+		if err := loader.load(iUpdate, iUpdate.disp); err != nil {
+			return err
+		}
+		iUpdate.loaded |= loader.field
+	}
+	return nil
+}
+
+// Loaded reports whether all the properties in fields have been read.
+func (iUpdate *IUpdate) Loaded(fields UpdateFields) bool {
+	return iUpdate.loaded&fields == fields
+}
+
+// GetCategories returns Categories, loading them on first use.
+func (iUpdate *IUpdate) GetCategories() ([]*ICategory, error) {
+	err := iUpdate.Load(UpdateFieldCategories)
+	return iUpdate.Categories, err
+}
+
+// GetEulaText returns EulaText, loading it on first use.
+func (iUpdate *IUpdate) GetEulaText() (string, error) {
+	err := iUpdate.Load(UpdateFieldEulaText)
+	return iUpdate.EulaText, err
+}
+
+// GetReleaseNotes returns ReleaseNotes, loading them on first use.
+func (iUpdate *IUpdate) GetReleaseNotes() (string, error) {
+	err := iUpdate.Load(UpdateFieldReleaseNotes)
+	return iUpdate.ReleaseNotes, err
+}
+
+// GetImage returns Image, loading it on first use.
+func (iUpdate *IUpdate) GetImage() (*IImageInformation, error) {
+	err := iUpdate.Load(UpdateFieldImage)
+	return iUpdate.Image, err
+}
+
+// GetDownloadContents returns DownloadContents, loading them on first use.
+func (iUpdate *IUpdate) GetDownloadContents() ([]*IUpdateDownloadContent, error) {
+	err := iUpdate.Load(UpdateFieldDownloadContents)
+	return iUpdate.DownloadContents, err
+}
+
+// GetBundledUpdates returns BundledUpdates, loading them on first use.
+func (iUpdate *IUpdate) GetBundledUpdates() ([]*IUpdateIdentity, error) {
+	err := iUpdate.Load(UpdateFieldBundledUpdates)
+	return iUpdate.BundledUpdates, err
+}
+
+// GetInstallationBehavior returns InstallationBehavior, loading it on first use.
+func (iUpdate *IUpdate) GetInstallationBehavior() (*IInstallationBehavior, error) {
+	err := iUpdate.Load(UpdateFieldBehavior)
+	return iUpdate.InstallationBehavior, err
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windowsupdate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestToIUpdateFields_Minimal(t *testing.T) {
+	disp := newFakeUpdate(nil)
+	update, err := toIUpdateFields(disp, MinimalUpdateFields)
+	if err != nil {
+		t.Fatalf("toIUpdateFields failed: %v", err)
+	}
+	want := []string{
+		"GetProperty Identity",
+		"GetProperty Title",
+		"GetProperty KBArticleIDs",
+		"GetProperty MaxDownloadSize",
+		"GetProperty MinDownloadSize",
+	}
+	if got := disp.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Calls() = %v, want %v", got, want)
+	}
+	if update.Title == "" || update.Identity == nil || len(update.KBArticleIDs) != 1 || update.MaxDownloadSize == 0 {
+		t.Errorf("minimal fields not read: %+v", update)
+	}
+	if update.Categories != nil || update.MsrcSeverity != "" {
+		t.Errorf("fields outside MinimalUpdateFields were read: %+v", update)
+	}
+	if !update.Loaded(MinimalUpdateFields) || update.Loaded(UpdateFieldCategories) {
+		t.Errorf("Loaded reports %b", update.loaded)
+	}
+}
+
+func TestIUpdate_LazyGetters(t *testing.T) {
+	disp := newFakeUpdate(map[string]interface{}{"EulaText": "terms"})
+	update, err := toIUpdateFields(disp, UpdateFieldTitle)
+	if err != nil {
+		t.Fatalf("toIUpdateFields failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		categories, err := update.GetCategories()
+		if err != nil {
+			t.Fatalf("GetCategories failed: %v", err)
+		}
+		if len(categories) != 1 || categories[0].Name != "Security Updates" {
+			t.Errorf("GetCategories() = %+v", categories)
+		}
+		if eula, err := update.GetEulaText(); err != nil || eula != "terms" {
+			t.Errorf("GetEulaText() = %q, %v", eula, err)
+		}
+		behavior, err := update.GetInstallationBehavior()
+		if err != nil || behavior == nil || behavior.RebootBehavior != InstallationRebootBehaviorIrbCanRequestReboot {
+			t.Errorf("GetInstallationBehavior() = %+v, %v", behavior, err)
+		}
+	}
+
+	counts := map[string]int{}
+	for _, call := range disp.Calls() {
+		counts[call]++
+	}
+	for _, name := range []string{"Categories", "EulaText", "InstallationBehavior", "UninstallationBehavior"} {
+		if n := counts["GetProperty "+name]; n != 1 {
+			t.Errorf("%s read %d times, want once", name, n)
+		}
+	}
+}
+
+func TestIUpdate_LoadError(t *testing.T) {
+	testErr := errors.New("access denied")
+	update, err := toIUpdateFields(newFakeUpdate(map[string]interface{}{"ReleaseNotes": testErr}), MinimalUpdateFields)
+	if err != nil {
+		t.Fatalf("toIUpdateFields failed: %v", err)
+	}
+	if _, err := update.GetReleaseNotes(); err != testErr {
+		t.Errorf("GetReleaseNotes error = %v, want %v", err, testErr)
+	}
+	if update.Loaded(UpdateFieldReleaseNotes) {
+		t.Error("ReleaseNotes marked loaded after an error")
+	}
+}
+
+func TestIUpdate_LoadWithoutDispatcher(t *testing.T) {
+	update := &IUpdate{Title: "built by hand"}
+	if err := update.Load(FullUpdateFields); err != nil {
+		t.Errorf("Load failed: %v", err)
+	}
+	if categories, err := update.GetCategories(); err != nil || categories != nil {
+		t.Errorf("GetCategories() = %v, %v", categories, err)
+	}
+}
+
+func TestIUpdateSearcher_UpdateFields(t *testing.T) {
+	update := newFakeUpdate(nil)
+	session, err := toIUpdateSession(newFakeSession(update))
+	if err != nil {
+		t.Fatalf("toIUpdateSession failed: %v", err)
+	}
+	session.updateFields = MinimalUpdateFields
+
+	searcher, err := session.CreateUpdateSearcher()
+	if err != nil {
+		t.Fatalf("CreateUpdateSearcher failed: %v", err)
+	}
+	if searcher.UpdateFields() != MinimalUpdateFields {
+		t.Errorf("UpdateFields() = %b, want the session's", searcher.UpdateFields())
+	}
+	result, err := searcher.Search("IsInstalled=0")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Updates) != 1 || !result.Updates[0].Loaded(MinimalUpdateFields) || result.Updates[0].Loaded(UpdateFieldState) {
+		t.Errorf("Updates = %+v", result.Updates)
+	}
+	for _, call := range update.Calls() {
+		if call == "GetProperty Categories" {
+			t.Errorf("Search read Categories with MinimalUpdateFields")
+		}
+	}
+
+	searcher.SetUpdateFields(0)
+	if searcher.UpdateFields() != FullUpdateFields {
+		t.Errorf("UpdateFields() = %b, want FullUpdateFields by default", searcher.UpdateFields())
+	}
+}
+
+func TestToIUpdate_LoadsFullUpdateFields(t *testing.T) {
+	update, err := toIUpdate(newFakeUpdate(nil))
+	if err != nil {
+		t.Fatalf("toIUpdate failed: %v", err)
+	}
+	if !update.Loaded(FullUpdateFields) {
+		t.Errorf("toIUpdate loaded %b, want FullUpdateFields", update.loaded)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+var _ windowsupdate.Clock = (*Clock)(nil)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC)
+	clock := NewClock(start)
+	if got := <-clock.After(time.Minute); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("After(1m) fired at %v, want %v", got, start.Add(time.Minute))
+	}
+	clock.Advance(time.Hour)
+	if got := clock.Now(); !got.Equal(start.Add(time.Hour + time.Minute)) {
+		t.Errorf("Now() = %v, want %v", got, start.Add(time.Hour+time.Minute))
+	}
+	if got := clock.Waits(); !slices.Equal(got, []time.Duration{time.Minute}) {
+		t.Errorf("Waits() = %v, want [1m]", got)
+	}
+}
+
+func TestClock_RetryPolicySearch(t *testing.T) {
+	catalog := &Catalog{SearchErr: ComError(0x8024402C)} // WU_E_PT_WINHTTP_NAME_NOT_RESOLVED
+	clock := NewClock(time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC))
+	policy := windowsupdate.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, Clock: clock}
+
+	_, err := policy.Search(context.Background(), newSearcher(t, catalog), "IsInstalled=0")
+	var retryErr *windowsupdate.RetryError
+	if !errors.As(err, &retryErr) || retryErr.Attempts != 3 {
+		t.Fatalf("Search() error = %v, want *RetryError after 3 attempts", err)
+	}
+	if !errors.Is(err, windowsupdate.ErrNoConnection) {
+		t.Errorf("errors.Is(%v, ErrNoConnection) = false, want true", err)
+	}
+	if got := len(catalog.Calls()); got != 3 {
+		t.Errorf("catalog has %d calls, want 3", got)
+	}
+	if got := clock.Waits(); !slices.Equal(got, []time.Duration{time.Second, 2 * time.Second}) {
+		t.Errorf("Waits() = %v, want [1s 2s]", got)
+	}
+}
+
+func TestClock_RetryPolicyDownloadFailedResult(t *testing.T) {
+	a := NewUpdate("a")
+	catalog := &Catalog{DownloadOutcomes: map[string]Outcome{
+		"a": {ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: int32(-2145107924)}, // 0x8024402C
+	}}
+	clock := NewClock(time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC))
+	policy := windowsupdate.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		Clock:          clock,
+		// The network comes back after the first attempt.
+		OnAttempt: func(windowsupdate.RetryAttempt) { delete(catalog.DownloadOutcomes, "a") },
+	}
+	downloader, err := NewSession(catalog).CreateDownloader()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := policy.Download(context.Background(), downloader, []*windowsupdate.IUpdate{a})
+	if err != nil || result.ResultCode != windowsupdate.OperationResultCodeOrcSucceeded {
+		t.Fatalf("Download() = %+v, %v; want a successful result", result, err)
+	}
+	if got := len(catalog.Calls()); got != 2 || !a.IsDownloaded {
+		t.Errorf("catalog has %d calls, IsDownloaded = %v; want 2 calls and the update downloaded", got, a.IsDownloaded)
+	}
+	if got := clock.Waits(); !slices.Equal(got, []time.Duration{time.Second}) {
+		t.Errorf("Waits() = %v, want [1s]", got)
+	}
+}
+
+func TestClock_RetryPolicyInstallGivesUp(t *testing.T) {
+	a := NewUpdate("a")
+	catalog := &Catalog{InstallOutcomes: map[string]Outcome{
+		"a": {ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: int32(-2145107924)}, // 0x8024402C
+	}}
+	policy := windowsupdate.RetryPolicy{MaxAttempts: 2, Clock: NewClock(time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC))}
+
+	result, err := policy.Install(context.Background(), newInstaller(t, catalog), []*windowsupdate.IUpdate{a})
+	if err != nil || result.ResultCode != windowsupdate.OperationResultCodeOrcFailed {
+		t.Fatalf("Install() = %+v, %v; want the failed result of the last attempt", result, err)
+	}
+	if got := len(catalog.Calls()); got != 2 {
+		t.Errorf("catalog has %d calls, want 2", got)
+	}
+}
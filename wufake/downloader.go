@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"context"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+// Downloader is a fake windowsupdate.Downloader.
+type Downloader struct {
+	catalog *Catalog
+	jobs    *jobTable[*windowsupdate.IDownloadJob, *windowsupdate.IDownloadResult]
+	handle
+}
+
+// Download applies Catalog.DownloadOutcomes to updates after Catalog.DownloadDelay.
+// Updates whose outcome succeeded are marked IsDownloaded.
+func (d *Downloader) Download(updates []*windowsupdate.IUpdate) (*windowsupdate.IDownloadResult, error) {
+	return d.DownloadContext(context.Background(), updates)
+}
+
+// DownloadContext is Download returning a *windowsupdate.DownloadAbortedError
+// with an aborted result if ctx is done during Catalog.DownloadDelay. Progress
+// is reported at 0 and 100 percent.
+func (d *Downloader) DownloadContext(ctx context.Context, updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IDownloadResult, error) {
+	report, done := windowsupdate.ProgressOf(ctx, options...)
+	defer done()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	d.catalog.record(Call{Op: "Download", UpdateIDs: updateIDs(updates)})
+	report(windowsupdate.Progress{DownloadPhase: windowsupdate.DownloadPhaseDownloading})
+	if err := sleep(ctx, d.catalog.DownloadDelay); err != nil {
+		return nil, &windowsupdate.DownloadAbortedError{
+			Err:    err,
+			Result: windowsupdate.NewDownloadResult(windowsupdate.OperationResultCodeOrcAborted, 0, nil),
+		}
+	}
+	if d.catalog.DownloadErr != nil {
+		return nil, d.catalog.DownloadErr
+	}
+	if len(updates) == 0 {
+		return nil, ComError(hrNoUpdate)
+	}
+
+	d.catalog.mu.Lock()
+	outcomes := make([]Outcome, len(updates))
+	for i, update := range updates {
+		outcomes[i] = outcomeFor(d.catalog.DownloadOutcomes, update)
+		if succeeded(outcomes[i]) {
+			update.IsDownloaded = true
+		}
+	}
+	d.catalog.mu.Unlock()
+	total := aggregate(outcomes)
+	perUpdate := updateOutcomes(updates, outcomes)
+	for i := range perUpdate {
+		perUpdate[i].RebootRequired = false
+	}
+	report(completed(len(updates), windowsupdate.DownloadPhaseVerifying))
+	return windowsupdate.NewDownloadResult(total.ResultCode, total.HResult, perUpdate), nil
+}
+
+// BeginDownload starts Download in the background. The Completed callback set
+// with windowsupdate.WithCallbacks is called when it finishes.
+func (d *Downloader) BeginDownload(updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IDownloadJob, error) {
+	job := &windowsupdate.IDownloadJob{Updates: updates}
+	d.jobs.start(job, windowsupdate.CallbacksOf(options...), func() (*windowsupdate.IDownloadResult, error) {
+		return d.Download(updates)
+	})
+	return job, nil
+}
+
+// EndDownload waits for the download started by BeginDownload and returns its result.
+func (d *Downloader) EndDownload(downloadJob *windowsupdate.IDownloadJob) (*windowsupdate.IDownloadResult, error) {
+	return d.jobs.end(downloadJob)
+}
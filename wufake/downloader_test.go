@@ -0,0 +1,105 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/go-ole/go-ole"
+)
+
+var _ windowsupdate.Downloader = (*Downloader)(nil)
+
+func newDownloader(t *testing.T, catalog *Catalog) windowsupdate.Downloader {
+	t.Helper()
+	downloader, err := NewSession(catalog).CreateDownloader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return downloader
+}
+
+func TestDownloader_Outcomes(t *testing.T) {
+	a, b := NewUpdate("a"), NewUpdate("b")
+	catalog := &Catalog{
+		DownloadOutcomes: map[string]Outcome{
+			"b": {ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: -2145107951},
+		},
+	}
+	result, err := newDownloader(t, catalog).Download([]*windowsupdate.IUpdate{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ResultCode != windowsupdate.OperationResultCodeOrcSucceededWithErrors {
+		t.Errorf("ResultCode = %d, want %d", result.ResultCode, windowsupdate.OperationResultCodeOrcSucceededWithErrors)
+	}
+	if !a.IsDownloaded || b.IsDownloaded {
+		t.Errorf("IsDownloaded = %v, %v; want true, false", a.IsDownloaded, b.IsDownloaded)
+	}
+}
+
+func TestDownloader_NoUpdates(t *testing.T) {
+	_, err := newDownloader(t, &Catalog{}).Download(nil)
+	var oleErr *ole.OleError
+	if !errors.As(err, &oleErr) || oleErr.Code() != hrNoUpdate {
+		t.Errorf("Download(nil) error = %v, want WU_E_NO_UPDATE", err)
+	}
+}
+
+func TestDownloader_BeginEndDownload(t *testing.T) {
+	catalog := &Catalog{DownloadErr: ComError(0x80072EE2)}
+	downloader := newDownloader(t, catalog)
+	job, err := downloader.BeginDownload([]*windowsupdate.IUpdate{NewUpdate("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(job.Updates) != 1 {
+		t.Errorf("job.Updates has %d updates, want 1", len(job.Updates))
+	}
+	if _, err := downloader.EndDownload(job); err != catalog.DownloadErr {
+		t.Errorf("EndDownload() error = %v, want %v", err, catalog.DownloadErr)
+	}
+}
+
+func TestDownloader_DownloadContext(t *testing.T) {
+	ch := make(chan windowsupdate.Progress, 2)
+	var funcCalls int
+	_, err := newDownloader(t, &Catalog{}).DownloadContext(context.Background(), []*windowsupdate.IUpdate{NewUpdate("a")},
+		windowsupdate.WithProgress(ch), windowsupdate.WithProgressFunc(func(windowsupdate.Progress) { funcCalls++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var percents []int32
+	for p := range ch {
+		percents = append(percents, p.PercentComplete)
+	}
+	if len(percents) != 2 || percents[1] != 100 || funcCalls != 2 {
+		t.Errorf("progress = %v with %d function calls, want 0 and 100 to both", percents, funcCalls)
+	}
+}
+
+func TestDownloader_DownloadContextCanceled(t *testing.T) {
+	catalog := &Catalog{DownloadDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := newDownloader(t, catalog).DownloadContext(ctx, []*windowsupdate.IUpdate{NewUpdate("a")})
+	var aborted *windowsupdate.DownloadAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, context.DeadlineExceeded) || aborted.Result.ResultCode != windowsupdate.OperationResultCodeOrcAborted {
+		t.Errorf("DownloadContext() error = %v, want a *DownloadAbortedError", err)
+	}
+}
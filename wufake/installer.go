@@ -0,0 +1,140 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"context"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+// Installer is a fake windowsupdate.Installer.
+type Installer struct {
+	catalog *Catalog
+	jobs    *jobTable[*windowsupdate.IInstallationJob, *windowsupdate.IInstallationResult]
+	handle
+}
+
+// Install applies Catalog.InstallOutcomes to updates after Catalog.InstallDelay.
+// Updates whose outcome succeeded are marked IsInstalled, and every update gets
+// an entry appended to Catalog.History.
+func (i *Installer) Install(updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+	return i.InstallContext(context.Background(), updates)
+}
+
+// InstallContext is Install returning a *windowsupdate.InstallationAbortedError
+// with an aborted result if ctx is done during Catalog.InstallDelay. Progress
+// is reported at 0 and 100 percent.
+func (i *Installer) InstallContext(ctx context.Context, updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationResult, error) {
+	return i.run(ctx, "Install", windowsupdate.UpdateOperationUoInstallation, updates, options)
+}
+
+// BeginInstall starts Install in the background. The Completed callback set with
+// windowsupdate.WithCallbacks is called when it finishes.
+func (i *Installer) BeginInstall(updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationJob, error) {
+	job := &windowsupdate.IInstallationJob{Updates: updates}
+	i.jobs.start(job, windowsupdate.CallbacksOf(options...), func() (*windowsupdate.IInstallationResult, error) {
+		return i.Install(updates)
+	})
+	return job, nil
+}
+
+// EndInstall waits for the installation started by BeginInstall and returns its result.
+func (i *Installer) EndInstall(installationJob *windowsupdate.IInstallationJob) (*windowsupdate.IInstallationResult, error) {
+	return i.jobs.end(installationJob)
+}
+
+// Uninstall applies Catalog.UninstallOutcomes to updates after
+// Catalog.UninstallDelay. Updates whose outcome succeeded are marked as no
+// longer installed, and every update gets an entry appended to Catalog.History.
+func (i *Installer) Uninstall(updates []*windowsupdate.IUpdate) (*windowsupdate.IInstallationResult, error) {
+	return i.UninstallContext(context.Background(), updates)
+}
+
+// UninstallContext is InstallContext for Uninstall, with
+// Catalog.UninstallDelay.
+func (i *Installer) UninstallContext(ctx context.Context, updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationResult, error) {
+	return i.run(ctx, "Uninstall", windowsupdate.UpdateOperationUoUninstallation, updates, options)
+}
+
+// BeginUninstall starts Uninstall in the background. The Completed callback set with
+// windowsupdate.WithCallbacks is called when it finishes.
+func (i *Installer) BeginUninstall(updates []*windowsupdate.IUpdate, options ...windowsupdate.JobOption) (*windowsupdate.IInstallationJob, error) {
+	job := &windowsupdate.IInstallationJob{Updates: updates}
+	i.jobs.start(job, windowsupdate.CallbacksOf(options...), func() (*windowsupdate.IInstallationResult, error) {
+		return i.Uninstall(updates)
+	})
+	return job, nil
+}
+
+// EndUninstall waits for the uninstallation started by BeginUninstall and returns its result.
+func (i *Installer) EndUninstall(installationJob *windowsupdate.IInstallationJob) (*windowsupdate.IInstallationResult, error) {
+	return i.jobs.end(installationJob)
+}
+
+func (i *Installer) run(ctx context.Context, op string, operation windowsupdate.UpdateOperation, updates []*windowsupdate.IUpdate, options []windowsupdate.JobOption) (*windowsupdate.IInstallationResult, error) {
+	report, done := windowsupdate.ProgressOf(ctx, options...)
+	defer done()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c := i.catalog
+	c.record(Call{Op: op, UpdateIDs: updateIDs(updates)})
+
+	delay, err, outcomes := c.InstallDelay, c.InstallErr, c.InstallOutcomes
+	if operation == windowsupdate.UpdateOperationUoUninstallation {
+		delay, err, outcomes = c.UninstallDelay, c.UninstallErr, c.UninstallOutcomes
+	}
+	report(windowsupdate.Progress{})
+	if err := sleep(ctx, delay); err != nil {
+		return nil, &windowsupdate.InstallationAbortedError{
+			Err:    err,
+			Result: windowsupdate.NewInstallationResult(windowsupdate.OperationResultCodeOrcAborted, 0, nil),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		return nil, ComError(hrNoUpdate)
+	}
+
+	c.mu.Lock()
+	results := make([]Outcome, len(updates))
+	for n, update := range updates {
+		results[n] = outcomeFor(outcomes, update)
+		if succeeded(results[n]) {
+			if operation == windowsupdate.UpdateOperationUoInstallation {
+				update.IsInstalled = true
+			} else {
+				update.IsInstalled = false
+			}
+			update.RebootRequired = results[n].RebootRequired
+		}
+
+		date := c.now()
+		c.History = append(c.History, &windowsupdate.IUpdateHistoryEntry{
+			Date:           &date,
+			HResult:        results[n].HResult,
+			Operation:      operation,
+			ResultCode:     results[n].ResultCode,
+			Title:          update.Title,
+			UpdateIdentity: update.Identity,
+		})
+	}
+	c.mu.Unlock()
+	total := aggregate(results)
+	report(completed(len(updates), 0))
+	return windowsupdate.NewInstallationResult(total.ResultCode, total.HResult, updateOutcomes(updates, results)), nil
+}
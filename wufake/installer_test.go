@@ -0,0 +1,143 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+)
+
+var _ windowsupdate.Installer = (*Installer)(nil)
+
+func newInstaller(t *testing.T, catalog *Catalog) windowsupdate.Installer {
+	t.Helper()
+	installer, err := NewSession(catalog).CreateInstaller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return installer
+}
+
+func TestInstaller_InstallRecordsHistory(t *testing.T) {
+	now := time.Date(2026, 1, 13, 2, 0, 0, 0, time.UTC)
+	a := NewUpdate("a")
+	catalog := &Catalog{
+		InstallOutcomes: map[string]Outcome{
+			"a": {ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: -2145124330},
+		},
+		Now: func() time.Time { return now },
+	}
+	result, err := newInstaller(t, catalog).Install([]*windowsupdate.IUpdate{a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ResultCode != windowsupdate.OperationResultCodeOrcFailed || result.HResult != -2145124330 {
+		t.Errorf("result = %+v, want failed with HResult -2145124330", result)
+	}
+	if a.IsInstalled {
+		t.Error("IsInstalled = true after failed install")
+	}
+	if len(catalog.History) != 1 {
+		t.Fatalf("History has %d entries, want 1", len(catalog.History))
+	}
+	entry := catalog.History[0]
+	if entry.Operation != windowsupdate.UpdateOperationUoInstallation || !entry.Date.Equal(now) || entry.UpdateIdentity != a.Identity {
+		t.Errorf("history entry = %+v", entry)
+	}
+}
+
+func TestInstaller_BeginEndUninstall(t *testing.T) {
+	a := NewUpdate("a")
+	a.IsInstalled = true
+	installer := newInstaller(t, &Catalog{})
+
+	job, err := installer.BeginUninstall([]*windowsupdate.IUpdate{a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := installer.EndUninstall(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ResultCode != windowsupdate.OperationResultCodeOrcSucceeded {
+		t.Errorf("ResultCode = %d, want %d", result.ResultCode, windowsupdate.OperationResultCodeOrcSucceeded)
+	}
+	if a.IsInstalled {
+		t.Error("IsInstalled = true after uninstall")
+	}
+}
+
+func TestInstaller_BeginEndInstall(t *testing.T) {
+	a := NewUpdate("a")
+	catalog := &Catalog{InstallOutcomes: map[string]Outcome{
+		"a": {ResultCode: windowsupdate.OperationResultCodeOrcSucceeded, RebootRequired: true},
+	}}
+	installer := newInstaller(t, catalog)
+
+	job, err := installer.BeginInstall([]*windowsupdate.IUpdate{a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := installer.EndInstall(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.RebootRequired || !a.IsInstalled || !a.RebootRequired {
+		t.Errorf("result.RebootRequired = %v, IsInstalled = %v, RebootRequired = %v; want all true", result.RebootRequired, a.IsInstalled, a.RebootRequired)
+	}
+}
+
+func TestInstaller_Outcomes(t *testing.T) {
+	a, b := NewUpdate("a"), NewUpdate("b")
+	catalog := &Catalog{InstallOutcomes: map[string]Outcome{
+		"a": {ResultCode: windowsupdate.OperationResultCodeOrcSucceeded, RebootRequired: true},
+		"b": {ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: -2145124330},
+	}}
+	updates := []*windowsupdate.IUpdate{a, b}
+	result, err := newInstaller(t, catalog).Install(updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcomes, err := result.Outcomes(updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outcomes) != 2 || !outcomes[0].RebootRequired || outcomes[1].Update != b {
+		t.Errorf("Outcomes() = %+v", outcomes)
+	}
+	if err := outcomes.Err(); !errors.Is(err, windowsupdate.ErrInstallNotAllowed) {
+		t.Errorf("Outcomes().Err() = %v, want ErrInstallNotAllowed", err)
+	}
+}
+
+func TestInstaller_InstallContextCanceled(t *testing.T) {
+	catalog := &Catalog{InstallDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	a := NewUpdate("a")
+	var percents []int32
+	_, err := newInstaller(t, catalog).InstallContext(ctx, []*windowsupdate.IUpdate{a},
+		windowsupdate.WithProgressFunc(func(p windowsupdate.Progress) { percents = append(percents, p.PercentComplete) }))
+	var aborted *windowsupdate.InstallationAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("InstallContext() error = %v, want an *InstallationAbortedError", err)
+	}
+	if a.IsInstalled || len(catalog.History) != 0 || len(percents) != 1 {
+		t.Errorf("IsInstalled = %v, history = %v, progress = %v after the abort", a.IsInstalled, catalog.History, percents)
+	}
+}
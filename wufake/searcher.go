@@ -0,0 +1,154 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"context"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/criteria"
+)
+
+// Searcher is a fake windowsupdate.Searcher.
+type Searcher struct {
+	catalog *Catalog
+	jobs    *jobTable[*windowsupdate.ISearchJob, *windowsupdate.ISearchResult]
+	handle
+}
+
+// Search returns the catalog updates matching criteria after Catalog.SearchDelay.
+func (s *Searcher) Search(criteria string) (*windowsupdate.ISearchResult, error) {
+	return s.SearchContext(context.Background(), criteria)
+}
+
+// SearchStream is Search: the fake updates need no conversion, and
+// ISearchResult.All ranges over Updates.
+func (s *Searcher) SearchStream(criteria string) (*windowsupdate.ISearchResult, error) {
+	return s.Search(criteria)
+}
+
+// SearchContext is Search returning a *windowsupdate.SearchAbortedError with an
+// aborted result if ctx is done during Catalog.SearchDelay. The fake does not
+// poll, so options are ignored.
+func (s *Searcher) SearchContext(ctx context.Context, criteria string, options ...windowsupdate.JobOption) (*windowsupdate.ISearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.catalog.record(Call{Op: "Search", Criteria: criteria})
+	if err := sleep(ctx, s.catalog.SearchDelay); err != nil {
+		return nil, &windowsupdate.SearchAbortedError{
+			Err:    err,
+			Result: &windowsupdate.ISearchResult{ResultCode: windowsupdate.OperationResultCodeOrcAborted},
+		}
+	}
+	if s.catalog.SearchErr != nil {
+		return nil, s.catalog.SearchErr
+	}
+
+	match := s.catalog.Match
+	if match == nil {
+		match = DefaultMatch
+	}
+
+	s.catalog.mu.Lock()
+	defer s.catalog.mu.Unlock()
+	updates := make([]*windowsupdate.IUpdate, 0, len(s.catalog.Updates))
+	for _, update := range s.catalog.Updates {
+		if match(criteria, update) {
+			updates = append(updates, update)
+		}
+	}
+	return &windowsupdate.ISearchResult{
+		ResultCode: windowsupdate.OperationResultCodeOrcSucceeded,
+		Updates:    updates,
+	}, nil
+}
+
+// SearchCriteria is Search with a criteria built with package criteria.
+func (s *Searcher) SearchCriteria(expr criteria.Expr) (*windowsupdate.ISearchResult, error) {
+	return s.Search(expr.String())
+}
+
+// SearchCriteriaContext is SearchContext with a criteria built with package criteria.
+func (s *Searcher) SearchCriteriaContext(ctx context.Context, expr criteria.Expr, options ...windowsupdate.JobOption) (*windowsupdate.ISearchResult, error) {
+	return s.SearchContext(ctx, expr.String(), options...)
+}
+
+// BeginSearch starts Search in the background. The Completed callback set with
+// windowsupdate.WithCallbacks is called when it finishes.
+func (s *Searcher) BeginSearch(criteria string, options ...windowsupdate.JobOption) (*windowsupdate.ISearchJob, error) {
+	job := &windowsupdate.ISearchJob{}
+	s.jobs.start(job, windowsupdate.CallbacksOf(options...), func() (*windowsupdate.ISearchResult, error) {
+		return s.Search(criteria)
+	})
+	return job, nil
+}
+
+// BeginSearchCriteria is BeginSearch with a criteria built with package criteria.
+func (s *Searcher) BeginSearchCriteria(expr criteria.Expr, options ...windowsupdate.JobOption) (*windowsupdate.ISearchJob, error) {
+	return s.BeginSearch(expr.String(), options...)
+}
+
+// EndSearch waits for the search started by BeginSearch and returns its result.
+func (s *Searcher) EndSearch(searchJob *windowsupdate.ISearchJob) (*windowsupdate.ISearchResult, error) {
+	return s.jobs.end(searchJob)
+}
+
+// QueryHistory returns count entries of Catalog.History starting at startIndex.
+func (s *Searcher) QueryHistory(startIndex int32, count int32) ([]*windowsupdate.IUpdateHistoryEntry, error) {
+	s.catalog.record(Call{Op: "QueryHistory"})
+	s.catalog.mu.Lock()
+	defer s.catalog.mu.Unlock()
+	history := s.catalog.History
+	start := min(max(int(startIndex), 0), len(history))
+	end := min(start+max(int(count), 0), len(history))
+	return append([]*windowsupdate.IUpdateHistoryEntry(nil), history[start:end]...), nil
+}
+
+// QueryHistoryAll returns every entry of Catalog.History.
+func (s *Searcher) QueryHistoryAll() ([]*windowsupdate.IUpdateHistoryEntry, error) {
+	count, err := s.GetTotalHistoryCount()
+	if err != nil {
+		return nil, err
+	}
+	return s.QueryHistory(0, count)
+}
+
+// QueryHistoryContext is QueryHistory failing with ctx.Err() if ctx is done; a
+// negative count reads up to the last entry.
+func (s *Searcher) QueryHistoryContext(ctx context.Context, startIndex int32, count int32) ([]*windowsupdate.IUpdateHistoryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if count < 0 {
+		total, err := s.GetTotalHistoryCount()
+		if err != nil {
+			return nil, err
+		}
+		count = total
+	}
+	return s.QueryHistory(startIndex, count)
+}
+
+// GetTotalHistoryCount returns the length of Catalog.History.
+func (s *Searcher) GetTotalHistoryCount() (int32, error) {
+	s.catalog.mu.Lock()
+	defer s.catalog.mu.Unlock()
+	return int32(len(s.catalog.History)), nil
+}
+
+// EscapeString returns windowsupdate.EscapeString(unescaped).
+func (s *Searcher) EscapeString(unescaped string) (string, error) {
+	return windowsupdate.EscapeString(unescaped), nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/ceshihao/windowsupdate/criteria"
+)
+
+var _ windowsupdate.Searcher = (*Searcher)(nil)
+
+func newSearcher(t *testing.T, catalog *Catalog) windowsupdate.Searcher {
+	t.Helper()
+	searcher, err := NewSession(catalog).CreateSearcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return searcher
+}
+
+func TestSearcher_SearchErr(t *testing.T) {
+	catalog := &Catalog{SearchErr: ComError(0x8024001E)}
+	if _, err := newSearcher(t, catalog).Search("IsInstalled=0"); err != catalog.SearchErr {
+		t.Errorf("Search() error = %v, want %v", err, catalog.SearchErr)
+	}
+}
+
+func TestSearcher_CustomMatch(t *testing.T) {
+	catalog := &Catalog{
+		Updates: []*windowsupdate.IUpdate{NewUpdate("a"), NewUpdate("b")},
+		Match: func(criteria string, update *windowsupdate.IUpdate) bool {
+			return criteria == "UpdateID='"+update.Identity.UpdateID+"'"
+		},
+	}
+	result, err := newSearcher(t, catalog).Search("UpdateID='b'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Updates) != 1 || result.Updates[0].Identity.UpdateID != "b" {
+		t.Errorf("Search() returned %v, want update b", result.Updates)
+	}
+	if result.ResultCode != windowsupdate.OperationResultCodeOrcSucceeded {
+		t.Errorf("ResultCode = %d, want %d", result.ResultCode, windowsupdate.OperationResultCodeOrcSucceeded)
+	}
+}
+
+func TestSearcher_BeginEndSearch(t *testing.T) {
+	catalog := &Catalog{
+		Updates:     []*windowsupdate.IUpdate{NewUpdate("a")},
+		SearchDelay: 20 * time.Millisecond,
+	}
+	searcher := newSearcher(t, catalog)
+
+	start := time.Now()
+	job, err := searcher.BeginSearch("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := searcher.EndSearch(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < catalog.SearchDelay {
+		t.Errorf("EndSearch returned after %v, want at least %v", elapsed, catalog.SearchDelay)
+	}
+	if len(result.Updates) != 1 {
+		t.Errorf("EndSearch returned %d updates, want 1", len(result.Updates))
+	}
+	if _, err := searcher.EndSearch(job); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("second EndSearch error = %v, want ErrUnknownJob", err)
+	}
+}
+
+func TestSearcher_QueryHistory(t *testing.T) {
+	catalog := &Catalog{History: []*windowsupdate.IUpdateHistoryEntry{{Title: "0"}, {Title: "1"}, {Title: "2"}}}
+	searcher := newSearcher(t, catalog)
+
+	count, err := searcher.GetTotalHistoryCount()
+	if err != nil || count != 3 {
+		t.Fatalf("GetTotalHistoryCount() = %d, %v; want 3, nil", count, err)
+	}
+
+	entries, err := searcher.QueryHistory(1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Title != "1" {
+		t.Errorf("QueryHistory(1, 5) returned %d entries starting at %q", len(entries), entries[0].Title)
+	}
+
+	all, err := searcher.QueryHistoryAll()
+	if err != nil || len(all) != 3 {
+		t.Errorf("QueryHistoryAll() = %d entries, %v; want 3, nil", len(all), err)
+	}
+}
+
+func TestSearcher_EscapeString(t *testing.T) {
+	got, err := newSearcher(t, &Catalog{}).EscapeString(`it's a\b`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `it\'s a\\b`; got != want {
+		t.Errorf("EscapeString() = %q, want %q", got, want)
+	}
+}
+
+func TestSearcher_SearchCriteria(t *testing.T) {
+	installed := NewUpdate("installed")
+	installed.IsInstalled = true
+	catalog := &Catalog{Updates: []*windowsupdate.IUpdate{NewUpdate("missing"), installed}}
+	searcher := newSearcher(t, catalog)
+
+	result, err := searcher.SearchCriteria(criteria.And(criteria.IsInstalled(false), criteria.IsHidden(false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Updates) != 1 || result.Updates[0].Identity.UpdateID != "missing" {
+		t.Errorf("SearchCriteria() returned %v, want update missing", result.Updates)
+	}
+
+	job, err := searcher.BeginSearchCriteria(criteria.IsInstalled(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result, err = searcher.EndSearch(job); err != nil || len(result.Updates) != 1 || result.Updates[0] != installed {
+		t.Errorf("EndSearch() = %v, %v; want update installed", result, err)
+	}
+	if calls := catalog.Calls(); calls[0].Criteria != "IsInstalled=0 and IsHidden=0" || calls[1].Criteria != "IsInstalled=1" {
+		t.Errorf("Calls() = %+v", calls)
+	}
+}
+
+func TestSearcher_SearchContext(t *testing.T) {
+	catalog := &Catalog{
+		Updates:     []*windowsupdate.IUpdate{NewUpdate("a")},
+		SearchDelay: time.Second,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := newSearcher(t, catalog).SearchContext(ctx, "IsInstalled=0")
+	var aborted *windowsupdate.SearchAbortedError
+	if !errors.As(err, &aborted) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SearchContext() error = %v, want a SearchAbortedError after the deadline", err)
+	}
+	if aborted.Result.ResultCode != windowsupdate.OperationResultCodeOrcAborted {
+		t.Errorf("aborted ResultCode = %d", aborted.Result.ResultCode)
+	}
+
+	catalog.SearchDelay = 0
+	result, err := newSearcher(t, catalog).SearchContext(context.Background(), "IsInstalled=0")
+	if err != nil || len(result.Updates) != 1 {
+		t.Errorf("SearchContext() = %v, %v; want update a", result, err)
+	}
+}
+
+func TestSearcher_QueryHistoryContext(t *testing.T) {
+	catalog := &Catalog{History: []*windowsupdate.IUpdateHistoryEntry{{Title: "0"}, {Title: "1"}, {Title: "2"}}}
+	searcher := newSearcher(t, catalog)
+
+	entries, err := searcher.QueryHistoryContext(context.Background(), 1, -1)
+	if err != nil || len(entries) != 2 {
+		t.Errorf("QueryHistoryContext(1, -1) = %v, %v; want 2 entries", entries, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := searcher.QueryHistoryContext(ctx, 0, -1); err != context.Canceled {
+		t.Errorf("QueryHistoryContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestSearcher_BeginSearchCallbacks(t *testing.T) {
+	searcher := newSearcher(t, &Catalog{SearchDelay: 5 * time.Millisecond})
+	completed := make(chan struct{})
+	job, err := searcher.BeginSearch("", windowsupdate.WithCallbacks(windowsupdate.JobCallbacks{
+		Completed: func() { close(completed) },
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-completed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Completed callback not called")
+	}
+	if _, err := searcher.EndSearch(job); err != nil {
+		t.Errorf("EndSearch failed: %v", err)
+	}
+}
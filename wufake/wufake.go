@@ -0,0 +1,365 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wufake is an in-memory Windows Update backend. It implements the
+// windowsupdate Session, Searcher, Downloader and Installer interfaces on top of
+// a scripted Catalog, so code written against those interfaces can be exercised
+// on any OS without COM.
+package wufake
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/go-ole/go-ole"
+)
+
+// HRESULTs reported by the fake in the same situations as WUA.
+const (
+	hrNoUpdate = 0x80240024 // WU_E_NO_UPDATE: the update collection is empty.
+)
+
+// ErrUnknownJob is returned by the EndXxx methods for a job that was not started
+// by the same fake object, or that has already been ended.
+var ErrUnknownJob = errors.New("wufake: unknown job")
+
+// ComError returns the error a failed WUA call reports for the given HRESULT,
+// e.g. ComError(0x8024402C) for WU_E_PT_WINHTTP_NAME_NOT_RESOLVED.
+func ComError(hr uint32) error {
+	return &windowsupdate.ComError{HRESULT: windowsupdate.HRESULT(hr), Err: ole.NewError(uintptr(hr))}
+}
+
+// Outcome scripts the per-update result of a download, install or uninstall.
+type Outcome struct {
+	ResultCode     windowsupdate.OperationResultCode
+	HResult        int32
+	RebootRequired bool
+}
+
+// Succeeded is the outcome of updates that have no scripted outcome.
+var Succeeded = Outcome{ResultCode: windowsupdate.OperationResultCodeOrcSucceeded}
+
+// Call records one operation performed against a Catalog.
+type Call struct {
+	Op        string // "Search", "Download", "Install", "Uninstall" or "QueryHistory"
+	Criteria  string
+	UpdateIDs []string
+}
+
+// NewUpdate returns an update to script a Catalog with: its UpdateID is id, its
+// title "Update " followed by id, and its EULA is accepted, as for most
+// updates offered by WUA.
+func NewUpdate(id string) *windowsupdate.IUpdate {
+	return &windowsupdate.IUpdate{
+		Identity:     &windowsupdate.IUpdateIdentity{UpdateID: id, RevisionNumber: 1},
+		Title:        "Update " + id,
+		EulaAccepted: true,
+	}
+}
+
+// Catalog is the scripted state shared by every object created from a Session.
+// The exported fields must be set before the catalog is used; afterwards they are
+// guarded by the catalog and must not be modified. Updates are mutated in place
+// as operations succeed: a download sets IsDownloaded, an install sets
+// IsInstalled and RebootRequired, an uninstall clears IsInstalled.
+type Catalog struct {
+	Updates []*windowsupdate.IUpdate
+	History []*windowsupdate.IUpdateHistoryEntry
+
+	// Match reports whether an update satisfies a search criteria string. If nil,
+	// DefaultMatch is used.
+	Match func(criteria string, update *windowsupdate.IUpdate) bool
+
+	// Errors returned by the operations instead of a result, typically built
+	// with ComError.
+	SearchErr    error
+	DownloadErr  error
+	InstallErr   error
+	UninstallErr error
+
+	// Delays applied before an operation completes, both for the synchronous
+	// methods and for the BeginXxx/EndXxx pairs.
+	SearchDelay    time.Duration
+	DownloadDelay  time.Duration
+	InstallDelay   time.Duration
+	UninstallDelay time.Duration
+
+	// Scripted per-update outcomes keyed by IUpdateIdentity.UpdateID.
+	DownloadOutcomes  map[string]Outcome
+	InstallOutcomes   map[string]Outcome
+	UninstallOutcomes map[string]Outcome
+
+	// Now returns the time stamped on history entries. If nil, time.Now is used.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	calls []Call
+	open  int
+}
+
+// Calls returns the operations performed against the catalog so far, in order.
+func (c *Catalog) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// Open returns the number of searchers, downloaders and installers created
+// from the catalog and not released yet, to check that code releases them.
+func (c *Catalog) Open() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.open
+}
+
+// handle counts a searcher, downloader or installer as open in its catalog
+// until it is released.
+type handle struct {
+	owner *Catalog
+	once  sync.Once
+}
+
+func newHandle(c *Catalog) handle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.open++
+	return handle{owner: c}
+}
+
+// Release marks the object released. Only the first call counts.
+func (h *handle) Release() {
+	h.once.Do(func() {
+		h.owner.mu.Lock()
+		defer h.owner.mu.Unlock()
+		h.owner.open--
+	})
+}
+
+func (c *Catalog) record(call Call) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+func (c *Catalog) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// Session is a fake windowsupdate.Session backed by a Catalog.
+type Session struct {
+	catalog *Catalog
+}
+
+// NewSession returns a session whose searchers, downloaders and installers all
+// operate on catalog.
+func NewSession(catalog *Catalog) *Session {
+	return &Session{catalog: catalog}
+}
+
+// Release does nothing: the session holds no resources.
+func (s *Session) Release() {}
+
+// CreateSearcher returns a fake searcher over the session's catalog.
+func (s *Session) CreateSearcher() (windowsupdate.Searcher, error) {
+	return &Searcher{catalog: s.catalog, jobs: newJobTable[*windowsupdate.ISearchJob, *windowsupdate.ISearchResult](), handle: newHandle(s.catalog)}, nil
+}
+
+// CreateDownloader returns a fake downloader over the session's catalog.
+func (s *Session) CreateDownloader() (windowsupdate.Downloader, error) {
+	return &Downloader{catalog: s.catalog, jobs: newJobTable[*windowsupdate.IDownloadJob, *windowsupdate.IDownloadResult](), handle: newHandle(s.catalog)}, nil
+}
+
+// CreateInstaller returns a fake installer over the session's catalog.
+func (s *Session) CreateInstaller() (windowsupdate.Installer, error) {
+	return &Installer{catalog: s.catalog, jobs: newJobTable[*windowsupdate.IInstallationJob, *windowsupdate.IInstallationResult](), handle: newHandle(s.catalog)}, nil
+}
+
+// DefaultMatch evaluates the subset of the WUA criteria language the fake
+// understands: boolean criteria (IsInstalled, IsHidden, IsPresent,
+// RebootRequired, BrowseOnly, AutoSelectOnWebSites) joined with "and". Any other
+// term is ignored, i.e. treated as matching.
+func DefaultMatch(criteria string, update *windowsupdate.IUpdate) bool {
+	for _, term := range strings.Split(criteria, " and ") {
+		name, value, ok := strings.Cut(strings.TrimSpace(term), "=")
+		if !ok {
+			continue
+		}
+		want := strings.TrimSpace(value) == "1"
+		var got bool
+		switch strings.TrimSpace(name) {
+		case "IsInstalled":
+			got = update.IsInstalled
+		case "IsHidden":
+			got = update.IsHidden
+		case "IsPresent":
+			got = update.IsPresent
+		case "RebootRequired":
+			got = update.RebootRequired
+		case "BrowseOnly":
+			got = update.BrowseOnly
+		case "AutoSelectOnWebSites":
+			got = update.AutoSelectOnWebSites
+		default:
+			continue
+		}
+		if got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// jobTable tracks the pending asynchronous operations of one fake object.
+type jobTable[J comparable, R any] struct {
+	mu   sync.Mutex
+	jobs map[J]*pendingJob[R]
+}
+
+type pendingJob[R any] struct {
+	done   chan struct{}
+	result R
+	err    error
+}
+
+func newJobTable[J comparable, R any]() *jobTable[J, R] {
+	return &jobTable[J, R]{jobs: make(map[J]*pendingJob[R])}
+}
+
+// start runs op in the background and associates its result with job. The
+// Completed callback is called once the result is available.
+func (t *jobTable[J, R]) start(job J, callbacks windowsupdate.JobCallbacks, op func() (R, error)) {
+	p := &pendingJob[R]{done: make(chan struct{})}
+	t.mu.Lock()
+	t.jobs[job] = p
+	t.mu.Unlock()
+	go func() {
+		p.result, p.err = op()
+		close(p.done)
+		if callbacks.Completed != nil {
+			callbacks.Completed()
+		}
+	}()
+}
+
+// end blocks until the operation associated with job has finished.
+func (t *jobTable[J, R]) end(job J) (R, error) {
+	t.mu.Lock()
+	p, ok := t.jobs[job]
+	delete(t.jobs, job)
+	t.mu.Unlock()
+	if !ok {
+		var zero R
+		return zero, ErrUnknownJob
+	}
+	<-p.done
+	return p.result, p.err
+}
+
+// sleep waits for d, or returns ctx.Err() if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// completed is the last progress snapshot of an operation on n updates.
+func completed(n int, phase windowsupdate.DownloadPhase) windowsupdate.Progress {
+	return windowsupdate.Progress{
+		PercentComplete:              100,
+		CurrentUpdateIndex:           int32(max(n-1, 0)),
+		CurrentUpdatePercentComplete: 100,
+		DownloadPhase:                phase,
+	}
+}
+
+func updateID(update *windowsupdate.IUpdate) string {
+	if update.Identity == nil {
+		return ""
+	}
+	return update.Identity.UpdateID
+}
+
+func updateIDs(updates []*windowsupdate.IUpdate) []string {
+	ids := make([]string, len(updates))
+	for i, update := range updates {
+		ids[i] = updateID(update)
+	}
+	return ids
+}
+
+func outcomeFor(outcomes map[string]Outcome, update *windowsupdate.IUpdate) Outcome {
+	if outcome, ok := outcomes[updateID(update)]; ok {
+		return outcome
+	}
+	return Succeeded
+}
+
+func succeeded(outcome Outcome) bool {
+	return outcome.ResultCode == windowsupdate.OperationResultCodeOrcSucceeded ||
+		outcome.ResultCode == windowsupdate.OperationResultCodeOrcSucceededWithErrors
+}
+
+// updateOutcomes pairs updates with their scripted outcomes.
+func updateOutcomes(updates []*windowsupdate.IUpdate, outcomes []Outcome) windowsupdate.UpdateOutcomes {
+	perUpdate := make(windowsupdate.UpdateOutcomes, len(updates))
+	for i, update := range updates {
+		perUpdate[i] = windowsupdate.UpdateOutcome{
+			Update:         update,
+			ResultCode:     outcomes[i].ResultCode,
+			HResult:        outcomes[i].HResult,
+			RebootRequired: outcomes[i].RebootRequired,
+		}
+	}
+	return perUpdate
+}
+
+// aggregate combines per-update outcomes into the operation-level result the way
+// WUA does: all succeeded, all failed, or succeeded with errors.
+func aggregate(outcomes []Outcome) Outcome {
+	var ok, failed int
+	var total Outcome
+	for _, outcome := range outcomes {
+		if succeeded(outcome) {
+			ok++
+		} else {
+			failed++
+			if total.HResult == 0 {
+				total.HResult = outcome.HResult
+			}
+		}
+		total.RebootRequired = total.RebootRequired || outcome.RebootRequired
+	}
+	switch {
+	case failed == 0:
+		total.ResultCode = windowsupdate.OperationResultCodeOrcSucceeded
+		total.HResult = 0
+	case ok == 0:
+		total.ResultCode = windowsupdate.OperationResultCodeOrcFailed
+	default:
+		total.ResultCode = windowsupdate.OperationResultCodeOrcSucceededWithErrors
+		total.HResult = 0
+	}
+	return total
+}
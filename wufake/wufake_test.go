@@ -0,0 +1,151 @@
+/*
+Copyright 2026 Zheng Dayu
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wufake
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ceshihao/windowsupdate"
+	"github.com/go-ole/go-ole"
+)
+
+var _ windowsupdate.Session = (*Session)(nil)
+
+func TestComError(t *testing.T) {
+	err := ComError(0x8024402C)
+	var oleErr *ole.OleError
+	if !errors.As(err, &oleErr) {
+		t.Fatalf("ComError returned %T, want *ole.OleError", err)
+	}
+	if oleErr.Code() != 0x8024402C {
+		t.Errorf("Code() = %#x, want 0x8024402C", oleErr.Code())
+	}
+	if !errors.Is(err, windowsupdate.ErrNoConnection) {
+		t.Errorf("errors.Is(%v, ErrNoConnection) = false, want true", err)
+	}
+}
+
+func TestDefaultMatch(t *testing.T) {
+	update := &windowsupdate.IUpdate{IsInstalled: true, IsHidden: false}
+	tests := []struct {
+		criteria string
+		want     bool
+	}{
+		{"", true},
+		{"IsInstalled=1", true},
+		{"IsInstalled=0", false},
+		{"IsInstalled=1 and IsHidden=0", true},
+		{"IsInstalled=1 and IsHidden=1", false},
+		{"IsInstalled=1 and Type='Software'", true},
+		{"IsInstalled = 0", false},
+	}
+	for _, tt := range tests {
+		if got := DefaultMatch(tt.criteria, update); got != tt.want {
+			t.Errorf("DefaultMatch(%q) = %v, want %v", tt.criteria, got, tt.want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	failed := Outcome{ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: -2145124318}
+	reboot := Outcome{ResultCode: windowsupdate.OperationResultCodeOrcSucceeded, RebootRequired: true}
+	tests := []struct {
+		name     string
+		outcomes []Outcome
+		want     Outcome
+	}{
+		{"all succeeded", []Outcome{Succeeded, reboot}, Outcome{ResultCode: windowsupdate.OperationResultCodeOrcSucceeded, RebootRequired: true}},
+		{"all failed", []Outcome{failed, failed}, Outcome{ResultCode: windowsupdate.OperationResultCodeOrcFailed, HResult: failed.HResult}},
+		{"mixed", []Outcome{Succeeded, failed}, Outcome{ResultCode: windowsupdate.OperationResultCodeOrcSucceededWithErrors}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aggregate(tt.outcomes); got != tt.want {
+				t.Errorf("aggregate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobTable_UnknownJob(t *testing.T) {
+	jobs := newJobTable[*windowsupdate.ISearchJob, *windowsupdate.ISearchResult]()
+	if _, err := jobs.end(&windowsupdate.ISearchJob{}); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("end() error = %v, want ErrUnknownJob", err)
+	}
+}
+
+func TestSession_PatchWorkflow(t *testing.T) {
+	catalog := &Catalog{
+		Updates: []*windowsupdate.IUpdate{NewUpdate("a"), NewUpdate("b")},
+		InstallOutcomes: map[string]Outcome{
+			"b": {ResultCode: windowsupdate.OperationResultCodeOrcSucceeded, RebootRequired: true},
+		},
+	}
+	var session windowsupdate.Session = NewSession(catalog)
+
+	searcher, err := session.CreateSearcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := searcher.Search("IsInstalled=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Updates) != 2 {
+		t.Fatalf("Search returned %d updates, want 2", len(result.Updates))
+	}
+
+	downloader, err := session.CreateDownloader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloader.Download(result.Updates); err != nil {
+		t.Fatal(err)
+	}
+
+	installer, err := session.CreateInstaller()
+	if err != nil {
+		t.Fatal(err)
+	}
+	installResult, err := installer.Install(result.Updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !installResult.RebootRequired {
+		t.Error("RebootRequired = false, want true")
+	}
+
+	result, err = searcher.Search("IsInstalled=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Updates) != 0 {
+		t.Errorf("Search after install returned %d updates, want 0", len(result.Updates))
+	}
+
+	var ops []string
+	for _, call := range catalog.Calls() {
+		ops = append(ops, call.Op)
+	}
+	want := []string{"Search", "Download", "Install", "Search"}
+	if len(ops) != len(want) {
+		t.Fatalf("Calls() = %v, want %v", ops, want)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Fatalf("Calls() = %v, want %v", ops, want)
+		}
+	}
+}